@@ -1,29 +1,155 @@
 package cmd
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/obonobo/esac/core/token/visitors"
+	"github.com/obonobo/esac/reporting"
 )
 
 const BUILD = "build"
 
-type BuildParams struct{}
+// Extension of the MOON assembly files produced by the build command
+const OUT_MOON = "m"
+
+var BUILD_USAGE = strings.TrimLeft(`
+usage: %v %v [-o output] [input files]
+
+%v compiles the input files to MOON assembly code. This command produces a
+file for every input file: 'myfile.m'
+
+Lexical, syntax, and semantic errors are printed to STDERR. No output is
+produced for a file that contains errors, and the command exits with a
+non-zero exit code.
+
+MOON has no floating point instructions, so floats are compiled to fixed point
+numbers with three decimal places. Their magnitude must stay below 2147483,
+products and quotients of operands larger than about 2147 overflow, and reading
+a float drops the digits past the third decimal.
+
+Flags:
+
+	-o, --output [outfile|-]
+		An alternative output location. If this flag is specified,
+		the file described above will not be created, only the specified
+		file will be created. Specify '-' to print the assembly code to
+		STDOUT. May only be used with a single input file.
+
+	-d, --outdir [outdir]
+		An alternative output location for the files. The default output
+		location is the current directory.
+
+`, "\n")
+
+type BuildParams struct {
+	LexParams
+}
 
 func buildCmd(config *Config) (usage func(), action func(args []string) int) {
 	buildCmd := flag.NewFlagSet(BUILD, flag.ExitOnError)
 	buildCmd.Usage = func() {
-		fmt.Printf(`The "%v" subcommand has not yet been implemented...`+"\n", BUILD)
+		fmt.Printf(
+			BUILD_USAGE,
+			path.Base(config.Command),
+			BUILD, strings.ToUpper(string(BUILD[0]))+BUILD[1:])
 	}
 
+	params := BuildParams{}
+	buildCmd.StringVar(&params.output, "o", "", "")
+	buildCmd.StringVar(&params.output, "output", "", "")
+	buildCmd.StringVar(&params.outdir, "d", "", "")
+	buildCmd.StringVar(&params.outdir, "outdir", "", "")
+
 	return buildCmd.Usage, func(args []string) int {
-		var params BuildParams
 		buildCmd.Parse(args)
-		fmt.Printf(`The '%v build' subcommand has not yet been implemented...`+"\n", config.Command)
-		fmt.Printf(`Run '%v help' for usage.`+"\n", config.Command)
+		params.inputFiles = buildCmd.Args()
+		params.outputMode = outputMode(params.output)
+		if exit := checkParams(config, params.LexParams, BUILD); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		params.outdir = outdir(params.outdir)
+		if params.outputMode == OUT_MODE_TOFILE && len(params.inputFiles) > 1 {
+			fmt.Fprintf(os.Stderr,
+				"flag '-o'/'--output' may only be used with a single input file\n")
+			return EXIT_CODE_NOT_OKAY
+		}
 		return Build(params)
 	}
 }
 
+// BUILD subcommand
 func Build(params BuildParams) (exit int) {
-	return 1
+	if exit := makeOutputDirIfNotExists(params.outdir); exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
+	chugged, exit := openAndChugFiles(params.inputFiles)
+	if exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
+	files := make([]string, 0, len(chugged))
+	for file := range chugged {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return chugged[files[i]].i < chugged[files[j]].i })
+
+	for _, file := range files {
+		rep := newReporter(file, chugged[file].src, os.Stderr)
+		ast, ok := analyze(chugged[file].src, rep)
+		if !ok {
+			exit = EXIT_CODE_NOT_OKAY
+			continue
+		}
+
+		code := new(bytes.Buffer)
+		ast.Root.Accept(visitors.NewCodeGenVisitor(code, func(e *visitors.VisitorError) {
+			rep.report(reporting.SemanticDiagnostic(e))
+			ok = false
+		}))
+		if !ok {
+			exit = EXIT_CODE_NOT_OKAY
+			continue
+		}
+
+		if e := writeCodeOutput(params.LexParams, file, OUT_MOON, code); e != EXIT_CODE_OKAY {
+			return e
+		}
+	}
+	return exit
+}
+
+// Writes the code generated for a file to the location specified by params, ext
+// is the extension of the file created in the normal output mode
+func writeCodeOutput(params LexParams, file, ext string, code io.Reader) (exit int) {
+	var out io.Writer
+	switch params.outputMode {
+	case OUT_MODE_STDOUT:
+		out = os.Stdout
+	default:
+		location := params.output
+		if params.outputMode == OUT_MODE_NORMAL {
+			location = path.Join(params.outdir, filename(file, ext))
+		}
+		fh, err := os.Create(location)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, failedToOpenFileError(err))
+			return EXIT_CODE_CANNOT_OPEN_OUTPUT_FILE
+		}
+		defer fh.Close()
+		out = fh
+	}
+
+	if _, err := io.Copy(out, code); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_CODE_NOT_OKAY
+	}
+	return EXIT_CODE_OKAY
 }
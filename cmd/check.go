@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/reporting"
+)
+
+const CHECK = "check"
+
+const (
+	OUT_SYMBOL_TABLES   = "outsymboltables"
+	OUT_SEMANTIC_ERRORS = "outsemanticerrors"
+)
+
+var CHECK_USAGE = strings.TrimLeft(`
+usage: %v %v [-o output] [--tables-format text|dot] [input files]
+
+%v parses the input files and performs semantic analysis on the AST. This
+command produces two files for every input file: 'myfile.outsymboltables', and
+'myfile.outsemanticerrors'.
+
+Lexical, syntax, and semantic errors are printed to STDERR. If any errors
+(warnings excluded) are found, the command exits with a non-zero exit code. No
+output is produced for a file that contains lexical or syntax errors.
+
+Flags:
+
+	-o, --output [outfile|-]
+		An alternative output location. If this flag is specified,
+		the files described above will not be created, only the specified
+		file will be created and it will contain the symbol tables of all
+		input files. Specify '-' to print the symbol tables to STDOUT.
+
+	-d, --outdir [outdir]
+		An alternative output location for the files. The default output
+		location is the current directory.
+
+	--error-format [text|json|sarif]
+		The format of the errors printed to STDERR. 'text' renders every
+		error with a snippet of the source, 'json' prints an array of
+		records, and 'sarif' prints a SARIF 2.1.0 log. The default is
+		'text'. '--format' is an alias, note that 'parse' uses '--format'
+		for the format of the AST instead.
+
+	--tables-format [text|dot]
+		The format of the symbol tables. 'text' draws every table as a
+		box, and 'dot' prints a Graphviz graph. The default is 'text'.
+
+`, "\n")
+
+// Symbol table formats, selected with --tables-format
+const (
+	TABLES_FORMAT_TEXT = "text"
+	TABLES_FORMAT_DOT  = "dot"
+)
+
+type CheckParams struct {
+	LexParams
+	tables string // One of the TABLES_FORMAT_* constants
+}
+
+func checkCmd(config *Config) (usage func(), action func(args []string) int) {
+	checkCmd := flag.NewFlagSet(CHECK, flag.ExitOnError)
+	checkCmd.Usage = func() {
+		fmt.Printf(
+			CHECK_USAGE,
+			path.Base(config.Command),
+			CHECK, strings.ToUpper(string(CHECK[0]))+CHECK[1:])
+	}
+
+	params := CheckParams{}
+	checkCmd.StringVar(&params.output, "o", "", "")
+	checkCmd.StringVar(&params.output, "output", "", "")
+	checkCmd.StringVar(&params.outdir, "d", "", "")
+	checkCmd.StringVar(&params.outdir, "outdir", "", "")
+	checkCmd.StringVar((*string)(&params.format), "error-format", string(reporting.FORMAT_TEXT), "")
+	checkCmd.StringVar((*string)(&params.format), "format", string(reporting.FORMAT_TEXT), "")
+	checkCmd.StringVar(&params.tables, "tables-format", TABLES_FORMAT_TEXT, "")
+
+	return checkCmd.Usage, func(args []string) int {
+		checkCmd.Parse(args)
+		params.inputFiles = checkCmd.Args()
+		params.outputMode = outputMode(params.output)
+		if exit := checkParams(config, params.LexParams, CHECK); exit != EXIT_CODE_OKAY {
+			if params.format == TABLES_FORMAT_DOT {
+				fmt.Fprintln(os.Stderr, ""+
+					"Hint: the format of the symbol tables is selected with "+
+					"'--tables-format=dot'")
+			}
+			return exit
+		}
+		if exit := checkTablesFormat(params.tables); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		params.outdir = outdir(params.outdir)
+		return Check(params)
+	}
+}
+
+// CHECK subcommand
+func Check(params CheckParams) (exit int) {
+	if exit := makeOutputDirIfNotExists(params.outdir); exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
+	chugged, exit := openAndChugFiles(params.inputFiles)
+	if exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
+	files := make([]string, 0, len(chugged))
+	for file := range chugged {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return chugged[files[i]].i < chugged[files[j]].i })
+
+	// In the -o modes, the symbol tables of all files are written to a single
+	// location
+	var out io.Writer
+	switch params.outputMode {
+	case OUT_MODE_STDOUT:
+		out = os.Stdout
+	case OUT_MODE_TOFILE:
+		fh, err := os.Create(params.output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, failedToOpenFileError(err))
+			return EXIT_CODE_CANNOT_OPEN_OUTPUT_FILE
+		}
+		defer fh.Close()
+		out = fh
+	}
+
+	emitter := reporting.NewEmitter(params.format, os.Stderr)
+	defer emitter.Flush()
+
+	for i, file := range files {
+		rep := emitTo(emitter, file, chugged[file].src)
+		ast, ok := reporting.ParseSource(chugged[file].src, rep.report)
+		if !ok {
+			reporting.CheckSemantics(ast, rep.report)
+			exit = EXIT_CODE_NOT_OKAY
+			continue
+		}
+
+		errs := new(bytes.Buffer)
+		rep.plain = errs
+		if !checkSemantics(ast, rep) {
+			exit = EXIT_CODE_NOT_OKAY
+		}
+
+		if out != nil {
+			if len(files) > 1 {
+				if i > 0 {
+					fmt.Fprintln(out)
+				}
+				if params.tables == TABLES_FORMAT_DOT {
+					fmt.Fprintf(out, "// %v:\n", file)
+				} else {
+					fmt.Fprintf(out, "%v:\n", file)
+				}
+			}
+			writeSymbolTables(out, ast.Root.Meta.SymbolTable, params.tables)
+			continue
+		}
+
+		tables := new(bytes.Buffer)
+		writeSymbolTables(tables, ast.Root.Meta.SymbolTable, params.tables)
+		if e := writeCheckOutput(params, file, tables, errs); e != EXIT_CODE_OKAY {
+			return e
+		}
+	}
+	return exit
+}
+
+func checkTablesFormat(format string) (exit int) {
+	switch format {
+	case TABLES_FORMAT_TEXT, TABLES_FORMAT_DOT:
+		return EXIT_CODE_OKAY
+	}
+	fmt.Fprintf(os.Stderr,
+		"Invalid symbol table format '%v', should be '%v', or '%v'\n",
+		format, TABLES_FORMAT_TEXT, TABLES_FORMAT_DOT)
+	return EXIT_CODE_NOT_OKAY
+}
+
+func writeSymbolTables(out io.Writer, table token.SymbolTable, format string) {
+	switch format {
+	case TABLES_FORMAT_DOT:
+		token.WriteSymbolTablesDot(out, table)
+	default:
+		token.WriteOutSymbolTables(out, table)
+	}
+}
+
+// Writes the '.outsymboltables' and '.outsemanticerrors' files for an input
+// file
+func writeCheckOutput(params CheckParams, file string, tables, errs io.Reader) (exit int) {
+	files, close, exit := openN(
+		path.Join(params.outdir, filename(file, OUT_SYMBOL_TABLES)),
+		path.Join(params.outdir, filename(file, OUT_SEMANTIC_ERRORS)))
+	if exit != EXIT_CODE_OKAY {
+		return exit
+	}
+	defer close()
+
+	for i, from := range []io.Reader{tables, errs} {
+		if _, err := io.Copy(files[i], from); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_CODE_NOT_OKAY
+		}
+	}
+	return EXIT_CODE_OKAY
+}
@@ -34,11 +34,23 @@ func Run(args []string) (exitCode int) {
 
 	lexUsage, lex := lexCmd(config)
 	parseUsage, parse := parseCmd(config)
+	checkUsage, check := checkCmd(config)
 	buildUsage, build := buildCmd(config)
+	irUsage, ir := irCmd(config)
+	runUsage, run := runCmd(config)
+	interpUsage, interp := interpCmd(config)
+	lspUsage, lsp := lspCmd(config)
+	fmtUsage, format := fmtCmd(config)
 	help := helpCmd(config, map[string]func(){
-		LEX:   lexUsage,
-		BUILD: buildUsage,
-		PARSE: parseUsage,
+		LEX:    lexUsage,
+		CHECK:  checkUsage,
+		BUILD:  buildUsage,
+		IR:     irUsage,
+		RUN:    runUsage,
+		INTERP: interpUsage,
+		LSP:    lspUsage,
+		FMT:    fmtUsage,
+		PARSE:  parseUsage,
 	})
 
 	config.Subcommand = args[1]
@@ -51,8 +63,20 @@ func Run(args []string) (exitCode int) {
 		return lex(rest)
 	case PARSE:
 		return parse(rest)
+	case CHECK:
+		return check(rest)
 	case BUILD:
 		return build(rest)
+	case IR:
+		return ir(rest)
+	case RUN:
+		return run(rest)
+	case INTERP:
+		return interp(rest)
+	case LSP:
+		return lsp(rest)
+	case FMT:
+		return format(rest)
 	default:
 		fmt.Println(unknownCommand(config.Command, config.Subcommand))
 		return 1
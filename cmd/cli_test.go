@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/obonobo/esac/core/moon"
+	"github.com/obonobo/esac/core/token"
 	"github.com/obonobo/esac/internal/testutils"
+	"github.com/obonobo/esac/reporting"
 )
 
 const CLI_OUTPUT_LEX_POSITIVE = `
@@ -105,96 +111,69 @@ const LEX_HELLOWORLD_SRC_TOKENS = `
 `
 
 const LEX_STRINGS_SRC_TOKENS = `
-[var, var, 1] [id, x, 1] [assign, =, 1] [id, this, 1] [id, is, 1] [id, not, 1] [id, valid, 1] [semi, ;, 1]
-`
-const LEX_STRINGS_SRC_ERRORS = `
-Lexical error: Invalid character: """: line 1.
-Lexical error: Invalid character: """: line 1.
+[var, var, 1] [id, x, 1] [assign, =, 1] [stringlit, "this is not valid", 1] [semi, ;, 1]
 `
+const LEX_STRINGS_SRC_ERRORS = ``
 
 const LEX_STRINGS_SRC_TOKENS_AND_ERRORS = `
-[var, var, 1] [id, x, 1] [assign, =, 1] [invalidchar, ", 1] [id, this, 1] [id, is, 1] [id, not, 1] [id, valid, 1] [invalidchar, ", 1] [semi, ;, 1]
+[var, var, 1] [id, x, 1] [assign, =, 1] [stringlit, "this is not valid", 1] [semi, ;, 1]
 `
 
 const LEX_SOMETHINGELSE_TOKENS = `
 [id, package, 1] [id, main, 1]
 [id, import, 3] [openpar, (, 3]
-[id, encoding, 4] [div, /, 4] [id, json, 4]
-[id, fmt, 5]
-[id, io, 6]
-[id, log, 7]
-[id, net, 8] [div, /, 8] [id, http, 8]
+[stringlit, "encoding/json", 4]
+[stringlit, "fmt", 5]
+[stringlit, "io", 6]
+[stringlit, "log", 7]
+[stringlit, "net/http", 8]
 [closepar, ), 9]
 [func, func, 11] [id, main, 11] [openpar, (, 11] [closepar, ), 11] [opencubr, {, 11]
-[id, resp, 12] [comma, ,, 12] [id, err, 12] [colon, :, 12] [assign, =, 12] [id, http, 12] [dot, ., 12] [id, Get, 12] [openpar, (, 12] [id, https, 12] [colon, :, 12] [inlinecmt, //www.google.com")\n, 12]
+[id, resp, 12] [comma, ,, 12] [id, err, 12] [colon, :, 12] [assign, =, 12] [id, http, 12] [dot, ., 12] [id, Get, 12] [openpar, (, 12] [stringlit, "https://www.google.com", 12] [closepar, ), 12]
 [if, if, 13] [id, err, 13] [not, !, 13] [assign, =, 13] [id, nil, 13] [opencubr, {, 13]
-[id, log, 14] [dot, ., 14] [id, Fatalf, 14] [openpar, (, 14] [id, Request, 14] [id, failed, 14] [colon, :, 14] [id, v, 14] [comma, ,, 14] [id, err, 14] [closepar, ), 14]
+[id, log, 14] [dot, ., 14] [id, Fatalf, 14] [openpar, (, 14] [stringlit, "Request failed: %v", 14] [comma, ,, 14] [id, err, 14] [closepar, ), 14]
 [closecubr, }, 15]
-[id, headers, 17] [comma, ,, 17] [id, err, 17] [colon, :, 17] [assign, =, 17] [id, json, 17] [dot, ., 17] [id, MarshalIndent, 17] [openpar, (, 17] [id, resp, 17] [dot, ., 17] [id, Header, 17] [comma, ,, 17] [comma, ,, 17] [closepar, ), 17]
+[id, headers, 17] [comma, ,, 17] [id, err, 17] [colon, :, 17] [assign, =, 17] [id, json, 17] [dot, ., 17] [id, MarshalIndent, 17] [openpar, (, 17] [id, resp, 17] [dot, ., 17] [id, Header, 17] [comma, ,, 17] [stringlit, "", 17] [comma, ,, 17] [stringlit, "    ", 17] [closepar, ), 17]
 [if, if, 18] [id, err, 18] [not, !, 18] [assign, =, 18] [id, nil, 18] [opencubr, {, 18]
-[id, log, 19] [dot, ., 19] [id, Fatalf, 19] [openpar, (, 19] [id, Failed, 19] [id, to, 19] [id, serialize, 19] [id, response, 19] [id, headers, 19] [colon, :, 19] [id, v, 19] [comma, ,, 19] [id, err, 19] [closepar, ), 19]
+[id, log, 19] [dot, ., 19] [id, Fatalf, 19] [openpar, (, 19] [stringlit, "Failed to serialize response headers: %v", 19] [comma, ,, 19] [id, err, 19] [closepar, ), 19]
 [closecubr, }, 20]
-[id, fmt, 21] [dot, ., 21] [id, Println, 21] [openpar, (, 21] [id, string, 21] [openpar, (, 21] [id, headers, 21] [closepar, ), 21] [closepar, ), 21]
+[id, fmt, 21] [dot, ., 21] [id, Println, 21] [openpar, (, 21] [string, string, 21] [openpar, (, 21] [id, headers, 21] [closepar, ), 21] [closepar, ), 21]
 [id, bod, 23] [comma, ,, 23] [id, err, 23] [colon, :, 23] [assign, =, 23] [id, io, 23] [dot, ., 23] [id, ReadAll, 23] [openpar, (, 23] [id, resp, 23] [dot, ., 23] [id, Body, 23] [closepar, ), 23]
 [if, if, 24] [id, err, 24] [not, !, 24] [assign, =, 24] [id, nil, 24] [opencubr, {, 24]
-[id, log, 25] [dot, ., 25] [id, Fatalf, 25] [openpar, (, 25] [id, Failed, 25] [id, to, 25] [read, read, 25] [id, body, 25] [closepar, ), 25]
+[id, log, 25] [dot, ., 25] [id, Fatalf, 25] [openpar, (, 25] [stringlit, "Failed to read body", 25] [closepar, ), 25]
 [closecubr, }, 26]
 [id, defer, 27] [id, resp, 27] [dot, ., 27] [id, Body, 27] [dot, ., 27] [id, Close, 27] [openpar, (, 27] [closepar, ), 27]
-[id, fmt, 29] [dot, ., 29] [id, Println, 29] [openpar, (, 29] [id, string, 29] [openpar, (, 29] [id, bod, 29] [closepar, ), 29] [closepar, ), 29]
+[id, fmt, 29] [dot, ., 29] [id, Println, 29] [openpar, (, 29] [string, string, 29] [openpar, (, 29] [id, bod, 29] [closepar, ), 29] [closepar, ), 29]
 [closecubr, }, 30]
 `
 
-const LEX_SOMETHINGELSE_ERRORS = `
-Lexical error: Invalid character: """: line 4.
-Lexical error: Invalid character: """: line 4.
-Lexical error: Invalid character: """: line 5.
-Lexical error: Invalid character: """: line 5.
-Lexical error: Invalid character: """: line 6.
-Lexical error: Invalid character: """: line 6.
-Lexical error: Invalid character: """: line 7.
-Lexical error: Invalid character: """: line 7.
-Lexical error: Invalid character: """: line 8.
-Lexical error: Invalid character: """: line 8.
-Lexical error: Invalid character: """: line 12.
-Lexical error: Invalid character: """: line 14.
-Lexical error: Invalid character: "%": line 14.
-Lexical error: Invalid character: """: line 14.
-Lexical error: Invalid character: """: line 17.
-Lexical error: Invalid character: """: line 17.
-Lexical error: Invalid character: """: line 17.
-Lexical error: Invalid character: """: line 17.
-Lexical error: Invalid character: """: line 19.
-Lexical error: Invalid character: "%": line 19.
-Lexical error: Invalid character: """: line 19.
-Lexical error: Invalid character: """: line 25.
-Lexical error: Invalid character: """: line 25.
-`
+const LEX_SOMETHINGELSE_ERRORS = ``
 
 const LEX_SOMETHINGELSE_TOKENS_AND_ERRORS = `
 [id, package, 1] [id, main, 1]
 [id, import, 3] [openpar, (, 3]
-[invalidchar, ", 4] [id, encoding, 4] [div, /, 4] [id, json, 4] [invalidchar, ", 4]
-[invalidchar, ", 5] [id, fmt, 5] [invalidchar, ", 5]
-[invalidchar, ", 6] [id, io, 6] [invalidchar, ", 6]
-[invalidchar, ", 7] [id, log, 7] [invalidchar, ", 7]
-[invalidchar, ", 8] [id, net, 8] [div, /, 8] [id, http, 8] [invalidchar, ", 8]
+[stringlit, "encoding/json", 4]
+[stringlit, "fmt", 5]
+[stringlit, "io", 6]
+[stringlit, "log", 7]
+[stringlit, "net/http", 8]
 [closepar, ), 9]
 [func, func, 11] [id, main, 11] [openpar, (, 11] [closepar, ), 11] [opencubr, {, 11]
-[id, resp, 12] [comma, ,, 12] [id, err, 12] [colon, :, 12] [assign, =, 12] [id, http, 12] [dot, ., 12] [id, Get, 12] [openpar, (, 12] [invalidchar, ", 12] [id, https, 12] [colon, :, 12] [inlinecmt, //www.google.com")\n, 12]
+[id, resp, 12] [comma, ,, 12] [id, err, 12] [colon, :, 12] [assign, =, 12] [id, http, 12] [dot, ., 12] [id, Get, 12] [openpar, (, 12] [stringlit, "https://www.google.com", 12] [closepar, ), 12]
 [if, if, 13] [id, err, 13] [not, !, 13] [assign, =, 13] [id, nil, 13] [opencubr, {, 13]
-[id, log, 14] [dot, ., 14] [id, Fatalf, 14] [openpar, (, 14] [invalidchar, ", 14] [id, Request, 14] [id, failed, 14] [colon, :, 14] [invalidchar, %, 14] [id, v, 14] [invalidchar, ", 14] [comma, ,, 14] [id, err, 14] [closepar, ), 14]
+[id, log, 14] [dot, ., 14] [id, Fatalf, 14] [openpar, (, 14] [stringlit, "Request failed: %v", 14] [comma, ,, 14] [id, err, 14] [closepar, ), 14]
 [closecubr, }, 15]
-[id, headers, 17] [comma, ,, 17] [id, err, 17] [colon, :, 17] [assign, =, 17] [id, json, 17] [dot, ., 17] [id, MarshalIndent, 17] [openpar, (, 17] [id, resp, 17] [dot, ., 17] [id, Header, 17] [comma, ,, 17] [invalidchar, ", 17] [invalidchar, ", 17] [comma, ,, 17] [invalidchar, ", 17] [invalidchar, ", 17] [closepar, ), 17]
+[id, headers, 17] [comma, ,, 17] [id, err, 17] [colon, :, 17] [assign, =, 17] [id, json, 17] [dot, ., 17] [id, MarshalIndent, 17] [openpar, (, 17] [id, resp, 17] [dot, ., 17] [id, Header, 17] [comma, ,, 17] [stringlit, "", 17] [comma, ,, 17] [stringlit, "    ", 17] [closepar, ), 17]
 [if, if, 18] [id, err, 18] [not, !, 18] [assign, =, 18] [id, nil, 18] [opencubr, {, 18]
-[id, log, 19] [dot, ., 19] [id, Fatalf, 19] [openpar, (, 19] [invalidchar, ", 19] [id, Failed, 19] [id, to, 19] [id, serialize, 19] [id, response, 19] [id, headers, 19] [colon, :, 19] [invalidchar, %, 19] [id, v, 19] [invalidchar, ", 19] [comma, ,, 19] [id, err, 19] [closepar, ), 19]
+[id, log, 19] [dot, ., 19] [id, Fatalf, 19] [openpar, (, 19] [stringlit, "Failed to serialize response headers: %v", 19] [comma, ,, 19] [id, err, 19] [closepar, ), 19]
 [closecubr, }, 20]
-[id, fmt, 21] [dot, ., 21] [id, Println, 21] [openpar, (, 21] [id, string, 21] [openpar, (, 21] [id, headers, 21] [closepar, ), 21] [closepar, ), 21]
+[id, fmt, 21] [dot, ., 21] [id, Println, 21] [openpar, (, 21] [string, string, 21] [openpar, (, 21] [id, headers, 21] [closepar, ), 21] [closepar, ), 21]
 [id, bod, 23] [comma, ,, 23] [id, err, 23] [colon, :, 23] [assign, =, 23] [id, io, 23] [dot, ., 23] [id, ReadAll, 23] [openpar, (, 23] [id, resp, 23] [dot, ., 23] [id, Body, 23] [closepar, ), 23]
 [if, if, 24] [id, err, 24] [not, !, 24] [assign, =, 24] [id, nil, 24] [opencubr, {, 24]
-[id, log, 25] [dot, ., 25] [id, Fatalf, 25] [openpar, (, 25] [invalidchar, ", 25] [id, Failed, 25] [id, to, 25] [read, read, 25] [id, body, 25] [invalidchar, ", 25] [closepar, ), 25]
+[id, log, 25] [dot, ., 25] [id, Fatalf, 25] [openpar, (, 25] [stringlit, "Failed to read body", 25] [closepar, ), 25]
 [closecubr, }, 26]
 [id, defer, 27] [id, resp, 27] [dot, ., 27] [id, Body, 27] [dot, ., 27] [id, Close, 27] [openpar, (, 27] [closepar, ), 27]
-[id, fmt, 29] [dot, ., 29] [id, Println, 29] [openpar, (, 29] [id, string, 29] [openpar, (, 29] [id, bod, 29] [closepar, ), 29] [closepar, ), 29]
+[id, fmt, 29] [dot, ., 29] [id, Println, 29] [openpar, (, 29] [string, string, 29] [openpar, (, 29] [id, bod, 29] [closepar, ), 29] [closepar, ), 29]
 [closecubr, }, 30]
 `
 
@@ -296,6 +275,30 @@ func TestLexHelloWorldCli(t *testing.T) {
 		LEX_HELLOWORLD_SRC_TOKENS)
 }
 
+func TestLexStreamStdin(t *testing.T) {
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(w, strings.Trim(testutils.LEX_HELLOWORLD_SRC, "\r\t\n"))
+	w.Close()
+	defer func(old *os.File) { os.Stdin = old }(os.Stdin)
+	os.Stdin = stdin
+
+	output := mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "lex", "--stream", "-o", "-", STDIN_FILE})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("Expected command to succeed, but got exit code '%v': %v", exit, data)
+	}
+
+	tailed := strings.Trim(testutils.Tail(data, -2), "\r\t\n\x00")
+	expected := strings.Trim(LEX_HELLOWORLD_SRC_TOKENS, "\t\r\n\x00")
+	if tailed != expected {
+		t.Fatalf("Expected output '%v' but got '%v'", expected, tailed)
+	}
+}
+
 func TestLexNegativeGradingNormalOutput(t *testing.T) {
 	assertCliNormal(t,
 		"TestLexPositiveGradingNormalOutput",
@@ -429,3 +432,542 @@ func mockStdoutStderr(t *testing.T) (output func() string) {
 	}
 	return output
 }
+
+func TestBuildBubbleSort(t *testing.T) {
+	tmp, rm := createTempFile(t, "tmp-build-bubblesort*.src", testutils.BUBBLESORT_SRC_2)
+	out := filename(tmp.Name(), OUT_MOON)
+	defer func() {
+		rm()
+		os.Remove(out)
+	}()
+
+	if exit := Run([]string{"esacc", "build", tmp.Name()}); exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v'", exit)
+	}
+
+	code := readFile(t, out)
+	for _, expected := range []string{"entry", "f_main", "f_bubbleSort", "f_printarray", "putint"} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected file '%v' to contain '%v'", out, expected)
+		}
+	}
+}
+
+func TestBuildPolynomial(t *testing.T) {
+	tmp, rm := createTempFile(t, "tmp-build-polynomial*.src", testutils.POLYNOMIAL_SRC_2)
+	out := filename(tmp.Name(), OUT_MOON)
+	defer func() {
+		rm()
+		os.Remove(out)
+	}()
+
+	if exit := Run([]string{"esacc", "build", tmp.Name()}); exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v'", exit)
+	}
+
+	code := readFile(t, out)
+	for _, expected := range []string{"f_LINEAR_evaluate", "f_QUADRATIC_build", "putfloat"} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected file '%v' to contain '%v'", out, expected)
+		}
+	}
+}
+
+func TestBuildSemanticErrorsFail(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-build-semerrors*.src", `
+	func main() -> void {
+		write(x);
+	}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "build", "-o", "-", tmp.Name()})
+	data := output()
+	if exit == EXIT_CODE_OKAY {
+		t.Fatalf("Expected command to fail, but got exit code '%v'", exit)
+	}
+	expected := "typecheck: id x was not found within the current scope (line 3)"
+	if !strings.Contains(data, expected) {
+		t.Errorf("Expected output to contain '%v' but got '%v'", expected, data)
+	}
+}
+
+func TestCheckBubbleSort(t *testing.T) {
+	tmp, rm := createTempFile(t, "tmp-check-bubblesort*.src", testutils.BUBBLESORT_SRC_2)
+	tables := filename(tmp.Name(), OUT_SYMBOL_TABLES)
+	errs := filename(tmp.Name(), OUT_SEMANTIC_ERRORS)
+	defer func() {
+		rm()
+		os.Remove(tables)
+		os.Remove(errs)
+	}()
+
+	if exit := Run([]string{"esacc", "check", tmp.Name()}); exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v'", exit)
+	}
+
+	data := readFile(t, tables)
+	for _, expected := range []string{
+		"| table: global ",
+		"| function | bubbleSort | (integer[],integer):void | 112 |",
+		"| table: ::bubbleSort ",
+		"| param   | arr  | integer[] | 4 | 4   |",
+		"| local   | arr  | integer[7] | 28 | 4  |",
+	} {
+		if !strings.Contains(data, expected) {
+			t.Errorf("Expected file '%v' to contain '%v' but got:\n%v", tables, expected, data)
+		}
+	}
+	if data := readFile(t, errs); data != "" {
+		t.Errorf("Expected file '%v' to be empty but got '%v'", errs, data)
+	}
+}
+
+func TestCheckSemanticErrorsFail(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-check-semerrors*.src", `
+	func main() -> void {
+		write(x);
+	}
+	`)
+	tables := filename(tmp.Name(), OUT_SYMBOL_TABLES)
+	errs := filename(tmp.Name(), OUT_SEMANTIC_ERRORS)
+	defer func() {
+		rm()
+		os.Remove(tables)
+		os.Remove(errs)
+	}()
+
+	exit := Run([]string{"esacc", "check", tmp.Name()})
+	data := output()
+	if exit == EXIT_CODE_OKAY {
+		t.Fatalf("Expected command to fail, but got exit code '%v'", exit)
+	}
+
+	expected := "typecheck: id x was not found within the current scope (line 3)"
+	if !strings.Contains(data, expected) {
+		t.Errorf("Expected output to contain '%v' but got '%v'", expected, data)
+	}
+	for _, rendered := range []string{"error[E0302]: " + expected, "3 |         write(x);", "  |               ^"} {
+		if !strings.Contains(data, rendered) {
+			t.Errorf("Expected output to contain '%v' but got '%v'", rendered, data)
+		}
+	}
+	if data := readFile(t, errs); strings.TrimSpace(data) != expected {
+		t.Errorf("Expected file '%v' to contain '%v' but got '%v'", errs, expected, data)
+	}
+	if data := readFile(t, tables); !strings.Contains(data, "| table: ::main ") {
+		t.Errorf("Expected file '%v' to contain the table for 'main' but got '%v'", tables, data)
+	}
+}
+
+func TestCheckFormatJSON(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-check-json*.src", "func main() -> void {\n\twrite(x);\n}")
+	defer rm()
+
+	exit := Run([]string{"esacc", "check", "-o", "-", "--format=json", tmp.Name()})
+	data := output()
+	if exit == EXIT_CODE_OKAY {
+		t.Fatalf("Expected command to fail, but got exit code '%v'", exit)
+	}
+
+	// The JSON document follows the symbol tables
+	var records []reporting.Record
+	if err := json.Unmarshal([]byte(data[strings.Index(data, "\n[")+1:]), &records); err != nil {
+		t.Fatalf("Expected output to end with a JSON array but got %v: '%v'", err, data)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record but got %+v", records)
+	}
+	r := records[0]
+	if r.File != tmp.Name() || r.Line != 2 || r.Column != 8 || r.Code != reporting.CODE_UNDEFINED_ID {
+		t.Errorf("Expected an undefined id error at %v:2:8 but got %+v", tmp.Name(), r)
+	}
+}
+
+func TestCheckWarningsDoNotFail(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-check-warnings*.src", `
+	func f(x: integer) -> void {}
+	func f(x: integer, y: integer) -> void {}
+	func main() -> void {}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "check", "-o", "-", tmp.Name()})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v':\n%v", exit, data)
+	}
+	expected := "'Global::f' has been overloaded 2 times"
+	if !strings.Contains(data, expected) {
+		t.Errorf("Expected output to contain '%v' but got '%v'", expected, data)
+	}
+}
+
+func TestIRBubbleSort(t *testing.T) {
+	tmp, rm := createTempFile(t, "tmp-ir-bubblesort*.src", testutils.BUBBLESORT_SRC_2)
+	out := filename(tmp.Name(), OUT_IR)
+	defer func() {
+		rm()
+		os.Remove(out)
+	}()
+
+	if exit := Run([]string{"esacc", "ir", tmp.Name()}); exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v'", exit)
+	}
+
+	code := readFile(t, out)
+	for _, expected := range []string{
+		"function main (frame",
+		"function bubbleSort (frame",
+		"arg printarray.arr = ",
+		"call printarray",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected file '%v' to contain '%v'", out, expected)
+		}
+	}
+}
+
+func TestIRRunFloats(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-ir-floats*.src", `
+	func main() -> void {
+		let x: float;
+		x = 1.5 * 3.0;
+		write(x);
+	}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "ir", "--run", tmp.Name()})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v': %v", exit, data)
+	}
+	if data != "4.5\n" {
+		t.Errorf("Expected output '4.5' but got '%v'", data)
+	}
+}
+
+func TestRunProgram(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-run*.src", `
+	func square(x: integer) -> integer { return (x * x); }
+	func main() -> void {
+		write(square(12));
+	}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "run", tmp.Name()})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v': %v", exit, data)
+	}
+	if data != "144\n" {
+		t.Errorf("Expected output '144' but got '%v'", data)
+	}
+}
+
+func TestInterpProgram(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-interp*.src", `
+	struct COUNTER { public let n: integer; public func inc() -> void; };
+	impl COUNTER { func inc() -> void { n = n + 1; } }
+	func main() -> void {
+		let c: COUNTER;
+		c.inc();
+		c.inc();
+		write(c.n);
+	}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "interp", tmp.Name()})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v': %v", exit, data)
+	}
+	if data != "2\n" {
+		t.Errorf("Expected output '2' but got '%v'", data)
+	}
+}
+
+func TestInterpRuntimeError(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-interp*.src", `
+	func main() -> void {
+		let a: integer[3];
+		write(a[3]);
+	}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "interp", tmp.Name()})
+	data := output()
+	if exit == EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned a non-zero exit code: %v", data)
+	}
+	if !strings.Contains(data, "out of bounds") {
+		t.Errorf("Expected an out of bounds error but got '%v'", data)
+	}
+}
+
+func TestLsp(t *testing.T) {
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(w, "Content-Length: %v\r\n\r\n%v", len(message), message)
+	}
+	w.Close()
+	defer func(old *os.File) { os.Stdin = old }(os.Stdin)
+	os.Stdin = stdin
+
+	output := mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "lsp"})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v': %v", exit, data)
+	}
+	if !strings.Contains(data, `"definitionProvider":true`) {
+		t.Errorf("Expected the server capabilities to be advertised but got '%v'", data)
+	}
+}
+
+func TestRunAssembly(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-run*.m", `
+        entry
+        addi    r1, r0, 79
+        putc    r1
+        addi    r1, r0, 75
+        putc    r1
+        hlt
+`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "run", tmp.Name()})
+	data := output()
+	if exit != EXIT_CODE_OKAY {
+		t.Fatalf("CLI should have returned '0' exit code but got code '%v': %v", exit, data)
+	}
+	if data != "OK" {
+		t.Errorf("Expected output 'OK' but got '%v'", data)
+	}
+}
+
+func TestRunStepLimit(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-run-loop*.src", `
+	func main() -> void {
+		while (1 == 1) { };
+	}
+	`)
+	defer rm()
+
+	exit := Run([]string{"esacc", "run", "-s", "1000", tmp.Name()})
+	data := output()
+	if exit == EXIT_CODE_OKAY {
+		t.Fatalf("Expected command to fail, but got exit code '%v'", exit)
+	}
+	if !strings.Contains(data, moon.ErrStepLimitExceeded.Error()) {
+		t.Errorf("Expected a step limit error but got '%v'", data)
+	}
+}
+
+func TestFmtDiffAndWrite(t *testing.T) {
+	output := mockStdoutStderr(t)
+	tmp, rm := createTempFile(t, "tmp-fmt*.src", "func main()->void{\n// comment\nwrite(1);}\n")
+	defer rm()
+
+	exit := Run([]string{"esacc", "fmt", "-d", tmp.Name()})
+	if data := output(); exit != EXIT_CODE_OKAY || !strings.Contains(data, "+\twrite(1);") {
+		t.Fatalf("Expected a diff and a '0' exit code but got code '%v': %v", exit, data)
+	}
+
+	output = mockStdoutStderr(t)
+	exit = Run([]string{"esacc", "fmt", "-w", tmp.Name()})
+	if data := output(); exit != EXIT_CODE_OKAY || data != "" {
+		t.Fatalf("Expected no output and a '0' exit code but got code '%v': %v", exit, data)
+	}
+	expected := "func main() -> void {\n\t// comment\n\twrite(1);\n}\n"
+	if contents, _ := os.ReadFile(tmp.Name()); string(contents) != expected {
+		t.Errorf("Expected the file to be rewritten as:\n%v\nbut got:\n%v", expected, string(contents))
+	}
+}
+
+func TestParseGrammar(t *testing.T) {
+	src, rmSrc := createTempFile(t, "tmp-parse-grammar*.src", "func main() -> void {\n\twrite(1 + 2);\n}\n")
+	defer rmSrc()
+
+	// This grammar only accepts subtraction
+	grm, rmGrm := createTempFile(t, "tmp-parse-grammar*.grm", `
+	<START> ::= 'func' 'id' '(' ')' '->' 'void' '{' <stat> '}'
+	<stat> ::= 'write' '(' 'intNum' '-' 'intNum' ')' ';'
+	`)
+	defer rmGrm()
+
+	output := mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "parse", "-o", "-", src.Name()})
+	if data := output(); exit != EXIT_CODE_OKAY || strings.Contains(data, "error") {
+		t.Fatalf("Expected the compiled-in grammar to accept the program but got code '%v': %v", exit, data)
+	}
+
+	output = mockStdoutStderr(t)
+	Run([]string{"esacc", "parse", "--grammar", grm.Name(), "-o", "-", src.Name()})
+	expected := "unexpected token 'plus', should be 'minus'"
+	if data := output(); !strings.Contains(data, expected) {
+		t.Errorf("Expected output to contain '%v' but got '%v'", expected, data)
+	}
+}
+
+func TestParseGrammarConflict(t *testing.T) {
+	grm, rm := createTempFile(t, "tmp-parse-grammar-conflict*.grm", `
+	<START> ::= 'id' <rest>
+	<rest> ::= ';'
+	<rest> ::= ';' ';'
+	`)
+	defer rm()
+
+	output := mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "parse", "--grammar", grm.Name(), "-o", "-", grm.Name()})
+	data := output()
+	if exit == EXIT_CODE_OKAY {
+		t.Fatalf("Expected command to fail, but got exit code '%v'", exit)
+	}
+	expected := "LL(1) conflict(s): <rest> on 'semi'"
+	if !strings.Contains(data, expected) {
+		t.Errorf("Expected output to contain '%v' but got '%v'", expected, data)
+	}
+}
+
+func TestParseLALR(t *testing.T) {
+	src, rm := createTempFile(t, "tmp-parse-lalr*.src", testutils.POLYNOMIAL_SRC)
+	defer rm()
+
+	output := mockStdoutStderr(t)
+	Run([]string{"esacc", "parse", "-o", "-", src.Name()})
+	expected := output()
+
+	output = mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "parse", "--parser", "lalr", "-o", "-", src.Name()})
+	if actual := output(); exit != EXIT_CODE_OKAY || actual != expected {
+		t.Errorf("Expected the LALR parser to print the same AST as the LL parser "+
+			"but got code '%v':\n%v\ninstead of:\n%v", exit, actual, expected)
+	}
+
+	output = mockStdoutStderr(t)
+	exit = Run([]string{"esacc", "parse", "--parser", "lr", "-o", "-", src.Name()})
+	if data := output(); exit == EXIT_CODE_OKAY || !strings.Contains(data, "Invalid parser 'lr'") {
+		t.Errorf("Expected an invalid parser error but got code '%v': %v", exit, data)
+	}
+}
+
+// The JSON and s-expression ASTs load back into the AST that is printed as a
+// tree
+func TestParseASTFormats(t *testing.T) {
+	src, rm := createTempFile(t, "tmp-parse-formats*.src", testutils.POLYNOMIAL_SRC)
+	defer rm()
+
+	output := mockStdoutStderr(t)
+	Run([]string{"esacc", "parse", "-o", "-", src.Name()})
+	expected := output()
+
+	for format, decode := range map[string]func(r io.Reader) (token.AST, error){
+		AST_FORMAT_JSON:  token.DecodeASTJson,
+		AST_FORMAT_SEXPR: token.DecodeASTSexpr,
+	} {
+		output := mockStdoutStderr(t)
+		exit := Run([]string{"esacc", "parse", "--format=" + format, "-o", "-", src.Name()})
+		data := output()
+		if exit != EXIT_CODE_OKAY {
+			t.Fatalf("Expected --format=%v to succeed but got code '%v': %v", format, exit, data)
+		}
+		ast, err := decode(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to load the AST printed with --format=%v: %v", format, err)
+		}
+		if actual := ast.TreeString(); actual != expected {
+			t.Errorf("Expected the AST printed with --format=%v to load back as:\n%v\nbut got:\n%v",
+				format, expected, actual)
+		}
+	}
+
+	output = mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "parse", "--format=xml", "-o", "-", src.Name()})
+	if data := output(); exit == EXIT_CODE_OKAY || !strings.Contains(data, "Invalid AST format 'xml'") ||
+		strings.Contains(data, "Hint:") {
+		t.Errorf("Expected an invalid AST format error but got code '%v': %v", exit, data)
+	}
+
+	// The format of the errors was meant
+	output = mockStdoutStderr(t)
+	exit = Run([]string{"esacc", "parse", "--format=sarif", "-o", "-", src.Name()})
+	if data := output(); exit == EXIT_CODE_OKAY || !strings.Contains(data, "'--error-format=sarif'") {
+		t.Errorf("Expected a hint to use --error-format but got code '%v': %v", exit, data)
+	}
+}
+
+// The format of the errors is selected with --error-format on every command,
+// lex and check also accept --format
+func TestErrorFormat(t *testing.T) {
+	src, rm := createTempFile(t, "tmp-error-format*.src", "func main() -> void {\n\twrite(01);\n}")
+	defer rm()
+
+	for _, args := range [][]string{
+		{"lex", "--error-format=json"},
+		{"lex", "--format=json"},
+		{"parse", "--error-format=json"},
+		{"check", "--error-format=json"},
+		{"check", "--format=json"},
+	} {
+		output := mockStdoutStderr(t)
+		exit := Run(append(append([]string{"esacc"}, args...), "-o", "-", src.Name()))
+		data := output()
+		var records []reporting.Record
+		if err := json.Unmarshal([]byte(data[strings.LastIndex(data, "\n[")+1:]), &records); err != nil {
+			t.Errorf("Expected %v to print a JSON array but got code '%v' (%v): %v", args, exit, err, data)
+			continue
+		}
+		if len(records) == 0 || records[0].Code != reporting.CODE_INVALID_NUM {
+			t.Errorf("Expected %v to report an invalid number first but got %+v", args, records)
+		}
+	}
+}
+
+func TestParseAndCheckDot(t *testing.T) {
+	src, rm := createTempFile(t, "tmp-dot*.src", "func main() -> void {\n\tlet x: integer;\n}")
+	defer rm()
+
+	output := mockStdoutStderr(t)
+	exit := Run([]string{"esacc", "parse", "--format=dot", "-o", "-", src.Name()})
+	if data := output(); exit != EXIT_CODE_OKAY ||
+		!strings.HasPrefix(data, "digraph AST {") || !strings.Contains(data, `[label="Id\n'x' 2:6"];`) {
+		t.Errorf("Expected the AST as a DOT graph but got code '%v': %v", exit, data)
+	}
+
+	output = mockStdoutStderr(t)
+	exit = Run([]string{"esacc", "check", "--tables-format=dot", "-o", "-", src.Name()})
+	if data := output(); exit != EXIT_CODE_OKAY ||
+		!strings.HasPrefix(data, "digraph SymbolTables {") ||
+		!strings.Contains(data, `[label="table: main()\nlocal x: integer (line 2)\l"];`) {
+		t.Errorf("Expected the symbol tables as a DOT graph but got code '%v': %v", exit, data)
+	}
+
+	// The format of the errors is not a format of the symbol tables
+	output = mockStdoutStderr(t)
+	exit = Run([]string{"esacc", "check", "--format=dot", "-o", "-", src.Name()})
+	if data := output(); exit == EXIT_CODE_OKAY || !strings.Contains(data, "'--tables-format=dot'") {
+		t.Errorf("Expected --format=dot to be rejected but got code '%v': %v", exit, data)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/obonobo/esac/core/formatter"
+	"github.com/obonobo/esac/reporting"
+)
+
+const FMT = "fmt"
+
+var FMT_USAGE = strings.TrimLeft(`
+usage: %v %v [-w] [-d] [input files]
+
+%v reformats the input files in the canonical style. By default, the formatted
+source of every input file is printed to STDOUT.
+
+Comments are kept, and attached to the nearest declaration or statement. Files
+that contain lexical or syntax errors cannot be formatted, their errors are
+printed to STDERR and the command exits with a non-zero exit code.
+
+Flags:
+
+	-w, --write
+		Rewrite the input files in place instead of printing them.
+
+	-d, --diff
+		Print a diff between each input file and its formatted source
+		instead of printing the formatted source. Files that are already
+		formatted produce no output.
+
+`, "\n")
+
+type FmtParams struct {
+	LexParams
+	write bool
+	diff  bool
+}
+
+func fmtCmd(config *Config) (usage func(), action func(args []string) int) {
+	fmtCmd := flag.NewFlagSet(FMT, flag.ExitOnError)
+	fmtCmd.Usage = func() {
+		fmt.Printf(
+			FMT_USAGE,
+			path.Base(config.Command),
+			FMT, strings.ToUpper(string(FMT[0]))+FMT[1:])
+	}
+
+	params := FmtParams{}
+	fmtCmd.BoolVar(&params.write, "w", false, "")
+	fmtCmd.BoolVar(&params.write, "write", false, "")
+	fmtCmd.BoolVar(&params.diff, "d", false, "")
+	fmtCmd.BoolVar(&params.diff, "diff", false, "")
+
+	return fmtCmd.Usage, func(args []string) int {
+		fmtCmd.Parse(args)
+		params.inputFiles = fmtCmd.Args()
+		if exit := checkParams(config, params.LexParams, FMT); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		return Fmt(params)
+	}
+}
+
+// FMT subcommand
+func Fmt(params FmtParams) (exit int) {
+	for _, file := range params.inputFiles {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, failedToOpenFileError(err))
+			return EXIT_CODE_NOT_OKAY
+		}
+
+		formatted, err := formatter.Format(src)
+		if err != nil {
+			var syntax *formatter.SyntaxError
+			if errors.As(err, &syntax) {
+				fmt.Fprintf(os.Stderr, "%v: cannot format a file that contains errors\n", file)
+				reporting.NewRenderer(file, src).RenderAll(os.Stderr, syntax.Diagnostics)
+			} else {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			exit = EXIT_CODE_NOT_OKAY
+			continue
+		}
+
+		if params.diff {
+			os.Stdout.Write(formatter.Diff(file, src, formatted))
+		}
+		if params.write {
+			if err := writeInPlace(file, formatted); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return EXIT_CODE_CANNOT_OPEN_OUTPUT_FILE
+			}
+		}
+		if !params.diff && !params.write {
+			os.Stdout.Write(formatted)
+		}
+	}
+	return exit
+}
+
+// Rewrites a file, keeping its permissions
+func writeInPlace(file string, contents []byte) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, contents, info.Mode().Perm())
+}
@@ -18,7 +18,13 @@ The commands are:
 
 	lex	scan input files, convert them to tokens
 	parse	parses token stream, converts it to AST
-	build	compile code
+	check	performs semantic analysis, outputs symbol tables
+	build	compiles source files to MOON assembly
+	ir	lowers source files to three-address code
+	run	compiles and executes a source file on the MOON machine
+	interp	executes a source file by walking its AST
+	lsp	runs a language server over STDIN and STDOUT
+	fmt	formats source files in the canonical style
 
 Use "%v help <command>" for more information about a command.
 `
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/obonobo/esac/core/interp"
+)
+
+const INTERP = "interp"
+
+var INTERP_USAGE = strings.TrimLeft(`
+usage: %v %v [-s steps] [input file]
+
+%v analyzes the input file and executes it by walking its AST, without
+generating any code. The program reads from STDIN and writes to STDOUT.
+
+Lexical, syntax, semantic, and runtime errors are printed to STDERR. If any
+errors are found, the command exits with a non-zero exit code.
+
+Flags:
+
+	-s, --steps [steps]
+		The maximum number of statements that the program may execute
+		before it is killed. Specify a negative number for no limit.
+
+`, "\n")
+
+type InterpParams struct {
+	LexParams
+	steps int
+}
+
+func interpCmd(config *Config) (usage func(), action func(args []string) int) {
+	interpCmd := flag.NewFlagSet(INTERP, flag.ExitOnError)
+	interpCmd.Usage = func() {
+		fmt.Printf(
+			INTERP_USAGE,
+			path.Base(config.Command),
+			INTERP, strings.ToUpper(string(INTERP[0]))+INTERP[1:])
+	}
+
+	params := InterpParams{}
+	interpCmd.IntVar(&params.steps, "s", interp.DEFAULT_MAX_STEPS, "")
+	interpCmd.IntVar(&params.steps, "steps", interp.DEFAULT_MAX_STEPS, "")
+
+	return interpCmd.Usage, func(args []string) int {
+		interpCmd.Parse(args)
+		params.inputFiles = interpCmd.Args()
+		if exit := checkParams(config, params.LexParams, INTERP); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		if len(params.inputFiles) > 1 {
+			fmt.Fprintf(os.Stderr, "%v accepts a single input file\n", INTERP)
+			return EXIT_CODE_NOT_OKAY
+		}
+		return Interp(params)
+	}
+}
+
+// INTERP subcommand
+func Interp(params InterpParams) int {
+	file := params.inputFiles[0]
+	chugged, exit := openAndChugFiles(params.inputFiles)
+	if exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
+	rep := newReporter(file, chugged[file].src, os.Stderr)
+	ast, ok := analyze(chugged[file].src, rep)
+	if !ok {
+		return EXIT_CODE_NOT_OKAY
+	}
+
+	it := interp.NewInterpreter(ast, os.Stdin, os.Stdout)
+	it.MaxSteps = params.steps
+	if err := it.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_CODE_NOT_OKAY
+	}
+	return EXIT_CODE_OKAY
+}
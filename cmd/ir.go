@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/obonobo/esac/core/ir"
+)
+
+const IR = "ir"
+
+// Extension of the IR files produced by the ir command
+const OUT_IR = "ir"
+
+var IR_USAGE = strings.TrimLeft(`
+usage: %v %v [-o output] [-r] [input files]
+
+%v lowers the input files to linear three-address code. This command produces
+a file for every input file: 'myfile.ir'
+
+Lexical, syntax, and semantic errors are printed to STDERR. No output is
+produced for a file that contains errors, and the command exits with a
+non-zero exit code.
+
+Flags:
+
+	-o, --output [outfile|-]
+		An alternative output location. If this flag is specified,
+		the file described above will not be created, only the specified
+		file will be created. Specify '-' to print the IR to STDOUT. May
+		only be used with a single input file.
+
+	-d, --outdir [outdir]
+		An alternative output location for the files. The default output
+		location is the current directory.
+
+	-r, --run
+		Run the IR with the IR interpreter instead of writing it out. The
+		program reads from STDIN and writes to STDOUT.
+
+`, "\n")
+
+type IRParams struct {
+	LexParams
+	run bool
+}
+
+func irCmd(config *Config) (usage func(), action func(args []string) int) {
+	irCmd := flag.NewFlagSet(IR, flag.ExitOnError)
+	irCmd.Usage = func() {
+		fmt.Printf(
+			IR_USAGE,
+			path.Base(config.Command),
+			IR, strings.ToUpper(IR))
+	}
+
+	params := IRParams{}
+	irCmd.StringVar(&params.output, "o", "", "")
+	irCmd.StringVar(&params.output, "output", "", "")
+	irCmd.StringVar(&params.outdir, "d", "", "")
+	irCmd.StringVar(&params.outdir, "outdir", "", "")
+	irCmd.BoolVar(&params.run, "r", false, "")
+	irCmd.BoolVar(&params.run, "run", false, "")
+
+	return irCmd.Usage, func(args []string) int {
+		irCmd.Parse(args)
+		params.inputFiles = irCmd.Args()
+		params.outputMode = outputMode(params.output)
+		if exit := checkParams(config, params.LexParams, IR); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		params.outdir = outdir(params.outdir)
+		if params.outputMode == OUT_MODE_TOFILE && len(params.inputFiles) > 1 {
+			fmt.Fprintf(os.Stderr,
+				"flag '-o'/'--output' may only be used with a single input file\n")
+			return EXIT_CODE_NOT_OKAY
+		}
+		return IRLower(params)
+	}
+}
+
+// IR subcommand
+func IRLower(params IRParams) (exit int) {
+	if !params.run {
+		if exit := makeOutputDirIfNotExists(params.outdir); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+	}
+
+	chugged, exit := openAndChugFiles(params.inputFiles)
+	if exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
+	files := make([]string, 0, len(chugged))
+	for file := range chugged {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return chugged[files[i]].i < chugged[files[j]].i })
+
+	for _, file := range files {
+		rep := newReporter(file, chugged[file].src, os.Stderr)
+		ast, ok := analyze(chugged[file].src, rep)
+		if !ok {
+			exit = EXIT_CODE_NOT_OKAY
+			continue
+		}
+
+		prog, err := ir.Lower(ast)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit = EXIT_CODE_NOT_OKAY
+			continue
+		}
+
+		if params.run {
+			if err := ir.Interpret(prog, os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exit = EXIT_CODE_NOT_OKAY
+			}
+			continue
+		}
+
+		code := new(bytes.Buffer)
+		prog.Print(code)
+		if e := writeCodeOutput(params.LexParams, file, OUT_IR, code); e != EXIT_CODE_OKAY {
+			return e
+		}
+	}
+	return exit
+}
@@ -10,18 +10,20 @@ import (
 	"sync"
 
 	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/streamingcharsource"
 	"github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
 	"github.com/obonobo/esac/reporting"
 )
 
 const LEX = "lex"
 
-const LEXUSAGE = `usage: %v %v [-o output] [input files]
+const LEXUSAGE = `usage: %v %v [-o output] [--stream] [input files]
 
 %v converts the input files to tokens and save them to files. Two files will be
 generated for every input file: 'myfile.outlextokens', and 'myfile.outlexerrors'.
 
-If no input files are specified, input is read from STDIN.
+If no input files are specified, input is read from STDIN. An input file named
+'-' is also read from STDIN.
 
 Flags:
 
@@ -35,6 +37,19 @@ Flags:
 		An alternative output location for the two files ('.outlextokens' and
 		'.outlexerrors'). The default output location is the current directory.
 
+	--error-format [text|json|sarif]
+		The format of the lexical errors printed to STDERR. 'text' renders
+		every error with a snippet of the source, 'json' prints an array of
+		records, and 'sarif' prints a SARIF 2.1.0 log. With '-o', errors are
+		only printed to STDERR in the 'json' and 'sarif' formats. The
+		default is 'text'. '--format' is an alias, note that 'parse' uses
+		'--format' for the format of the AST instead.
+
+	--stream
+		Read the input files as they are scanned, instead of loading them
+		into memory first. Lexical errors are printed without snippets of
+		the source.
+
 `
 
 const (
@@ -55,11 +70,16 @@ const (
 	OUT_LEX_ERRORS = "outlexerrors"
 )
 
+// The name of the input file that stands for STDIN
+const STDIN_FILE = "-"
+
 type LexParams struct {
 	output     string
 	outdir     string
 	outputMode int // Should be once of OUT_MODE_STDOUT, OUT_MODE_NORMAL, or OUT_MODE_TOFILE
 	inputFiles []string
+	format     reporting.Format // The format of the diagnostics written to STDERR
+	stream     bool             // Stream the input files instead of chugging them
 }
 
 func lexCmd(config *Config) (usage func(), action func(args []string) int) {
@@ -78,6 +98,10 @@ func lexCmd(config *Config) (usage func(), action func(args []string) int) {
 	lexerCmdOutdir := lexerCmd.String("outdir", "", "")
 	lexerCmdD := lexerCmd.String("d", "", "")
 
+	lexerCmdFormat := lexerCmd.String("error-format", string(reporting.FORMAT_TEXT), "")
+	lexerCmd.StringVar(lexerCmdFormat, "format", string(reporting.FORMAT_TEXT), "")
+	lexerCmdStream := lexerCmd.Bool("stream", false, "")
+
 	return lexerCmd.Usage, func(args []string) int {
 		var params LexParams
 		lexerCmd.Parse(args)
@@ -102,6 +126,8 @@ func lexCmd(config *Config) (usage func(), action func(args []string) int) {
 		}
 
 		params.outputMode = outputMode(params.output)
+		params.format = reporting.Format(*lexerCmdFormat)
+		params.stream = *lexerCmdStream
 
 		if exit := checkParams(config, params, LEX); exit != 0 {
 			return exit
@@ -138,14 +164,19 @@ func lexNormal(params LexParams) int {
 		return code
 	}
 
-	chugged, exit := openAndChugFiles(params.inputFiles)
+	chugged, closeSources, exit := openSources(params)
 	if exit != EXIT_CODE_OKAY {
 		return exit
 	}
+	defer closeSources()
 
-	// Lex and write output files
+	// Lex and write output files, lexical errors are also emitted to STDERR
+	// once all files have been lexed
 	var wait sync.WaitGroup
+	diagnostics := make([][]reporting.Diagnostic, len(chugged))
 	for file, source := range chugged {
+		i := source.i
+
 		outTokens, err := os.Create(
 			path.Join(outdir, inputFileNameToOutputFileName(file, OUT_LEX_TOKENS)))
 
@@ -168,8 +199,10 @@ func lexNormal(params LexParams) int {
 
 		defer outErrors.Close()
 
-		out, errs := reporting.StreamLinesSplitErrors(
-			compositetable.NewTableDrivenScanner(source.src), -1)
+		scnr := reporting.ReportLexicalErrors(
+			compositetable.NewTableDrivenScanner(source.src),
+			func(d reporting.Diagnostic) { diagnostics[i] = append(diagnostics[i], d) })
+		out, errs := reporting.StreamLinesSplitErrors(scnr, -1)
 
 		wait.Add(1)
 		go func() {
@@ -189,11 +222,36 @@ func lexNormal(params LexParams) int {
 	}
 
 	wait.Wait()
+	emitLexicalDiagnostics(params, chugged, diagnostics)
 	return EXIT_CODE_OKAY
 }
 
+// Emits the lexical errors of every source to STDERR in the order of the input
+// files, diagnostics[i] holds the errors of the source with index i
+func emitLexicalDiagnostics(
+	params LexParams,
+	sources map[string]indexedSource,
+	diagnostics [][]reporting.Diagnostic,
+) {
+	files := make([]string, len(sources))
+	for file, source := range sources {
+		files[source.i] = file
+	}
+	emitter := reporting.NewEmitter(params.format, os.Stderr)
+	for i, file := range files {
+		emitter.Source(file, sourceText(sources[file].src))
+		for _, d := range diagnostics[i] {
+			emitter.Emit(file, d)
+		}
+	}
+	emitter.Flush()
+}
+
 func inputFileNameToOutputFileName(name string, extension string) string {
 	base := path.Base(name)
+	if !strings.Contains(base, ".") {
+		return base + "." + extension
+	}
 	trimExtension := strings.TrimRightFunc(base, func(r rune) bool { return r != '.' })
 	return trimExtension + extension
 }
@@ -222,12 +280,14 @@ func reportFileErrors(errs []error, out io.Writer) int {
 }
 
 func lexTo(params LexParams, to io.Writer) int {
-	chugged, exit := openAndChugFiles(params.inputFiles)
+	chugged, closeSources, exit := openSources(params)
 	if exit != EXIT_CODE_OKAY {
 		return exit
 	}
+	defer closeSources()
 
-	lines := streamCharSources(chugged)
+	diagnostics := make([][]reporting.Diagnostic, len(chugged))
+	lines := streamCharSources(chugged, diagnostics)
 
 	var i int
 	for fileName, out := range lines {
@@ -242,6 +302,10 @@ func lexTo(params LexParams, to io.Writer) int {
 		i++
 	}
 
+	// Error tokens are already part of the text output
+	if !params.format.IsText() {
+		emitLexicalDiagnostics(params, chugged, diagnostics)
+	}
 	return EXIT_CODE_OKAY
 }
 
@@ -251,12 +315,19 @@ func capitalizeFirstLetter(err error) string {
 	return fmt.Sprint(strings.ToUpper(s[:1]) + s[1:])
 }
 
+// Streams the tokens of the sources, the error tokens of the source with index
+// i are also collected into diagnostics[i]. The diagnostics are complete once
+// the stream of the source is closed
 func streamCharSources(
 	sources map[string]indexedSource,
+	diagnostics [][]reporting.Diagnostic,
 ) (lines map[string]<-chan string) {
 	lines = make(map[string]<-chan string, len(sources))
 	for fileName, chugger := range sources {
-		s := compositetable.NewTableDrivenScanner(chugger.src)
+		i := chugger.i
+		s := reporting.ReportLexicalErrors(
+			compositetable.NewTableDrivenScanner(chugger.src),
+			func(d reporting.Diagnostic) { diagnostics[i] = append(diagnostics[i], d) })
 		lines[fileName] = reporting.StreamLines(s, -1)
 	}
 	return lines
@@ -291,6 +362,37 @@ func openAndChugFiles(inputFiles []string) (map[string]indexedSource, int) {
 	return ret, EXIT_CODE_OKAY
 }
 
+// Opens the input files as char sources. The files are chugged into memory,
+// unless params.stream is set, in which case they are read as they are scanned
+// and stay open until closeSources is called
+func openSources(params LexParams) (
+	sources map[string]indexedSource,
+	closeSources func(),
+	exit int,
+) {
+	if !params.stream {
+		sources, exit := openAndChugFiles(params.inputFiles)
+		return sources, func() {}, exit
+	}
+
+	files, errs := openInputFiles(params.inputFiles)
+	closeSources = func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	if e := reportFileErrors(errs, os.Stdout); e != EXIT_CODE_OKAY {
+		closeSources()
+		return nil, func() {}, e
+	}
+
+	sources = make(map[string]indexedSource, len(files))
+	for i, f := range files {
+		sources[f.Name()] = indexedSource{i, streamingcharsource.StreamingReader(f)}
+	}
+	return sources, closeSources, EXIT_CODE_OKAY
+}
+
 func chugFiles(files []*os.File) ([]*chuggingcharsource.ChuggingCharSource, []error) {
 	out := make([]*chuggingcharsource.ChuggingCharSource, 0, len(files))
 	errs := make([]error, 0, len(files))
@@ -306,11 +408,15 @@ func chugFiles(files []*os.File) ([]*chuggingcharsource.ChuggingCharSource, []er
 	return out, errs
 }
 
-// Open a set of files and report errors
+// Open a set of files and report errors, a file named STDIN_FILE is STDIN
 func openInputFiles(files []string) ([]*os.File, []error) {
 	out := make([]*os.File, 0, len(files))
 	errs := make([]error, 0, len(files))
 	for _, file := range files {
+		if file == STDIN_FILE {
+			out = append(out, os.Stdin)
+			continue
+		}
 		f, err := os.Open(file)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to open input file '%v'", file))
@@ -328,6 +434,17 @@ func checkParams(config *Config, params LexParams, subcommand string) (exit int)
 	if exit := checkInputFiles(config, params, subcommand); exit != EXIT_CODE_OKAY {
 		return exit
 	}
+	if exit := checkFormat(params); exit != EXIT_CODE_OKAY {
+		return exit
+	}
+	return EXIT_CODE_OKAY
+}
+
+func checkFormat(params LexParams) (exit int) {
+	if _, err := reporting.ParseFormat(string(params.format)); err != nil {
+		fmt.Fprintln(os.Stderr, capitalizeFirstLetter(err))
+		return EXIT_CODE_NOT_OKAY
+	}
 	return EXIT_CODE_OKAY
 }
 
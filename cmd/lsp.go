@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/obonobo/esac/core/lsp"
+)
+
+const LSP = "lsp"
+
+var LSP_USAGE = strings.TrimLeft(`
+usage: %v %v
+
+%v runs a language server that speaks the Language Server Protocol over STDIN
+and STDOUT. It is meant to be launched by an editor, not used directly.
+
+The server publishes lexical, syntax, and semantic errors as diagnostics
+whenever a document is opened or changed, and supports go-to-definition, hover,
+and document symbols.
+
+`, "\n")
+
+func lspCmd(config *Config) (usage func(), action func(args []string) int) {
+	lspCmd := flag.NewFlagSet(LSP, flag.ExitOnError)
+	lspCmd.Usage = func() {
+		fmt.Printf(
+			LSP_USAGE,
+			path.Base(config.Command),
+			LSP, strings.ToUpper(string(LSP[0]))+LSP[1:])
+	}
+
+	return lspCmd.Usage, func(args []string) int {
+		lspCmd.Parse(args)
+		if lspCmd.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "%v does not accept input files\n", LSP)
+			return EXIT_CODE_NOT_OKAY
+		}
+		return Lsp()
+	}
+}
+
+// LSP subcommand
+func Lsp() int {
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_CODE_NOT_OKAY
+	}
+	return EXIT_CODE_OKAY
+}
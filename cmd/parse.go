@@ -11,8 +11,11 @@ import (
 	"sync"
 
 	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/grammar"
+	"github.com/obonobo/esac/core/lrparser"
 	"github.com/obonobo/esac/core/parser"
 	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/streamingcharsource"
 	"github.com/obonobo/esac/core/tabledrivenparser"
 	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
 	"github.com/obonobo/esac/core/tabledrivenscanner"
@@ -25,13 +28,15 @@ const PARSE = "parse"
 const OUTAST = "outast"
 
 var PARSE_USAGE = strings.TrimLeft(`
-usage: %v %v [-o output] [input files]
+usage: %v %v [-o output] [--format tree|json|sexpr|dot] [--stream]
+       [--grammar file.grm] [--parser ll|lalr] [input files]
 
 %v converts the input files to tokens and then consumes the token stream to
 convert it to an AST. This command produces a file for every input file:
 'myfile.outast'
 
-If no input files are specified, input is read from STDIN.
+If no input files are specified, input is read from STDIN. An input file named
+'-' is also read from STDIN.
 
 Flags:
 
@@ -49,6 +54,42 @@ Flags:
 		Also creates the .outderivation, .outsyntaxerrors, .outlextokens,
 		and .outlexerrors files.
 
+	--format [tree|json|sexpr|dot]
+		The format of the AST. 'tree' prints one node per line, indented
+		by depth. 'json' and 'sexpr' follow a versioned schema that
+		records the type, the token (with its line and column), and the
+		children of every node, the AST can be loaded back from them.
+		'dot' prints a Graphviz graph, and with --debug the
+		.outderivation file also becomes a Graphviz graph of the parse
+		tree. The default is 'tree'. Unlike 'lex' and 'check', where
+		'--format' is an alias of '--error-format', here it only selects
+		the format of the AST.
+
+	--error-format [text|json|sarif]
+		The format of the lexical and syntax errors printed to STDERR.
+		'text' renders every error with a snippet of the source, 'json'
+		prints an array of records, and 'sarif' prints a SARIF 2.1.0 log.
+		The default is 'text'.
+
+	--stream
+		Read the input files as they are parsed, instead of loading them
+		into memory first. Errors are printed without snippets of the
+		source.
+
+	--grammar [file.grm]
+		Parse with an LL(1) table computed from the grammar file when the
+		command starts, instead of the table that is compiled into %v.
+		The grammar can only use the semantic actions that are compiled
+		in.
+
+	--parser [ll|lalr]
+		The parser backend. 'll' is the table-driven LL(1) parser, which
+		recovers from syntax errors and reports all of them. 'lalr' is a
+		shift-reduce parser driven by an LALR(1) table that is computed
+		from the grammar when the command starts, it stops at the first
+		syntax error. Both backends build the same AST. The default is
+		'll'.
+
 `, "\n")
 
 const (
@@ -56,10 +97,27 @@ const (
 	OUT_SYNTAX_ERRORS = "outsyntaxerrors"
 )
 
+// AST formats, selected with --format
+const (
+	AST_FORMAT_TREE  = "tree"
+	AST_FORMAT_JSON  = "json"
+	AST_FORMAT_SEXPR = "sexpr"
+	AST_FORMAT_DOT   = "dot"
+)
+
+// Parser backends, selected with --parser
+const (
+	PARSER_LL   = "ll"
+	PARSER_LALR = "lalr"
+)
+
 type ParseParams struct {
 	LexParams
-	debug bool
-	input *os.File
+	debug   bool
+	grammar string // Grammar file to compute the parser table from
+	backend string // PARSER_LL or PARSER_LALR
+	ast     string // One of the AST_FORMAT_* constants
+	input   *os.File
 }
 
 func parseCmd(config *Config) (usage func(), action func(args []string) (exit int)) {
@@ -68,7 +126,8 @@ func parseCmd(config *Config) (usage func(), action func(args []string) (exit in
 		fmt.Printf(
 			PARSE_USAGE,
 			path.Base(config.Command),
-			PARSE, strings.ToUpper(string(PARSE[0]))+PARSE[1:])
+			PARSE, strings.ToUpper(string(PARSE[0]))+PARSE[1:],
+			path.Base(config.Command))
 	}
 
 	params := ParseParams{}
@@ -77,6 +136,11 @@ func parseCmd(config *Config) (usage func(), action func(args []string) (exit in
 	parseCmd.StringVar(&params.LexParams.outdir, "d", "", "")
 	parseCmd.StringVar(&params.LexParams.outdir, "outdir", "", "")
 	parseCmd.BoolVar(&params.debug, "debug", false, "")
+	parseCmd.StringVar(&params.ast, "format", AST_FORMAT_TREE, "")
+	parseCmd.StringVar((*string)(&params.format), "error-format", string(reporting.FORMAT_TEXT), "")
+	parseCmd.BoolVar(&params.stream, "stream", false, "")
+	parseCmd.StringVar(&params.grammar, "grammar", "", "")
+	parseCmd.StringVar(&params.backend, "parser", PARSER_LL, "")
 
 	return parseCmd.Usage, func(args []string) (exit int) {
 		parseCmd.Parse(args)
@@ -86,6 +150,12 @@ func parseCmd(config *Config) (usage func(), action func(args []string) (exit in
 		if exit := checkInputFiles(config, params.LexParams, PARSE); exit != 0 {
 			return exit
 		}
+		if exit := checkFormat(params.LexParams); exit != 0 {
+			return exit
+		}
+		if exit := checkASTFormat(params.ast); exit != 0 {
+			return exit
+		}
 		if len(params.LexParams.inputFiles) == 0 {
 			params.input = os.Stdin
 		}
@@ -95,6 +165,11 @@ func parseCmd(config *Config) (usage func(), action func(args []string) (exit in
 
 // PARSE subcommand
 func Parse(params ParseParams) (exit int) {
+	newParser, exit := loadParser(params.grammar, params.backend)
+	if exit != EXIT_CODE_OKAY {
+		return exit
+	}
+
 	if exit := makeOutputDirIfNotExists(params.outdir); exit != EXIT_CODE_OKAY {
 		return exit
 	}
@@ -105,6 +180,9 @@ func Parse(params ParseParams) (exit int) {
 	}
 	defer close()
 
+	emitter := reporting.NewEmitter(params.format, os.Stderr)
+	defer emitter.Flush()
+
 	var i int
 	moreThanOne := len(params.inputFiles) > 1
 	for _, out := range sortedOutputLocations(output) {
@@ -116,53 +194,129 @@ func Parse(params ParseParams) (exit int) {
 			}
 			fmt.Fprintf(to, "%v:\n", file)
 		}
-		parse(out.locations, params)
+		parse(emitTo(emitter, file, out.locations.source), out.locations, newParser, params)
 		i++
 	}
 
 	return EXIT_CODE_OKAY
 }
 
-// Parses a single input file
-func parse(out *outputLocations, params ParseParams) {
+// Parses a single input file. Lexical and syntax errors are reported once the
+// parse is complete, sorted by their position in the file
+func parse(
+	rep *reporter,
+	out *outputLocations,
+	newParser parserFactory,
+	params ParseParams,
+) {
 	scnr := createScanner(out.source)
 	outlextokens, outlexerrors := reporting.StreamTokensSplitErrors(scnr.Subscribe())
-	outsyntaxerrors := make(chan tabledrivenparser.ParserError, 1024)
+	lexical := collectLexicalDiagnostics(scnr.Subscribe())
 
-	var outderivation chan token.Rule
+	var (
+		outsyntaxerrors chan error
+		outderivation   chan token.Rule
+		syntax          []reporting.Diagnostic
+	)
 	if params.debug {
+		outsyntaxerrors = make(chan error, 1024)
 		outderivation = make(chan token.Rule, 1024)
 	}
 
-	prsr := createParser(scnr, outsyntaxerrors, outderivation)
+	prsr := createParser(scnr, newParser, func(e *tabledrivenparser.ParserError) {
+		syntax = append(syntax, reporting.SyntaxDiagnostic(e))
+		if outsyntaxerrors != nil {
+			outsyntaxerrors <- e
+		}
+	}, outderivation)
 
 	var wait sync.WaitGroup
 	if params.debug {
 		wait.Add(4)
 		goWriteTo(&wait, outlextokens, out.outlextokens)
-		goWriteTo(&wait, outlexerrors, out.outlexerrors, os.Stderr)
-		goWriteTo(&wait, outderivation, out.outderivation)
-		goWriteTo(&wait, outsyntaxerrors, out.outsyntaxerrors, os.Stderr)
+		goWriteTo(&wait, outlexerrors, out.outlexerrors)
+		if params.ast == AST_FORMAT_DOT {
+			goWriteDerivationDot(&wait, outderivation, scnr.Subscribe(), out.outderivation)
+		} else {
+			goWriteTo(&wait, outderivation, out.outderivation)
+		}
+		goWriteTo(&wait, outsyntaxerrors, out.outsyntaxerrors)
 	} else {
-		wait.Add(3)
-
-		// Eat outlextokens
-		go func() {
-			for range outlextokens {
-			}
-			wait.Done()
-		}()
+		wait.Add(2)
 
-		// Log the errors
-		goWriteTo(&wait, outlexerrors, os.Stderr)
-		goWriteTo(&wait, outsyntaxerrors, os.Stderr)
+		// Eat outlextokens and outlexerrors, lexical errors are reported as
+		// diagnostics instead
+		goWriteTo(&wait, outlextokens)
+		goWriteTo(&wait, outlexerrors)
 	}
 
 	// Write the AST
 	if prsr.Parse() {
-		prsr.AST().Print(out.outast)
+		if ast := prsr.AST(); ast.Root != nil {
+			writeAST(out.outast, ast, params.ast)
+		}
+	}
+
+	// The parser may stop before the scanner reaches EOF, in which case the
+	// subscribers have not been closed yet
+	scnr.Close()
+	if params.debug {
+		close(outsyntaxerrors)
+		close(outderivation)
 	}
 	wait.Wait()
+
+	diagnostics := append(<-lexical, syntax...)
+	reporting.SortDiagnostics(diagnostics)
+	for _, d := range diagnostics {
+		rep.report(d)
+	}
+}
+
+func checkASTFormat(format string) (exit int) {
+	switch format {
+	case AST_FORMAT_TREE, AST_FORMAT_JSON, AST_FORMAT_SEXPR, AST_FORMAT_DOT:
+		return EXIT_CODE_OKAY
+	}
+	fmt.Fprintf(os.Stderr,
+		"Invalid AST format '%v', should be '%v', '%v', '%v', or '%v'\n",
+		format, AST_FORMAT_TREE, AST_FORMAT_JSON, AST_FORMAT_SEXPR, AST_FORMAT_DOT)
+
+	// The lex and check commands take the format of the errors with --format
+	if _, err := reporting.ParseFormat(format); err == nil {
+		fmt.Fprintf(os.Stderr,
+			"Hint: the format of the errors is selected with '--error-format=%v'\n", format)
+	}
+	return EXIT_CODE_NOT_OKAY
+}
+
+func writeAST(out io.Writer, ast token.AST, format string) {
+	switch format {
+	case AST_FORMAT_JSON:
+		ast.PrintJson(out)
+	case AST_FORMAT_SEXPR:
+		ast.PrintSexpr(out)
+	case AST_FORMAT_DOT:
+		ast.PrintDot(out)
+	default:
+		ast.Print(out)
+	}
+}
+
+// Converts the error tokens of the token stream to diagnostics, the result is
+// available once the token stream is closed
+func collectLexicalDiagnostics(tokens <-chan token.Token) <-chan []reporting.Diagnostic {
+	out := make(chan []reporting.Diagnostic, 1)
+	go func() {
+		var diagnostics []reporting.Diagnostic
+		for tok := range tokens {
+			if token.IsError(tok.Id) {
+				diagnostics = append(diagnostics, reporting.LexicalDiagnostic(tok))
+			}
+		}
+		out <- diagnostics
+	}()
+	return out
 }
 
 // Asynchronously writes from channel to writer(s), calls wait.Done() upon
@@ -182,6 +336,33 @@ func goWriteTo[T any](
 	}()
 }
 
+// Asynchronously collects the derivation and the tokens that it was derived
+// from, and writes the parse tree as a DOT graph once both channels are
+// closed. Calls wait.Done() upon completion
+func goWriteDerivationDot(
+	wait *sync.WaitGroup,
+	rulec <-chan token.Rule,
+	tokenc <-chan token.Token,
+	to io.Writer,
+) {
+	tokens := make(chan []token.Token, 1)
+	go func() {
+		collected := make([]token.Token, 0, 1024)
+		for tok := range tokenc {
+			collected = append(collected, tok)
+		}
+		tokens <- collected
+	}()
+	go func() {
+		rules := make([]token.Rule, 0, 1024)
+		for r := range rulec {
+			rules = append(rules, r)
+		}
+		token.WriteDerivationDot(to, rules, <-tokens)
+		wait.Done()
+	}()
+}
+
 // This function opens all files that need to be opened per params
 //
 // We need to open 4-5 output files:
@@ -195,7 +376,7 @@ func openOutputLocations(params ParseParams) (
 	close func(),
 	exit int,
 ) {
-	chugged, exit := openAndChugFilesHandleStdin(params)
+	chugged, closeSources, exit := openSourcesHandleStdin(params)
 	if exit != EXIT_CODE_OKAY {
 		return nil, func() {}, exit
 	}
@@ -205,6 +386,7 @@ func openOutputLocations(params ParseParams) (
 		for _, v := range outputs {
 			v.close()
 		}
+		closeSources()
 	}
 
 	for file, source := range chugged {
@@ -224,17 +406,21 @@ type indexedSource struct {
 	src scanner.CharSource
 }
 
-func openAndChugFilesHandleStdin(params ParseParams) (map[string]indexedSource, int) {
+func openSourcesHandleStdin(params ParseParams) (map[string]indexedSource, func(), int) {
 	if params.input != nil {
 		// Then we have a single file, read from stdin
-		in, err := chuggingcharsource.ChuggingReader(os.Stdin)
+		if params.stream {
+			in := streamingcharsource.StreamingReader(params.input)
+			return map[string]indexedSource{"a.in": {0, in}}, func() {}, EXIT_CODE_OKAY
+		}
+		in, err := chuggingcharsource.ChuggingReader(params.input)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			return nil, EXIT_CODE_NOT_OKAY
+			return nil, func() {}, EXIT_CODE_NOT_OKAY
 		}
-		return map[string]indexedSource{"a.in": {0, in}}, EXIT_CODE_OKAY
+		return map[string]indexedSource{"a.in": {0, in}}, func() {}, EXIT_CODE_OKAY
 	}
-	return openAndChugFiles(params.inputFiles)
+	return openSources(params.LexParams)
 }
 
 // A big record holding all information output information
@@ -348,15 +534,109 @@ func outdir(dir string) string {
 	return dir
 }
 
+// Creates a parser of the selected backend for a single input file
+type parserFactory func(
+	scnr scanner.Scanner,
+	errc func(e *tabledrivenparser.ParserError),
+	rulec func(r token.Rule),
+) parser.Parser
+
 func createParser(
 	scnr scanner.Scanner,
-	errc chan<- tabledrivenparser.ParserError,
+	newParser parserFactory,
+	errc func(e *tabledrivenparser.ParserError),
 	rulec chan<- token.Rule,
 ) parser.Parser {
-	// return tabledrivenparser.NewParser(scnr, parsertable.TABLE(), errc, rulec)
-	return tabledrivenparser.NewParser(scnr, parsertable.TABLE(),
-		func(e *tabledrivenparser.ParserError) { errc <- *e },
-		func(r token.Rule) { rulec <- r })
+	var rules func(r token.Rule)
+	if rulec != nil {
+		rules = func(r token.Rule) { rulec <- r }
+	}
+	return newParser(scnr, errc, rules)
+}
+
+// Computes the parser table of the backend, from the grammar file if there is
+// one. The LL(1) table is compiled in, so it is only computed for a grammar
+// file. The LALR(1) table is always computed, from the compiled-in rules if
+// there is no grammar file
+func loadParser(grammarFile, backend string) (parserFactory, int) {
+	if backend != PARSER_LL && backend != PARSER_LALR {
+		fmt.Fprintf(os.Stderr,
+			"Invalid parser '%v', should be '%v' or '%v'\n",
+			backend, PARSER_LL, PARSER_LALR)
+		return nil, EXIT_CODE_NOT_OKAY
+	}
+
+	if grammarFile == "" && backend == PARSER_LL {
+		return llParser(parsertable.TABLE()), EXIT_CODE_OKAY
+	}
+
+	g, exit := loadGrammar(grammarFile)
+	if exit != EXIT_CODE_OKAY {
+		return nil, exit
+	}
+
+	if backend == PARSER_LALR {
+		table, err := g.LALRTable()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, capitalizeFirstLetter(err))
+			return nil, EXIT_CODE_NOT_OKAY
+		}
+		return lrParser(table), EXIT_CODE_OKAY
+	}
+
+	table, err := g.Table()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, capitalizeFirstLetter(err))
+		return nil, EXIT_CODE_NOT_OKAY
+	}
+	return llParser(table), EXIT_CODE_OKAY
+}
+
+func llParser(table tabledrivenparser.Table) parserFactory {
+	return func(
+		scnr scanner.Scanner,
+		errc func(e *tabledrivenparser.ParserError),
+		rulec func(r token.Rule),
+	) parser.Parser {
+		return tabledrivenparser.NewParser(scnr, table, errc, rulec)
+	}
+}
+
+func lrParser(table lrparser.Table) parserFactory {
+	return func(
+		scnr scanner.Scanner,
+		errc func(e *tabledrivenparser.ParserError),
+		rulec func(r token.Rule),
+	) parser.Parser {
+		return lrparser.NewParser(scnr, table, errc, rulec)
+	}
+}
+
+// Parses the grammar file, or loads the compiled-in rules if there is no
+// grammar file
+func loadGrammar(grammarFile string) (*grammar.Grammar, int) {
+	if grammarFile == "" {
+		g, err := grammar.FromRules(token.START, token.RULES())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, capitalizeFirstLetter(err))
+			return nil, EXIT_CODE_NOT_OKAY
+		}
+		return g, EXIT_CODE_OKAY
+	}
+
+	fh, err := os.Open(grammarFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, capitalizeFirstLetter(failedToOpenFileError(err)))
+		return nil, EXIT_CODE_NOT_OKAY
+	}
+	defer fh.Close()
+
+	g, err := grammar.Parse(fh)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, capitalizeFirstLetter(err))
+		return nil, EXIT_CODE_NOT_OKAY
+	}
+	return g, EXIT_CODE_OKAY
 }
 
 func createScanner(chrs scanner.CharSource) *scanner.ObservableScanner {
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/core/token/visitors"
+	"github.com/obonobo/esac/reporting"
+)
+
+// Runs the front end of the compiler on a single source: scanning, parsing,
+// semantic analysis, and memory layout.
+// Lexical errors, syntax errors, semantic errors, and warnings are all reported
+// as they are discovered.
+//
+// If ok is false, an error (not just a warning) was reported and the AST
+// should not be handed to the back end.
+func analyze(src scanner.CharSource, rep *reporter) (ast token.AST, ok bool) {
+	ast, ok = reporting.ParseSource(src, rep.report)
+	if !ok {
+		reporting.CheckSemantics(ast, rep.report)
+		return token.AST{}, false
+	}
+	return ast, checkSemantics(ast, rep)
+}
+
+// Checks the AST like reporting.CheckSemantics, then walks it with the
+// MemoryLayoutVisitor. Returns false if an error (not just a warning) was
+// reported.
+func checkSemantics(ast token.AST, rep *reporter) (ok bool) {
+	reporting.CheckSemantics(ast, rep.report)
+	ast.Root.Accept(visitors.NewMemoryLayoutVisitor(
+		func(e *visitors.VisitorError) { rep.report(reporting.SemanticDiagnostic(e)) }))
+	return !rep.failed
+}
+
+// Emits the diagnostics for a single source file, and remembers whether any of
+// them were errors
+type reporter struct {
+	emitter *reporting.Emitter
+	file    string
+	plain   io.Writer // Optional, receives only the message of each diagnostic
+	failed  bool
+}
+
+// Creates a reporter that renders diagnostics for the file to out. Snippets of
+// the source are included if the text of the source can be recovered
+func newReporter(file string, src scanner.CharSource, out io.Writer) *reporter {
+	return emitTo(reporting.NewEmitter(reporting.FORMAT_TEXT, out), file, src)
+}
+
+// Creates a reporter that emits the diagnostics of the file to the emitter,
+// the emitter may be shared by the reporters of several files
+func emitTo(emitter *reporting.Emitter, file string, src scanner.CharSource) *reporter {
+	emitter.Source(file, sourceText(src))
+	return &reporter{emitter: emitter, file: file}
+}
+
+func (r *reporter) report(d reporting.Diagnostic) {
+	r.emitter.Emit(r.file, d)
+	if r.plain != nil {
+		fmt.Fprintln(r.plain, d.Msg)
+	}
+	if d.Severity == reporting.SEVERITY_ERROR {
+		r.failed = true
+	}
+}
+
+// Recovers the text of a source that has been read into memory, returns nil if
+// the source does not expose its text
+func sourceText(src scanner.CharSource) []byte {
+	if text, ok := src.(interface{ Bytes() []byte }); ok {
+		return text.Bytes()
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/obonobo/esac/core/moon"
+	"github.com/obonobo/esac/core/token/visitors"
+	"github.com/obonobo/esac/reporting"
+)
+
+const RUN = "run"
+
+var RUN_USAGE = strings.TrimLeft(`
+usage: %v %v [-s steps] [input file]
+
+%v compiles the input file to MOON assembly code and executes it on the
+built-in MOON machine. The program reads from STDIN and writes to STDOUT. Files
+with the '.m' extension are assumed to contain MOON assembly code already, they
+are assembled and executed as is.
+
+Lexical, syntax, semantic, and runtime errors are printed to STDERR. If any
+errors are found, the command exits with a non-zero exit code.
+
+Flags:
+
+	-s, --steps [steps]
+		The maximum number of instructions that the program may execute
+		before it is killed. Specify a negative number for no limit.
+
+`, "\n")
+
+type RunParams struct {
+	LexParams
+	steps int
+}
+
+func runCmd(config *Config) (usage func(), action func(args []string) int) {
+	runCmd := flag.NewFlagSet(RUN, flag.ExitOnError)
+	runCmd.Usage = func() {
+		fmt.Printf(
+			RUN_USAGE,
+			path.Base(config.Command),
+			RUN, strings.ToUpper(string(RUN[0]))+RUN[1:])
+	}
+
+	params := RunParams{}
+	runCmd.IntVar(&params.steps, "s", moon.DEFAULT_MAX_STEPS, "")
+	runCmd.IntVar(&params.steps, "steps", moon.DEFAULT_MAX_STEPS, "")
+
+	return runCmd.Usage, func(args []string) int {
+		runCmd.Parse(args)
+		params.inputFiles = runCmd.Args()
+		if exit := checkParams(config, params.LexParams, RUN); exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		if len(params.inputFiles) > 1 {
+			fmt.Fprintf(os.Stderr, "%v accepts a single input file\n", RUN)
+			return EXIT_CODE_NOT_OKAY
+		}
+		return Execute(params)
+	}
+}
+
+// RUN subcommand
+func Execute(params RunParams) int {
+	file := params.inputFiles[0]
+	code := new(bytes.Buffer)
+	if path.Ext(file) == "."+OUT_MOON {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, failedToOpenFileError(err))
+			return EXIT_CODE_NOT_OKAY
+		}
+		code.Write(data)
+	} else {
+		chugged, exit := openAndChugFiles(params.inputFiles)
+		if exit != EXIT_CODE_OKAY {
+			return exit
+		}
+		rep := newReporter(file, chugged[file].src, os.Stderr)
+		ast, ok := analyze(chugged[file].src, rep)
+		if !ok {
+			return EXIT_CODE_NOT_OKAY
+		}
+		ast.Root.Accept(visitors.NewCodeGenVisitor(code, func(e *visitors.VisitorError) {
+			rep.report(reporting.SemanticDiagnostic(e))
+			ok = false
+		}))
+		if !ok {
+			return EXIT_CODE_NOT_OKAY
+		}
+	}
+
+	prog, err := moon.Assemble(code)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_CODE_NOT_OKAY
+	}
+	machine := moon.NewMachine(prog, os.Stdin, os.Stdout)
+	machine.MaxSteps = params.steps
+	if err := machine.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_CODE_NOT_OKAY
+	}
+	return EXIT_CODE_OKAY
+}
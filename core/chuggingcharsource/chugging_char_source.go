@@ -72,6 +72,12 @@ func (c *ChuggingCharSource) ChugReader(reader io.Reader) error {
 	return nil
 }
 
+// Returns the entire contents of the source, regardless of the current
+// position. The returned slice must not be modified
+func (c *ChuggingCharSource) Bytes() []byte {
+	return c.buf
+}
+
 // Reads the next character in the input
 func (c *ChuggingCharSource) NextChar() (rune, error) {
 	r, _, err := c.ReadRune()
@@ -0,0 +1,134 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Number of unchanged lines shown around each change
+const DIFF_CONTEXT = 3
+
+// Produces a unified diff between the original and the formatted source of a
+// file, or nil if they are identical
+func Diff(name string, before, after []byte) []byte {
+	if bytes.Equal(before, after) {
+		return nil
+	}
+	a, b := splitLines(before), splitLines(after)
+	edits := diffLines(a, b)
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, "--- %v\n+++ %v\n", name, name+" (formatted)")
+	for start := 0; start < len(edits); {
+		// Find the next change, and extend the hunk for as long as the changes
+		// are separated by no more than twice the context
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].op != ' ' {
+				end = i + 1
+			} else if i-end >= 2*DIFF_CONTEXT {
+				break
+			}
+		}
+
+		from, to := maxInt(start-DIFF_CONTEXT, 0), minInt(end+DIFF_CONTEXT, len(edits))
+		hunk := edits[from:to]
+		aStart, bStart := edits[from].a, edits[from].b
+		aLen, bLen := 0, 0
+		for _, e := range hunk {
+			if e.op != '+' {
+				aLen++
+			}
+			if e.op != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(out, "@@ -%v +%v @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, e := range hunk {
+			fmt.Fprintf(out, "%c%v\n", e.op, e.line)
+		}
+		start = to
+	}
+	return out.Bytes()
+}
+
+type edit struct {
+	op   byte // ' ', '-', or '+'
+	line string
+	a, b int // Line numbers of the edit in both files, starting at 1
+}
+
+// Finds the shortest edit script between two lists of lines, using their
+// longest common subsequence
+func diffLines(a, b []string) []edit {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = maxInt(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i], i + 1, j + 1})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j], i + 1, j + 1})
+			j++
+		}
+	}
+	return edits
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		start--
+	}
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%v,%v", start, length)
+}
+
+func splitLines(src []byte) []string {
+	lines := strings.Split(string(src), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
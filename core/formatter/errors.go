@@ -0,0 +1,23 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/obonobo/esac/reporting"
+)
+
+// Returned when a source cannot be formatted because it does not parse
+type SyntaxError struct {
+	Diagnostics []reporting.Diagnostic
+}
+
+func (e *SyntaxError) Error() string {
+	msgs := make([]string, 0, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		msgs = append(msgs, d.Error())
+	}
+	if len(msgs) == 0 {
+		return "syntax error"
+	}
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,672 @@
+// Package formatter pretty-prints source code in a canonical style.
+//
+// The source is parsed by the TableDrivenParser and the resulting AST is
+// printed back, so the layout of the original source is not relevant except for
+// the comments, which are gathered by the CommentlessScanner and re-attached to
+// the nearest declaration or statement, and single blank lines between
+// statements, which are kept.
+//
+// Formatting is idempotent, and the AST of the formatted source is identical
+// to the AST of the original source.
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/tabledrivenscanner"
+	scannertable "github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/reporting"
+)
+
+const INDENT = "\t"
+
+// Lexemes of the operators, by the kind of their AST node
+var operators = map[token.Kind]string{
+	token.FINAL_PLUS:     "+",
+	token.FINAL_MINUS:    "-",
+	token.FINAL_OR:       "|",
+	token.FINAL_MULT:     "*",
+	token.FINAL_DIV:      "/",
+	token.FINAL_AND:      "&",
+	token.FINAL_EQ:       "==",
+	token.FINAL_NEQ:      "<>",
+	token.FINAL_LT:       "<",
+	token.FINAL_GT:       ">",
+	token.FINAL_LEQ:      "<=",
+	token.FINAL_GEQ:      ">=",
+	token.FINAL_NOT:      "!",
+	token.FINAL_NEGATIVE: "-",
+	token.FINAL_POSITIVE: "+",
+}
+
+// Formats a source file. Sources containing lexical or syntax errors cannot be
+// formatted, a *SyntaxError listing the errors is returned instead
+func Format(src []byte) ([]byte, error) {
+	errs := new(SyntaxError)
+	comments := scanner.IgnoringComments(
+		tabledrivenscanner.NewScanner(
+			chuggingcharsource.MustChuggingReader(bytes.NewReader(src)),
+			scannertable.TABLE()),
+		token.Comments()...)
+	recorder := &recordingScanner{Scanner: comments}
+	prsr := tabledrivenparser.NewParser(recorder, parsertable.TABLE(),
+		func(e *tabledrivenparser.ParserError) {
+			errs.Diagnostics = append(errs.Diagnostics, reporting.SyntaxDiagnostic(e))
+		}, nil)
+
+	ok := prsr.Parse()
+	for _, tok := range recorder.tokens {
+		if token.IsError(tok.Id) {
+			errs.Diagnostics = append(errs.Diagnostics, reporting.LexicalDiagnostic(tok))
+		}
+	}
+	reporting.SortDiagnostics(errs.Diagnostics)
+	if !ok || len(errs.Diagnostics) > 0 {
+		return nil, errs
+	}
+
+	p := newPrinter(src, recorder.tokens, comments.Collected)
+	p.prog(prsr.AST().Root)
+	return p.out.Bytes(), nil
+}
+
+// Records the tokens that are handed to the parser
+type recordingScanner struct {
+	scanner.Scanner
+	tokens []token.Token
+}
+
+func (s *recordingScanner) NextToken() (token.Token, error) {
+	tok, err := s.Scanner.NextToken()
+	if err == nil {
+		s.tokens = append(s.tokens, tok)
+	}
+	return tok, err
+}
+
+type position struct {
+	line, column int
+}
+
+func positionOf(tok token.Token) position {
+	return position{tok.Line, tok.Column}
+}
+
+func (p position) before(p2 position) bool {
+	return p.line < p2.line || p.line == p2.line && p.column < p2.column
+}
+
+type comment struct {
+	text  string
+	pos   position
+	end   int // The line on which the comment ends
+	block int // Index of the '{' token enclosing the comment, -1 at the top level
+}
+
+type printer struct {
+	out   *bytes.Buffer
+	lines []string // Lines of the source, for finding blank lines
+
+	tokens    []token.Token
+	index     map[position]int // Index of each token in tokens
+	enclosing []int            // Index of the '{' token enclosing each token
+	matching  map[int]int      // Index of the '{' matched by each '}'
+	closing   map[int]int      // Index of the '}' matched by each '{'
+
+	comments []comment
+	next     int // Index of the first comment that has not been printed
+
+	indent int
+
+	// The source line on which the last printed entry ended, 0 at the start of
+	// a block where blank lines are never printed
+	last int
+
+	// Set after a top-level declaration, which are always separated by a blank
+	// line
+	forceBlank bool
+}
+
+func newPrinter(src []byte, tokens []token.Token, collected []token.Token) *printer {
+	p := &printer{
+		out:       new(bytes.Buffer),
+		lines:     strings.Split(string(src), "\n"),
+		tokens:    tokens,
+		index:     make(map[position]int, len(tokens)),
+		enclosing: make([]int, len(tokens)),
+		matching:  make(map[int]int, 64),
+		closing:   make(map[int]int, 64),
+		comments:  make([]comment, 0, len(collected)),
+	}
+
+	// Walk the tokens and comments in order, keeping track of the blocks
+	open := make([]int, 0, 16)
+	enclosing := func() int {
+		if len(open) == 0 {
+			return -1
+		}
+		return open[len(open)-1]
+	}
+
+	c := 0
+	addComments := func(before position) {
+		for ; c < len(collected) && positionOf(collected[c]).before(before); c++ {
+			text := strings.TrimRight(string(collected[c].Lexeme), "\r\n")
+			p.comments = append(p.comments, comment{
+				text:  text,
+				pos:   positionOf(collected[c]),
+				end:   collected[c].Line + strings.Count(text, "\n"),
+				block: enclosing(),
+			})
+		}
+	}
+
+	for i, tok := range tokens {
+		addComments(positionOf(tok))
+		p.index[positionOf(tok)] = i
+		p.enclosing[i] = enclosing()
+		switch tok.Id {
+		case token.OPENCUBR:
+			open = append(open, i)
+		case token.CLOSECUBR:
+			if len(open) > 0 {
+				p.matching[i] = open[len(open)-1]
+				p.closing[open[len(open)-1]] = i
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	addComments(position{int(^uint(0) >> 1), 0})
+	return p
+}
+
+// Prints the whole program, top-level declarations are separated by blank
+// lines
+func (p *printer) prog(root *token.ASTNode) {
+	if root != nil && len(root.Children) > 0 {
+		for _, node := range root.Children[0].Children {
+			p.item(node, func() { p.topLevel(node) })
+			p.forceBlank = true
+		}
+	}
+
+	// Remaining comments are found at the end of the file
+	p.forceBlank = false
+	for p.next < len(p.comments) {
+		p.leadingComment(p.comments[p.next])
+	}
+}
+
+func (p *printer) topLevel(node *token.ASTNode) {
+	switch node.Type {
+	case token.FINAL_STRUCT_DECL:
+		p.print("struct ", id(node))
+		if inherits := node.Children[1].Children; len(inherits) > 0 {
+			names := make([]string, 0, len(inherits))
+			for _, parent := range inherits {
+				names = append(names, lexeme(parent))
+			}
+			p.print(" inherits ", strings.Join(names, ", "))
+		}
+		p.print(" ")
+		p.block(p.opening(node, 0), node.Children[2].Children, p.member)
+		p.print(";")
+	case token.FINAL_IMPL_DEF:
+		p.print("impl ", id(node), " ")
+		p.block(p.opening(node, 0), node.Children[1].Children, func(def *token.ASTNode) {
+			p.item(def, func() { p.funcDef(def) })
+		})
+	case token.FINAL_FUNC_DEF:
+		p.funcDef(node)
+	}
+}
+
+func (p *printer) member(node *token.ASTNode) {
+	p.item(node, func() {
+		p.print(lexeme(node.Children[0]), " ")
+		switch decl := node.Children[1]; decl.Type {
+		case token.FINAL_VAR_DECL:
+			p.varDecl(decl)
+		case token.FINAL_FUNC_DECL:
+			p.funcHead(decl)
+			p.print(";")
+		}
+	})
+}
+
+func (p *printer) funcDef(node *token.ASTNode) {
+	p.funcHead(node)
+	p.print(" ")
+	p.block(p.opening(node, 0), node.Children[3].Children, p.statement)
+}
+
+// Prints 'func id(params) -> type'
+func (p *printer) funcHead(node *token.ASTNode) {
+	params := make([]string, 0, len(node.Children[1].Children))
+	for _, param := range node.Children[1].Children {
+		params = append(params, fmt.Sprintf("%v: %v%v",
+			id(param), typ(param.Children[1]), dims(param.Children[2])))
+	}
+	p.print("func ", id(node), "(", strings.Join(params, ", "), ") -> ", typ(node.Children[2]))
+}
+
+func (p *printer) varDecl(node *token.ASTNode) {
+	p.print("let ", id(node), ": ", typ(node.Children[1]), dims(node.Children[2]), ";")
+}
+
+// Prints 'variable = expr' without the semicolon
+func assignment(node *token.ASTNode) string {
+	return expr(node.Children[0]) + " = " + expr(node.Children[1])
+}
+
+// Prints the init clause of a for loop, which may declare the loop variable
+func forInit(block *token.ASTNode) string {
+	assign := block.Children[len(block.Children)-1]
+	if len(block.Children) == 1 {
+		return assignment(assign)
+	}
+	decl := block.Children[0]
+	return fmt.Sprintf("let %v: %v%v = %v",
+		id(decl), typ(decl.Children[1]), dims(decl.Children[2]), expr(assign.Children[1]))
+}
+
+func (p *printer) statement(node *token.ASTNode) {
+	p.item(node, func() {
+		switch node.Type {
+		case token.FINAL_VAR_DECL:
+			p.varDecl(node)
+		case token.FINAL_ASSIGN:
+			p.print(assignment(node), ";")
+		case token.FINAL_FUNC_CALL:
+			p.print(expr(node), ";")
+		case token.FINAL_IF:
+			p.ifStatement(node)
+			p.print(";")
+		case token.FINAL_WHILE:
+			p.print("while (", expr(node.Children[0]), ") ")
+			p.block(p.opening(node, 0), node.Children[1].Children, p.statement)
+			p.print(";")
+		case token.FINAL_FOR:
+			p.print("for (", forInit(node.Children[0]), "; ", expr(node.Children[1]), "; ",
+				assignment(node.Children[2]), ") ")
+			p.block(p.opening(node, 0), node.Children[3].Children, p.statement)
+			p.print(";")
+		case token.FINAL_BREAK:
+			p.print("break;")
+		case token.FINAL_CONTINUE:
+			p.print("continue;")
+		case token.FINAL_READ:
+			p.print("read(", expr(node.Children[0]), ");")
+		case token.FINAL_WRITE:
+			p.print("write(", expr(node.Children[0]), ");")
+		case token.FINAL_RETURN:
+			p.print("return (", expr(node.Children[0]), ");")
+		default:
+			panic(fmt.Errorf("formatter: unexpected statement %v", node.Type))
+		}
+	})
+}
+
+// Prints an if statement without its closing ';', which is shared by all the
+// links of an else if chain
+func (p *printer) ifStatement(node *token.ASTNode) {
+	p.print("if (", expr(node.Children[0]), ") then ")
+	p.block(p.opening(node, 0), node.Children[1].Children, p.statement)
+	if len(node.Children) < 3 {
+		return
+	}
+
+	open := p.opening(node, 1)
+	els := node.Children[2].Children
+	switch {
+	case len(els) == 0 && !p.hasComments(open):
+		p.print(" else ")
+	case open < 0 && len(els) == 1 && els[0].Type == token.FINAL_IF:
+		p.print(" else ")
+		p.ifStatement(els[0])
+	default:
+		p.print(" else ")
+		p.block(open, els, p.statement)
+	}
+}
+
+// Prints a declaration, member, or statement on its own lines, along with the
+// comments that precede it and the comments that trail it on its last line
+func (p *printer) item(node *token.ASTNode, print func()) {
+	first, _ := p.span(node)
+	start := position{}
+	if first >= 0 {
+		start = positionOf(p.tokens[first])
+	}
+	for p.next < len(p.comments) && p.comments[p.next].pos.before(start) {
+		p.leadingComment(p.comments[p.next])
+	}
+
+	if first >= 0 {
+		p.separate(start.line)
+	}
+	p.writeIndent()
+	print()
+
+	end := start.line
+	if first >= 0 {
+		// Trailing comments are found between the end of the item and the next
+		// token, which may be on the same line
+		i := p.end(first)
+		end = p.tokens[i].Line
+		next := position{end + 1, 0}
+		if i+1 < len(p.tokens) {
+			next = positionOf(p.tokens[i+1])
+		}
+		for p.next < len(p.comments) &&
+			p.comments[p.next].pos.line == p.tokens[i].Line &&
+			p.comments[p.next].pos.before(next) {
+			c := p.comments[p.next]
+			p.print(" ", c.text)
+			end = c.end
+			p.next++
+		}
+	}
+	p.print("\n")
+	p.last = end
+}
+
+func (p *printer) leadingComment(c comment) {
+	p.separate(c.pos.line)
+	p.writeIndent()
+	p.print(c.text, "\n")
+	p.last = c.end
+	p.next++
+}
+
+// Prints a blank line before an entry starting on the given line, if there was
+// one in the source
+func (p *printer) separate(line int) {
+	if p.forceBlank || p.last > 0 && p.blankBetween(p.last, line) {
+		p.print("\n")
+	}
+	p.forceBlank = false
+}
+
+func (p *printer) blankBetween(from, to int) bool {
+	for line := from + 1; line < to && line <= len(p.lines); line++ {
+		if strings.TrimSpace(p.lines[line-1]) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Prints a block of items between curly brackets, along with the comments that
+// are found at the end of the block. The block is identified by the index of
+// its '{' token, or -1 if it had none in the source
+func (p *printer) block(block int, items []*token.ASTNode, print func(*token.ASTNode)) {
+	if len(items) == 0 && !p.hasComments(block) {
+		p.print("{ }")
+		return
+	}
+
+	p.print("{\n")
+	p.indent++
+	p.last = 0
+	for _, item := range items {
+		print(item)
+	}
+	for p.next < len(p.comments) && block >= 0 && p.comments[p.next].block == block {
+		p.leadingComment(p.comments[p.next])
+	}
+	p.indent--
+	p.writeIndent()
+	p.print("}")
+}
+
+// Finds the index of the '{' token opening the block of a declaration or
+// statement, the second block of an if statement is its else block. Returns -1
+// if the block is not enclosed in curly brackets
+func (p *printer) opening(node *token.ASTNode, second int) int {
+	first, _ := p.span(node)
+	if first < 0 {
+		return -1
+	}
+
+	switch node.Type {
+	case token.FINAL_STRUCT_DECL, token.FINAL_IMPL_DEF, token.FINAL_FUNC_DEF:
+		for i := first; i < len(p.tokens); i++ {
+			if p.tokens[i].Id == token.OPENCUBR {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// The parenthesized header is followed by the 'then' of if statements
+	i := p.afterParens(first + 1)
+	if node.Type == token.FINAL_IF && i < len(p.tokens) && p.tokens[i].Id == token.THEN {
+		i++
+	}
+	if second == 0 {
+		return p.openCubr(i)
+	}
+
+	// The else block follows the end of the then block
+	i = p.blockEnd(i) + 1
+	if i < len(p.tokens) && p.tokens[i].Id == token.ELSE {
+		return p.openCubr(i + 1)
+	}
+	return -1
+}
+
+// Finds the index of the token following the ')' that matches the '(' at the
+// given index
+func (p *printer) afterParens(i int) int {
+	depth := 0
+	for ; i < len(p.tokens); i++ {
+		switch p.tokens[i].Id {
+		case token.OPENPAR:
+			depth++
+		case token.CLOSEPAR:
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// Finds the last token of the statement block starting at the given index. An
+// empty block ends just before the given index
+func (p *printer) blockEnd(i int) int {
+	if i >= len(p.tokens) {
+		return len(p.tokens) - 1
+	}
+	switch p.tokens[i].Id {
+	case token.OPENCUBR:
+		if close, ok := p.closing[i]; ok {
+			return close
+		}
+		return len(p.tokens) - 1
+	case token.SEMI, token.ELSE:
+		return i - 1
+	}
+	return p.end(i)
+}
+
+// Finds the last token of the blocks of the if statement starting at the given
+// index, following its else if chain
+func (p *printer) ifEnd(i int) int {
+	i = p.blockEnd(p.afterParens(i+1) + 1)
+	if i+1 < len(p.tokens) && p.tokens[i+1].Id == token.ELSE {
+		if i+2 < len(p.tokens) && p.tokens[i+2].Id == token.IF {
+			return p.ifEnd(i + 2)
+		}
+		return p.blockEnd(i + 2)
+	}
+	return i
+}
+
+func (p *printer) openCubr(i int) int {
+	if i < len(p.tokens) && p.tokens[i].Id == token.OPENCUBR {
+		return i
+	}
+	return -1
+}
+
+func (p *printer) hasComments(block int) bool {
+	return block >= 0 && p.next < len(p.comments) && p.comments[p.next].block == block
+}
+
+// Finds the indices of the first and last tokens of a subtree, -1 if it has
+// none
+func (p *printer) span(node *token.ASTNode) (first, last int) {
+	first, last = -1, -1
+	var walk func(n *token.ASTNode)
+	walk = func(n *token.ASTNode) {
+		if i, ok := p.index[positionOf(n.Token)]; ok && n.Token.Line > 0 {
+			if first < 0 || i < first {
+				first = i
+			}
+			if i > last {
+				last = i
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(node)
+	return first, last
+}
+
+// Finds the token that terminates the item starting at the given token. If,
+// while, and for statements end with the ';' following their blocks, other items
+// end with the first ';' or '}' found on the same level of nesting
+func (p *printer) end(first int) int {
+	switch p.tokens[first].Id {
+	case token.IF:
+		return p.bounded(p.ifEnd(first) + 1)
+	case token.WHILE, token.FOR:
+		return p.bounded(p.blockEnd(p.afterParens(first+1)) + 1)
+	}
+
+	level := p.enclosing[first]
+	for i := first; i < len(p.tokens); i++ {
+		if p.enclosing[i] != level {
+			if open, ok := p.matching[i]; !ok || p.enclosing[open] != level {
+				continue
+			}
+		}
+		switch p.tokens[i].Id {
+		case token.SEMI:
+			return i
+		case token.CLOSECUBR:
+			if i+1 < len(p.tokens) && p.tokens[i+1].Id == token.SEMI {
+				return i + 1
+			}
+			return i
+		}
+	}
+	return len(p.tokens) - 1
+}
+
+// Clamps a token index to the last token
+func (p *printer) bounded(i int) int {
+	if i >= len(p.tokens) {
+		return len(p.tokens) - 1
+	}
+	return i
+}
+
+func (p *printer) writeIndent() {
+	for i := 0; i < p.indent; i++ {
+		p.out.WriteString(INDENT)
+	}
+}
+
+func (p *printer) print(s ...string) {
+	for _, str := range s {
+		p.out.WriteString(str)
+	}
+}
+
+// Formats an expression, a variable, or a function call
+func expr(node *token.ASTNode) string {
+	switch node.Type {
+	case token.FINAL_ARITH_EXPR,
+		token.FINAL_REL_EXPR,
+		token.FINAL_EXPR,
+		token.FINAL_TERM,
+		token.FINAL_INDEX,
+		token.FINAL_FUNC_CALL_PARAM:
+		return expr(node.Children[0])
+	case token.FINAL_FACTOR:
+		if len(node.Children) == 2 {
+			return operators[node.Children[0].Type] + expr(node.Children[1])
+		}
+		if child := node.Children[0]; child.Type == token.FINAL_ARITH_EXPR ||
+			child.Type == token.FINAL_REL_EXPR {
+			return "(" + expr(child) + ")"
+		}
+		return expr(node.Children[0])
+	case token.FINAL_INTNUM, token.FINAL_FLOATNUM, token.FINAL_STRINGLIT, token.FINAL_BOOLLIT:
+		return lexeme(node)
+	case token.FINAL_VARIABLE:
+		out := subject(node.Children[0]) + lexeme(node.Children[1])
+		for _, index := range node.Children[2].Children {
+			out += "[" + expr(index) + "]"
+		}
+		return out
+	case token.FINAL_FUNC_CALL:
+		params := make([]string, 0, len(node.Children[2].Children))
+		for _, param := range node.Children[2].Children {
+			params = append(params, expr(param))
+		}
+		return fmt.Sprintf("%v%v(%v)",
+			subject(node.Children[0]), lexeme(node.Children[1]), strings.Join(params, ", "))
+	}
+	if op, ok := operators[node.Type]; ok && len(node.Children) == 2 {
+		return fmt.Sprintf("%v %v %v", expr(node.Children[0]), op, expr(node.Children[1]))
+	}
+	panic(fmt.Errorf("formatter: unexpected expression %v", node.Type))
+}
+
+func subject(node *token.ASTNode) string {
+	if len(node.Children) == 0 {
+		return ""
+	}
+	return expr(node.Children[0]) + "."
+}
+
+// Formats a Type or ReturnType node
+func typ(node *token.ASTNode) string {
+	if len(node.Children) > 0 {
+		return typ(node.Children[0])
+	}
+	return lexeme(node)
+}
+
+func dims(node *token.ASTNode) string {
+	var out strings.Builder
+	for _, dim := range node.Children {
+		if dim.Token.Id == token.EMPTY_DIM {
+			out.WriteString("[]")
+		} else {
+			fmt.Fprintf(&out, "[%v]", lexeme(dim))
+		}
+	}
+	return out.String()
+}
+
+func id(node *token.ASTNode) string {
+	return lexeme(node.Children[0])
+}
+
+func lexeme(node *token.ASTNode) string {
+	return string(node.Token.Lexeme)
+}
@@ -0,0 +1,278 @@
+package formatter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/tabledrivenscanner"
+	scannertable "github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		src  string
+		out  string
+	}{
+		{
+			name: "structs and impls",
+			src: `
+struct  A inherits B,C{private let x:integer[2][];public func f(p:A,q:float[])->void;};
+impl A{func f(p:A,q:float[])->void{p.x[1][0]=1;}}
+`,
+			out: `struct A inherits B, C {
+	private let x: integer[2][];
+	public func f(p: A, q: float[]) -> void;
+};
+
+impl A {
+	func f(p: A, q: float[]) -> void {
+		p.x[1][0] = 1;
+	}
+}
+`,
+		},
+		{
+			name: "statements",
+			src: `func main()->void{let x:integer;
+read(x);
+if(x<>1)then write(x);else{x=-(x+1)*2;};
+while(!x&(x+1)*2<20){}; return(f(x,y.z()).w);}`,
+			out: `func main() -> void {
+	let x: integer;
+	read(x);
+	if (x <> 1) then {
+		write(x);
+	} else {
+		x = -(x + 1) * 2;
+	};
+	while (!x & (x + 1) * 2 < 20) { };
+	return (f(x, y.z()).w);
+}
+`,
+		},
+		{
+			name: "comments and blank lines",
+			src: `// leading
+
+
+/* block
+   comment */
+func main() -> void { // moved inside
+    x = 1;    /* trailing */
+
+
+    while (x < 2) {
+        x = 2;
+        // end of while
+    };
+    // end of main
+}
+// end of file
+`,
+			out: `// leading
+
+/* block
+   comment */
+func main() -> void {
+	// moved inside
+	x = 1; /* trailing */
+
+	while (x < 2) {
+		x = 2;
+		// end of while
+	};
+	// end of main
+}
+// end of file
+`,
+		},
+		{
+			name: "trailing comments of nested blocks",
+			src: `func f() -> void {
+  if (a < 1) then if (b < 1) then x = 1; else ; else ; // outer
+  if (a < 1) then x = 1; // then
+  else { x = 2; }; // if
+  while (a < 1) { // empty
+  };
+}
+`,
+			out: `func f() -> void {
+	if (a < 1) then {
+		if (b < 1) then {
+			x = 1;
+		} else ;
+	} else ; // outer
+	if (a < 1) then {
+		x = 1; // then
+	} else {
+		x = 2;
+	}; // if
+	while (a < 1) {
+		// empty
+	};
+}
+`,
+		},
+		{
+			name: "for loops",
+			src: `func f() -> void {
+  for (let i:integer=0;i<(n);i=i+1) { // loop
+    if (i == 2) then continue; else { break; };
+  };
+  for (i = 0; i < 2; i = i + 1) ;
+}
+`,
+			out: `func f() -> void {
+	for (let i: integer = 0; i < (n); i = i + 1) {
+		// loop
+		if (i == 2) then {
+			continue;
+		} else {
+			break;
+		};
+	};
+	for (i = 0; i < 2; i = i + 1) { };
+}
+`,
+		},
+		{
+			name: "else-less if and else if chains",
+			src: `func f() -> void {
+  if (a < 1) then write(1);;
+  if (a < 1) then write(1); // one
+  else if (a < 2) then { write(2); }
+  else if (a < 3) then else write(3);;
+  if (a < 1) then if (a < 2) then write(2); else write(3);;;
+}
+`,
+			out: `func f() -> void {
+	if (a < 1) then {
+		write(1);
+	};
+	if (a < 1) then {
+		write(1); // one
+	} else if (a < 2) then {
+		write(2);
+	} else if (a < 3) then { } else {
+		write(3);
+	};
+	if (a < 1) then {
+		if (a < 2) then {
+			write(2);
+		} else {
+			write(3);
+		};
+	};
+}
+`,
+		},
+		{
+			name: "bools",
+			src: `func f(b:bool) -> bool {
+  while ((a<1)&!(b==false)) ;
+  return (true|b);
+}
+`,
+			out: `func f(b: bool) -> bool {
+	while ((a < 1) & !(b == false)) { };
+	return (true | b);
+}
+`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out := mustFormat(t, []byte(strings.TrimLeft(tc.src, "\n")))
+			if string(out) != tc.out {
+				t.Errorf("expected:\n%v\nbut got:\n%v", tc.out, string(out))
+			}
+			assertRoundTrip(t, []byte(tc.src))
+		})
+	}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, file := range []string{"bubblesort.src", "polynomial.src"} {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			t.Parallel()
+			src, err := os.ReadFile(filepath.Join("..", "..", "resources", "src", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertRoundTrip(t, src)
+		})
+	}
+}
+
+func TestFormat_SyntaxError(t *testing.T) {
+	t.Parallel()
+	_, err := Format([]byte("func main() -> void { x = ; }"))
+	var syntax *SyntaxError
+	if !errors.As(err, &syntax) || len(syntax.Diagnostics) == 0 {
+		t.Fatalf("expected a SyntaxError but got %v", err)
+	}
+}
+
+// Formatting must be idempotent, and must not change the AST
+func assertRoundTrip(t *testing.T, src []byte) {
+	t.Helper()
+	once := mustFormat(t, src)
+	twice := mustFormat(t, once)
+	if !bytes.Equal(once, twice) {
+		t.Errorf("formatting is not idempotent, first:\n%v\nsecond:\n%v", string(once), string(twice))
+	}
+	if !sameAST(parse(t, src), parse(t, once)) {
+		t.Errorf("formatting changed the AST of:\n%v", string(src))
+	}
+}
+
+func mustFormat(t *testing.T, src []byte) []byte {
+	t.Helper()
+	out, err := Format(src)
+	if err != nil {
+		t.Fatalf("failed to format:\n%v\nerror: %v", string(src), err)
+	}
+	return out
+}
+
+func parse(t *testing.T, src []byte) *token.ASTNode {
+	t.Helper()
+	prsr := tabledrivenparser.NewParserNoDefaultComments(
+		tabledrivenscanner.NewScanner(
+			chuggingcharsource.MustChuggingReader(bytes.NewReader(src)),
+			scannertable.TABLE()),
+		parsertable.TABLE(), nil, nil)
+	if !prsr.Parse() {
+		t.Fatalf("failed to parse:\n%v", string(src))
+	}
+	return prsr.AST().Root
+}
+
+// Compares the kinds and lexemes of two trees, positions are ignored
+func sameAST(a, b *token.ASTNode) bool {
+	if a.Type != b.Type ||
+		a.Token.Id != b.Token.Id ||
+		!bytes.Equal([]byte(a.Token.Lexeme), []byte(b.Token.Lexeme)) ||
+		len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i := range a.Children {
+		if !sameAST(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
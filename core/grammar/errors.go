@@ -0,0 +1,86 @@
+package grammar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+var (
+	ErrEmptyGrammar = fmt.Errorf("grammar has no productions")
+)
+
+// Returned by Parse when a line of the grammar is not a production
+type SyntaxError struct {
+	Line   int
+	Text   string
+	Symbol string // The offending symbol, empty if the whole line is malformed
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Symbol != "" {
+		return fmt.Sprintf(
+			"grammar: line %v: unrecognized symbol '%v' in '%v'",
+			e.Line, e.Symbol, e.Text)
+	}
+	return fmt.Sprintf("grammar: line %v: expected '<lhs> ::= ...' but got '%v'", e.Line, e.Text)
+}
+
+// Returned by Parse when a nonterminal appears on the RHS of a production but
+// has no productions of its own
+type UndefinedError struct {
+	Nonterminal token.Kind
+}
+
+func (e *UndefinedError) Error() string {
+	return fmt.Sprintf("grammar: no productions for nonterminal '%v'", e.Nonterminal)
+}
+
+// Returned by Grammar.Table when the grammar is left recursive, a top-down
+// parser can't be built for it
+type LeftRecursionError struct {
+	Cycles []string // Paths such as "<a> -> <b> -> <a>"
+}
+
+func (e *LeftRecursionError) Error() string {
+	return fmt.Sprintf("grammar: left recursion: %v", strings.Join(e.Cycles, "; "))
+}
+
+// Two or more productions that compete for the same entry of the parser table
+type Conflict struct {
+	Nonterminal token.Kind
+	Terminal    token.Kind
+	Rules       []token.Rule
+}
+
+func (c Conflict) String() string {
+	rules := make([]string, 0, len(c.Rules))
+	for _, r := range c.Rules {
+		rhs := make([]string, 0, len(r.RHS))
+		for _, k := range r.RHS {
+			rhs = append(rhs, string(k))
+		}
+		rules = append(rules, fmt.Sprintf("%v ::= %v", r.LHS, strings.Join(rhs, " ")))
+	}
+	return fmt.Sprintf(
+		"%v on '%v': %v",
+		c.Nonterminal, c.Terminal, strings.Join(rules, " | "))
+}
+
+// Returned by Grammar.Table when the grammar is not LL(1), or by
+// Grammar.LALRTable when the grammar is not LALR(1)
+type ConflictError struct {
+	Table     string // The kind of table, e.g.: LL(1)
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	conflicts := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		conflicts = append(conflicts, c.String())
+	}
+	return fmt.Sprintf(
+		"grammar: %v %v conflict(s): %v",
+		len(e.Conflicts), e.Table, strings.Join(conflicts, "; "))
+}
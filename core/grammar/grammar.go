@@ -0,0 +1,304 @@
+// Package grammar parses grammar files written in the format of
+// generate/grammar-sem.grm and computes their FIRST and FOLLOW sets, LL(1)
+// parser table, and LALR(1) ACTION and GOTO tables. The LL(1) table can be
+// handed straight to a TableDrivenParser and the LALR(1) tables to an
+// LRParser, so parsers can be built from a grammar at runtime instead of from
+// the code that generate/tool.go bakes into the token package.
+//
+// A grammar file has one production per line:
+//
+//	<LHS> ::= <nonterminal> 'terminal' (SEMANTIC-ACTION) ...
+//
+// Blank lines and lines starting with "//" are ignored. The LHS of the first
+// production is the start symbol. Terminals are converted to the token.Kind
+// that the scanner produces for them, e.g.: 'intNum' is token.INTNUM and '+' is
+// token.PLUS. Semantic actions are converted to the token.Kind of the generated
+// SEM_* constants, e.g.: (FACTOR-MAKENODE) is token.SEM_FACTOR_MAKENODE.
+package grammar
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/obonobo/esac/core/grammar/ll1"
+	"github.com/obonobo/esac/core/token"
+)
+
+// A parsed grammar, its FIRST and FOLLOW sets and LL(1) table are computed by
+// the ll1 package from its productions without their semantic actions
+type Grammar struct {
+	start           token.Kind
+	rules           token.Rules
+	order           []token.Kind // Nonterminals in the order of their first rule
+	terminals       token.KindSet
+	nonterminals    token.KindSet
+	semanticActions token.KindSet
+	ll1             *ll1.Grammar[token.Kind]
+}
+
+// Parses a grammar from the reader, returns a *SyntaxError if a line is not a
+// production, or an *UndefinedError if a nonterminal is used without having
+// any productions
+func Parse(r io.Reader) (*Grammar, error) {
+	g := &Grammar{
+		rules:           make(token.Rules, 128),
+		terminals:       make(token.KindSet, 128),
+		nonterminals:    make(token.KindSet, 128),
+		semanticActions: make(token.KindSet, 128),
+	}
+
+	scnr := bufio.NewScanner(r)
+	for line := 1; scnr.Scan(); line++ {
+		text := strings.TrimSpace(scnr.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+		if err := g.addRule(text, line); err != nil {
+			return nil, err
+		}
+	}
+	if err := scnr.Err(); err != nil {
+		return nil, err
+	}
+	if len(g.order) == 0 {
+		return nil, ErrEmptyGrammar
+	}
+
+	for _, nonterminal := range sortedKinds(g.nonterminals) {
+		if _, ok := g.rules[nonterminal]; !ok {
+			return nil, &UndefinedError{Nonterminal: nonterminal}
+		}
+	}
+
+	g.start = g.order[0]
+	g.analyse()
+	return g, nil
+}
+
+// Creates a grammar from a set of rules, such as the token.RULES() that are
+// generated by generate/tool.go. Symbols that have rules are nonterminals,
+// symbols that are known to token.IsSemAction are semantic actions, and all
+// other symbols are terminals. Returns an *UndefinedError if the start symbol
+// has no rules
+func FromRules(start token.Kind, rules token.Rules) (*Grammar, error) {
+	g := &Grammar{
+		start:           start,
+		rules:           rules,
+		terminals:       make(token.KindSet, 128),
+		nonterminals:    make(token.KindSet, len(rules)),
+		semanticActions: make(token.KindSet, 128),
+	}
+	if _, ok := rules[start]; !ok {
+		return nil, &UndefinedError{Nonterminal: start}
+	}
+
+	g.order = append(g.order, start)
+	for _, lhs := range sortedKinds(keys(rules)) {
+		g.nonterminals[lhs] = struct{}{}
+		if lhs != start {
+			g.order = append(g.order, lhs)
+		}
+	}
+	for _, lhs := range g.order {
+		for _, r := range rules[lhs] {
+			for _, k := range r.RHS {
+				if _, ok := rules[k]; ok {
+					continue
+				}
+				if token.IsSemAction(k) {
+					g.semanticActions[k] = struct{}{}
+				} else {
+					g.terminals[k] = struct{}{}
+				}
+			}
+		}
+	}
+
+	g.analyse()
+	return g, nil
+}
+
+// Hands the productions, without their semantic actions, to the ll1 package
+func (g *Grammar) analyse() {
+	productions := make(map[token.Kind][]ll1.Production[token.Kind], len(g.rules))
+	for _, a := range g.order {
+		for _, r := range g.rules[a] {
+			productions[a] = append(productions[a], g.production(r))
+		}
+	}
+	g.ll1 = &ll1.Grammar[token.Kind]{
+		Start:        g.start,
+		Order:        g.order,
+		Productions:  productions,
+		Terminals:    g.terminals,
+		Nonterminals: g.nonterminals,
+	}
+}
+
+// Returns the starting nonterminal symbol
+func (g *Grammar) Start() token.Kind {
+	return g.start
+}
+
+// Returns the productions of the grammar, keyed by their LHS
+func (g *Grammar) Rules() token.Rules {
+	return g.rules
+}
+
+// Returns the set of terminal symbols, including EPSILON if the grammar uses it
+func (g *Grammar) Terminals() token.KindSet {
+	return g.terminals
+}
+
+// Returns the set of nonterminal symbols
+func (g *Grammar) Nonterminals() token.KindSet {
+	return g.nonterminals
+}
+
+// Returns the set of semantic actions that appear in the productions
+func (g *Grammar) SemanticActions() token.KindSet {
+	return g.semanticActions
+}
+
+// Returns the FIRST set of every terminal and nonterminal symbol
+func (g *Grammar) Firsts() map[token.Kind]token.KindSet {
+	return g.ll1.Firsts()
+}
+
+// Returns the FOLLOW set of every terminal and nonterminal symbol
+func (g *Grammar) Follows() map[token.Kind]token.KindSet {
+	return g.ll1.Follows()
+}
+
+func (g *Grammar) addRule(text string, line int) error {
+	words := strings.Fields(text)
+	if len(words) < 3 || words[1] != "::=" || !isNonterminal(words[0]) {
+		return &SyntaxError{Line: line, Text: text}
+	}
+
+	lhs := token.Kind(words[0])
+	rule := token.Rule{LHS: lhs, RHS: make([]token.Kind, 0, len(words)-2)}
+	for _, word := range words[2:] {
+		var kind token.Kind
+		switch {
+		case isNonterminal(word):
+			kind = token.Kind(word)
+			g.nonterminals[kind] = struct{}{}
+		case isSemanticAction(word):
+			kind = semanticAction(word)
+			g.semanticActions[kind] = struct{}{}
+		case isTerminal(word):
+			kind = terminal(word)
+			g.terminals[kind] = struct{}{}
+		default:
+			return &SyntaxError{Line: line, Text: text, Symbol: word}
+		}
+		rule.RHS = append(rule.RHS, kind)
+	}
+
+	if _, ok := g.rules[lhs]; !ok {
+		g.order = append(g.order, lhs)
+	}
+	g.nonterminals[lhs] = struct{}{}
+	g.rules[lhs] = append(g.rules[lhs], rule)
+	return nil
+}
+
+// Converts a symbol as it is written in a grammar file to its token.Kind, e.g.:
+// '+' is token.PLUS and (FACTOR-MAKENODE) is token.SEM_FACTOR_MAKENODE.
+// Nonterminals, and words that are not symbols, are returned unchanged
+func Symbol(word string) token.Kind {
+	switch {
+	case isSemanticAction(word):
+		return semanticAction(word)
+	case isTerminal(word):
+		return terminal(word)
+	}
+	return token.Kind(word)
+}
+
+// Returns the RHS of the rule without its semantic actions
+func (g *Grammar) symbols(rule token.Rule) []token.Kind {
+	out := make([]token.Kind, 0, len(rule.RHS))
+	for _, k := range rule.RHS {
+		if !g.isSemanticAction(k) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// Returns the rule without its semantic actions
+func (g *Grammar) production(rule token.Rule) ll1.Production[token.Kind] {
+	return ll1.Production[token.Kind]{LHS: rule.LHS, RHS: g.symbols(rule)}
+}
+
+func (g *Grammar) isSemanticAction(symbol token.Kind) bool {
+	_, ok := g.semanticActions[symbol]
+	return ok
+}
+
+func isNonterminal(word string) bool {
+	return len(word) > 2 && strings.HasPrefix(word, "<") && strings.HasSuffix(word, ">")
+}
+
+func isSemanticAction(word string) bool {
+	return len(word) > 2 && strings.HasPrefix(word, "(") && strings.HasSuffix(word, ")")
+}
+
+func isTerminal(word string) bool {
+	return word == string(token.EPSILON) ||
+		len(word) > 2 && strings.HasPrefix(word, "'") && strings.HasSuffix(word, "'")
+}
+
+// Semantic actions are prefixed with SEM- if they aren't already
+func semanticAction(word string) token.Kind {
+	if strings.HasPrefix(word, "(SEM-") {
+		return token.Kind(word)
+	}
+	return token.Kind("(SEM-" + word[1:])
+}
+
+// Converts a quoted terminal to the kind of token that the scanner produces
+func terminal(word string) token.Kind {
+	if word == string(token.EPSILON) {
+		return token.EPSILON
+	}
+	word = word[1 : len(word)-1]
+	if k, ok := punctuation[word]; ok {
+		return k
+	}
+	if k, ok := renamed[strings.ToLower(word)]; ok {
+		return k
+	}
+	return token.Kind(strings.ToLower(word))
+}
+
+// Terminals that are spelled out by their symbol in the grammar
+var punctuation = map[string]token.Kind{
+	"+":  token.PLUS,
+	"-":  token.MINUS,
+	"*":  token.MULT,
+	"/":  token.DIV,
+	"=":  token.ASSIGN,
+	"[":  token.OPENSQBR,
+	"]":  token.CLOSESQBR,
+	"{":  token.OPENCUBR,
+	"}":  token.CLOSECUBR,
+	"(":  token.OPENPAR,
+	")":  token.CLOSEPAR,
+	";":  token.SEMI,
+	".":  token.DOT,
+	":":  token.COLON,
+	"::": token.COLONCOLON,
+	",":  token.COMMA,
+	"->": token.ARROW,
+}
+
+// Terminals whose name in the grammar differs from their token kind
+var renamed = map[string]token.Kind{
+	"neq":      token.NOTEQ,
+	"intlit":   token.INTNUM,
+	"floatlit": token.FLOATNUM,
+}
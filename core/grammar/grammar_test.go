@@ -0,0 +1,311 @@
+package grammar
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	"github.com/obonobo/esac/core/token"
+)
+
+// The grammar package and generate/tool.go must agree on the grammar of the
+// language
+func TestMatchesGeneratedTable(t *testing.T) {
+	fh, err := os.Open("../../generate/grammar-sem.grm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+
+	g, err := Parse(fh)
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	table, err := g.Table()
+	if err != nil {
+		t.Fatalf("Failed to build the table: %v", err)
+	}
+
+	if g.Start() != token.START {
+		t.Errorf("Expected start symbol %v but got %v", token.START, g.Start())
+	}
+	for _, tc := range []struct {
+		name             string
+		expected, actual interface{}
+	}{
+		{"rules", token.RULES(), g.Rules()},
+		{"nonterminals", token.NONTERMINALS(), g.Nonterminals()},
+		{"firsts", token.FIRSTS(), g.Firsts()},
+		{"follows", token.FOLLOWS(), g.Follows()},
+		{"table", token.TABLE(), table.TT},
+	} {
+		if !reflect.DeepEqual(tc.expected, tc.actual) {
+			t.Errorf("The %v differ from the generated %v", tc.name, tc.name)
+		}
+	}
+	for k := range g.Terminals() {
+		if !token.IsTerminal(k) {
+			t.Errorf("Terminal %v is not a token kind", k)
+		}
+	}
+}
+
+// Builds a parser from an inline grammar
+func TestInlineGrammar(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	// Sums of ids
+	<expr> ::= <term> <expr-tail>
+	<expr-tail> ::= '+' <term> <expr-tail>
+	<expr-tail> ::= EPSILON
+	<term> ::= 'id'
+	<term> ::= '(' <expr> ')'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	table, err := g.Table()
+	if err != nil {
+		t.Fatalf("Failed to build the table: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		input    []token.Kind
+		expected string // The first syntax error, empty if the parse succeeds
+	}{
+		{
+			name:  "sum",
+			input: []token.Kind{token.ID, token.PLUS, token.OPENPAR, token.ID, token.PLUS, token.ID, token.CLOSEPAR},
+		},
+		{
+			name:     "missing operand",
+			input:    []token.Kind{token.ID, token.PLUS, token.PLUS, token.ID},
+			expected: "unexpected token 'plus', should be 'id', or 'openpar'",
+		},
+		{
+			name:     "unclosed parenthesis",
+			input:    []token.Kind{token.OPENPAR, token.ID},
+			expected: "got EOF in the middle of a sentence",
+		},
+		{
+			name:     "trailing input",
+			input:    []token.Kind{token.ID, token.PLUS, token.ID, token.ID},
+			expected: "unexpected token 'id'",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var errs []string
+			parser := tabledrivenparser.NewParser(
+				&kindScanner{kinds: tc.input},
+				table,
+				func(e *tabledrivenparser.ParserError) { errs = append(errs, e.Err.Error()) },
+				nil)
+
+			ok := parser.Parse()
+			if tc.expected == "" {
+				if !ok || len(errs) > 0 {
+					t.Errorf("Expected the parse to succeed but got errors %v", errs)
+				}
+				return
+			}
+			if ok || len(errs) == 0 || !strings.HasPrefix(errs[0], tc.expected) {
+				t.Errorf("Expected an error starting with %q but got %v", tc.expected, errs)
+			}
+		})
+	}
+}
+
+// Once the start symbol is derived, any remaining token is a syntax error
+func TestTrailingInput(t *testing.T) {
+	t.Parallel()
+	g, err := Parse(strings.NewReader(`<START> ::= 'id'`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	table, err := g.Table()
+	if err != nil {
+		t.Fatalf("Failed to build the table: %v", err)
+	}
+
+	var errs []*tabledrivenparser.ParserError
+	parser := tabledrivenparser.NewParser(
+		&kindScanner{kinds: []token.Kind{token.ID, token.ID, token.ID}},
+		table,
+		func(e *tabledrivenparser.ParserError) { errs = append(errs, e) },
+		nil)
+	if parser.Parse() {
+		t.Fatalf("Expected the parse to fail")
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Expected exactly 1 error but got %v", errs)
+	}
+	if _, ok := errs[0].Err.(*tabledrivenparser.UnexpectedTokenError); !ok {
+		t.Errorf("Expected an UnexpectedTokenError but got %T: %v", errs[0].Err, errs[0].Err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var syntax *SyntaxError
+	var undefined *UndefinedError
+	for _, tc := range []struct {
+		name    string
+		grammar string
+		target  interface{}
+	}{
+		{"no productions", "// nothing here\n", &ErrEmptyGrammar},
+		{"missing arrow", "<a> 'x'", &syntax},
+		{"bad symbol", "<a> ::= x", &syntax},
+		{"undefined nonterminal", "<a> ::= <b> 'x'", &undefined},
+	} {
+		_, err := Parse(strings.NewReader(tc.grammar))
+		switch target := tc.target.(type) {
+		case *error:
+			if !errors.Is(err, *target) {
+				t.Errorf("%v: expected %v but got %v", tc.name, *target, err)
+			}
+		default:
+			if !errors.As(err, target) {
+				t.Errorf("%v: expected a %T but got %v", tc.name, target, err)
+			}
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	<START> ::= <a>
+	<a> ::= 'x' 'y'
+	<a> ::= 'x' 'z'
+	<a> ::= 'if' <a>
+	<a> ::= <b>
+	<b> ::= 'if' 'x'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+
+	// Only one rule starts with 'if', but <b> does not derive a nested <a> so
+	// this is not a dangling 'else' and the conflict is not resolved
+	_, err = g.Table()
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected a *ConflictError but got %v", err)
+	}
+	expected := []Conflict{
+		{
+			Nonterminal: "<a>",
+			Terminal:    "x",
+			Rules: []token.Rule{
+				{LHS: "<a>", RHS: []token.Kind{"x", "y"}},
+				{LHS: "<a>", RHS: []token.Kind{"x", "z"}},
+			},
+		},
+		{
+			Nonterminal: "<a>",
+			Terminal:    "if",
+			Rules: []token.Rule{
+				{LHS: "<a>", RHS: []token.Kind{"if", "<a>"}},
+				{LHS: "<a>", RHS: []token.Kind{"<b>"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(conflict.Conflicts, expected) {
+		t.Errorf("Expected conflicts %v but got %v", expected, conflict.Conflicts)
+	}
+}
+
+func TestDanglingElse(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	<START> ::= <stat>
+	<stat> ::= 'if' 'id' 'then' <stat> <else>
+	<stat> ::= 'id'
+	<else> ::= 'else' <else-disambiguate>
+	<else> ::= ';'
+	<else-disambiguate> ::= 'if' 'id' 'then' <stat> <else>
+	<else-disambiguate> ::= <stat> ';'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	table, err := g.Table()
+	if err != nil {
+		t.Fatalf("Failed to build the table: %v", err)
+	}
+
+	expected := token.Rule{LHS: "<else-disambiguate>", RHS: []token.Kind{"if", "id", "then", "<stat>", "<else>"}}
+	if actual := table.TT[token.Key{Nonterminal: "<else-disambiguate>", Terminal: "if"}]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected the entry to be resolved to %v but got %v", expected, actual)
+	}
+	if report := g.Lint(); report.Problems() != 0 || len(report.Resolved) != 1 {
+		t.Errorf("Expected one resolved conflict and no problems but got %+v", report)
+	}
+}
+
+func TestLeftRecursion(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	<START> ::= <a>
+	<a> ::= <a> 'id'
+	<a> ::= 'id'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+
+	_, err = g.Table()
+	var recursion *LeftRecursionError
+	if !errors.As(err, &recursion) {
+		t.Fatalf("Expected a *LeftRecursionError but got %v", err)
+	}
+	if expected := []string{"<a> -> <a>"}; !reflect.DeepEqual(recursion.Cycles, expected) {
+		t.Errorf("Expected cycles %v but got %v", expected, recursion.Cycles)
+	}
+
+	// The conflict caused by the recursion is not mistaken for a dangling 'else'
+	report := g.Lint()
+	if len(report.Conflicts) != 1 || len(report.Resolved) != 0 {
+		t.Errorf("Expected the conflict on 'id' to be reported but got %+v", report)
+	}
+}
+
+func TestLint(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	<START> ::= <a>
+	<a> ::= 'x' <a>
+	<b> ::= 'y'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	report := g.Lint()
+	expected := &Report{
+		Unproductive: []token.Kind{"<START>", "<a>"},
+		Unreachable:  []token.Kind{"<b>"},
+		Unused:       []token.Kind{"y"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected report %+v but got %+v", expected, report)
+	}
+	if report.Problems() != 4 {
+		t.Errorf("Expected 4 problems but got %v", report.Problems())
+	}
+}
+
+// Produces a token of each kind, followed by io.EOF
+type kindScanner struct {
+	kinds []token.Kind
+	i     int
+}
+
+func (s *kindScanner) NextToken() (token.Token, error) {
+	if s.i >= len(s.kinds) {
+		return token.Token{}, io.EOF
+	}
+	s.i++
+	return token.Token{Id: s.kinds[s.i-1], Line: 1, Column: s.i}, nil
+}
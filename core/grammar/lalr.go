@@ -0,0 +1,405 @@
+package grammar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/obonobo/esac/core/grammar/ll1"
+	"github.com/obonobo/esac/core/lrparser"
+	"github.com/obonobo/esac/core/token"
+)
+
+// The start symbol of the augmented grammar, <ACCEPT> ::= <START>
+const accept token.Kind = "<ACCEPT>"
+
+// LALR(1) ACTION and GOTO tables
+//
+// IMPLEMENTS:
+// t *LALRTable lrparser.Table
+type LALRTable struct {
+	actions     []map[token.Kind]lrparser.Action
+	gotos       []map[token.Kind]int
+	productions []lrparser.Production
+}
+
+func (t *LALRTable) Action(state int, terminal token.Kind) (lrparser.Action, bool) {
+	if state < 0 || state >= len(t.actions) {
+		return lrparser.Action{}, false
+	}
+	a, ok := t.actions[state][terminal]
+	return a, ok
+}
+
+func (t *LALRTable) Goto(state int, nonterminal token.Kind) (int, bool) {
+	if state < 0 || state >= len(t.gotos) {
+		return 0, false
+	}
+	s, ok := t.gotos[state][nonterminal]
+	return s, ok
+}
+
+func (t *LALRTable) Production(i int) lrparser.Production {
+	return t.productions[i]
+}
+
+func (t *LALRTable) Expected(state int) []token.Kind {
+	if state < 0 || state >= len(t.actions) {
+		return nil
+	}
+	expected := make(token.KindSet, len(t.actions[state]))
+	for k := range t.actions[state] {
+		expected[k] = struct{}{}
+	}
+	return sortedKinds(expected)
+}
+
+// Returns the number of states of the LR automaton
+func (t *LALRTable) States() int {
+	return len(t.actions)
+}
+
+// Builds the LALR(1) tables of the grammar, for the LRParser.
+//
+// Unlike an LL(1) grammar, the grammar may be left recursive and does not need
+// to be left factored. Shift-reduce conflicts are resolved in favour of the
+// shift. Reduce-reduce conflicts are resolved the same way that Table resolves
+// LL(1) conflicts: if exactly one of the reductions comes from a rule that
+// starts with the lookahead, that reduction wins. This is how the dangling
+// 'else' is resolved when semantic actions precede the 'if'. Any other
+// reduce-reduce conflict makes the grammar unusable, and a *ConflictError
+// listing all of them is returned.
+//
+// Semantic actions in the middle of a rule become productions of their own (see
+// lrparser.Production), which may introduce conflicts that the grammar wouldn't
+// have otherwise
+func (g *Grammar) LALRTable() (*LALRTable, error) {
+	lg := newLRGrammar(g)
+	states := lg.automaton()
+
+	table := &LALRTable{
+		actions:     make([]map[token.Kind]lrparser.Action, len(states)),
+		gotos:       make([]map[token.Kind]int, len(states)),
+		productions: make([]lrparser.Production, len(lg.prods)),
+	}
+	for i, p := range lg.prods {
+		table.productions[i] = lrparser.Production{
+			LHS:     p.lhs,
+			Length:  len(p.rhs),
+			Actions: p.actions,
+			Rule:    p.rule,
+		}
+	}
+
+	var conflicts []Conflict
+	for s, state := range states {
+		actions := make(map[token.Kind]lrparser.Action, 16)
+		gotos := make(map[token.Kind]int, 16)
+		for x, target := range state.gotos {
+			if lg.isNonterminal(x) {
+				gotos[x] = target
+			} else {
+				actions[x] = lrparser.Action{Type: lrparser.SHIFT, Target: target}
+			}
+		}
+
+		// Shifts have been added already, so reductions only need to check
+		// for other reductions
+		items := lg.closure(state.lookaheads)
+		reductions := make(map[token.Kind][]int)
+		for _, it := range sortedItems(items) {
+			p := lg.prods[it.prod]
+			if it.dot < len(p.rhs) {
+				continue
+			}
+			for _, t := range sortedKinds(items[it]) {
+				if _, ok := actions[t]; ok && actions[t].Type == lrparser.SHIFT {
+					continue
+				}
+				reductions[t] = append(reductions[t], it.prod)
+				if it.prod == 0 {
+					actions[t] = lrparser.Action{Type: lrparser.ACCEPT}
+				} else {
+					actions[t] = lrparser.Action{Type: lrparser.REDUCE, Target: it.prod}
+				}
+			}
+		}
+		for _, t := range sortedKinds(keys(reductions)) {
+			prods := reductions[t]
+			if len(prods) < 2 {
+				continue
+			}
+			if p, ok := lg.resolveConflict(g, t, prods); ok {
+				actions[t] = lrparser.Action{Type: lrparser.REDUCE, Target: p}
+			} else {
+				conflicts = append(conflicts, lg.conflict(t, prods))
+			}
+		}
+
+		table.actions[s] = actions
+		table.gotos[s] = gotos
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &ConflictError{Table: "LALR(1)", Conflicts: conflicts}
+	}
+	return table, nil
+}
+
+// The augmented grammar that the LR automaton is built from
+type lrGrammar struct {
+	prods  []lrProd // prods[0] is <ACCEPT> ::= start
+	byLHS  map[token.Kind][]int
+	firsts map[token.Kind]token.KindSet
+}
+
+type lrProd struct {
+	lhs     token.Kind
+	rhs     []token.Kind
+	actions []token.Kind
+	rule    token.Rule // Emitted when reducing, empty for semantic actions
+	origin  token.Rule // The rule that this production was made from
+}
+
+// An LR(0) item: a production with a dot at some position of its RHS
+type lrItem struct{ prod, dot int }
+
+// A state of the LR automaton, identified by the LR(0) items of its kernel
+type lrState struct {
+	lookaheads map[lrItem]token.KindSet // The kernel items and their lookaheads
+	gotos      map[token.Kind]int
+}
+
+func newLRGrammar(g *Grammar) *lrGrammar {
+	lg := &lrGrammar{byLHS: make(map[token.Kind][]int, len(g.rules)+1)}
+	lg.add(lrProd{lhs: accept, rhs: []token.Kind{g.start}})
+
+	for _, a := range g.order {
+		for _, r := range g.rules[a] {
+			rhs := make([]token.Kind, 0, len(r.RHS))
+			var pending []token.Kind // Semantic actions since the last symbol
+			for _, k := range r.RHS {
+				switch {
+				case g.isSemanticAction(k):
+					pending = append(pending, k)
+				case k == token.EPSILON:
+				default:
+					if len(pending) > 0 {
+						rhs = append(rhs, lg.marker(pending, r))
+						pending = nil
+					}
+					rhs = append(rhs, k)
+				}
+			}
+			lg.add(lrProd{lhs: a, rhs: rhs, actions: pending, rule: r, origin: r})
+		}
+	}
+
+	lg.firsts = lg.computeFirsts()
+	return lg
+}
+
+// Adds a nonterminal whose EPSILON production executes the semantic actions.
+// Every occurrence gets its own marker, so that a conflict between markers is
+// a conflict between the rules that they come from
+func (lg *lrGrammar) marker(actions []token.Kind, origin token.Rule) token.Kind {
+	m := token.Kind(fmt.Sprintf("<@%v>", len(lg.prods)))
+	lg.add(lrProd{lhs: m, actions: actions, origin: origin})
+	return m
+}
+
+func (lg *lrGrammar) add(p lrProd) {
+	lg.byLHS[p.lhs] = append(lg.byLHS[p.lhs], len(lg.prods))
+	lg.prods = append(lg.prods, p)
+}
+
+func (lg *lrGrammar) isNonterminal(symbol token.Kind) bool {
+	_, ok := lg.byLHS[symbol]
+	return ok
+}
+
+// Computes the FIRST sets of the augmented grammar with the ll1 package, the
+// markers are nonterminals like any other
+func (lg *lrGrammar) computeFirsts() map[token.Kind]token.KindSet {
+	augmented := &ll1.Grammar[token.Kind]{
+		Start:        accept,
+		Productions:  make(map[token.Kind][]ll1.Production[token.Kind], len(lg.byLHS)),
+		Terminals:    make(token.KindSet, 128),
+		Nonterminals: make(token.KindSet, len(lg.byLHS)),
+	}
+	for _, p := range lg.prods {
+		if _, ok := augmented.Nonterminals[p.lhs]; !ok {
+			augmented.Nonterminals[p.lhs] = struct{}{}
+			augmented.Order = append(augmented.Order, p.lhs)
+		}
+		augmented.Productions[p.lhs] = append(augmented.Productions[p.lhs],
+			ll1.Production[token.Kind]{LHS: p.lhs, RHS: p.rhs})
+		for _, k := range p.rhs {
+			if !lg.isNonterminal(k) {
+				augmented.Terminals[k] = struct{}{}
+			}
+		}
+	}
+	return augmented.Firsts()
+}
+
+// Computes the LR(1) closure of the kernel items, each item with its set of
+// lookaheads
+func (lg *lrGrammar) closure(kernel map[lrItem]token.KindSet) map[lrItem]token.KindSet {
+	items := make(map[lrItem]token.KindSet, len(kernel)*4)
+	queue := make([]lrItem, 0, len(kernel)*4)
+	for it, la := range kernel {
+		items[it] = copySet(la)
+		queue = append(queue, it)
+	}
+
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		p := lg.prods[it.prod]
+		if it.dot >= len(p.rhs) || !lg.isNonterminal(p.rhs[it.dot]) {
+			continue
+		}
+
+		la := ll1.FirstOf(lg.firsts, p.rhs[it.dot+1:])
+		if _, ok := la[token.EPSILON]; ok {
+			delete(la, token.EPSILON)
+			union(la, items[it])
+		}
+		for _, q := range lg.byLHS[p.rhs[it.dot]] {
+			next := lrItem{q, 0}
+			set, ok := items[next]
+			if !ok {
+				set = make(token.KindSet, len(la))
+				items[next] = set
+			}
+			if union(set, la) || !ok {
+				queue = append(queue, next)
+			}
+		}
+	}
+	return items
+}
+
+// Builds the LALR(1) automaton. States with the same LR(0) kernel are merged
+// as they are found, and a state is revisited whenever the lookaheads of its
+// kernel grow, until nothing changes
+func (lg *lrGrammar) automaton() []*lrState {
+	start := &lrState{
+		lookaheads: map[lrItem]token.KindSet{{0, 0}: {lrparser.END: {}}},
+		gotos:      map[token.Kind]int{},
+	}
+	states := []*lrState{start}
+	index := map[string]int{kernelKey(start.lookaheads): 0}
+	queued := map[int]bool{0: true}
+	queue := []int{0}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		queued[s] = false
+
+		// Advance the dot over each symbol
+		advanced := make(map[token.Kind]map[lrItem]token.KindSet)
+		for it, la := range lg.closure(states[s].lookaheads) {
+			p := lg.prods[it.prod]
+			if it.dot >= len(p.rhs) {
+				continue
+			}
+			x := p.rhs[it.dot]
+			if advanced[x] == nil {
+				advanced[x] = make(map[lrItem]token.KindSet)
+			}
+			next := lrItem{it.prod, it.dot + 1}
+			if advanced[x][next] == nil {
+				advanced[x][next] = make(token.KindSet, len(la))
+			}
+			union(advanced[x][next], la)
+		}
+
+		for _, x := range sortedKinds(keys(advanced)) {
+			kernel := advanced[x]
+			key := kernelKey(kernel)
+			t, ok := index[key]
+			grew := false
+			if !ok {
+				t = len(states)
+				index[key] = t
+				states = append(states, &lrState{lookaheads: kernel, gotos: map[token.Kind]int{}})
+				grew = true
+			} else {
+				for it, la := range kernel {
+					if union(states[t].lookaheads[it], la) {
+						grew = true
+					}
+				}
+			}
+			if grew && !queued[t] {
+				queued[t] = true
+				queue = append(queue, t)
+			}
+			states[s].gotos[x] = t
+		}
+	}
+	return states
+}
+
+// Picks the reduction to use when more than one competes for the terminal, in
+// the same way as Grammar.Table picks a rule. Returns false if the conflict is
+// not the dangling 'else'
+func (lg *lrGrammar) resolveConflict(g *Grammar, terminal token.Kind, prods []int) (int, bool) {
+	productions := make([]ll1.Production[token.Kind], 0, len(prods))
+	for _, p := range prods {
+		productions = append(productions, g.production(lg.prods[p].origin))
+	}
+	i, ok := g.ll1.Resolve(terminal, productions)
+	if !ok {
+		return 0, false
+	}
+	return prods[i], true
+}
+
+func (lg *lrGrammar) conflict(terminal token.Kind, prods []int) Conflict {
+	c := Conflict{Nonterminal: lg.prods[prods[0]].origin.LHS, Terminal: terminal}
+	for _, p := range prods {
+		c.Rules = append(c.Rules, lg.prods[p].origin)
+	}
+	return c
+}
+
+// Identifies a state by the LR(0) items of its kernel
+func kernelKey(kernel map[lrItem]token.KindSet) string {
+	var key strings.Builder
+	for _, it := range sortedItems(kernel) {
+		fmt.Fprintf(&key, "%v.%v,", it.prod, it.dot)
+	}
+	return key.String()
+}
+
+func sortedItems(items map[lrItem]token.KindSet) []lrItem {
+	sorted := make([]lrItem, 0, len(items))
+	for it := range items {
+		sorted = append(sorted, it)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].prod != sorted[j].prod {
+			return sorted[i].prod < sorted[j].prod
+		}
+		return sorted[i].dot < sorted[j].dot
+	})
+	return sorted
+}
+
+func keys[V any](m map[token.Kind]V) token.KindSet {
+	set := make(token.KindSet, len(m))
+	for k := range m {
+		set[k] = struct{}{}
+	}
+	return set
+}
+
+func copySet(set token.KindSet) token.KindSet {
+	c := make(token.KindSet, len(set))
+	union(c, set)
+	return c
+}
@@ -0,0 +1,194 @@
+package grammar
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/lrparser"
+	"github.com/obonobo/esac/core/parser"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/tabledrivenscanner"
+	scannertable "github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/internal/testutils"
+)
+
+// The LRParser must build the same AST as the TableDrivenParser for every
+// program that the TableDrivenParser accepts
+func TestLALRMatchesLL(t *testing.T) {
+	g, err := FromRules(token.START, token.RULES())
+	if err != nil {
+		t.Fatalf("Failed to load the grammar: %v", err)
+	}
+	table, err := g.LALRTable()
+	if err != nil {
+		t.Fatalf("Failed to build the LALR table: %v", err)
+	}
+
+	files, err := filepath.Glob("../../resources/src/*.src")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No source files found: %v", err)
+	}
+	sources := map[string]string{
+		"polynomial":   testutils.POLYNOMIAL_SRC,
+		"polynomial 2": testutils.POLYNOMIAL_SRC_2,
+		"bubblesort":   testutils.BUBBLESORT_SRC,
+		"bubblesort 2": testutils.BUBBLESORT_SRC_2,
+		"statements": `
+		func main() -> void {
+			let i: integer;
+			let s: string;
+			let b: bool;
+			s = "hello";
+			b = !(1 < 2) | true;
+			for (let j: integer = 0; j < 10; j = j + 1) {
+				if (j == 3) then continue; else if (j > 7) then break; else write(j);;
+			};
+			while (b) { b = false; };
+			if (b) then write(s);;
+		}`,
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[filepath.Base(file)] = string(src)
+	}
+
+	for name, src := range sources {
+		name, src := name, []byte(src)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ll := tabledrivenparser.NewParserNoDefaultComments(
+				scan(src), parsertable.TABLE(), nil, nil)
+			var errs []string
+			lr := lrparser.NewParserNoDefaultComments(
+				scan(src), table,
+				func(e *tabledrivenparser.ParserError) { errs = append(errs, e.Error()) },
+				nil)
+
+			llOk, lrOk := ll.Parse(), lr.Parse()
+			if llOk != lrOk {
+				t.Fatalf("The LL parser returned %v but the LR parser returned %v: %v", llOk, lrOk, errs)
+			}
+			if llOk {
+				if expected, actual := printAST(ll), printAST(lr); expected != actual {
+					t.Errorf("Expected AST:\n%v\nbut got:\n%v", expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// Left recursive grammars need no rewriting for the LRParser
+func TestLALRLeftRecursion(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	<expr> ::= <expr> '+' <term>
+	<expr> ::= <term>
+	<term> ::= <term> '*' 'id'
+	<term> ::= 'id'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	table, err := g.LALRTable()
+	if err != nil {
+		t.Fatalf("Failed to build the LALR table: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		input    []token.Kind
+		rules    []string // The reductions, in order
+		expected string   // The syntax error, empty if the parse succeeds
+	}{
+		{
+			name:  "precedence",
+			input: []token.Kind{token.ID, token.PLUS, token.ID, token.MULT, token.ID},
+			rules: []string{
+				"<term> ::= id", "<expr> ::= <term>",
+				"<term> ::= id", "<term> ::= <term> mult id",
+				"<expr> ::= <expr> plus <term>",
+			},
+		},
+		{
+			name:     "missing operand",
+			input:    []token.Kind{token.ID, token.PLUS, token.MULT},
+			expected: "unexpected token 'mult', should be 'id'",
+		},
+		{
+			name:     "unterminated",
+			input:    []token.Kind{token.ID, token.PLUS},
+			expected: "got EOF in the middle of a sentence, expected to find symbols for [id]",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var rules, errs []string
+			p := lrparser.NewParser(
+				&kindScanner{kinds: tc.input}, table,
+				func(e *tabledrivenparser.ParserError) { errs = append(errs, e.Err.Error()) },
+				func(r token.Rule) { rules = append(rules, formatRule(r)) })
+
+			ok := p.Parse()
+			if tc.expected != "" {
+				if ok || len(errs) != 1 || errs[0] != tc.expected {
+					t.Errorf("Expected the error %q but got %v", tc.expected, errs)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Expected the parse to succeed but got errors %v", errs)
+			}
+			if strings.Join(rules, "\n") != strings.Join(tc.rules, "\n") {
+				t.Errorf("Expected reductions %v but got %v", tc.rules, rules)
+			}
+		})
+	}
+}
+
+func TestLALRReduceReduceConflict(t *testing.T) {
+	g, err := Parse(strings.NewReader(`
+	<START> ::= <a> 'x'
+	<START> ::= <b> 'x'
+	<a> ::= 'id'
+	<b> ::= 'id'
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse the grammar: %v", err)
+	}
+	_, err = g.LALRTable()
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || len(conflict.Conflicts) != 1 || conflict.Conflicts[0].Terminal != "x" {
+		t.Errorf("Expected a reduce-reduce conflict on 'x' but got %v", err)
+	}
+}
+
+func scan(src []byte) *tabledrivenscanner.TableDrivenScanner {
+	return tabledrivenscanner.NewScanner(
+		chuggingcharsource.MustChuggingReader(bytes.NewReader(src)),
+		scannertable.TABLE())
+}
+
+func printAST(p parser.Parser) string {
+	out := new(bytes.Buffer)
+	p.AST().Print(out)
+	return out.String()
+}
+
+func formatRule(r token.Rule) string {
+	rhs := make([]string, 0, len(r.RHS))
+	for _, k := range r.RHS {
+		rhs = append(rhs, string(k))
+	}
+	return string(r.LHS) + " ::= " + strings.Join(rhs, " ")
+}
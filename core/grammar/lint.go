@@ -0,0 +1,40 @@
+package grammar
+
+import (
+	"github.com/obonobo/esac/core/token"
+)
+
+// Everything that stops a grammar from being a well-behaved LL(1) grammar,
+// along with the LL(1) conflicts that Table is able to resolve
+type Report struct {
+	LeftRecursion []string     // Cycles such as "<a> -> <b> -> <a>"
+	Unproductive  []token.Kind // Nonterminals that can't derive a string of terminals
+	Unreachable   []token.Kind // Nonterminals that can't be derived from the start symbol
+	Unused        []token.Kind // Terminals that can't be derived from the start symbol
+	Conflicts     []Conflict   // LL(1) conflicts that make Table fail
+	Resolved      []Conflict   // LL(1) conflicts that Table resolves, the first rule wins
+}
+
+// Returns the number of problems found, resolved conflicts are not problems
+func (r *Report) Problems() int {
+	return len(r.LeftRecursion) + len(r.Unproductive) + len(r.Unreachable) +
+		len(r.Unused) + len(r.Conflicts)
+}
+
+// Lints the grammar (see ll1.Grammar.Lint)
+func (g *Grammar) Lint() *Report {
+	lint := g.ll1.Lint()
+	report := &Report{
+		LeftRecursion: lint.LeftRecursion,
+		Unproductive:  lint.Unproductive,
+		Unreachable:   lint.Unreachable,
+		Unused:        lint.Unused,
+	}
+	for _, c := range lint.Conflicts {
+		report.Conflicts = append(report.Conflicts, g.conflict(c))
+	}
+	for _, c := range lint.Resolved {
+		report.Resolved = append(report.Resolved, g.conflict(c))
+	}
+	return report
+}
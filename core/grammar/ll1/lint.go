@@ -0,0 +1,214 @@
+package ll1
+
+import "strings"
+
+// Everything that stops a grammar from being a well-behaved LL(1) grammar,
+// along with the LL(1) conflicts that Resolve is able to resolve
+type Report[S ~string] struct {
+	LeftRecursion []string      // Cycles such as "<a> -> <b> -> <a>"
+	Unproductive  []S           // Nonterminals that can't derive a string of terminals
+	Unreachable   []S           // Nonterminals that can't be derived from the start symbol
+	Unused        []S           // Terminals that can't be derived from the start symbol
+	Conflicts     []Conflict[S] // LL(1) conflicts that can't be resolved
+	Resolved      []Conflict[S] // LL(1) conflicts that Resolve resolves
+}
+
+// Two or more productions of a nonterminal that compete for the same entry of
+// the parser table. The productions are indices into the productions of the
+// nonterminal, for a resolved conflict the chosen production comes first
+type Conflict[S ~string] struct {
+	Key[S]
+	Productions []int
+}
+
+// Returns the number of problems found, resolved conflicts are not problems
+func (r *Report[S]) Problems() int {
+	return len(r.LeftRecursion) + len(r.Unproductive) + len(r.Unreachable) +
+		len(r.Unused) + len(r.Conflicts)
+}
+
+// Lints the grammar
+func (g *Grammar[S]) Lint() *Report[S] {
+	report := &Report[S]{LeftRecursion: g.LeftRecursion()}
+
+	productive := g.productiveNonterminals()
+	for _, n := range sorted(g.Nonterminals) {
+		if _, ok := productive[n]; !ok {
+			report.Unproductive = append(report.Unproductive, n)
+		}
+	}
+
+	reachable := g.reachableSymbols()
+	for _, n := range sorted(g.Nonterminals) {
+		if _, ok := reachable[n]; !ok {
+			report.Unreachable = append(report.Unreachable, n)
+		}
+	}
+	for _, t := range sorted(g.Terminals) {
+		if _, ok := reachable[t]; !ok && t != EPSILON {
+			report.Unused = append(report.Unused, t)
+		}
+	}
+
+	keys, candidates := g.Candidates()
+	for _, k := range keys {
+		productions := candidates[k]
+		if len(productions) < 2 {
+			continue
+		}
+		winner, ok := g.ResolveEntry(k, productions)
+		if !ok {
+			report.Conflicts = append(report.Conflicts, Conflict[S]{k, productions})
+			continue
+		}
+		resolved := Conflict[S]{k, []int{winner}}
+		for _, i := range productions {
+			if i != winner {
+				resolved.Productions = append(resolved.Productions, i)
+			}
+		}
+		report.Resolved = append(report.Resolved, resolved)
+	}
+	return report
+}
+
+// Finds the cycles of nonterminals that can derive themselves as the leftmost
+// symbol of a sentential form. Each cycle is reported once, as a path such as
+// "<a> -> <b> -> <a>". A top-down parser can't be built for a left recursive
+// grammar
+func (g *Grammar[S]) LeftRecursion() []string {
+	nullable := g.nullableNonterminals()
+
+	// An edge A -> B means that A ::= α B β where α can derive EPSILON
+	edges := make(map[S][]S, len(g.Nonterminals))
+	for _, a := range sorted(g.Nonterminals) {
+		seen := make(map[S]struct{})
+		for _, p := range g.Productions[a] {
+			for _, sym := range p.RHS {
+				_, isNonterminal := g.Nonterminals[sym]
+				if _, ok := seen[sym]; isNonterminal && !ok {
+					seen[sym] = struct{}{}
+					edges[a] = append(edges[a], sym)
+				}
+				if _, ok := nullable[sym]; !ok {
+					break
+				}
+			}
+		}
+	}
+
+	var cycles []string
+	reported := make(map[S]struct{})
+	for _, start := range sorted(g.Nonterminals) {
+		if _, ok := reported[start]; ok {
+			continue
+		}
+
+		// Breadth first search back to the start, remembering how we got there
+		parents := make(map[S]S)
+		queue := append([]S(nil), edges[start]...)
+		for _, n := range queue {
+			parents[n] = start
+		}
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+			for _, next := range edges[n] {
+				if _, ok := parents[next]; !ok {
+					parents[next] = n
+					queue = append(queue, next)
+				}
+			}
+		}
+		if _, ok := parents[start]; !ok {
+			continue
+		}
+
+		path := []string{string(start)}
+		for n := parents[start]; n != start; n = parents[n] {
+			path = append([]string{string(n)}, path...)
+		}
+		path = append([]string{string(start)}, path...)
+		for _, n := range path {
+			reported[S(n)] = struct{}{}
+		}
+		cycles = append(cycles, strings.Join(path, " -> "))
+	}
+	return cycles
+}
+
+// Computes the set of nonterminals that can derive EPSILON, the set includes
+// EPSILON itself
+func (g *Grammar[S]) nullableNonterminals() map[S]struct{} {
+	nullable := map[S]struct{}{EPSILON: {}}
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.Order {
+			if _, ok := nullable[n]; ok {
+				continue
+			}
+			for _, p := range g.Productions[n] {
+				if all(p.RHS, nullable) {
+					nullable[n] = struct{}{}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return nullable
+}
+
+// Computes the set of nonterminals that can derive a string of terminals
+func (g *Grammar[S]) productiveNonterminals() map[S]struct{} {
+	productive := make(map[S]struct{}, len(g.Nonterminals))
+	for t := range g.Terminals {
+		productive[t] = struct{}{}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.Order {
+			if _, ok := productive[n]; ok {
+				continue
+			}
+			for _, p := range g.Productions[n] {
+				if all(p.RHS, productive) {
+					productive[n] = struct{}{}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return productive
+}
+
+// Computes the set of symbols, terminal or nonterminal, that appear in some
+// sentential form derived from the start symbol
+func (g *Grammar[S]) reachableSymbols() map[S]struct{} {
+	reachable := map[S]struct{}{g.Start: {}}
+	queue := []S{g.Start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, p := range g.Productions[n] {
+			for _, sym := range p.RHS {
+				if _, ok := reachable[sym]; !ok {
+					reachable[sym] = struct{}{}
+					queue = append(queue, sym)
+				}
+			}
+		}
+	}
+	return reachable
+}
+
+// Returns true if every symbol is in the set
+func all[S ~string](symbols []S, set map[S]struct{}) bool {
+	for _, sym := range symbols {
+		if _, ok := set[sym]; !ok {
+			return false
+		}
+	}
+	return true
+}
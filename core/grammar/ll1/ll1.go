@@ -0,0 +1,146 @@
+// Package ll1 analyses grammars for top-down parsing: it computes their FIRST
+// and FOLLOW sets, finds the productions that compete for each entry of their
+// LL(1) parser table, and lints them.
+//
+// The analyses are shared by core/grammar and generate/tool.go. The tool
+// generates the token package that core/grammar is written against, so this
+// package only depends on the standard library and leaves the spelling of
+// symbols to its callers: a symbol is any string type, e.g. a token.Kind or a
+// word of a grammar file.
+package ll1
+
+import "sort"
+
+// The empty sentence, it only ever appears alone on the RHS of a production
+const EPSILON = "EPSILON"
+
+// A grammar whose productions have been stripped of their semantic actions.
+// The productions of a nonterminal are identified by their index in
+// Productions, so the callers can map them back to their own rules
+type Grammar[S ~string] struct {
+	Start        S
+	Order        []S                   // Nonterminals, in the order that they are analysed and reported
+	Productions  map[S][]Production[S] // The productions of each nonterminal
+	Terminals    map[S]struct{}        // Includes EPSILON if a production uses it
+	Nonterminals map[S]struct{}
+
+	firsts, follows map[S]map[S]struct{}
+}
+
+// A production without its semantic actions
+type Production[S ~string] struct {
+	LHS S
+	RHS []S
+}
+
+// Returns the FIRST set of every terminal and nonterminal symbol. The sets are
+// computed by iterating until nothing changes, which terminates even if the
+// grammar is left recursive. They are computed once, the grammar must not be
+// changed afterwards
+func (g *Grammar[S]) Firsts() map[S]map[S]struct{} {
+	if g.firsts != nil {
+		return g.firsts
+	}
+
+	firsts := make(map[S]map[S]struct{}, len(g.Terminals)+len(g.Nonterminals))
+	for t := range g.Terminals {
+		firsts[t] = map[S]struct{}{t: {}}
+	}
+	for n := range g.Nonterminals {
+		firsts[n] = map[S]struct{}{}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.Order {
+			for _, p := range g.Productions[n] {
+				if union(firsts[n], FirstOf(firsts, p.RHS)) {
+					changed = true
+				}
+			}
+		}
+	}
+	g.firsts = firsts
+	return firsts
+}
+
+// Returns the FOLLOW set of every terminal and nonterminal symbol, computed by
+// iterating until nothing changes.
+//
+// The end of input is not a token that the scanner produces, so FOLLOW sets
+// never contain '$'. The TableDrivenParser instead checks that the symbols left
+// on the stack at EOF can derive EPSILON
+func (g *Grammar[S]) Follows() map[S]map[S]struct{} {
+	if g.follows != nil {
+		return g.follows
+	}
+
+	firsts := g.Firsts()
+	follows := make(map[S]map[S]struct{}, len(g.Terminals)+len(g.Nonterminals))
+	for t := range g.Terminals {
+		follows[t] = map[S]struct{}{}
+	}
+	for n := range g.Nonterminals {
+		follows[n] = map[S]struct{}{}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.Order {
+			for _, p := range g.Productions[n] {
+				for i, x := range p.RHS {
+					beta := FirstOf(firsts, p.RHS[i+1:])
+					_, nullable := beta[EPSILON]
+					delete(beta, EPSILON)
+					if union(follows[x], beta) {
+						changed = true
+					}
+					if nullable && union(follows[x], follows[n]) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	g.follows = follows
+	return follows
+}
+
+// Computes the FIRST set of a sentential form, it contains EPSILON only if
+// every symbol of the form can derive EPSILON
+func FirstOf[S ~string](firsts map[S]map[S]struct{}, symbols []S) map[S]struct{} {
+	set := make(map[S]struct{}, 8)
+	for _, s := range symbols {
+		_, nullable := firsts[s][EPSILON]
+		for k := range firsts[s] {
+			if k != EPSILON {
+				set[k] = struct{}{}
+			}
+		}
+		if !nullable {
+			return set
+		}
+	}
+	set[EPSILON] = struct{}{}
+	return set
+}
+
+// Adds all elements of src to dst, returns true if dst grew
+func union[S ~string](dst, src map[S]struct{}) (grew bool) {
+	for k := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = struct{}{}
+			grew = true
+		}
+	}
+	return grew
+}
+
+func sorted[S ~string](set map[S]struct{}) []S {
+	sorted := make([]S, 0, len(set))
+	for k := range set {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
@@ -0,0 +1,120 @@
+package ll1
+
+// An entry of the parser table
+type Key[S ~string] struct {
+	Nonterminal S
+	Terminal    S
+}
+
+// Collects the productions that want to occupy each entry of the parser table,
+// as indices into the productions of the nonterminal of the entry. An entry
+// with more than one candidate is an LL(1) conflict. The keys are returned in
+// the order they were first encountered
+func (g *Grammar[S]) Candidates() ([]Key[S], map[Key[S]][]int) {
+	firsts, follows := g.Firsts(), g.Follows()
+	keys := make([]Key[S], 0, 256)
+	candidates := make(map[Key[S]][]int, 256)
+	add := func(a, t S, i int) {
+		if t == EPSILON {
+			return
+		}
+		k := Key[S]{Nonterminal: a, Terminal: t}
+		if _, ok := candidates[k]; !ok {
+			keys = append(keys, k)
+		}
+		candidates[k] = append(candidates[k], i)
+	}
+
+	for _, a := range g.Order {
+		for i, p := range g.Productions[a] {
+			first := FirstOf(firsts, p.RHS)
+			for _, t := range sorted(first) {
+				add(a, t, i)
+			}
+			if _, ok := first[EPSILON]; ok {
+				for _, t := range sorted(follows[a]) {
+					add(a, t, i)
+				}
+			}
+		}
+	}
+	return keys, candidates
+}
+
+// Picks the production to use for a table entry that more than one production
+// competes for, returns its index in candidates. Only the dangling 'else' is
+// resolved: exactly one production starts with the terminal of the entry, and
+// every other production starts with a nonterminal that derives that same
+// production as a nested construct, e.g.: <else-disambiguate> ::= 'if' ...
+// versus <else-disambiguate> ::= <statBlock> ';' where <statBlock> derives the
+// nested 'if' .... The production starting with the terminal wins, which
+// continues the if-else chain. Returns false for any other conflict
+func (g *Grammar[S]) Resolve(terminal S, candidates []Production[S]) (int, bool) {
+	resolved, found := 0, 0
+	for i, p := range candidates {
+		if len(p.RHS) > 0 && p.RHS[0] == terminal {
+			resolved = i
+			found++
+		}
+	}
+	if found != 1 {
+		return 0, false
+	}
+
+	winner := candidates[resolved]
+	for i, p := range candidates {
+		if i == resolved {
+			continue
+		}
+		if len(p.RHS) == 0 || !g.derivesNested(p.RHS[0], winner.RHS, map[S]struct{}{winner.LHS: {}}) {
+			return 0, false
+		}
+	}
+	return resolved, true
+}
+
+// Picks the production to use for an entry of the table that more than one
+// production competes for, as returned by Candidates (see Resolve). Returns the
+// index of the production among the productions of the nonterminal
+func (g *Grammar[S]) ResolveEntry(k Key[S], candidates []int) (int, bool) {
+	productions := make([]Production[S], 0, len(candidates))
+	for _, i := range candidates {
+		productions = append(productions, g.Productions[k.Nonterminal][i])
+	}
+	i, ok := g.Resolve(k.Terminal, productions)
+	if !ok {
+		return 0, false
+	}
+	return candidates[i], true
+}
+
+// Returns true if a production with the target RHS can be reached from the
+// symbol by repeatedly expanding the leftmost nonterminal. The nonterminals in
+// seen are not expanded, which rules out left recursion
+func (g *Grammar[S]) derivesNested(symbol S, target []S, seen map[S]struct{}) bool {
+	if _, ok := seen[symbol]; ok {
+		return false
+	}
+	seen[symbol] = struct{}{}
+	for _, p := range g.Productions[symbol] {
+		if equal(p.RHS, target) {
+			return true
+		}
+		if len(p.RHS) > 0 && g.derivesNested(p.RHS[0], target, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal[S ~string](a, b []S) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package grammar
+
+import (
+	"sort"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// Adds all elements of src to dst, returns true if dst grew
+func union(dst, src token.KindSet) (grew bool) {
+	for k := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = struct{}{}
+			grew = true
+		}
+	}
+	return grew
+}
+
+func sortedKinds(set token.KindSet) []token.Kind {
+	sorted := make([]token.Kind, 0, len(set))
+	for k := range set {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
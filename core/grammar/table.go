@@ -0,0 +1,71 @@
+package grammar
+
+import (
+	"github.com/obonobo/esac/core/grammar/ll1"
+	"github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/token"
+)
+
+// Builds the LL(1) parser table of the grammar. The table implements
+// tabledrivenparser.Table, so it can be handed straight to a TableDrivenParser.
+//
+// A left recursive grammar can't be parsed top-down, a *LeftRecursionError is
+// returned for it. An entry that more than one rule competes for is only
+// resolved if it is the dangling 'else' (see ll1.Grammar.Resolve), any other
+// conflict makes the grammar unusable and a *ConflictError listing all of them
+// is returned.
+//
+// Semantic actions are executed by the TableDrivenParser through
+// token.SEM_DISPATCH, so a grammar can only use the semantic actions that have
+// been generated in the token package.
+func (g *Grammar) Table() (*compositetable.CompositeTable, error) {
+	if cycles := g.ll1.LeftRecursion(); len(cycles) > 0 {
+		return nil, &LeftRecursionError{Cycles: cycles}
+	}
+
+	keys, candidates := g.ll1.Candidates()
+	tt := make(map[token.Key]token.Rule, len(keys))
+	var conflicts []Conflict
+	for _, k := range keys {
+		productions := candidates[k]
+		if len(productions) == 1 {
+			tt[tableKey(k)] = g.rules[k.Nonterminal][productions[0]]
+		} else if i, ok := g.ll1.ResolveEntry(k, productions); ok {
+			tt[tableKey(k)] = g.rules[k.Nonterminal][i]
+		} else {
+			conflicts = append(conflicts, g.conflict(ll1.Conflict[token.Kind]{Key: k, Productions: productions}))
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, &ConflictError{Table: "LL(1)", Conflicts: conflicts}
+	}
+
+	return &compositetable.CompositeTable{
+		StartTerminal: g.start,
+		Rules:         g.rules,
+		Terminals:     g.terminals,
+		Nonterminals:  g.nonterminals,
+		Firsts:        g.Firsts(),
+		Follows:       g.Follows(),
+		TT:            tt,
+		NoPush:        token.KindSet{token.EPSILON: {}},
+	}, nil
+}
+
+func tableKey(k ll1.Key[token.Kind]) token.Key {
+	return token.Key{Nonterminal: k.Nonterminal, Terminal: k.Terminal}
+}
+
+// Looks up the rules of a conflict that the ll1 package found
+func (g *Grammar) conflict(c ll1.Conflict[token.Kind]) Conflict {
+	return Conflict{c.Nonterminal, c.Terminal, g.rulesOf(c.Nonterminal, c.Productions)}
+}
+
+// Looks up the rules of the nonterminal with the given indices
+func (g *Grammar) rulesOf(nonterminal token.Kind, indices []int) []token.Rule {
+	rules := make([]token.Rule, 0, len(indices))
+	for _, i := range indices {
+		rules = append(rules, g.rules[nonterminal][i])
+	}
+	return rules
+}
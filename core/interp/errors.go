@@ -0,0 +1,26 @@
+package interp
+
+import (
+	"fmt"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+var (
+	ErrNoMain            = fmt.Errorf("program has no main function")
+	ErrNoSymbolTable     = fmt.Errorf("program has no symbol table")
+	ErrStepLimitExceeded = fmt.Errorf("interp: step limit exceeded")
+)
+
+// Emitted when a program cannot continue executing
+type RuntimeError struct {
+	Msg   string
+	Token token.Token
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Token.Line > 0 {
+		return fmt.Sprintf("runtime error: %v (line %v)", e.Msg, e.Token.Line)
+	}
+	return fmt.Sprintf("runtime error: %v", e.Msg)
+}
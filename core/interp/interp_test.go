@@ -0,0 +1,262 @@
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/internal/testutils"
+)
+
+func TestInterpret(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		src    string
+		input  string
+		output string
+	}{
+		{
+			name: "recursion",
+			src: `
+			func ack(m: integer, n: integer) -> integer {
+				if (m == 0) then { return (n + 1); } else ;
+				if (n == 0) then { return (ack(m - 1, 1)); } else ;
+				return (ack(m - 1, ack(m, n - 1)));
+			}
+			func main() -> void { write(ack(2, 3)); }
+			`,
+			output: "9\n",
+		},
+		{
+			name: "multi-dimensional arrays and array parameters",
+			src: `
+			func fill(row: integer[], n: integer) -> void {
+				let i: integer;
+				i = 0;
+				while (i < 3) { row[i] = n * 10 + i; i = i + 1; };
+			}
+			func main() -> void {
+				let m: integer[2][3]; let copy: integer[2][3];
+				fill(m[0], 1);
+				fill(m[1], 2);
+				copy = m;
+				m[1][2] = 0;
+				write(m[0][1]); write(copy[1][2]); write(m[1][2]);
+			}
+			`,
+			output: "11\n22\n0\n",
+		},
+		{
+			name: "struct instances have value semantics",
+			src: `
+			struct P { public let x: integer; public let y: integer[2]; };
+			func bump(p: P) -> P { p.x = p.x + 1; return (p); }
+			func main() -> void {
+				let a: P; let b: P;
+				a.x = 1; a.y[1] = 7;
+				b = bump(a);
+				write(a.x); write(b.x); write(b.y[1]);
+			}
+			`,
+			output: "1\n2\n7\n",
+		},
+		{
+			name: "methods and inheritance",
+			src: `
+			struct SHAPE {
+				public let sides: integer;
+				public func describe() -> integer;
+			};
+			struct SQUARE inherits SHAPE {
+				private let side: integer;
+				public func init(s: integer) -> void;
+				public func area() -> integer;
+			};
+			impl SHAPE { func describe() -> integer { return (sides); } }
+			impl SQUARE {
+				func init(s: integer) -> void { side = s; sides = 4; }
+				func area() -> integer { return (side * side + 0 * describe()); }
+			}
+			func main() -> void {
+				let sq: SQUARE;
+				sq.init(5);
+				write(sq.area());
+				write(sq.describe());
+				write(sq.sides);
+			}
+			`,
+			output: "25\n4\n4\n",
+		},
+		{
+			name: "floats",
+			src: `
+			func main() -> void {
+				let f: float;
+				f = 1.5;
+				while (f < 10.0) { f = f * 2.0; };
+				write(f);
+				write(-f / 4.0);
+			}
+			`,
+			output: "12\n-3\n",
+		},
+		{
+			name: "read and write",
+			src: `
+			func main() -> void {
+				let n: integer; let x: float; let sum: integer; let i: integer;
+				read(n);
+				i = 0;
+				while (i < n) { read(i); sum = sum + i; };
+				read(x);
+				write(sum); write(x);
+			}
+			`,
+			input:  "3 1 2 4\n0.5",
+			output: "7\n0.5\n",
+		},
+		{
+			name: "strings",
+			src: `
+			func greet(name: string) -> string { return (name); }
+			func main() -> void {
+				let s: string; let words: string[2];
+				s = "say \"hi\"\n\\";
+				words[1] = greet(s);
+				read(words[0]);
+				write("hello"); write(words[1]); write(words[0]);
+			}
+			`,
+			input:  "world",
+			output: "hello\nsay \"hi\"\n\\\nworld\n",
+		},
+		{
+			name: "for loops, break, and continue",
+			src: `
+			func main() -> void {
+				let j: integer;
+				for (let i: integer = 0; i < 5; i = i + 1) {
+					if (i == 1) then continue; else ;
+					if (i == 3) then break; else ;
+					write(i);
+				};
+				for (j = 0; j < 3; j = j + 1) {
+					while (1 == 1) { break; };
+				};
+				write(j);
+			}
+			`,
+			output: "0\n2\n3\n",
+		},
+		{
+			name: "else-less if and else if chains",
+			src: `
+			func sign(x: integer) -> integer {
+				if (x < 0) then return (-1);
+				else if (x == 0) then return (0);
+				else return (1);;
+			}
+			func main() -> void {
+				if (1 < 2) then write(1);;
+				if (2 < 1) then write(2);;
+				if (1 > 2) then { write(3); } else if (1 < 2) then { write(4); };
+				if (1 > 1) then if (1 > 0) then write(5); else write(6);;;
+				write(sign(-7)); write(sign(0)); write(sign(7));
+			}
+			`,
+			output: "1\n4\n-1\n0\n1\n",
+		},
+		{
+			name: "bools",
+			src: `
+			func flip(b: bool) -> bool { return (!b); }
+			func main() -> void {
+				let b: bool; let bs: bool[2];
+				b = (1 < 2) & !(3 == 4);
+				bs[1] = flip(b);
+				write(b); write(bs[1]); write(bs[0] == false);
+				while (b) { b = false; };
+				if (b | (2 >= 2)) then write(true);;
+			}
+			`,
+			output: "true\nfalse\ntrue\ntrue\n",
+		},
+		{
+			name: "integers wrap around",
+			src: `
+			func main() -> void {
+				let x: integer;
+				x = 2147483647;
+				write(x + 1);
+			}
+			`,
+			output: "-2147483648\n",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out := new(bytes.Buffer)
+			if err := Interpret(testutils.MustAnalyze(t, tc.src), strings.NewReader(tc.input), out); err != nil {
+				t.Fatalf("Interpret failed: %v", err)
+			}
+			if actual := out.String(); actual != tc.output {
+				t.Errorf("Expected output %q but got %q", tc.output, actual)
+			}
+		})
+	}
+}
+
+func TestInterpret_RuntimeErrors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{
+			name: "division by zero",
+			src:  "func main() -> void {\n let x: integer;\n write(1 / x);\n}",
+			err:  "runtime error: division by zero (line 3)",
+		},
+		{
+			name: "index out of bounds",
+			src:  "func main() -> void {\n let a: integer[2];\n a[2] = 1;\n}",
+			err:  "runtime error: index 2 out of bounds for 'a' of size 2 (line 3)",
+		},
+		{
+			name: "stack overflow",
+			src:  "func f() -> void { f(); }\nfunc main() -> void { f(); }",
+			err:  "runtime error: stack overflow (line 1)",
+		},
+		{
+			name: "step limit",
+			src:  "func main() -> void { while (1 == 1) { }; }",
+			err:  ErrStepLimitExceeded.Error(),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			it := NewInterpreter(testutils.MustAnalyze(t, tc.src), strings.NewReader(""), new(bytes.Buffer))
+			it.MaxSteps, it.MaxDepth = 10_000, 100
+			err := it.Run()
+			if err == nil {
+				t.Fatalf("Expected error %q", tc.err)
+			}
+			if err.Error() != tc.err {
+				t.Errorf("Expected error %q but got %q", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestInterpret_NoMain(t *testing.T) {
+	t.Parallel()
+	err := Interpret(testutils.MustAnalyze(t, `func f() -> void { }`), strings.NewReader(""), new(bytes.Buffer))
+	if !errors.Is(err, ErrNoMain) {
+		t.Errorf("Expected %v but got %v", ErrNoMain, err)
+	}
+}
@@ -0,0 +1,649 @@
+// Package interp executes programs by walking their AST. The AST must have been
+// decorated by the SymTabVisitor and the SemCheckVisitor: the symbol tables
+// provide the variables of every function and the data members of every
+// struct, and function calls are dispatched to the overload that the
+// SemCheckVisitor has resolved.
+//
+// The interpreter does not depend on the memory layout of the program, which
+// makes it a reference for the output of the code generators.
+package interp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+const (
+	// Default number of statements that a program may execute before it is
+	// killed. Use a negative number for no limit
+	DEFAULT_MAX_STEPS = 50_000_000
+
+	// Default maximum depth of the call stack
+	DEFAULT_MAX_DEPTH = 10_000
+)
+
+// Executes a program. Structs and arrays have value semantics: they are copied
+// when assigned, passed as arguments, and returned, except that array
+// parameters are passed by reference. Methods operate on the object they have
+// been called on.
+//
+// The read statement reads whitespace-separated numbers from the input, a
+// missing or malformed number reads as zero. The write statement writes a
+// number followed by a newline.
+type Interpreter struct {
+	MaxSteps int
+	MaxDepth int
+
+	ast     token.AST
+	global  token.SymbolTable
+	funcs   map[token.SymbolTable]*function
+	objects map[token.SymbolTable]bool // Structs being instantiated
+	in      *bufio.Reader
+	out     io.Writer
+	steps   int
+	depth   int
+}
+
+func NewInterpreter(ast token.AST, in io.Reader, out io.Writer) *Interpreter {
+	return &Interpreter{
+		MaxSteps: DEFAULT_MAX_STEPS,
+		MaxDepth: DEFAULT_MAX_DEPTH,
+		ast:      ast,
+		funcs:    make(map[token.SymbolTable]*function, 64),
+		objects:  make(map[token.SymbolTable]bool, 16),
+		in:       bufio.NewReader(in),
+		out:      out,
+	}
+}
+
+// Runs a program with a fresh Interpreter
+func Interpret(ast token.AST, in io.Reader, out io.Writer) error {
+	return NewInterpreter(ast, in, out).Run()
+}
+
+// A function or method definition
+type function struct {
+	node  *token.ASTNode
+	table token.SymbolTable
+	owner token.SymbolTable // The struct table for methods, nil otherwise
+}
+
+// The state of a single function call
+type frame struct {
+	fn   *function
+	vars map[string]Value
+	self *Object
+}
+
+// Runtime errors are propagated with a panic, which is recovered by Run
+type abort struct {
+	err error
+}
+
+// Runs the main function of the program
+func (it *Interpreter) Run() (err error) {
+	root := it.ast.Root
+	if root == nil || root.Type != token.FINAL_PROG {
+		return &RuntimeError{Msg: "expected a program"}
+	}
+	if it.global = root.Meta.SymbolTable; it.global == nil {
+		return ErrNoSymbolTable
+	}
+
+	var main *function
+	for _, child := range root.Children[0].Children {
+		switch child.Type {
+		case token.FINAL_FUNC_DEF:
+			fn := it.define(child, nil)
+			if fn != nil && string(child.Children[0].Token.Lexeme) == "main" {
+				main = fn
+			}
+		case token.FINAL_IMPL_DEF:
+			for _, method := range child.Children[1].Children {
+				it.define(method, child.Meta.SymbolTable)
+			}
+		}
+	}
+	if main == nil {
+		return ErrNoMain
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			a, ok := r.(abort)
+			if !ok {
+				panic(r)
+			}
+			err = a.err
+		}
+	}()
+	it.steps, it.depth = 0, 0
+	it.invoke(main, nil, nil, main.node.Token)
+	return nil
+}
+
+func (it *Interpreter) define(node *token.ASTNode, owner token.SymbolTable) *function {
+	if node.Meta.SymbolTable == nil {
+		return nil
+	}
+	fn := &function{node: node, table: node.Meta.SymbolTable, owner: owner}
+	it.funcs[fn.table] = fn
+	return fn
+}
+
+func (it *Interpreter) fail(at token.Token, msg string, args ...any) {
+	panic(abort{&RuntimeError{Msg: fmt.Sprintf(msg, args...), Token: at}})
+}
+
+// Calls a function, args have already been evaluated
+func (it *Interpreter) invoke(fn *function, self *Object, args []Value, at token.Token) Value {
+	it.depth++
+	defer func() { it.depth-- }()
+	if it.MaxDepth > 0 && it.depth > it.MaxDepth {
+		it.fail(at, "stack overflow")
+	}
+
+	f := &frame{fn: fn, vars: make(map[string]Value, 16), self: self}
+	var params int
+	for _, entry := range fn.table.Entries() {
+		switch entry.Kind {
+		case token.FINAL_FUNC_DEF_PARAM:
+			if params >= len(args) {
+				it.fail(at, "missing argument '%v'", entry.Name)
+			}
+			f.vars[entry.Name] = args[params]
+			params++
+		case token.FINAL_VAR_DECL:
+			f.vars[entry.Name] = it.zero(entry.Type, entry.Type.Token)
+		}
+	}
+	if params != len(args) {
+		it.fail(at, "expected %v arguments but got %v", params, len(args))
+	}
+
+	ret, fl := it.block(f, fn.node.Children[3].Children)
+	if fl != flowReturn {
+		// Falling off the end of a non-void function yields a zero value
+		if typ := returnType(fn.table); typ.Type != "" && typ.Type != token.FINAL_VOID {
+			return it.zero(typ, at)
+		}
+	}
+	return ret
+}
+
+// Creates the zero value of a type
+func (it *Interpreter) zero(typ token.Type, at token.Token) Value {
+	return it.zeroDims(typ, typ.Dimlist, at)
+}
+
+func (it *Interpreter) zeroDims(typ token.Type, dims []int, at token.Token) Value {
+	if len(dims) > 0 {
+		if dims[0] == token.DIMENSION_ANY {
+			it.fail(at, "cannot create an array of unknown size")
+		}
+		arr := &Array{Elements: make([]Value, dims[0])}
+		for i := range arr.Elements {
+			arr.Elements[i] = it.zeroDims(typ, dims[1:], at)
+		}
+		return arr
+	}
+
+	switch typ.Type {
+	case token.FINAL_INTEGER:
+		return Int(0)
+	case token.FINAL_FLOAT:
+		return Float(0)
+	case token.FINAL_STRING:
+		return String("")
+	case token.FINAL_BOOL:
+		return Bool(false)
+	case token.FINAL_ID:
+		table := it.structTable(string(typ.Token.Lexeme))
+		if table == nil {
+			it.fail(at, "type '%v' is not a struct", typ.Token.Lexeme)
+		}
+		return it.newObject(table, at)
+	}
+	it.fail(at, "cannot create a value of type '%v'", typ)
+	return nil
+}
+
+// Creates an object with all of its data members (including inherited ones)
+// set to their zero value
+func (it *Interpreter) newObject(table token.SymbolTable, at token.Token) *Object {
+	if it.objects[table] {
+		it.fail(at, "struct '%v' contains itself", table.Id())
+	}
+	it.objects[table] = true
+	defer delete(it.objects, table)
+
+	obj := &Object{Struct: table, Fields: make(map[string]Value, 16)}
+	for _, inherited := range table.Inherited() {
+		base := it.newObject(inherited, at)
+		for _, name := range base.Order {
+			if _, ok := obj.Fields[name]; !ok {
+				obj.Fields[name] = base.Fields[name]
+				obj.Order = append(obj.Order, name)
+			}
+		}
+	}
+	for _, entry := range table.Entries() {
+		if entry.Kind != token.FINAL_VAR_DECL {
+			continue
+		}
+		if _, ok := obj.Fields[entry.Name]; !ok {
+			obj.Order = append(obj.Order, entry.Name)
+		}
+		obj.Fields[entry.Name] = it.zero(entry.Type, entry.Type.Token)
+	}
+	return obj
+}
+
+func (it *Interpreter) structTable(name string) token.SymbolTable {
+	for _, record := range it.global.Search(name) {
+		if record.Link != nil &&
+			(record.Kind == token.FINAL_STRUCT_DECL || record.Kind == token.FINAL_IMPL_DEF) {
+			return record.Link
+		}
+	}
+	return nil
+}
+
+// How control leaves a statement
+type flow int
+
+const (
+	flowNext     flow = iota // Carry on with the next statement
+	flowBreak                // Leave the innermost loop
+	flowContinue             // Start the next iteration of the innermost loop
+	flowReturn               // Return from the function
+)
+
+// Executes a list of statements, stopping at the first statement that does not
+// carry on with the next one
+func (it *Interpreter) block(f *frame, statements []*token.ASTNode) (ret Value, fl flow) {
+	for _, statement := range statements {
+		if ret, fl = it.statement(f, statement); fl != flowNext {
+			return ret, fl
+		}
+	}
+	return nil, flowNext
+}
+
+// Executes the body of a loop, done is true if the loop must not run another
+// iteration
+func (it *Interpreter) loopBody(f *frame, body *token.ASTNode) (ret Value, fl flow, done bool) {
+	it.step()
+	switch ret, fl = it.block(f, body.Children); fl {
+	case flowReturn:
+		return ret, fl, true
+	case flowBreak:
+		return nil, flowNext, true
+	}
+	return nil, flowNext, false
+}
+
+// Counts an execution step, aborting the program once the limit is exceeded
+func (it *Interpreter) step() {
+	it.steps++
+	if it.MaxSteps >= 0 && it.steps > it.MaxSteps {
+		panic(abort{ErrStepLimitExceeded})
+	}
+}
+
+func (it *Interpreter) statement(f *frame, node *token.ASTNode) (ret Value, fl flow) {
+	it.step()
+
+	switch node.Type {
+	case token.FINAL_VAR_DECL:
+		// Local variables are created when the function is called
+	case token.FINAL_ASSIGN:
+		dst := it.locate(f, node.Children[0])
+		src := it.eval(f, node.Children[1])
+		it.assign(dst, src, node.Children[0].Children[1].Token)
+	case token.FINAL_IF:
+		if it.truth(it.eval(f, node.Children[0]), node.Token) {
+			return it.block(f, node.Children[1].Children)
+		}
+		if len(node.Children) > 2 {
+			return it.block(f, node.Children[2].Children)
+		}
+	case token.FINAL_WHILE:
+		for it.truth(it.eval(f, node.Children[0]), node.Token) {
+			if ret, fl, done := it.loopBody(f, node.Children[1]); done {
+				return ret, fl
+			}
+		}
+	case token.FINAL_FOR:
+		defer token.EnterScope(f.vars, node.Meta.SymbolTable, func(entry token.SymbolTableRecord) Value {
+			return it.zero(entry.Type, entry.Type.Token)
+		})()
+		it.block(f, node.Children[0].Children)
+		for it.truth(it.eval(f, node.Children[1]), node.Token) {
+			if ret, fl, done := it.loopBody(f, node.Children[3]); done {
+				return ret, fl
+			}
+			it.statement(f, node.Children[2])
+		}
+	case token.FINAL_BREAK:
+		return nil, flowBreak
+	case token.FINAL_CONTINUE:
+		return nil, flowContinue
+	case token.FINAL_READ:
+		dst := it.locate(f, node.Children[0])
+		it.assign(dst, it.read(dst.get(), node.Token), node.Token)
+	case token.FINAL_WRITE:
+		v := it.eval(f, node.Children[0])
+		switch v.(type) {
+		case Int, Float, String, Bool:
+			fmt.Fprintln(it.out, v)
+		default:
+			it.fail(node.Token, "cannot write a value of type '%v'", typeName(v))
+		}
+	case token.FINAL_RETURN:
+		return clone(it.eval(f, node.Children[0])), flowReturn
+	case token.FINAL_FUNC_CALL:
+		it.call(f, node)
+	default:
+		it.fail(node.Token, "unsupported statement %v", node.Type)
+	}
+	return nil, flowNext
+}
+
+// Reads a number (or a word, for strings) of the same type as the current
+// value of the variable
+func (it *Interpreter) read(current Value, at token.Token) Value {
+	var word string
+	fmt.Fscan(it.in, &word)
+	switch current.(type) {
+	case Int:
+		i, _ := strconv.ParseInt(word, 10, 32)
+		return Int(i)
+	case Float:
+		f, _ := strconv.ParseFloat(word, 64)
+		return Float(f)
+	case String:
+		return String(word)
+	case Bool:
+		i, _ := strconv.ParseInt(word, 10, 32)
+		return Bool(i != 0) // Any non-zero integer is true
+	}
+	it.fail(at, "cannot read a value of type '%v'", typeName(current))
+	return nil
+}
+
+// An assignable location: a variable, a data member, or an array element
+type lvalue struct {
+	get func() Value
+	set func(v Value)
+}
+
+func (it *Interpreter) assign(dst lvalue, src Value, at token.Token) {
+	if old := dst.get(); typeName(old) != typeName(src) {
+		it.fail(at, "cannot assign a value of type '%v' to '%v'", typeName(src), typeName(old))
+	}
+	dst.set(clone(src))
+}
+
+func (it *Interpreter) locate(f *frame, node *token.ASTNode) lvalue {
+	subject, id, indices := node.Children[0], node.Children[1], node.Children[2].Children
+	name := string(id.Token.Lexeme)
+
+	var loc lvalue
+	switch {
+	case len(subject.Children) > 0:
+		obj, ok := it.eval(f, subject.Children[0]).(*Object)
+		if !ok {
+			it.fail(id.Token, "cannot access member '%v' of a value that is not a struct", name)
+		}
+		loc = it.member(obj, name, id.Token)
+	case hasKey(f.vars, name):
+		loc = lvalue{
+			get: func() Value { return f.vars[name] },
+			set: func(v Value) { f.vars[name] = v },
+		}
+	case f.self != nil:
+		loc = it.member(f.self, name, id.Token)
+	default:
+		it.fail(id.Token, "cannot resolve variable '%v'", name)
+	}
+
+	for _, index := range indices {
+		arr, ok := loc.get().(*Array)
+		if !ok {
+			it.fail(id.Token, "cannot index '%v', it is not an array", name)
+		}
+		i, ok := it.eval(f, index).(Int)
+		if !ok {
+			it.fail(id.Token, "array index must be an integer")
+		}
+		if i < 0 || int(i) >= len(arr.Elements) {
+			it.fail(id.Token, "index %v out of bounds for '%v' of size %v", i, name, len(arr.Elements))
+		}
+		loc = lvalue{
+			get: func() Value { return arr.Elements[i] },
+			set: func(v Value) { arr.Elements[i] = v },
+		}
+	}
+	return loc
+}
+
+func (it *Interpreter) member(obj *Object, name string, at token.Token) lvalue {
+	if !hasKey(obj.Fields, name) {
+		it.fail(at, "'%v' has no data member '%v'", obj.Struct.Id(), name)
+	}
+	return lvalue{
+		get: func() Value { return obj.Fields[name] },
+		set: func(v Value) { obj.Fields[name] = v },
+	}
+}
+
+// Calls the function that the SemCheckVisitor has resolved for the node,
+// returns nil for void functions
+func (it *Interpreter) call(f *frame, node *token.ASTNode) Value {
+	subject, id := node.Children[0], node.Children[1]
+	var fn *function
+	if record := node.Meta.Record; record != nil {
+		fn = it.funcs[record.Link]
+	}
+	if fn == nil {
+		it.fail(id.Token, "cannot resolve function '%v'", id.Token.Lexeme)
+	}
+
+	var self *Object
+	if fn.owner != nil {
+		if len(subject.Children) > 0 {
+			obj, ok := it.eval(f, subject.Children[0]).(*Object)
+			if !ok {
+				it.fail(id.Token, "cannot call method '%v' on a value that is not a struct",
+					id.Token.Lexeme)
+			}
+			self = obj
+		} else if self = f.self; self == nil {
+			it.fail(id.Token, "cannot call method '%v' without an object", id.Token.Lexeme)
+		}
+	}
+
+	args := make([]Value, 0, len(node.Children[2].Children))
+	for _, arg := range node.Children[2].Children {
+		v := it.eval(f, arg)
+		if _, ok := v.(*Array); !ok {
+			v = clone(v) // Arrays are passed by reference
+		}
+		args = append(args, v)
+	}
+	return it.invoke(fn, self, args, id.Token)
+}
+
+// Evaluates an expression. Structs and arrays are returned by reference, they
+// must be cloned before they are stored
+func (it *Interpreter) eval(f *frame, node *token.ASTNode) Value {
+	switch node.Type {
+	case token.FINAL_ARITH_EXPR,
+		token.FINAL_REL_EXPR,
+		token.FINAL_EXPR,
+		token.FINAL_INDEX,
+		token.FINAL_FUNC_CALL_PARAM:
+		return it.eval(f, node.Children[0])
+	case token.FINAL_FACTOR:
+		if len(node.Children) == 2 {
+			return it.unary(node.Children[0], it.eval(f, node.Children[1]))
+		}
+		return it.eval(f, node.Children[0])
+	case token.FINAL_PLUS,
+		token.FINAL_MINUS,
+		token.FINAL_MULT,
+		token.FINAL_DIV,
+		token.FINAL_AND,
+		token.FINAL_OR,
+		token.FINAL_EQ,
+		token.FINAL_NEQ,
+		token.FINAL_LT,
+		token.FINAL_GT,
+		token.FINAL_LEQ,
+		token.FINAL_GEQ:
+		return it.binary(node, it.eval(f, node.Children[0]), it.eval(f, node.Children[1]))
+	case token.FINAL_INTNUM:
+		i, err := strconv.ParseInt(string(node.Token.Lexeme), 10, 32)
+		if err != nil {
+			it.fail(node.Token, "integer literal %v is out of range", node.Token.Lexeme)
+		}
+		return Int(i)
+	case token.FINAL_FLOATNUM:
+		x, err := strconv.ParseFloat(string(node.Token.Lexeme), 64)
+		if err != nil {
+			it.fail(node.Token, "malformed float literal %v", node.Token.Lexeme)
+		}
+		return Float(x)
+	case token.FINAL_STRINGLIT:
+		return String(token.StringLitText(node.Token.Lexeme))
+	case token.FINAL_BOOLLIT:
+		return Bool(node.Token.Id == token.TRUE)
+	case token.FINAL_VARIABLE:
+		return it.locate(f, node).get()
+	case token.FINAL_FUNC_CALL:
+		v := it.call(f, node)
+		if v == nil {
+			it.fail(node.Children[1].Token,
+				"function '%v' does not return a value", node.Children[1].Token.Lexeme)
+		}
+		return v
+	}
+	it.fail(node.Token, "unsupported expression %v", node.Type)
+	return nil
+}
+
+func (it *Interpreter) unary(op *token.ASTNode, v Value) Value {
+	switch op.Type {
+	case token.FINAL_NOT:
+		return Bool(!it.truth(v, op.Token))
+	case token.FINAL_NEGATIVE:
+		switch v := v.(type) {
+		case Int:
+			return -v
+		case Float:
+			return -v
+		}
+	case token.FINAL_POSITIVE:
+		switch v.(type) {
+		case Int, Float:
+			return v
+		}
+	}
+	it.fail(op.Token, "invalid operand of type '%v' for %v", typeName(v), op.Type)
+	return nil
+}
+
+func (it *Interpreter) binary(node *token.ASTNode, left, right Value) Value {
+	switch node.Type {
+	case token.FINAL_AND:
+		return Bool(it.truth(left, node.Token) && it.truth(right, node.Token))
+	case token.FINAL_OR:
+		return Bool(it.truth(left, node.Token) || it.truth(right, node.Token))
+	}
+
+	switch l := left.(type) {
+	case Bool:
+		if r, ok := right.(Bool); ok {
+			switch node.Type {
+			case token.FINAL_EQ:
+				return Bool(l == r)
+			case token.FINAL_NEQ:
+				return Bool(l != r)
+			}
+		}
+	case Int:
+		if r, ok := right.(Int); ok {
+			if node.Type == token.FINAL_DIV && r == 0 {
+				it.fail(node.Token, "division by zero")
+			}
+			return arithmetic(node.Type, l, r)
+		}
+	case Float:
+		if r, ok := right.(Float); ok {
+			if node.Type == token.FINAL_DIV && r == 0 {
+				it.fail(node.Token, "division by zero")
+			}
+			return arithmetic(node.Type, l, r)
+		}
+	}
+	it.fail(node.Token, "invalid operands of types '%v' and '%v' for %v",
+		typeName(left), typeName(right), node.Type)
+	return nil
+}
+
+func arithmetic[T Int | Float](op token.Kind, l, r T) Value {
+	switch op {
+	case token.FINAL_PLUS:
+		return Value(l + r)
+	case token.FINAL_MINUS:
+		return Value(l - r)
+	case token.FINAL_MULT:
+		return Value(l * r)
+	case token.FINAL_DIV:
+		return Value(l / r)
+	case token.FINAL_EQ:
+		return Bool(l == r)
+	case token.FINAL_NEQ:
+		return Bool(l != r)
+	case token.FINAL_LT:
+		return Bool(l < r)
+	case token.FINAL_GT:
+		return Bool(l > r)
+	case token.FINAL_LEQ:
+		return Bool(l <= r)
+	case token.FINAL_GEQ:
+		return Bool(l >= r)
+	}
+	return nil
+}
+
+func (it *Interpreter) truth(v Value, at token.Token) bool {
+	if b, ok := v.(Bool); ok {
+		return bool(b)
+	}
+	it.fail(at, "expected a bool but found a value of type '%v'", typeName(v))
+	return false
+}
+
+func hasKey(m map[string]Value, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// Returns the return type of a function given its table
+func returnType(table token.SymbolTable) token.Type {
+	if table.Parent() == nil {
+		return token.Type{}
+	}
+	for _, entry := range table.Parent().Entries() {
+		if entry.Link == table {
+			return entry.Type
+		}
+	}
+	return token.Type{}
+}
@@ -0,0 +1,113 @@
+package interp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// A runtime value, one of Int, Float, String, Bool, *Object, or *Array
+type Value interface {
+	fmt.Stringer
+	value()
+}
+
+// Integers are 32 bits wide and wrap around, like on the MOON machine
+type Int int32
+
+type Float float64
+
+type String string
+
+type Bool bool
+
+// An instance of a struct. The data members of all inherited structs are
+// flattened into the object, the first member of a given name wins
+type Object struct {
+	Struct token.SymbolTable
+	Fields map[string]Value
+	Order  []string // Order of declaration of the fields, for printing
+}
+
+// An array, the elements of a multi-dimensional array are themselves arrays
+type Array struct {
+	Elements []Value
+}
+
+func (Int) value()     {}
+func (Float) value()   {}
+func (String) value()  {}
+func (Bool) value()    {}
+func (*Object) value() {}
+func (*Array) value()  {}
+
+func (i Int) String() string { return strconv.Itoa(int(i)) }
+
+func (f Float) String() string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+func (s String) String() string { return string(s) }
+
+func (b Bool) String() string { return strconv.FormatBool(bool(b)) }
+
+func (o *Object) String() string {
+	fields := make([]string, 0, len(o.Order))
+	for _, name := range o.Order {
+		fields = append(fields, fmt.Sprintf("%v: %v", name, o.Fields[name]))
+	}
+	return fmt.Sprintf("%v{%v}", o.Struct.Id(), strings.Join(fields, ", "))
+}
+
+func (a *Array) String() string {
+	elements := make([]string, 0, len(a.Elements))
+	for _, e := range a.Elements {
+		elements = append(elements, e.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// Makes a deep copy of a value, structs and arrays have value semantics
+func clone(v Value) Value {
+	switch v := v.(type) {
+	case *Object:
+		o := &Object{Struct: v.Struct, Fields: make(map[string]Value, len(v.Fields)), Order: v.Order}
+		for name, field := range v.Fields {
+			o.Fields[name] = clone(field)
+		}
+		return o
+	case *Array:
+		a := &Array{Elements: make([]Value, len(v.Elements))}
+		for i, e := range v.Elements {
+			a.Elements[i] = clone(e)
+		}
+		return a
+	}
+	return v
+}
+
+// Returns the name of the type of a value, for error messages
+func typeName(v Value) string {
+	switch v := v.(type) {
+	case Int:
+		return "integer"
+	case Float:
+		return "float"
+	case String:
+		return "string"
+	case Bool:
+		return "bool"
+	case *Object:
+		return v.Struct.Id()
+	case *Array:
+		if len(v.Elements) > 0 {
+			return typeName(v.Elements[0]) + "[]"
+		}
+		return "array"
+	case nil:
+		return "void"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,37 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+var (
+	ErrNoMain        = fmt.Errorf("program has no main function")
+	ErrNoSymbolTable = fmt.Errorf("program has no symbol table")
+)
+
+// Emitted when an AST cannot be lowered to the IR, usually because it has not
+// been fully decorated by the semantic analysis visitors
+type LoweringError struct {
+	Msg   string
+	Token token.Token
+}
+
+func (e *LoweringError) Error() string {
+	if e.Token.Line > 0 {
+		return fmt.Sprintf("ir: %v (line %v)", e.Msg, e.Token.Line)
+	}
+	return fmt.Sprintf("ir: %v", e.Msg)
+}
+
+// Emitted by the Interpreter when a program cannot continue executing
+type RuntimeError struct {
+	Msg  string
+	Func string // The function that was executing
+	Pc   int    // The index of the offending instruction
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("runtime error in %v (instruction %v): %v", e.Func, e.Pc, e.Msg)
+}
@@ -0,0 +1,505 @@
+package ir
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/obonobo/esac/core/token/visitors"
+)
+
+const (
+	// Default amount of memory available to the stack, in bytes
+	DEFAULT_MEMORY_SIZE = 1 << 20
+
+	// Default number of instructions after which a program is aborted
+	DEFAULT_MAX_STEPS = 50_000_000
+)
+
+// Executes a Program. Integers are 32-bit and wrap around, floats are 64-bit.
+// Memory is a flat array of bytes holding the stack: main's frame sits at
+// address 0, and every call places the frame of the callee right after the
+// frame of the caller.
+//
+// Strings are kept out of memory, a string value is the index of its text in
+// a table of all the strings seen so far. The zero value is the empty string.
+//
+// The read instruction reads whitespace-separated numbers (or words, for
+// strings) from the input, a missing or malformed number reads as zero. The
+// write instruction writes a value followed by a newline.
+type Interpreter struct {
+	Program  *Program
+	MaxSteps int
+
+	in       *bufio.Reader
+	out      io.Writer
+	memory   []byte
+	strings  []string
+	interned map[string]int
+	steps    int
+}
+
+func NewInterpreter(prog *Program, in io.Reader, out io.Writer) *Interpreter {
+	return &Interpreter{
+		Program:  prog,
+		MaxSteps: DEFAULT_MAX_STEPS,
+		in:       bufio.NewReader(in),
+		out:      out,
+		memory:   make([]byte, DEFAULT_MEMORY_SIZE),
+		strings:  []string{""},
+		interned: map[string]int{"": 0},
+	}
+}
+
+// Runs a program with a fresh Interpreter
+func Interpret(prog *Program, in io.Reader, out io.Writer) error {
+	return NewInterpreter(prog, in, out).Run()
+}
+
+// Runs the main function of the program
+func (it *Interpreter) Run() error {
+	if it.Program == nil || it.Program.Main == nil {
+		return ErrNoMain
+	}
+	it.steps = 0
+	_, err := it.call(it.Program.Main, 0)
+	return err
+}
+
+// A value held by a temporary, INT and ADDR values use i
+type val struct {
+	i int64
+	f float64
+}
+
+// The state of a single function call
+type activation struct {
+	fn    *Function
+	fp    int // Address of the frame
+	temps []val
+	pc    int
+}
+
+func (it *Interpreter) call(fn *Function, fp int) (val, error) {
+	if fp < 0 || fp+fn.FrameSize > len(it.memory) {
+		return val{}, &RuntimeError{Msg: "stack overflow", Func: fn.Name}
+	}
+	a := &activation{fn: fn, fp: fp, temps: make([]val, fn.Temps+1)}
+
+	for a.pc < len(fn.Code) {
+		it.steps++
+		if it.MaxSteps > 0 && it.steps > it.MaxSteps {
+			return val{}, a.fail("exceeded the maximum of %v steps", it.MaxSteps)
+		}
+
+		i := fn.Code[a.pc]
+		a.pc++
+		switch i.Op {
+		case OP_LABEL:
+		case OP_GOTO:
+			if err := a.jump(i.Label); err != nil {
+				return val{}, err
+			}
+		case OP_IFZ:
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			if isZero(v, i.Type) {
+				if err := a.jump(i.Label); err != nil {
+					return val{}, err
+				}
+			}
+		case OP_MOVE:
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			if err := it.set(a, i.Dst, i.Type, v); err != nil {
+				return val{}, err
+			}
+		case OP_ADDR:
+			v, ok := i.A.(*Var)
+			if !ok {
+				return val{}, a.fail("cannot take the address of %v", i.A)
+			}
+			if err := it.set(a, i.Dst, ADDR, val{i: int64(a.fp + v.Offset)}); err != nil {
+				return val{}, err
+			}
+		case OP_LOAD:
+			addr, err := it.get(a, i.A, ADDR)
+			if err != nil {
+				return val{}, err
+			}
+			v, err := it.read(a, int(addr.i), i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			if err := it.set(a, i.Dst, i.Type, v); err != nil {
+				return val{}, err
+			}
+		case OP_STORE:
+			addr, err := it.get(a, i.Dst, ADDR)
+			if err != nil {
+				return val{}, err
+			}
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			if err := it.write(a, int(addr.i), i.Type, v); err != nil {
+				return val{}, err
+			}
+		case OP_COPY:
+			dst, err := it.get(a, i.Dst, ADDR)
+			if err != nil {
+				return val{}, err
+			}
+			src, err := it.get(a, i.A, ADDR)
+			if err != nil {
+				return val{}, err
+			}
+			if err := it.copy(a, int(dst.i), int(src.i), i.Size); err != nil {
+				return val{}, err
+			}
+		case OP_ARG:
+			param, ok := i.Dst.(*Var)
+			if !ok {
+				return val{}, a.fail("malformed argument %v", i.Dst)
+			}
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			callee := a.fp + fn.FrameSize
+			if callee+i.Func.FrameSize > len(it.memory) {
+				return val{}, a.fail("stack overflow")
+			}
+			addr := callee + param.Offset
+			if i.Size > 0 {
+				err = it.copy(a, addr, int(v.i), i.Size)
+			} else {
+				err = it.write(a, addr, i.Type, v)
+			}
+			if err != nil {
+				return val{}, err
+			}
+		case OP_CALL:
+			callee := a.fp + fn.FrameSize
+			v, err := it.call(i.Func, callee)
+			if err != nil {
+				return val{}, err
+			}
+			switch {
+			case i.Dst == nil:
+			case i.Size > 0:
+				dst, ok := i.Dst.(*Var)
+				if !ok {
+					return val{}, a.fail("malformed call destination %v", i.Dst)
+				}
+				err = it.copy(a, a.fp+dst.Offset, callee+visitors.FRAME_RETURN_VALUE_OFFSET, i.Size)
+			default:
+				err = it.set(a, i.Dst, i.Type, v)
+			}
+			if err != nil {
+				return val{}, err
+			}
+		case OP_RETURN:
+			if i.A == nil {
+				return val{}, nil
+			}
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			if i.Size > 0 {
+				err = it.copy(a, a.fp+visitors.FRAME_RETURN_VALUE_OFFSET, int(v.i), i.Size)
+			} else {
+				err = it.write(a, a.fp+visitors.FRAME_RETURN_VALUE_OFFSET, i.Type, v)
+			}
+			return v, err
+		case OP_READ:
+			if err := it.set(a, i.Dst, i.Type, it.readNumber(i.Type)); err != nil {
+				return val{}, err
+			}
+		case OP_WRITE:
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			switch i.Type {
+			case BOOL:
+				fmt.Fprintln(it.out, v.i != 0)
+			case FLOAT:
+				fmt.Fprintln(it.out, strconv.FormatFloat(v.f, 'g', -1, 64))
+			case STRING:
+				text, err := it.text(a, v)
+				if err != nil {
+					return val{}, err
+				}
+				fmt.Fprintln(it.out, text)
+			default:
+				fmt.Fprintln(it.out, v.i)
+			}
+		case OP_NEG, OP_NOT:
+			v, err := it.get(a, i.A, i.Type)
+			if err != nil {
+				return val{}, err
+			}
+			typ := i.Type
+			switch {
+			case i.Op == OP_NOT:
+				v, typ = boolean(isZero(v, i.Type)), INT
+			case i.Type == FLOAT:
+				v.f = -v.f
+			default:
+				v.i = int64(-int32(v.i))
+			}
+			if err := it.set(a, i.Dst, typ, v); err != nil {
+				return val{}, err
+			}
+		default:
+			if !i.Op.IsBinary() {
+				return val{}, a.fail("unknown instruction %v", i)
+			}
+			if err := it.binary(a, i); err != nil {
+				return val{}, err
+			}
+		}
+	}
+	return val{}, nil
+}
+
+func (it *Interpreter) binary(a *activation, i Instruction) error {
+	left, err := it.get(a, i.A, i.Type)
+	if err != nil {
+		return err
+	}
+	right, err := it.get(a, i.B, i.Type)
+	if err != nil {
+		return err
+	}
+
+	switch i.Op {
+	case OP_AND:
+		return it.set(a, i.Dst, INT, boolean(!isZero(left, i.Type) && !isZero(right, i.Type)))
+	case OP_OR:
+		return it.set(a, i.Dst, INT, boolean(!isZero(left, i.Type) || !isZero(right, i.Type)))
+	}
+
+	if i.Type == FLOAT {
+		l, r := left.f, right.f
+		var res val
+		switch i.Op {
+		case OP_ADD:
+			res.f = l + r
+		case OP_SUB:
+			res.f = l - r
+		case OP_MUL:
+			res.f = l * r
+		case OP_DIV:
+			if r == 0 {
+				return a.fail("division by zero")
+			}
+			res.f = l / r
+		case OP_EQ:
+			return it.set(a, i.Dst, INT, boolean(l == r))
+		case OP_NEQ:
+			return it.set(a, i.Dst, INT, boolean(l != r))
+		case OP_LT:
+			return it.set(a, i.Dst, INT, boolean(l < r))
+		case OP_GT:
+			return it.set(a, i.Dst, INT, boolean(l > r))
+		case OP_LEQ:
+			return it.set(a, i.Dst, INT, boolean(l <= r))
+		case OP_GEQ:
+			return it.set(a, i.Dst, INT, boolean(l >= r))
+		}
+		return it.set(a, i.Dst, FLOAT, res)
+	}
+
+	l, r := left.i, right.i
+	var res int64
+	switch i.Op {
+	case OP_ADD:
+		res = l + r
+	case OP_SUB:
+		res = l - r
+	case OP_MUL:
+		res = l * r
+	case OP_DIV:
+		if r == 0 {
+			return a.fail("division by zero")
+		}
+		res = l / r
+	case OP_EQ:
+		return it.set(a, i.Dst, INT, boolean(l == r))
+	case OP_NEQ:
+		return it.set(a, i.Dst, INT, boolean(l != r))
+	case OP_LT:
+		return it.set(a, i.Dst, INT, boolean(l < r))
+	case OP_GT:
+		return it.set(a, i.Dst, INT, boolean(l > r))
+	case OP_LEQ:
+		return it.set(a, i.Dst, INT, boolean(l <= r))
+	case OP_GEQ:
+		return it.set(a, i.Dst, INT, boolean(l >= r))
+	}
+	if i.Type == INT {
+		res = int64(int32(res))
+	}
+	return it.set(a, i.Dst, i.Type, val{i: res})
+}
+
+// Evaluates an operand
+func (it *Interpreter) get(a *activation, op Operand, typ Type) (val, error) {
+	switch op := op.(type) {
+	case *Temp:
+		if op.Id < 0 || op.Id >= len(a.temps) {
+			return val{}, a.fail("no such temporary %v", op)
+		}
+		return a.temps[op.Id], nil
+	case *Var:
+		return it.read(a, a.fp+op.Offset, typ)
+	case IntConst:
+		if typ == FLOAT {
+			return val{f: float64(op)}, nil
+		}
+		return val{i: int64(op)}, nil
+	case FloatConst:
+		return val{f: float64(op)}, nil
+	case StringConst:
+		return it.intern(string(op)), nil
+	}
+	return val{}, a.fail("malformed operand %v", op)
+}
+
+// Returns the value of a string, adding it to the table of strings if needed
+func (it *Interpreter) intern(text string) val {
+	i, ok := it.interned[text]
+	if !ok {
+		i = len(it.strings)
+		it.strings = append(it.strings, text)
+		it.interned[text] = i
+	}
+	return val{i: int64(i)}
+}
+
+// Returns the text of a string value
+func (it *Interpreter) text(a *activation, v val) (string, error) {
+	if v.i < 0 || v.i >= int64(len(it.strings)) {
+		return "", a.fail("no such string %v", v.i)
+	}
+	return it.strings[v.i], nil
+}
+
+// Assigns to a temporary or a variable
+func (it *Interpreter) set(a *activation, op Operand, typ Type, v val) error {
+	switch op := op.(type) {
+	case *Temp:
+		if op.Id < 0 || op.Id >= len(a.temps) {
+			return a.fail("no such temporary %v", op)
+		}
+		a.temps[op.Id] = v
+		return nil
+	case *Var:
+		return it.write(a, a.fp+op.Offset, typ, v)
+	}
+	return a.fail("cannot assign to %v", op)
+}
+
+func (it *Interpreter) read(a *activation, addr int, typ Type) (val, error) {
+	if err := it.check(a, addr, sizeOf(typ)); err != nil {
+		return val{}, err
+	}
+	if typ == FLOAT {
+		bits := binary.LittleEndian.Uint64(it.memory[addr:])
+		return val{f: math.Float64frombits(bits)}, nil
+	}
+	return val{i: int64(int32(binary.LittleEndian.Uint32(it.memory[addr:])))}, nil
+}
+
+func (it *Interpreter) write(a *activation, addr int, typ Type, v val) error {
+	if err := it.check(a, addr, sizeOf(typ)); err != nil {
+		return err
+	}
+	if typ == FLOAT {
+		binary.LittleEndian.PutUint64(it.memory[addr:], math.Float64bits(v.f))
+	} else {
+		binary.LittleEndian.PutUint32(it.memory[addr:], uint32(v.i))
+	}
+	return nil
+}
+
+func (it *Interpreter) copy(a *activation, dst, src, size int) error {
+	if err := it.check(a, dst, size); err != nil {
+		return err
+	}
+	if err := it.check(a, src, size); err != nil {
+		return err
+	}
+	copy(it.memory[dst:dst+size], it.memory[src:src+size])
+	return nil
+}
+
+func (it *Interpreter) check(a *activation, addr, size int) error {
+	if addr < 0 || addr+size > len(it.memory) {
+		return a.fail("memory access out of bounds at address %v", addr)
+	}
+	return nil
+}
+
+// Reads the next whitespace-separated number (or word) from the input
+func (it *Interpreter) readNumber(typ Type) val {
+	var word string
+	fmt.Fscan(it.in, &word)
+	switch typ {
+	case STRING:
+		return it.intern(word)
+	case FLOAT:
+		f, _ := strconv.ParseFloat(word, 64)
+		return val{f: f}
+	}
+	i, _ := strconv.ParseInt(word, 10, 32)
+	if typ == BOOL {
+		return boolean(i != 0) // Any non-zero integer is true
+	}
+	return val{i: i}
+}
+
+func (a *activation) jump(label string) error {
+	pc, ok := a.fn.Labels[label]
+	if !ok {
+		return a.fail("no such label %v", label)
+	}
+	a.pc = pc
+	return nil
+}
+
+func (a *activation) fail(msg string, args ...any) error {
+	return &RuntimeError{Msg: fmt.Sprintf(msg, args...), Func: a.fn.Name, Pc: a.pc - 1}
+}
+
+func isZero(v val, typ Type) bool {
+	if typ == FLOAT {
+		return v.f == 0
+	}
+	return v.i == 0
+}
+
+func boolean(b bool) val {
+	if b {
+		return val{i: 1}
+	}
+	return val{}
+}
+
+func sizeOf(typ Type) int {
+	if typ == FLOAT {
+		return visitors.FLOAT_SIZE
+	}
+	return visitors.WORD_SIZE
+}
@@ -0,0 +1,270 @@
+// Package ir defines a linear three-address intermediate representation of a
+// program, lowered from an AST that has been decorated by the SymTabVisitor,
+// SemCheckVisitor, and MemoryLayoutVisitor.
+//
+// Every function is a flat list of instructions operating on an unbounded
+// number of temporaries, with control flow expressed using labels and jumps.
+// Variables live in memory: every function has a stack frame laid out as
+// described by the MemoryLayoutVisitor, and the variables of the frame are
+// accessed through Var operands. Array elements and struct members are accessed
+// through computed addresses.
+package ir
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The type of a value held by a temporary or stored in memory
+type Type int
+
+const (
+	INT Type = iota
+	FLOAT
+	ADDR
+	STRING
+	BOOL
+)
+
+func (t Type) String() string {
+	switch t {
+	case INT:
+		return "int"
+	case FLOAT:
+		return "float"
+	case ADDR:
+		return "addr"
+	case STRING:
+		return "string"
+	case BOOL:
+		return "bool"
+	}
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+type Op int
+
+const (
+	OP_LABEL  Op = iota // Label:
+	OP_GOTO             // goto Label
+	OP_IFZ              // ifz A goto Label
+	OP_MOVE             // Dst = A
+	OP_ADDR             // Dst = &A
+	OP_LOAD             // Dst = *A
+	OP_STORE            // *Dst = A
+	OP_COPY             // *Dst = *A (Size bytes)
+	OP_NEG              // Dst = -A
+	OP_NOT              // Dst = !A
+	OP_ADD              // Dst = A + B
+	OP_SUB              // Dst = A - B
+	OP_MUL              // Dst = A * B
+	OP_DIV              // Dst = A / B
+	OP_AND              // Dst = A && B
+	OP_OR               // Dst = A || B
+	OP_EQ               // Dst = A == B
+	OP_NEQ              // Dst = A != B
+	OP_LT               // Dst = A < B
+	OP_GT               // Dst = A > B
+	OP_LEQ              // Dst = A <= B
+	OP_GEQ              // Dst = A >= B
+	OP_ARG              // arg Func.Dst = A
+	OP_CALL             // Dst = call Func
+	OP_RETURN           // return A
+	OP_READ             // Dst = read
+	OP_WRITE            // write A
+)
+
+var opSymbols = map[Op]string{
+	OP_NEG: "-",
+	OP_NOT: "!",
+	OP_ADD: "+",
+	OP_SUB: "-",
+	OP_MUL: "*",
+	OP_DIV: "/",
+	OP_AND: "&&",
+	OP_OR:  "||",
+	OP_EQ:  "==",
+	OP_NEQ: "!=",
+	OP_LT:  "<",
+	OP_GT:  ">",
+	OP_LEQ: "<=",
+	OP_GEQ: ">=",
+}
+
+// Whether the operator compares its operands, producing an INT
+func (o Op) IsComparison() bool {
+	return OP_EQ <= o && o <= OP_GEQ
+}
+
+func (o Op) IsBinary() bool {
+	return OP_ADD <= o && o <= OP_GEQ
+}
+
+// An operand of an instruction, one of *Temp, *Var, IntConst, FloatConst, or
+// StringConst
+type Operand interface {
+	fmt.Stringer
+	operand()
+}
+
+// A temporary, the IR has an unbounded number of them. Temporaries are local
+// to a function call
+type Temp struct {
+	Id int
+}
+
+// A variable in the stack frame of a function
+type Var struct {
+	Name   string
+	Offset int
+	Size   int
+}
+
+type IntConst int32
+
+type FloatConst float64
+
+// The text of a string literal, with its escape sequences already replaced
+type StringConst string
+
+func (*Temp) operand()       {}
+func (*Var) operand()        {}
+func (IntConst) operand()    {}
+func (FloatConst) operand()  {}
+func (StringConst) operand() {}
+
+func (t *Temp) String() string       { return "t" + strconv.Itoa(t.Id) }
+func (v *Var) String() string        { return v.Name }
+func (c IntConst) String() string    { return strconv.Itoa(int(c)) }
+func (c StringConst) String() string { return strconv.Quote(string(c)) }
+
+func (c FloatConst) String() string {
+	s := strconv.FormatFloat(float64(c), 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+type Instruction struct {
+	Op Op
+
+	// The type of the values that are operated on. For comparisons, this is
+	// the type of the operands, the result is always an INT
+	Type Type
+
+	Dst, A, B Operand
+	Label     string
+	Func      *Function // The callee of OP_ARG and OP_CALL
+	Size      int       // Number of bytes moved when copying an aggregate
+}
+
+func (i Instruction) String() string {
+	suffix := ""
+	if i.Type == FLOAT || i.Type == STRING || i.Type == BOOL {
+		suffix = fmt.Sprintf(" (%v)", i.Type)
+	}
+	if i.Size > 0 {
+		suffix = fmt.Sprintf(" (%v bytes)", i.Size)
+	}
+
+	switch i.Op {
+	case OP_LABEL:
+		return i.Label + ":"
+	case OP_GOTO:
+		return "goto " + i.Label
+	case OP_IFZ:
+		return fmt.Sprintf("ifz %v goto %v", i.A, i.Label)
+	case OP_MOVE:
+		return fmt.Sprintf("%v = %v%v", i.Dst, i.A, suffix)
+	case OP_ADDR:
+		return fmt.Sprintf("%v = &%v", i.Dst, i.A)
+	case OP_LOAD:
+		return fmt.Sprintf("%v = *%v%v", i.Dst, i.A, suffix)
+	case OP_STORE:
+		return fmt.Sprintf("*%v = %v%v", i.Dst, i.A, suffix)
+	case OP_COPY:
+		return fmt.Sprintf("*%v = *%v%v", i.Dst, i.A, suffix)
+	case OP_NEG, OP_NOT:
+		return fmt.Sprintf("%v = %v%v%v", i.Dst, opSymbols[i.Op], i.A, suffix)
+	case OP_ARG:
+		if i.Size > 0 {
+			return fmt.Sprintf("arg %v.%v = *%v%v", i.Func.Name, i.Dst, i.A, suffix)
+		}
+		return fmt.Sprintf("arg %v.%v = %v%v", i.Func.Name, i.Dst, i.A, suffix)
+	case OP_CALL:
+		if i.Dst == nil {
+			return "call " + i.Func.Name
+		}
+		return fmt.Sprintf("%v = call %v%v", i.Dst, i.Func.Name, suffix)
+	case OP_RETURN:
+		switch {
+		case i.A == nil:
+			return "return"
+		case i.Size > 0:
+			return fmt.Sprintf("return *%v%v", i.A, suffix)
+		}
+		return fmt.Sprintf("return %v%v", i.A, suffix)
+	case OP_READ:
+		return fmt.Sprintf("%v = read%v", i.Dst, suffix)
+	case OP_WRITE:
+		return fmt.Sprintf("write %v%v", i.A, suffix)
+	}
+	if i.Op.IsBinary() {
+		return fmt.Sprintf("%v = %v %v %v%v", i.Dst, i.A, opSymbols[i.Op], i.B, suffix)
+	}
+	return fmt.Sprintf("Op(%v)", int(i.Op))
+}
+
+// A function or method. Methods are named 'Struct::method', overloads get a
+// numeric suffix to keep names unique
+type Function struct {
+	Name      string
+	FrameSize int
+	Temps     int // Number of temporaries used by the code
+	Returns   Type
+	Void      bool
+	Code      []Instruction
+
+	// Labels of the code, mapped to their index in Code
+	Labels map[string]int
+}
+
+func (f *Function) String() string {
+	b := new(strings.Builder)
+	f.Print(b)
+	return b.String()
+}
+
+func (f *Function) Print(w io.Writer) {
+	fmt.Fprintf(w, "function %v (frame %v)\n", f.Name, f.FrameSize)
+	for _, instruction := range f.Code {
+		if instruction.Op == OP_LABEL {
+			fmt.Fprintln(w, instruction)
+			continue
+		}
+		fmt.Fprintf(w, "\t%v\n", instruction)
+	}
+}
+
+type Program struct {
+	Functions []*Function
+	Main      *Function
+}
+
+func (p *Program) String() string {
+	b := new(strings.Builder)
+	p.Print(b)
+	return b.String()
+}
+
+func (p *Program) Print(w io.Writer) {
+	for i, f := range p.Functions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		f.Print(w)
+	}
+}
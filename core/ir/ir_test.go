@@ -0,0 +1,323 @@
+package ir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/internal/testutils"
+)
+
+func TestLower(t *testing.T) {
+	t.Parallel()
+	prog := mustLower(t, `
+	func fact(n: integer) -> integer {
+		if (n <= 1) then { return (1); } else { return (n * fact(n - 1)); };
+	}
+	func main() -> void {
+		let a: integer[3];
+		a[1] = fact(3);
+		write(a[1]);
+	}
+	`)
+
+	expected := strings.TrimLeft(`
+function fact (frame 40)
+	t1 = n
+	t2 = t1 <= 1
+	ifz t2 goto L1
+	return 1
+	goto L2
+L1:
+	t3 = n
+	t4 = n
+	t5 = t4 - 1
+	arg fact.n = t5
+	t6 = call fact
+	t7 = t3 * t6
+	return t7
+L2:
+	return
+
+function main (frame 32)
+	t1 = &a
+	t2 = 1 * 4
+	t3 = t1 + t2
+	arg fact.n = 3
+	t4 = call fact
+	*t3 = t4
+	t5 = &a
+	t6 = 1 * 4
+	t7 = t5 + t6
+	t8 = *t7
+	write t8
+	return
+`, "\n")
+
+	if actual := prog.String(); actual != expected {
+		t.Errorf("\nExpected IR:\n%v\nActual IR:\n%v", expected, actual)
+	}
+}
+
+func TestInterpret(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		src    string
+		input  string
+		output string
+	}{
+		{
+			name: "recursion",
+			src: `
+			func fib(n: integer) -> integer {
+				if (n < 2) then { return (n); } else { return (fib(n - 1) + fib(n - 2)); };
+			}
+			func main() -> void { write(fib(15)); }
+			`,
+			output: "610\n",
+		},
+		{
+			name: "arrays passed by reference",
+			src: `
+			func sort(arr: integer[], size: integer) -> void {
+				let i: integer; let j: integer; let tmp: integer;
+				i = 0;
+				while (i < size - 1) {
+					j = 0;
+					while (j < size - i - 1) {
+						if (arr[j] > arr[j + 1]) then {
+							tmp = arr[j]; arr[j] = arr[j + 1]; arr[j + 1] = tmp;
+						} else ;
+						j = j + 1;
+					};
+					i = i + 1;
+				};
+			}
+			func main() -> void {
+				let arr: integer[5]; let i: integer;
+				arr[0] = 5; arr[1] = 1; arr[2] = 4; arr[3] = 2; arr[4] = 3;
+				sort(arr, 5);
+				i = 0;
+				while (i < 5) { write(arr[i]); i = i + 1; };
+			}
+			`,
+			output: "1\n2\n3\n4\n5\n",
+		},
+		{
+			name: "for loops, break, and continue",
+			src: `
+			func main() -> void {
+				let j: integer;
+				for (let i: integer = 0; i < 5; i = i + 1) {
+					if (i == 1) then continue; else ;
+					if (i == 3) then break; else ;
+					write(i);
+				};
+				for (j = 0; j < 3; j = j + 1) {
+					while (1 == 1) { break; };
+				};
+				write(j);
+			}
+			`,
+			output: "0\n2\n3\n",
+		},
+		{
+			name: "else-less if and else if chains",
+			src: `
+			func sign(x: integer) -> integer {
+				if (x < 0) then return (-1);
+				else if (x == 0) then return (0);
+				else return (1);;
+			}
+			func main() -> void {
+				if (1 < 2) then write(1);;
+				if (2 < 1) then write(2);;
+				if (1 > 2) then { write(3); } else if (1 < 2) then { write(4); };
+				if (1 > 1) then if (1 > 0) then write(5); else write(6);;;
+				write(sign(-7)); write(sign(0)); write(sign(7));
+			}
+			`,
+			output: "1\n4\n-1\n0\n1\n",
+		},
+		{
+			name: "bools",
+			src: `
+			func flip(b: bool) -> bool { return (!b); }
+			func main() -> void {
+				let b: bool; let bs: bool[2];
+				b = (1 < 2) & !(3 == 4);
+				bs[1] = flip(b);
+				write(b); write(bs[1]); write(bs[0] == false);
+				while (b) { b = false; };
+				if (b | (2 >= 2)) then write(true);;
+			}
+			`,
+			output: "true\nfalse\ntrue\ntrue\n",
+		},
+		{
+			name: "multi-dimensional arrays",
+			src: `
+			func main() -> void {
+				let m: integer[2][3]; let i: integer; let j: integer;
+				i = 0;
+				while (i < 2) {
+					j = 0;
+					while (j < 3) { m[i][j] = i * 10 + j; j = j + 1; };
+					i = i + 1;
+				};
+				write(m[1][2]); write(m[0][1]);
+			}
+			`,
+			output: "12\n1\n",
+		},
+		{
+			name: "structs, methods, and inheritance",
+			src: `
+			struct A { public let a: integer; public func geta() -> integer; public func seta(v: integer) -> void; };
+			struct B { public let b: integer[2]; public func sum() -> integer; };
+			struct C inherits A, B { public let c: integer; public func all() -> integer; };
+			impl A { func geta() -> integer { return (a); } func seta(v: integer) -> void { a = v; } }
+			impl B { func sum() -> integer { return (b[0] + b[1]); } }
+			impl C { func all() -> integer { return (geta() + sum() + c); } }
+			func mk(x: integer) -> C {
+				let r: C;
+				r.seta(x); r.b[0] = x * 10; r.b[1] = x * 100; r.c = 1;
+				return (r);
+			}
+			func byval(c: C) -> integer { c.c = 99; return (c.c); }
+			func main() -> void {
+				let c: C; let d: C;
+				c = mk(2);
+				write(c.all());
+				write(mk(3).sum());
+				d = c; d.c = 5;
+				write(c.c); write(d.c); write(d.geta());
+				write(byval(c)); write(c.c);
+			}
+			`,
+			output: "223\n330\n1\n5\n2\n99\n1\n",
+		},
+		{
+			name: "floats",
+			src: `
+			func half(x: float) -> float { return (x / 2.0); }
+			func main() -> void {
+				let f: float;
+				f = half(5.0) + 0.25;
+				write(f);
+				write(-f);
+				write(f > 2.5);
+			}
+			`,
+			output: "2.75\n-2.75\ntrue\n",
+		},
+		{
+			name: "read",
+			src: `
+			func main() -> void {
+				let x: integer; let y: integer;
+				read(x); read(y);
+				write(x * y);
+				write(!(x == 0) | (y == 0));
+				if (x < y) then write((x > 0) & (y > 0)); else ;
+			}
+			`,
+			input:  "6\n7\n",
+			output: "42\ntrue\ntrue\n",
+		},
+		{
+			name: "strings",
+			src: `
+			func pick(words: string[], i: integer) -> string { return (words[i]); }
+			func main() -> void {
+				let words: string[3];
+				words[0] = "a";
+				words[1] = "say \"hi\"";
+				read(words[2]);
+				write(pick(words, 1)); write(words[2]); write("");
+			}
+			`,
+			input:  "word",
+			output: "say \"hi\"\nword\n\n",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			prog := mustLower(t, tc.src)
+			out := new(bytes.Buffer)
+			if err := Interpret(prog, strings.NewReader(tc.input), out); err != nil {
+				t.Fatalf("Interpret failed: %v\n%v", err, prog)
+			}
+			if actual := out.String(); actual != tc.output {
+				t.Errorf("Expected output %q but got %q", tc.output, actual)
+			}
+		})
+	}
+}
+
+func TestInterpret_RuntimeErrors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{
+			name: "division by zero",
+			src:  `func main() -> void { let x: integer; x = 0; write(1 / x); }`,
+			msg:  "division by zero",
+		},
+		{
+			name: "infinite loop",
+			src:  `func main() -> void { while (1 == 1) { }; }`,
+			msg:  "exceeded the maximum of 1000 steps",
+		},
+		{
+			name: "stack overflow",
+			src: `
+			func f(n: integer) -> integer { return (f(n + 1)); }
+			func main() -> void { write(f(0)); }
+			`,
+			msg: "stack overflow",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			it := NewInterpreter(mustLower(t, tc.src), strings.NewReader(""), new(bytes.Buffer))
+			it.MaxSteps = 1000
+			if tc.name == "stack overflow" {
+				it.MaxSteps = 0
+			}
+
+			err := it.Run()
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) {
+				t.Fatalf("Expected a RuntimeError but got %v", err)
+			}
+			if runtimeErr.Msg != tc.msg {
+				t.Errorf("Expected error %q but got %q", tc.msg, runtimeErr.Msg)
+			}
+		})
+	}
+}
+
+func TestLower_NoMain(t *testing.T) {
+	t.Parallel()
+	_, err := Lower(testutils.MustAnalyze(t, `func f() -> void { }`))
+	if err != ErrNoMain {
+		t.Errorf("Expected %v but got %v", ErrNoMain, err)
+	}
+}
+
+func mustLower(t *testing.T, src string) *Program {
+	t.Helper()
+	prog, err := Lower(testutils.MustAnalyze(t, src))
+	if err != nil {
+		t.Fatalf("Lower failed: %v", err)
+	}
+	return prog
+}
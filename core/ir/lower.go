@@ -0,0 +1,792 @@
+package ir
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/core/token/visitors"
+	"github.com/obonobo/esac/util"
+)
+
+// Lowers an AST to the IR. The AST must have been decorated by the
+// SymTabVisitor, SemCheckVisitor, and MemoryLayoutVisitor without errors. The
+// first problem encountered is returned as an error.
+func Lower(ast token.AST) (*Program, error) {
+	if ast.Root == nil || ast.Root.Type != token.FINAL_PROG {
+		return nil, &LoweringError{Msg: "expected a program"}
+	}
+	l := &lowerer{
+		global:  ast.Root.Meta.SymbolTable,
+		funcs:   make(map[token.SymbolTable]*funcDef, 64),
+		structs: visitors.NewStructLayouts(ast.Root.Meta.SymbolTable),
+		names:   make(map[string]int, 64),
+	}
+	if l.global == nil {
+		return nil, ErrNoSymbolTable
+	}
+	return l.lowerProgram(ast.Root)
+}
+
+type lowerer struct {
+	global  token.SymbolTable
+	funcs   map[token.SymbolTable]*funcDef
+	structs *visitors.StructLayouts
+	names   map[string]int
+	labels  int
+	err     error
+}
+
+// A function or method being lowered
+type funcDef struct {
+	fn     *Function
+	node   *token.ASTNode
+	table  token.SymbolTable
+	owner  token.SymbolTable // The struct table for methods, nil otherwise
+	ret    token.Type
+	self   *Var // The self pointer of methods
+	slots  map[string]slot
+	params []slot
+}
+
+// A parameter or local variable in a stack frame
+type slot struct {
+	v   *Var
+	typ token.Type
+	ref bool // The variable holds the address of the value (array params)
+}
+
+// The result of evaluating an expression. Aggregates (structs and arrays) are
+// represented by their address
+type value struct {
+	op   Operand
+	typ  token.Type
+	addr bool
+}
+
+// Where a variable lives, either directly in the frame or at a computed address
+type location struct {
+	v    *Var
+	addr Operand
+	typ  token.Type
+}
+
+func (l *lowerer) lowerProgram(node *token.ASTNode) (*Program, error) {
+	var defs []*funcDef
+	for _, child := range node.Children[0].Children {
+		switch child.Type {
+		case token.FINAL_FUNC_DEF:
+			defs = append(defs, l.newFuncDef(child, nil))
+		case token.FINAL_IMPL_DEF:
+			for _, method := range child.Children[1].Children {
+				defs = append(defs, l.newFuncDef(method, child.Meta.SymbolTable))
+			}
+		}
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	prog := &Program{Functions: make([]*Function, 0, len(defs))}
+	for _, def := range defs {
+		l.lowerFunction(def)
+		if l.err != nil {
+			return nil, l.err
+		}
+		prog.Functions = append(prog.Functions, def.fn)
+		if def.owner == nil && def.fn.Name == "main" {
+			prog.Main = def.fn
+		}
+	}
+	if prog.Main == nil {
+		return nil, ErrNoMain
+	}
+	return prog, nil
+}
+
+func (l *lowerer) newFuncDef(node *token.ASTNode, owner token.SymbolTable) *funcDef {
+	table := node.Meta.SymbolTable
+	if table == nil {
+		l.fail(node.Token, "function has no symbol table")
+		return nil
+	}
+
+	name := string(node.Children[0].Token.Lexeme)
+	if owner != nil {
+		name = owner.Id() + "::" + name
+	}
+	l.names[name]++
+	if n := l.names[name]; n > 1 {
+		name += "#" + strconv.Itoa(n)
+	}
+
+	def := &funcDef{
+		fn:    &Function{Name: name, Labels: make(map[string]int, 16)},
+		node:  node,
+		table: table,
+		owner: owner,
+		ret:   returnType(table),
+		slots: make(map[string]slot, 32),
+	}
+	def.fn.Void = def.ret.Type == "" || def.ret.Type == token.FINAL_VOID
+	if !def.fn.Void && !isAggregate(def.ret) {
+		def.fn.Returns = irType(def.ret)
+	}
+
+	def.fn.FrameSize = visitors.FRAME_RETURN_VALUE_OFFSET
+	for _, entry := range table.Entries() {
+		def.fn.FrameSize = util.Max(def.fn.FrameSize, entry.Offset+entry.Size)
+		v := &Var{Name: entry.Name, Offset: entry.Offset, Size: entry.Size}
+		switch entry.Kind {
+		case token.RECORD_SELF:
+			def.self = v
+		case token.FINAL_FUNC_DEF_PARAM:
+			s := slot{v: v, typ: entry.Type, ref: len(entry.Type.Dimlist) > 0}
+			def.params = append(def.params, s)
+			def.slots[entry.Name] = s
+		case token.FINAL_VAR_DECL:
+			def.slots[entry.Name] = slot{v: v, typ: entry.Type}
+		}
+	}
+
+	l.funcs[table] = def
+	return def
+}
+
+func (l *lowerer) layoutStruct(table token.SymbolTable, at token.Token) *visitors.StructLayout {
+	layout, err := l.structs.Struct(table)
+	if err != nil {
+		l.fail(at, "%v", err)
+	}
+	return layout
+}
+
+func (l *lowerer) layoutOf(typ token.Type, at token.Token) *visitors.StructLayout {
+	layout, err := l.structs.Of(typ)
+	if err != nil {
+		l.fail(at, "%v", err)
+	}
+	return layout
+}
+
+// Returns the size of a value of the given type in bytes
+func (l *lowerer) sizeOf(typ token.Type, at token.Token) int {
+	size, err := l.structs.SizeOf(typ)
+	if err != nil {
+		l.fail(at, "%v", err)
+	}
+	return size
+}
+
+// Records the first error, the remaining ones are usually a consequence of it
+func (l *lowerer) fail(at token.Token, msg string, args ...any) {
+	if l.err == nil {
+		l.err = &LoweringError{Msg: fmt.Sprintf(msg, args...), Token: at}
+	}
+}
+
+func (l *lowerer) label() string {
+	l.labels++
+	return "L" + strconv.Itoa(l.labels)
+}
+
+// Builds the code of a single function
+type builder struct {
+	l     *lowerer
+	def   *funcDef
+	loops []loop // The enclosing loops, innermost last
+}
+
+// The jump targets of break and continue statements
+type loop struct {
+	breakLabel    string
+	continueLabel string
+}
+
+func (l *lowerer) lowerFunction(def *funcDef) {
+	b := &builder{l: l, def: def}
+	b.block(def.node.Children[3].Children)
+	if code := def.fn.Code; len(code) == 0 || code[len(code)-1].Op != OP_RETURN {
+		b.emit(Instruction{Op: OP_RETURN})
+	}
+}
+
+func (b *builder) emit(i Instruction) {
+	if i.Op == OP_LABEL {
+		b.def.fn.Labels[i.Label] = len(b.def.fn.Code)
+	}
+	b.def.fn.Code = append(b.def.fn.Code, i)
+}
+
+func (b *builder) temp() *Temp {
+	b.def.fn.Temps++
+	return &Temp{Id: b.def.fn.Temps}
+}
+
+func (b *builder) block(statements []*token.ASTNode) {
+	for _, statement := range statements {
+		b.statement(statement)
+	}
+}
+
+func (b *builder) statement(node *token.ASTNode) {
+	switch node.Type {
+	case token.FINAL_VAR_DECL:
+		// Variables are allocated in the frame by the MemoryLayoutVisitor
+	case token.FINAL_ASSIGN:
+		b.assign(node)
+	case token.FINAL_IF:
+		b.ifStatement(node)
+	case token.FINAL_WHILE:
+		b.whileStatement(node)
+	case token.FINAL_FOR:
+		b.forStatement(node)
+	case token.FINAL_BREAK, token.FINAL_CONTINUE:
+		b.jump(node)
+	case token.FINAL_READ:
+		b.read(node)
+	case token.FINAL_WRITE:
+		b.write(node)
+	case token.FINAL_RETURN:
+		b.returnStatement(node)
+	case token.FINAL_FUNC_CALL:
+		b.call(node)
+	default:
+		b.l.fail(node.Token, "unsupported statement %v", node.Type)
+	}
+}
+
+func (b *builder) assign(node *token.ASTNode) {
+	dst := b.locate(node.Children[0])
+	src := b.expr(node.Children[1])
+	b.store(dst, src, node.Children[0].Children[1].Token)
+}
+
+func (b *builder) ifStatement(node *token.ASTNode) {
+	cond := b.scalar(b.expr(node.Children[0]), node.Token)
+	if len(node.Children) < 3 {
+		endLabel := b.l.label()
+		b.emit(Instruction{Op: OP_IFZ, Type: irType(cond.typ), A: cond.op, Label: endLabel})
+		b.block(node.Children[1].Children)
+		b.emit(Instruction{Op: OP_LABEL, Label: endLabel})
+		return
+	}
+
+	elseLabel, endLabel := b.l.label(), b.l.label()
+	b.emit(Instruction{Op: OP_IFZ, Type: irType(cond.typ), A: cond.op, Label: elseLabel})
+	b.block(node.Children[1].Children)
+	b.emit(Instruction{Op: OP_GOTO, Label: endLabel})
+	b.emit(Instruction{Op: OP_LABEL, Label: elseLabel})
+	b.block(node.Children[2].Children)
+	b.emit(Instruction{Op: OP_LABEL, Label: endLabel})
+}
+
+func (b *builder) whileStatement(node *token.ASTNode) {
+	topLabel, endLabel := b.l.label(), b.l.label()
+	b.emit(Instruction{Op: OP_LABEL, Label: topLabel})
+	cond := b.scalar(b.expr(node.Children[0]), node.Token)
+	b.emit(Instruction{Op: OP_IFZ, Type: irType(cond.typ), A: cond.op, Label: endLabel})
+	b.loopBody(node.Children[1], loop{breakLabel: endLabel, continueLabel: topLabel})
+	b.emit(Instruction{Op: OP_GOTO, Label: topLabel})
+	b.emit(Instruction{Op: OP_LABEL, Label: endLabel})
+}
+
+func (b *builder) forStatement(node *token.ASTNode) {
+	defer token.EnterScope(b.def.slots, node.Meta.SymbolTable, func(entry token.SymbolTableRecord) slot {
+		return slot{v: &Var{Name: entry.Name, Offset: entry.Offset, Size: entry.Size}, typ: entry.Type}
+	})()
+	topLabel, stepLabel, endLabel := b.l.label(), b.l.label(), b.l.label()
+	b.block(node.Children[0].Children)
+	b.emit(Instruction{Op: OP_LABEL, Label: topLabel})
+	cond := b.scalar(b.expr(node.Children[1]), node.Token)
+	b.emit(Instruction{Op: OP_IFZ, Type: irType(cond.typ), A: cond.op, Label: endLabel})
+	b.loopBody(node.Children[3], loop{breakLabel: endLabel, continueLabel: stepLabel})
+	b.emit(Instruction{Op: OP_LABEL, Label: stepLabel})
+	b.assign(node.Children[2])
+	b.emit(Instruction{Op: OP_GOTO, Label: topLabel})
+	b.emit(Instruction{Op: OP_LABEL, Label: endLabel})
+}
+
+func (b *builder) loopBody(body *token.ASTNode, lp loop) {
+	b.loops = append(b.loops, lp)
+	b.block(body.Children)
+	b.loops = b.loops[:len(b.loops)-1]
+}
+
+// Lowers a break or continue statement to a jump out of the innermost loop
+func (b *builder) jump(node *token.ASTNode) {
+	if len(b.loops) == 0 {
+		b.l.fail(node.Token, "'%v' is not inside a loop", node.Token.Lexeme)
+		return
+	}
+	lp := b.loops[len(b.loops)-1]
+	label := lp.continueLabel
+	if node.Type == token.FINAL_BREAK {
+		label = lp.breakLabel
+	}
+	b.emit(Instruction{Op: OP_GOTO, Label: label})
+}
+
+func (b *builder) read(node *token.ASTNode) {
+	variable := node.Children[0]
+	if variable.Type != token.FINAL_VARIABLE {
+		b.l.fail(node.Token, "read expects a variable")
+		return
+	}
+	dst := b.locate(variable)
+	if isAggregate(dst.typ) {
+		b.l.fail(variable.Children[1].Token, "cannot read a value of type '%v'", dst.typ)
+		return
+	}
+	t := b.temp()
+	b.emit(Instruction{Op: OP_READ, Type: irType(dst.typ), Dst: t})
+	b.store(dst, value{op: t, typ: dst.typ}, variable.Children[1].Token)
+}
+
+func (b *builder) write(node *token.ASTNode) {
+	v := b.scalar(b.expr(node.Children[0]), node.Token)
+	b.emit(Instruction{Op: OP_WRITE, Type: irType(v.typ), A: v.op})
+}
+
+func (b *builder) returnStatement(node *token.ASTNode) {
+	v := b.expr(node.Children[0])
+	if isAggregate(b.def.ret) {
+		if !v.addr {
+			b.l.fail(node.Token, "cannot return '%v' from function returning '%v'", v.typ, b.def.ret)
+			return
+		}
+		b.emit(Instruction{Op: OP_RETURN, A: v.op, Size: b.l.sizeOf(b.def.ret, node.Token)})
+		return
+	}
+	v = b.scalar(v, node.Token)
+	b.emit(Instruction{Op: OP_RETURN, Type: irType(v.typ), A: v.op})
+}
+
+// Evaluates an expression into an operand
+func (b *builder) expr(node *token.ASTNode) value {
+	switch node.Type {
+	case token.FINAL_ARITH_EXPR,
+		token.FINAL_REL_EXPR,
+		token.FINAL_EXPR,
+		token.FINAL_INDEX,
+		token.FINAL_FUNC_CALL_PARAM:
+		return b.expr(node.Children[0])
+	case token.FINAL_FACTOR:
+		if len(node.Children) == 2 {
+			return b.unary(node)
+		}
+		return b.expr(node.Children[0])
+	case token.FINAL_PLUS,
+		token.FINAL_MINUS,
+		token.FINAL_MULT,
+		token.FINAL_DIV,
+		token.FINAL_AND,
+		token.FINAL_OR,
+		token.FINAL_EQ,
+		token.FINAL_NEQ,
+		token.FINAL_LT,
+		token.FINAL_GT,
+		token.FINAL_LEQ,
+		token.FINAL_GEQ:
+		return b.binary(node)
+	case token.FINAL_INTNUM:
+		val, err := strconv.ParseInt(string(node.Token.Lexeme), 10, 32)
+		if err != nil {
+			b.l.fail(node.Token, "integer literal %v is out of range", node.Token.Lexeme)
+		}
+		return value{op: IntConst(val), typ: basicType(token.FINAL_INTEGER, node.Token)}
+	case token.FINAL_FLOATNUM:
+		val, err := strconv.ParseFloat(string(node.Token.Lexeme), 64)
+		if err != nil {
+			b.l.fail(node.Token, "malformed float literal %v", node.Token.Lexeme)
+		}
+		return value{op: FloatConst(val), typ: basicType(token.FINAL_FLOAT, node.Token)}
+	case token.FINAL_STRINGLIT:
+		text := token.StringLitText(node.Token.Lexeme)
+		return value{op: StringConst(text), typ: basicType(token.FINAL_STRING, node.Token)}
+	case token.FINAL_BOOLLIT:
+		var bit IntConst
+		if node.Token.Id == token.TRUE {
+			bit = 1
+		}
+		return value{op: bit, typ: basicType(token.FINAL_BOOL, node.Token)}
+	case token.FINAL_VARIABLE:
+		return b.load(b.locate(node))
+	case token.FINAL_FUNC_CALL:
+		v := b.call(node)
+		if v.op == nil && b.l.err == nil {
+			b.l.fail(node.Children[1].Token,
+				"function '%v' does not return a value", node.Children[1].Token.Lexeme)
+		}
+		return v
+	}
+	b.l.fail(node.Token, "unsupported expression %v", node.Type)
+	return value{}
+}
+
+func (b *builder) unary(node *token.ASTNode) value {
+	op, operand := node.Children[0], b.scalar(b.expr(node.Children[1]), node.Token)
+	var code Op
+	switch op.Type {
+	case token.FINAL_NEGATIVE:
+		code = OP_NEG
+	case token.FINAL_NOT:
+		code = OP_NOT
+	default:
+		return operand
+	}
+	t := b.temp()
+	b.emit(Instruction{Op: code, Type: irType(operand.typ), Dst: t, A: operand.op})
+	if code == OP_NOT {
+		return value{op: t, typ: basicType(token.FINAL_BOOL, op.Token)}
+	}
+	return value{op: t, typ: operand.typ}
+}
+
+var binaryOps = map[token.Kind]Op{
+	token.FINAL_PLUS:  OP_ADD,
+	token.FINAL_MINUS: OP_SUB,
+	token.FINAL_MULT:  OP_MUL,
+	token.FINAL_DIV:   OP_DIV,
+	token.FINAL_AND:   OP_AND,
+	token.FINAL_OR:    OP_OR,
+	token.FINAL_EQ:    OP_EQ,
+	token.FINAL_NEQ:   OP_NEQ,
+	token.FINAL_LT:    OP_LT,
+	token.FINAL_GT:    OP_GT,
+	token.FINAL_LEQ:   OP_LEQ,
+	token.FINAL_GEQ:   OP_GEQ,
+}
+
+func (b *builder) binary(node *token.ASTNode) value {
+	left := b.scalar(b.expr(node.Children[0]), node.Token)
+	right := b.scalar(b.expr(node.Children[1]), node.Token)
+	if left.typ.Type != right.typ.Type {
+		b.l.fail(node.Token, "mismatched operand types '%v' and '%v'", left.typ, right.typ)
+	}
+
+	op := binaryOps[node.Type]
+	t := b.temp()
+	b.emit(Instruction{Op: op, Type: irType(left.typ), Dst: t, A: left.op, B: right.op})
+	if op.IsComparison() || op == OP_AND || op == OP_OR {
+		return value{op: t, typ: basicType(token.FINAL_BOOL, node.Token)}
+	}
+	return value{op: t, typ: basicType(left.typ.Type, node.Token)}
+}
+
+// Checks that a value is an integer or a float
+func (b *builder) scalar(v value, at token.Token) value {
+	if v.addr || isAggregate(v.typ) {
+		b.l.fail(at, "expected an integer, a float, or a string but found '%v'", v.typ)
+	}
+	return v
+}
+
+// Produces the value stored at a location
+func (b *builder) load(loc location) value {
+	if isAggregate(loc.typ) {
+		return value{op: b.addressOf(loc), typ: loc.typ, addr: true}
+	}
+	t := b.temp()
+	if loc.v != nil {
+		b.emit(Instruction{Op: OP_MOVE, Type: irType(loc.typ), Dst: t, A: loc.v})
+	} else {
+		b.emit(Instruction{Op: OP_LOAD, Type: irType(loc.typ), Dst: t, A: loc.addr})
+	}
+	return value{op: t, typ: loc.typ}
+}
+
+// Stores a value at a location
+func (b *builder) store(dst location, src value, at token.Token) {
+	if !isAggregate(dst.typ) {
+		src = b.scalar(src, at)
+		switch {
+		case dst.typ.Type != src.typ.Type:
+			b.l.fail(at, "cannot assign a value of type '%v' to '%v'", src.typ, dst.typ)
+		case dst.v != nil:
+			b.emit(Instruction{Op: OP_MOVE, Type: irType(dst.typ), Dst: dst.v, A: src.op})
+		default:
+			b.emit(Instruction{Op: OP_STORE, Type: irType(dst.typ), Dst: dst.addr, A: src.op})
+		}
+		return
+	}
+
+	if !src.addr {
+		b.l.fail(at, "cannot assign a value of type '%v' to '%v'", src.typ, dst.typ)
+		return
+	}
+	typ := dst.typ
+	if hasUnknownDimension(typ) {
+		typ = src.typ
+	}
+	b.emit(Instruction{Op: OP_COPY, Dst: b.addressOf(dst), A: src.op, Size: b.l.sizeOf(typ, at)})
+}
+
+// Produces the address of a location
+func (b *builder) addressOf(loc location) Operand {
+	if loc.v == nil {
+		return loc.addr
+	}
+	t := b.temp()
+	b.emit(Instruction{Op: OP_ADDR, Type: ADDR, Dst: t, A: loc.v})
+	return t
+}
+
+// Adds a constant offset to an address
+func (b *builder) offsetBy(addr Operand, offset int) Operand {
+	if offset == 0 {
+		return addr
+	}
+	t := b.temp()
+	b.emit(Instruction{Op: OP_ADD, Type: ADDR, Dst: t, A: addr, B: IntConst(offset)})
+	return t
+}
+
+// Reads the self pointer of the current method
+func (b *builder) self(at token.Token) Operand {
+	if b.def.self == nil {
+		b.l.fail(at, "function '%v' has no self pointer", b.def.fn.Name)
+		return nil
+	}
+	t := b.temp()
+	b.emit(Instruction{Op: OP_MOVE, Type: ADDR, Dst: t, A: b.def.self})
+	return t
+}
+
+// Finds the location of a variable
+func (b *builder) locate(node *token.ASTNode) location {
+	subject, id, indices := node.Children[0], node.Children[1], node.Children[2].Children
+	name := string(id.Token.Lexeme)
+
+	var loc location
+	if len(subject.Children) == 0 {
+		if s, ok := b.def.slots[name]; ok {
+			loc = location{v: s.v, typ: s.typ}
+			if s.ref {
+				t := b.temp()
+				b.emit(Instruction{Op: OP_MOVE, Type: ADDR, Dst: t, A: s.v})
+				loc = location{addr: t, typ: s.typ}
+			}
+		} else if b.def.owner != nil {
+			layout := b.l.layoutStruct(b.def.owner, id.Token)
+			if layout == nil {
+				return location{}
+			}
+			m, ok := layout.Members[name]
+			if !ok {
+				b.l.fail(id.Token, "cannot resolve variable '%v'", name)
+				return location{}
+			}
+			loc = location{addr: b.offsetBy(b.self(id.Token), m.Offset), typ: m.Type}
+		} else {
+			b.l.fail(id.Token, "cannot resolve variable '%v'", name)
+			return location{}
+		}
+	} else {
+		object, layout := b.object(subject.Children[0], id.Token)
+		if layout == nil {
+			return location{}
+		}
+		m, ok := layout.Members[name]
+		if !ok {
+			b.l.fail(id.Token, "'%v' has no data member '%v'", object.typ, name)
+			return location{}
+		}
+		loc = location{addr: b.offsetBy(object.op, m.Offset), typ: m.Type}
+	}
+
+	return b.index(loc, indices, id.Token)
+}
+
+// Evaluates the subject of a member access, producing the address of a struct
+func (b *builder) object(node *token.ASTNode, at token.Token) (value, *visitors.StructLayout) {
+	var object value
+	switch node.Type {
+	case token.FINAL_VARIABLE:
+		object = b.load(b.locate(node))
+	case token.FINAL_FUNC_CALL:
+		object = b.call(node)
+	default:
+		b.l.fail(at, "unsupported subject %v", node.Type)
+		return value{}, nil
+	}
+	if object.typ.Type == "" {
+		return value{}, nil
+	}
+	if object.typ.Type != token.FINAL_ID || len(object.typ.Dimlist) > 0 || !object.addr {
+		b.l.fail(at, "'%v' is not a struct", object.typ)
+		return value{}, nil
+	}
+	return object, b.l.layoutOf(object.typ, at)
+}
+
+// Applies an index list to an array
+func (b *builder) index(loc location, indices []*token.ASTNode, at token.Token) location {
+	if len(indices) == 0 {
+		return loc
+	}
+
+	dims := loc.typ.Dimlist
+	if len(indices) > len(dims) {
+		b.l.fail(at, "too many subscripts for '%v'", loc.typ)
+		return location{}
+	}
+
+	element := token.Type{Type: loc.typ.Type, Token: loc.typ.Token}
+	addr := b.addressOf(loc)
+	for i, index := range indices {
+		subscript := b.scalar(b.expr(index), at)
+		stride := b.l.sizeOf(token.Type{
+			Type:    element.Type,
+			Token:   element.Token,
+			Dimlist: dims[i+1:],
+		}, at)
+		scaled := b.temp()
+		b.emit(Instruction{Op: OP_MUL, Type: INT, Dst: scaled, A: subscript.op, B: IntConst(stride)})
+		t := b.temp()
+		b.emit(Instruction{Op: OP_ADD, Type: ADDR, Dst: t, A: addr, B: scaled})
+		addr = t
+	}
+
+	element.Dimlist = dims[len(indices):]
+	return location{addr: addr, typ: element}
+}
+
+// Lowers a function call. Void calls produce a value with a nil operand
+func (b *builder) call(node *token.ASTNode) value {
+	subject, id := node.Children[0], node.Children[1]
+	var callee *funcDef
+	if record := node.Meta.Record; record != nil {
+		callee = b.l.funcs[record.Link]
+	}
+	if callee == nil {
+		b.l.fail(id.Token, "cannot resolve function '%v'", id.Token.Lexeme)
+		return value{}
+	}
+
+	// Methods receive a pointer to the struct they have been called on. When
+	// the method has been inherited, the pointer needs to be adjusted so that
+	// it points at the part of the struct that the method knows about
+	var self Operand
+	if callee.owner != nil {
+		var layout *visitors.StructLayout
+		if len(subject.Children) > 0 {
+			var object value
+			object, layout = b.object(subject.Children[0], id.Token)
+			self = object.op
+		} else if b.def.owner != nil {
+			layout = b.l.layoutStruct(b.def.owner, id.Token)
+			self = b.self(id.Token)
+		}
+		offset, ok := 0, false
+		if layout != nil {
+			offset, ok = layout.Bases[callee.owner]
+		}
+		if !ok {
+			b.l.fail(id.Token, "cannot call method '%v' here", id.Token.Lexeme)
+			return value{}
+		}
+		self = b.offsetBy(self, offset)
+	}
+
+	args := node.Children[2].Children
+	if len(args) != len(callee.params) {
+		b.l.fail(id.Token, "wrong number of arguments for '%v'", id.Token.Lexeme)
+		return value{}
+	}
+	values := make([]value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, b.expr(arg))
+	}
+
+	if self != nil {
+		b.emit(Instruction{Op: OP_ARG, Type: ADDR, Func: callee.fn, Dst: callee.self, A: self})
+	}
+	for i, param := range callee.params {
+		arg := values[i]
+		switch {
+		case param.ref:
+			if !arg.addr {
+				b.l.fail(id.Token, "cannot pass '%v' as '%v'", arg.typ, param.typ)
+			}
+			b.emit(Instruction{Op: OP_ARG, Type: ADDR, Func: callee.fn, Dst: param.v, A: arg.op})
+		case isAggregate(param.typ):
+			if !arg.addr {
+				b.l.fail(id.Token, "cannot pass '%v' as '%v'", arg.typ, param.typ)
+			}
+			b.emit(Instruction{Op: OP_ARG, Func: callee.fn, Dst: param.v, A: arg.op,
+				Size: b.l.sizeOf(param.typ, id.Token)})
+		default:
+			arg = b.scalar(arg, id.Token)
+			b.emit(Instruction{Op: OP_ARG, Type: irType(param.typ), Func: callee.fn,
+				Dst: param.v, A: arg.op})
+		}
+	}
+
+	ret := callee.ret
+	switch {
+	case callee.fn.Void:
+		b.emit(Instruction{Op: OP_CALL, Func: callee.fn})
+		return value{typ: ret}
+	case isAggregate(ret):
+		// The frame of the callee will be clobbered by the next call, so the
+		// value is copied to the temporary allocated for it by the layout
+		temp := node.Meta.Temp
+		if temp == nil {
+			b.l.fail(id.Token, "no temporary allocated for the result of '%v'", id.Token.Lexeme)
+			return value{}
+		}
+		dst := &Var{Name: temp.Name, Offset: temp.Offset, Size: temp.Size}
+		b.emit(Instruction{Op: OP_CALL, Func: callee.fn, Dst: dst, Size: temp.Size})
+		return value{op: b.addressOf(location{v: dst}), typ: ret, addr: true}
+	default:
+		t := b.temp()
+		b.emit(Instruction{Op: OP_CALL, Type: irType(ret), Func: callee.fn, Dst: t})
+		return value{op: t, typ: ret}
+	}
+}
+
+// Returns the return type of a function given its table
+func returnType(table token.SymbolTable) token.Type {
+	if table.Parent() == nil {
+		return token.Type{}
+	}
+	for _, entry := range table.Parent().Entries() {
+		if entry.Link == table {
+			typ := entry.Type
+			typ.Privacy = ""
+			return typ
+		}
+	}
+	return token.Type{}
+}
+
+func irType(typ token.Type) Type {
+	switch typ.Type {
+	case token.FINAL_FLOAT:
+		return FLOAT
+	case token.FINAL_STRING:
+		return STRING
+	case token.FINAL_BOOL:
+		return BOOL
+	}
+	return INT
+}
+
+func basicType(kind token.Kind, at token.Token) token.Type {
+	return token.Type{Type: kind, Token: at}
+}
+
+// Structs and arrays can't be held in a temporary
+func isAggregate(typ token.Type) bool {
+	return len(typ.Dimlist) > 0 || typ.Type == token.FINAL_ID
+}
+
+func hasUnknownDimension(typ token.Type) bool {
+	for _, dim := range typ.Dimlist {
+		if dim == token.DIMENSION_ANY {
+			return true
+		}
+	}
+	return false
+}
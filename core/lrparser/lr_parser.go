@@ -0,0 +1,188 @@
+// Package lrparser is a shift-reduce parser driven by LR ACTION and GOTO
+// tables, such as the LALR(1) tables built by the grammar package. It is an
+// alternative backend to the TableDrivenParser: it executes the same semantic
+// actions in the same order, so it produces the same token.AST.
+package lrparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	"github.com/obonobo/esac/core/token"
+)
+
+// A parser that shifts tokens and reduces productions as directed by an LR
+// Table. Syntax errors are reported with the same error types as the
+// TableDrivenParser, but there is no error recovery: the parse stops at the
+// first syntax error
+type LRParser struct {
+	scnr  scanner.Scanner
+	table Table
+
+	errc  func(e *tabledrivenparser.ParserError) // Called on syntax errors
+	rulec func(r token.Rule)                     // Called with the rules that are reduced
+
+	states   []int            // Parse stack
+	semStack []*token.ASTNode // Semantic stack
+	ast      token.AST
+	err      error
+}
+
+// Creates a new LRParser loaded with the scnr and table. If the scnr or table
+// is nil, then this function returns nil.
+//
+// The rulec callback receives the rules of the grammar in the order that they
+// are reduced, i.e. the rightmost derivation in reverse
+func NewParser(
+	scnr scanner.Scanner,
+	table Table,
+	errc func(e *tabledrivenparser.ParserError),
+	rulec func(r token.Rule),
+) *LRParser {
+	if scnr == nil || table == nil {
+		return nil
+	}
+	return &LRParser{
+		scnr:     scnr,
+		table:    table,
+		errc:     errc,
+		rulec:    rulec,
+		states:   make([]int, 0, 1024),
+		semStack: make([]*token.ASTNode, 0, 1024),
+	}
+}
+
+// Same as NewParser except ignores the comment tokens declared in the token
+// package
+func NewParserNoDefaultComments(
+	scnr scanner.Scanner,
+	table Table,
+	errc func(e *tabledrivenparser.ParserError),
+	rulec func(r token.Rule),
+) *LRParser {
+	return NewParser(
+		scanner.IgnoringComments(scnr, token.Comments()...),
+		table, errc, rulec)
+}
+
+func (p *LRParser) AST() token.AST {
+	if p.err != nil || p.ast.Root != nil {
+		return p.ast
+	}
+	switch l := len(p.semStack); l {
+	case 0:
+		return p.ast // The grammar has no semantic actions, there is no tree
+	case 1:
+		p.ast.Root = p.semStack[0]
+		return p.ast
+	default:
+		panic(fmt.Errorf("stack should be [PROG] but got %v", p.semStack))
+	}
+}
+
+// Parses the token stream that is loaded in the Parser. Returns true if the
+// parse was successful, false otherwise
+func (p *LRParser) Parse() bool {
+	if p.err != nil {
+		return false
+	}
+
+	a, err := p.next()
+	if err != nil {
+		p.emitError(err, token.Token{})
+		return false
+	}
+
+	p.states = append(p.states[:0], 0)
+	for prev := a; ; {
+		state := p.states[len(p.states)-1]
+		action, ok := p.table.Action(state, a.Id)
+		if !ok {
+			at := a
+			if a.Id == END {
+				at = prev // There is no token at the end of the input
+			}
+			p.emitError(p.syntaxError(state, a), at)
+			return false
+		}
+
+		switch action.Type {
+		case SHIFT:
+			p.states = append(p.states, action.Target)
+			prev = a
+			if a, err = p.next(); err != nil {
+				p.emitError(err, prev)
+				return false
+			}
+
+		case REDUCE:
+			prod := p.table.Production(action.Target)
+			p.states = p.states[:len(p.states)-prod.Length]
+			next, ok := p.table.Goto(p.states[len(p.states)-1], prod.LHS)
+			if !ok {
+				panic(fmt.Errorf("no GOTO entry for state %v on %v", p.states[len(p.states)-1], prod.LHS))
+			}
+			p.states = append(p.states, next)
+
+			// At the end of the input, the TableDrivenParser pops the
+			// nonterminals left on its stack without expanding their EPSILON
+			// rules, so the semantic actions of those rules never run. Skip
+			// them here too, so that both parsers build the same AST
+			if a.Id != END || prod.Length > 0 || prod.Rule.LHS == "" {
+				for _, x := range prod.Actions {
+					p.executeSemanticAction(x, prev)
+				}
+			}
+			if prod.Rule.LHS != "" && p.rulec != nil {
+				p.rulec(prod.Rule)
+			}
+
+		case ACCEPT:
+			return true
+		}
+	}
+}
+
+// Reads the next token, the end of the input is returned as a token of kind
+// END
+func (p *LRParser) next() (token.Token, error) {
+	tok, err := p.scnr.NextToken()
+	if errors.Is(err, io.EOF) {
+		return token.Token{Id: END, Line: tok.Line, Column: tok.Column}, nil
+	}
+	return tok, err
+}
+
+func (p *LRParser) syntaxError(state int, a token.Token) error {
+	expected := p.table.Expected(state)
+	instead := make([]token.Kind, 0, len(expected))
+	for _, k := range expected {
+		if k != END {
+			instead = append(instead, k)
+		}
+	}
+	if a.Id == END {
+		return &tabledrivenparser.UnterminatedSentence{ExpectedSymbols: instead}
+	}
+	return &tabledrivenparser.UnexpectedTokenError{Token: a, InsteadSlice: instead}
+}
+
+func (p *LRParser) executeSemanticAction(x token.Kind, previousToken token.Token) {
+	action, ok := token.SEM_DISPATCH[x]
+	if !ok {
+		panic(fmt.Errorf(
+			"no semantic action found, x = %v, previousToken = %v",
+			x, previousToken))
+	}
+	action(&p.semStack, previousToken)
+}
+
+func (p *LRParser) emitError(err error, tok token.Token) {
+	p.err = err
+	if p.errc != nil {
+		p.errc(&tabledrivenparser.ParserError{Err: err, Tok: tok})
+	}
+}
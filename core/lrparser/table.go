@@ -0,0 +1,58 @@
+package lrparser
+
+import "github.com/obonobo/esac/core/token"
+
+// The kind of the lookahead once the scanner has reached the end of the input
+const END token.Kind = "$"
+
+type ActionType int
+
+const (
+	SHIFT ActionType = iota + 1
+	REDUCE
+	ACCEPT
+)
+
+// An entry of the ACTION table
+type Action struct {
+	Type ActionType
+
+	// The state to shift to if this is a SHIFT, or the index of the
+	// Production to reduce by if this is a REDUCE
+	Target int
+}
+
+// A production of the grammar, as seen by the LRParser.
+//
+// Semantic actions in the middle of a rule are moved into productions of their
+// own: 'A ::= x (ACT) y' becomes 'A ::= x M y' and 'M ::= EPSILON' with the
+// Actions [ACT]. Semantic actions at the end of a rule are executed when the
+// rule itself is reduced. Either way, the actions are executed at the same
+// point in the parse that the TableDrivenParser would have executed them
+type Production struct {
+	LHS     token.Kind
+	Length  int          // The number of symbols on the RHS, i.e. the number of states to pop
+	Actions []token.Kind // The semantic actions executed when reducing, in order
+
+	// The rule of the grammar that this production comes from, emitted on the
+	// parser's rule callback when reducing. Empty for the productions of
+	// semantic actions
+	Rule token.Rule
+}
+
+// The ACTION and GOTO tables used by the LRParser
+type Table interface {
+
+	// Look up the action for the state and lookahead terminal, returns false
+	// if the lookahead is a syntax error
+	Action(state int, terminal token.Kind) (Action, bool)
+
+	// Look up the state to go to after reducing to the nonterminal
+	Goto(state int, nonterminal token.Kind) (int, bool)
+
+	// Retrieve a production by index, as referenced by a REDUCE Action
+	Production(i int) Production
+
+	// Returns the terminals that have an action in the state, sorted
+	Expected(state int) []token.Kind
+}
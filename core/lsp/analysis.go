@@ -0,0 +1,238 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/reporting"
+)
+
+const DIAGNOSTIC_SOURCE = "esac"
+
+// An open document along with the results of its analysis
+type document struct {
+	uri string
+
+	// Nil if the document could not be parsed, partial if it has syntax errors
+	ast         *token.ASTNode
+	diagnostics []Diagnostic
+
+	// Declaration nodes, indexed by the records that they declare
+	decls   map[declKey]*token.ASTNode
+	structs map[string]*token.ASTNode
+	impls   map[string]*token.ASTNode
+}
+
+// Records are copied into the symbol tables, so they are identified by their
+// name and the position of their type token, which is unique for each
+// declaration
+type declKey struct {
+	name         string
+	line, column int
+}
+
+func keyOf(record token.SymbolTableRecord) declKey {
+	return declKey{record.Name, record.Type.Token.Line, record.Type.Token.Column}
+}
+
+// Scans, parses, and semantically checks the text of a document
+func analyze(uri, text string) (doc *document) {
+	doc = &document{
+		uri:     uri,
+		decls:   make(map[declKey]*token.ASTNode, 64),
+		structs: make(map[string]*token.ASTNode, 16),
+		impls:   make(map[string]*token.ASTNode, 16),
+	}
+
+	// A broken document must never bring down the server
+	defer func() {
+		if r := recover(); r != nil {
+			doc.ast = nil
+			doc.diagnostics = append(doc.diagnostics, Diagnostic{
+				Severity: SEVERITY_ERROR,
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  fmt.Sprintf("internal error: %v", r),
+			})
+		}
+	}()
+
+	report := func(d reporting.Diagnostic) {
+		doc.diagnostics = append(doc.diagnostics, doc.diagnostic(d))
+	}
+
+	// The AST is partial after syntax errors, it is checked all the same
+	ast, _ := reporting.ParseSource(
+		chuggingcharsource.MustChuggingReader(strings.NewReader(text)), report)
+	reporting.CheckSemantics(ast, report)
+	if ast.Root == nil || ast.Root.Type == token.FINAL_ERROR {
+		return doc
+	}
+
+	doc.ast = ast.Root
+	doc.index(doc.ast)
+	return doc
+}
+
+// Remembers the declaration nodes of the AST
+func (d *document) index(node *token.ASTNode) {
+	if node == nil {
+		return
+	}
+	if record := node.Meta.Record; record != nil && isDecl(node) {
+		d.decls[keyOf(*record)] = node
+		switch node.Type {
+		case token.FINAL_STRUCT_DECL:
+			d.structs[record.Name] = node
+		case token.FINAL_IMPL_DEF:
+			d.impls[record.Name] = node
+		}
+	}
+	for _, child := range node.Children {
+		d.index(child)
+	}
+}
+
+func isDecl(node *token.ASTNode) bool {
+	switch node.Type {
+	case token.FINAL_STRUCT_DECL,
+		token.FINAL_IMPL_DEF,
+		token.FINAL_FUNC_DEF,
+		token.FINAL_FUNC_DECL,
+		token.FINAL_FUNC_DEF_PARAM,
+		token.FINAL_VAR_DECL:
+		return true
+	}
+	return false
+}
+
+// Converts a diagnostic to the protocol's representation, notes that point
+// to a location become related information
+func (d *document) diagnostic(diagnostic reporting.Diagnostic) Diagnostic {
+	out := Diagnostic{
+		Range:    d.spanRange(diagnostic.Span),
+		Severity: SEVERITY_ERROR,
+		Code:     string(diagnostic.Code),
+		Source:   DIAGNOSTIC_SOURCE,
+		Message:  diagnostic.Msg,
+	}
+	if diagnostic.Severity == reporting.SEVERITY_WARNING {
+		out.Severity = SEVERITY_WARNING
+	}
+	for _, note := range diagnostic.Notes {
+		if !note.Span.Valid() {
+			continue
+		}
+		out.RelatedInformation = append(out.RelatedInformation, DiagnosticRelatedInformation{
+			Location: Location{URI: d.uri, Range: d.spanRange(note.Span)},
+			Message:  note.Msg,
+		})
+	}
+	return out
+}
+
+// Converts the one-based span of a diagnostic into a zero-based range, spans
+// that only know their line cover the tokens of the line
+func (d *document) spanRange(span reporting.Span) Range {
+	switch {
+	case !span.Valid():
+		return Range{}
+	case span.Column == 0:
+		return lineRange(d.ast, span.Line)
+	}
+	start := Position{Line: span.Line - 1, Character: span.Column - 1}
+	end := Position{Line: start.Line, Character: start.Character + span.Length}
+	return Range{Start: start, End: end}
+}
+
+// The range of the identifier of a declaration
+func idRange(node *token.ASTNode) Range {
+	if node == nil || len(node.Children) == 0 {
+		return Range{}
+	}
+	return tokenRange(node.Children[0].Token)
+}
+
+// The range covered by the tokens of the AST that lie on the given line
+func lineRange(root *token.ASTNode, line int) Range {
+	if line < 1 {
+		return Range{}
+	}
+	var r *Range
+	walk(root, func(node *token.ASTNode) {
+		if node.Token.Line != line || len(node.Token.Lexeme) == 0 {
+			return
+		}
+		tr := tokenRange(node.Token)
+		if r == nil {
+			r = &tr
+			return
+		}
+		if tr.Start.before(r.Start) {
+			r.Start = tr.Start
+		}
+		if r.End.before(tr.End) {
+			r.End = tr.End
+		}
+	})
+	if r == nil {
+		return Range{Start: Position{Line: line - 1}, End: Position{Line: line - 1}}
+	}
+	return *r
+}
+
+// The range covered by all the tokens of a subtree
+func nodeRange(node *token.ASTNode) Range {
+	var r *Range
+	walk(node, func(n *token.ASTNode) {
+		if n.Token.Line == 0 {
+			return
+		}
+		tr := tokenRange(n.Token)
+		if r == nil {
+			r = &tr
+			return
+		}
+		if tr.Start.before(r.Start) {
+			r.Start = tr.Start
+		}
+		if r.End.before(tr.End) {
+			r.End = tr.End
+		}
+	})
+	if r == nil {
+		return Range{}
+	}
+	return *r
+}
+
+// Converts the one-based line and column of a token into a zero-based range,
+// tokens such as block comments may span several lines
+func tokenRange(tok token.Token) Range {
+	if tok.Line == 0 {
+		return Range{}
+	}
+	start := Position{Line: tok.Line - 1, Character: tok.Column - 1}
+	end := start
+	for _, c := range string(tok.Lexeme) {
+		if c == '\n' {
+			end.Line++
+			end.Character = 0
+		} else {
+			end.Character++
+		}
+	}
+	return Range{Start: start, End: end}
+}
+
+// Calls f on every node of the subtree in pre-order
+func walk(node *token.ASTNode, f func(node *token.ASTNode)) {
+	if node == nil {
+		return
+	}
+	f(node)
+	for _, child := range node.Children {
+		walk(child, f)
+	}
+}
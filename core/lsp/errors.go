@@ -0,0 +1,18 @@
+package lsp
+
+import "fmt"
+
+var (
+	ErrExitWithoutShutdown = fmt.Errorf("lsp: exit notification received before shutdown")
+	ErrMissingContentLen   = fmt.Errorf("lsp: message is missing a Content-Length header")
+)
+
+// The error of a failed request, sent back to the client
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("lsp: %v (code %v)", e.Message, e.Code)
+}
@@ -0,0 +1,480 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const URI = "file:///tmp/test.src"
+
+const SRC = `struct SHAPE {
+	public let sides: integer;
+	public func area() -> float;
+};
+struct SQUARE inherits SHAPE {
+	private let side: float;
+	public func area() -> float;
+};
+impl SHAPE { func area() -> float { return (0.0); } }
+impl SQUARE { func area() -> float { return (side * side); } }
+func main() -> void {
+	let sq: SQUARE;
+	let arr: integer[3];
+	write(sq.area());
+	write(sq.sides);
+}
+`
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		src      string
+		expected []Diagnostic
+	}{
+		{
+			name:     "no errors",
+			src:      "func main() -> void {\n\twrite(1);\n}",
+			expected: []Diagnostic{},
+		},
+		{
+			name: "warning",
+			src:  SRC,
+			expected: []Diagnostic{{
+				Range:    Range{Position{6, 23}, Position{6, 28}},
+				Severity: SEVERITY_WARNING,
+				Code:     "E0211",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "shadowing: parent member(s) 'SHAPE::area()' shadowed by 'SQUARE::area()'",
+				RelatedInformation: []DiagnosticRelatedInformation{{
+					Location: Location{URI, Range{Position{2, 23}, Position{2, 28}}},
+					Message:  "shadows the member of 'SHAPE' defined here",
+				}},
+			}},
+		},
+		{
+			name: "lexical error",
+			src:  "func main() -> void {\n\tlet x: integer;\n\tx = 1 + 01;\n}",
+			expected: []Diagnostic{{
+				Range:    Range{Position{2, 9}, Position{2, 11}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0003",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "invalid number '01'",
+			}, {
+				Range:    Range{Position{2, 9}, Position{2, 11}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0101",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message: "syntax error: unexpected token 'invalidnum', " +
+					"should be 'false', 'floatnum', 'id', 'intnum', 'minus', 'not', 'openpar', 'plus', 'stringlit', or 'true'",
+			}},
+		},
+		{
+			name: "semantic error",
+			src:  "func main() -> void {\n\twrite(y);\n}",
+			expected: []Diagnostic{{
+				Range:    Range{Position{1, 7}, Position{1, 8}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0302",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "typecheck: id y was not found within the current scope (line 2)",
+			}},
+		},
+		{
+			name: "syntax and semantic errors",
+			src:  "func main() -> void {\n\tlet x integer;\n\twrite(y);\n}",
+			expected: []Diagnostic{{
+				Range:    Range{Position{1, 7}, Position{1, 14}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0101",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "syntax error: unexpected token 'integer', should be 'colon': inserted ':'",
+			}, {
+				Range:    Range{Position{2, 7}, Position{2, 8}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0302",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "typecheck: id y was not found within the current scope (line 3)",
+			}},
+		},
+		{
+			name: "truncated input",
+			src:  "func main() -> void {\n\twrite(y);\n}\nfunc broken(",
+			expected: []Diagnostic{{
+				Range:    Range{Position{3, 11}, Position{3, 12}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0102",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "syntax error: got EOF in the middle of a sentence, expected to find symbols for [closepar id]",
+			}, {
+				Range:    Range{Position{1, 7}, Position{1, 8}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0302",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "typecheck: id y was not found within the current scope (line 2)",
+			}},
+		},
+		{
+			name: "duplicate declaration",
+			src:  "func main() -> void {\n\tlet x: integer;\n\tlet x: float;\n}",
+			expected: []Diagnostic{{
+				Range:    Range{Position{2, 8}, Position{2, 13}},
+				Severity: SEVERITY_ERROR,
+				Code:     "E0201",
+				Source:   DIAGNOSTIC_SOURCE,
+				Message:  "duplicate definition for 'x' (defined on line 2, and again on line 3)",
+				RelatedInformation: []DiagnosticRelatedInformation{{
+					Location: Location{URI, Range{Position{1, 8}, Position{1, 15}}},
+					Message:  "'x' is first defined here",
+				}},
+			}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			messages := session(t, append(open(tc.src), shutdown()...)...)
+			actual := diagnostics(t, messages)
+			if !equalJson(actual, tc.expected) {
+				t.Errorf("Expected diagnostics:\n%v\nbut got:\n%v", jsonify(tc.expected), jsonify(actual))
+			}
+		})
+	}
+}
+
+func TestDiagnostics_SyntaxError(t *testing.T) {
+	t.Parallel()
+	messages := session(t, append(open("func main() -> void {\n\tlet x integer;\n}"), shutdown()...)...)
+	actual := diagnostics(t, messages)
+	if len(actual) == 0 {
+		t.Fatalf("Expected syntax error diagnostics")
+	}
+	first := actual[0]
+	if !strings.HasPrefix(first.Message, "syntax error") || first.Code != "E0101" {
+		t.Errorf("Expected an unexpected token error but got %v %q", first.Code, first.Message)
+	}
+	if first.Range.Start.Line != 1 {
+		t.Errorf("Expected the syntax error to start on line 1 but got %v", first.Range.Start.Line)
+	}
+}
+
+func TestDiagnostics_Change(t *testing.T) {
+	t.Parallel()
+	messages := session(t,
+		append(open("func main() -> void { x = 1; }"),
+			message("textDocument/didChange", nil, map[string]any{
+				"textDocument":   map[string]any{"uri": URI, "version": 2},
+				"contentChanges": []map[string]any{{"text": "func main() -> void { }"}},
+			}),
+			message("textDocument/didClose", nil, map[string]any{
+				"textDocument": map[string]any{"uri": URI},
+			}))...)
+
+	published := make([]int, 0, 3)
+	for _, m := range messages {
+		if m.Method == "textDocument/publishDiagnostics" {
+			var params PublishDiagnosticsParams
+			json.Unmarshal(m.Params, &params)
+			published = append(published, len(params.Diagnostics))
+		}
+	}
+	if fmt.Sprint(published) != "[2 0 0]" {
+		t.Errorf("Expected diagnostics to be published 3 times as [2 0 0] but got %v", published)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		at       Position
+		expected *Location
+	}{
+		{
+			name:     "local variable",
+			at:       Position{13, 8},
+			expected: &Location{URI, Range{Position{11, 5}, Position{11, 7}}},
+		},
+		{
+			name:     "method resolves to impl",
+			at:       Position{13, 11},
+			expected: &Location{URI, Range{Position{9, 19}, Position{9, 23}}},
+		},
+		{
+			name:     "inherited data member",
+			at:       Position{14, 10},
+			expected: &Location{URI, Range{Position{1, 12}, Position{1, 17}}},
+		},
+		{
+			name:     "member used within impl",
+			at:       Position{9, 45},
+			expected: &Location{URI, Range{Position{5, 13}, Position{5, 17}}},
+		},
+		{
+			name:     "type",
+			at:       Position{11, 10},
+			expected: &Location{URI, Range{Position{4, 7}, Position{4, 13}}},
+		},
+		{
+			name:     "inherited struct",
+			at:       Position{4, 25},
+			expected: &Location{URI, Range{Position{0, 7}, Position{0, 12}}},
+		},
+		{
+			name:     "impl resolves to struct",
+			at:       Position{9, 6},
+			expected: &Location{URI, Range{Position{4, 7}, Position{4, 13}}},
+		},
+		{
+			name: "keyword",
+			at:   Position{10, 1},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			result := sendRequest(t, "textDocument/definition", map[string]any{
+				"textDocument": map[string]any{"uri": URI},
+				"position":     tc.at,
+			})
+			var actual *Location
+			if err := json.Unmarshal(result, &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !equalJson(actual, tc.expected) {
+				t.Errorf("Expected %v but got %v", jsonify(tc.expected), jsonify(actual))
+			}
+		})
+	}
+}
+
+func TestHover(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		at       Position
+		expected string
+	}{
+		{name: "local array", at: Position{12, 6}, expected: "let arr: integer[3]"},
+		{name: "method", at: Position{13, 11}, expected: "public func SQUARE::area() -> float"},
+		{name: "data member", at: Position{14, 11}, expected: "public let sides: integer"},
+		{name: "struct", at: Position{11, 9}, expected: "struct SQUARE inherits SHAPE"},
+		{name: "function", at: Position{10, 6}, expected: "func main() -> void"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			result := sendRequest(t, "textDocument/hover", map[string]any{
+				"textDocument": map[string]any{"uri": URI},
+				"position":     tc.at,
+			})
+			var actual Hover
+			if err := json.Unmarshal(result, &actual); err != nil {
+				t.Fatal(err)
+			}
+			expected := fmt.Sprintf("```\n%v\n```", tc.expected)
+			if actual.Contents.Value != expected {
+				t.Errorf("Expected hover %q but got %q", expected, actual.Contents.Value)
+			}
+		})
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	t.Parallel()
+	result := sendRequest(t, "textDocument/documentSymbol", map[string]any{
+		"textDocument": map[string]any{"uri": URI},
+	})
+	var symbols []DocumentSymbol
+	if err := json.Unmarshal(result, &symbols); err != nil {
+		t.Fatal(err)
+	}
+
+	var flatten func(symbols []DocumentSymbol, depth int) []string
+	flatten = func(symbols []DocumentSymbol, depth int) []string {
+		var out []string
+		for _, s := range symbols {
+			out = append(out, fmt.Sprintf("%v%v %v (%v)",
+				strings.Repeat("\t", depth), s.Kind, s.Name, s.Detail))
+			out = append(out, flatten(s.Children, depth+1)...)
+		}
+		return out
+	}
+
+	expected := strings.Join([]string{
+		"23 SHAPE ()",
+		"\t8 sides (integer)",
+		"\t6 area (() -> float)",
+		"23 SQUARE (inherits SHAPE)",
+		"\t8 side (float)",
+		"\t6 area (() -> float)",
+		"5 SHAPE (impl)",
+		"\t6 area (() -> float)",
+		"5 SQUARE (impl)",
+		"\t6 area (() -> float)",
+		"12 main (() -> void)",
+		"\t13 sq (SQUARE)",
+		"\t13 arr (integer[3])",
+	}, "\n")
+	if actual := strings.Join(flatten(symbols, 0), "\n"); actual != expected {
+		t.Errorf("Expected symbols:\n%v\n\nbut got:\n%v", expected, actual)
+	}
+}
+
+func TestServe_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	// Requests before initialize are rejected
+	out := new(bytes.Buffer)
+	in := frame(message("textDocument/hover", 1, map[string]any{}), exit())
+	if err := NewServer(in, out).Serve(); !errors.Is(err, ErrExitWithoutShutdown) {
+		t.Errorf("Expected %v but got %v", ErrExitWithoutShutdown, err)
+	}
+	messages := decode(t, out)
+	if len(messages) != 1 || messages[0].Error == nil ||
+		messages[0].Error.Code != CODE_SERVER_NOT_INITIALIZED {
+		t.Errorf("Expected a server not initialized error but got %v", out)
+	}
+
+	// Unknown methods are reported
+	messages = session(t,
+		initialize(),
+		message("textDocument/completion", 2, map[string]any{}),
+		message("shutdown", 3, nil),
+		exit())
+	if l := len(messages); l != 3 {
+		t.Fatalf("Expected 3 messages but got %v", l)
+	}
+	if messages[1].Error == nil || messages[1].Error.Code != CODE_METHOD_NOT_FOUND {
+		t.Errorf("Expected a method not found error but got %v", jsonify(messages[1]))
+	}
+	if string(messages[2].Result) != "null" {
+		t.Errorf("Expected shutdown to return null but got %s", messages[2].Result)
+	}
+}
+
+// A message received from the server
+type received struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *ResponseError  `json:"error"`
+}
+
+// Runs the server on the given messages, returning all messages sent back
+func session(t *testing.T, messages ...[]byte) []received {
+	t.Helper()
+	out := new(bytes.Buffer)
+	if err := NewServer(frame(messages...), out).Serve(); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	return decode(t, out)
+}
+
+// Opens SRC and sends a single request about it, returning its result
+func sendRequest(t *testing.T, method string, params any) json.RawMessage {
+	t.Helper()
+	messages := session(t, append(open(SRC), message(method, 2, params))...)
+	for _, m := range messages {
+		if m.ID != nil && *m.ID == 2 {
+			if m.Error != nil {
+				t.Fatalf("Request failed: %v", m.Error)
+			}
+			return m.Result
+		}
+	}
+	t.Fatalf("No response to request")
+	return nil
+}
+
+func diagnostics(t *testing.T, messages []received) []Diagnostic {
+	t.Helper()
+	for _, m := range messages {
+		if m.Method == "textDocument/publishDiagnostics" {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(m.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			return params.Diagnostics
+		}
+	}
+	t.Fatalf("No diagnostics were published")
+	return nil
+}
+
+func initialize() []byte {
+	return message("initialize", 1, map[string]any{"capabilities": map[string]any{}})
+}
+
+func open(src string) [][]byte {
+	return [][]byte{
+		initialize(),
+		message("initialized", nil, map[string]any{}),
+		message("textDocument/didOpen", nil, map[string]any{
+			"textDocument": TextDocumentItem{URI: URI, LanguageID: "esac", Version: 1, Text: src},
+		}),
+	}
+}
+
+func shutdown() [][]byte {
+	return [][]byte{message("shutdown", 99, nil), exit()}
+}
+
+func exit() []byte {
+	return message("exit", nil, nil)
+}
+
+// Creates a request, or a notification if the id is nil
+func message(method string, id any, params any) []byte {
+	m := map[string]any{"jsonrpc": "2.0", "method": method}
+	if id != nil {
+		m["id"] = id
+	}
+	if params != nil {
+		m["params"] = params
+	}
+	data, _ := json.Marshal(m)
+	return data
+}
+
+func frame(messages ...[]byte) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	for _, m := range messages {
+		fmt.Fprintf(buf, "Content-Length: %v\r\n\r\n%s", len(m), m)
+	}
+	return buf
+}
+
+func decode(t *testing.T, out *bytes.Buffer) []received {
+	t.Helper()
+	r := bufio.NewReader(out)
+	messages := make([]received, 0, 8)
+	for r.Buffered() > 0 || out.Len() > 0 {
+		body, err := readMessage(r)
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		var m received
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatalf("Failed to decode message %s: %v", body, err)
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+func equalJson(a, b any) bool {
+	return jsonify(a) == jsonify(b)
+}
+
+func jsonify(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
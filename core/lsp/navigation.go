@@ -0,0 +1,275 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// Returns the path from the root of the AST down to the leaf whose token is
+// found at the given position, or nil if there is no such leaf
+func (d *document) pathAt(pos Position) []*token.ASTNode {
+	var search func(node *token.ASTNode, path []*token.ASTNode) []*token.ASTNode
+	search = func(node *token.ASTNode, path []*token.ASTNode) []*token.ASTNode {
+		path = append(path, node)
+		if len(node.Children) == 0 {
+			if node.Token.Line > 0 && tokenRange(node.Token).contains(pos) {
+				return path
+			}
+			return nil
+		}
+		for _, child := range node.Children {
+			if found := search(child, path); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if d.ast == nil {
+		return nil
+	}
+	return search(d.ast, make([]*token.ASTNode, 0, 32))
+}
+
+// Finds the record of the identifier at the given position, along with the
+// node of that identifier
+func (d *document) recordAt(pos Position) (*token.SymbolTableRecord, *token.ASTNode) {
+	path := d.pathAt(pos)
+	if len(path) < 2 {
+		return nil, nil
+	}
+	leaf, parent := path[len(path)-1], path[len(path)-2]
+	if leaf.Type != token.FINAL_ID {
+		return nil, nil
+	}
+
+	switch {
+	case parent.Type == token.FINAL_VARIABLE || parent.Type == token.FINAL_FUNC_CALL:
+		// Resolved by the SemCheckVisitor, this takes care of members, inherited
+		// members, and overloads
+		return parent.Meta.Record, leaf
+	case isDecl(parent) && parent.Children[0] == leaf:
+		if parent.Type == token.FINAL_IMPL_DEF {
+			return d.structRecord(string(leaf.Token.Lexeme)), leaf
+		}
+		return parent.Meta.Record, leaf
+	case parent.Type == token.FINAL_TYPE ||
+		parent.Type == token.FINAL_RETURNTYPE ||
+		parent.Type == token.FINAL_INHERITS:
+		return d.structRecord(string(leaf.Token.Lexeme)), leaf
+	}
+	return nil, nil
+}
+
+func (d *document) structRecord(name string) *token.SymbolTableRecord {
+	if node, ok := d.structs[name]; ok {
+		return node.Meta.Record
+	}
+	return nil
+}
+
+// Finds the declaration of the identifier at the given position
+func (d *document) definition(pos Position) *Location {
+	record, _ := d.recordAt(pos)
+	if record == nil {
+		return nil
+	}
+	decl := d.decls[keyOf(*record)]
+	switch {
+	case record.Kind == token.FINAL_STRUCT_DECL || record.Kind == token.FINAL_IMPL_DEF:
+		decl = d.structs[record.Name]
+	case decl != nil && decl.Type == token.FINAL_FUNC_DECL:
+		// Methods are recorded with the declaration found in the struct, but
+		// their body is found in the impl
+		if def := d.methodDef(*record); def != nil {
+			decl = def
+		}
+	}
+	if decl == nil {
+		return nil
+	}
+	return &Location{URI: d.uri, Range: idRange(decl)}
+}
+
+// Finds the definition of a method declared in a struct
+func (d *document) methodDef(decl token.SymbolTableRecord) *token.ASTNode {
+	if decl.Parent == nil {
+		return nil
+	}
+	impl, ok := d.impls[decl.Parent.Id()]
+	if !ok {
+		return nil
+	}
+	for _, def := range impl.Children[1].Children {
+		if record := def.Meta.Record; record != nil &&
+			record.Name == decl.Name && detail(*record) == detail(decl) {
+			return def
+		}
+	}
+	return nil
+}
+
+// Describes the identifier at the given position
+func (d *document) hover(pos Position) *Hover {
+	record, node := d.recordAt(pos)
+	if record == nil {
+		return nil
+	}
+	r := tokenRange(node.Token)
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: fmt.Sprintf("```\n%v\n```", describe(*record)),
+		},
+		Range: &r,
+	}
+}
+
+// Lists the structs, impls, and functions of the document along with their
+// members
+func (d *document) symbols() []DocumentSymbol {
+	symbols := make([]DocumentSymbol, 0, 16)
+	if d.ast == nil || len(d.ast.Children) == 0 {
+		return symbols
+	}
+
+	for _, node := range d.ast.Children[0].Children {
+		record := node.Meta.Record
+		if record == nil {
+			continue
+		}
+		switch node.Type {
+		case token.FINAL_STRUCT_DECL:
+			symbol := d.symbol(node, SYMBOL_STRUCT, inherits(*record))
+			for _, member := range node.Children[2].Children {
+				if member.Meta.Record == nil {
+					continue
+				}
+				kind := SYMBOL_FIELD
+				if member.Meta.Record.Kind == token.FINAL_FUNC_DECL {
+					kind = SYMBOL_METHOD
+				}
+				symbol.Children = append(symbol.Children,
+					d.symbol(member.Children[1], kind, detail(*member.Meta.Record)))
+			}
+			symbols = append(symbols, symbol)
+		case token.FINAL_IMPL_DEF:
+			symbol := d.symbol(node, SYMBOL_CLASS, "impl")
+			for _, method := range node.Children[1].Children {
+				if method.Meta.Record != nil {
+					symbol.Children = append(symbol.Children, d.functionSymbol(method, SYMBOL_METHOD))
+				}
+			}
+			symbols = append(symbols, symbol)
+		case token.FINAL_FUNC_DEF:
+			symbols = append(symbols, d.functionSymbol(node, SYMBOL_FUNCTION))
+		}
+	}
+	return symbols
+}
+
+func (d *document) symbol(node *token.ASTNode, kind SymbolKind, detail string) DocumentSymbol {
+	return DocumentSymbol{
+		Name:           node.Meta.Record.Name,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          nodeRange(node),
+		SelectionRange: idRange(node),
+	}
+}
+
+// The children of a function are the parameters and local variables found in
+// its table
+func (d *document) functionSymbol(node *token.ASTNode, kind SymbolKind) DocumentSymbol {
+	symbol := d.symbol(node, kind, detail(*node.Meta.Record))
+	if node.Meta.SymbolTable == nil {
+		return symbol
+	}
+	symbol.Children = d.variableSymbols(node.Meta.SymbolTable, symbol.Children)
+	return symbol
+}
+
+// Appends the parameters and local variables of a scope to symbols, including
+// the variables of the for loops nested in the scope
+func (d *document) variableSymbols(table token.SymbolTable, symbols []DocumentSymbol) []DocumentSymbol {
+	for _, entry := range table.Entries() {
+		switch entry.Kind {
+		case token.FINAL_FOR:
+			if entry.Link != nil {
+				symbols = d.variableSymbols(entry.Link, symbols)
+			}
+		case token.FINAL_FUNC_DEF_PARAM, token.FINAL_VAR_DECL:
+			if decl := d.decls[keyOf(entry)]; decl != nil {
+				symbols = append(symbols, d.symbol(decl, SYMBOL_VARIABLE, detail(entry)))
+			}
+		}
+	}
+	return symbols
+}
+
+// A one line summary of the declaration of a record, used for hovers
+func describe(record token.SymbolTableRecord) string {
+	privacy := ""
+	if record.Type.Privacy != "" {
+		privacy = string(record.Type.Privacy) + " "
+	}
+
+	switch record.Kind {
+	case token.FINAL_STRUCT_DECL, token.FINAL_IMPL_DEF:
+		if parents := inherits(record); parents != "" {
+			return fmt.Sprintf("struct %v %v", record.Name, parents)
+		}
+		return "struct " + record.Name
+	case token.FINAL_FUNC_DEF, token.FINAL_FUNC_DECL:
+		name := record.Name
+		if record.Parent != nil && record.Parent.Id() != token.GLOBAL {
+			name = record.Parent.Id() + "::" + name
+		}
+		return fmt.Sprintf("%vfunc %v%v", privacy, name, detail(record))
+	case token.FINAL_FUNC_DEF_PARAM:
+		return fmt.Sprintf("(parameter) %v: %v", record.Name, formatType(record.Type))
+	}
+	return fmt.Sprintf("%vlet %v: %v", privacy, record.Name, formatType(record.Type))
+}
+
+// Formats the signature of a function, or the type of a variable
+func detail(record token.SymbolTableRecord) string {
+	if record.Kind != token.FINAL_FUNC_DEF && record.Kind != token.FINAL_FUNC_DECL {
+		return formatType(record.Type)
+	}
+	params := make([]string, 0, 8)
+	if record.Link != nil {
+		for _, e := range record.Link.Entries() {
+			if e.Kind == token.FINAL_FUNC_DEF_PARAM {
+				params = append(params, fmt.Sprintf("%v: %v", e.Name, formatType(e.Type)))
+			}
+		}
+	}
+	return fmt.Sprintf("(%v) -> %v", strings.Join(params, ", "), formatType(record.Type))
+}
+
+func inherits(record token.SymbolTableRecord) string {
+	if record.Link == nil || len(record.Link.Inherited()) == 0 {
+		return ""
+	}
+	parents := make([]string, 0, len(record.Link.Inherited()))
+	for _, parent := range record.Link.Inherited() {
+		parents = append(parents, parent.Id())
+	}
+	return "inherits " + strings.Join(parents, ", ")
+}
+
+// Formats a type the way it is written in source
+func formatType(t token.Type) string {
+	var builder strings.Builder
+	builder.WriteString(string(t.Token.Lexeme))
+	for _, dim := range t.Dimlist {
+		if dim == token.DIMENSION_ANY {
+			builder.WriteString("[]")
+		} else {
+			fmt.Fprintf(&builder, "[%v]", dim)
+		}
+	}
+	return builder.String()
+}
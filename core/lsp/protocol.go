@@ -0,0 +1,185 @@
+// Package lsp implements a Language Server Protocol server for the language,
+// spoken over a pair of streams (usually STDIN and STDOUT).
+//
+// The server keeps the text of every open document, and reanalyzes a document
+// every time that it changes: the document is scanned, parsed, and decorated by
+// the SymTabVisitor and the SemCheckVisitor. Lexical, syntax, and semantic
+// errors are published as diagnostics, and the decorated AST is used to answer
+// go-to-definition, hover, and document symbol requests.
+//
+// Only the subset of the protocol needed for these features is implemented.
+// Documents are always synchronized in full.
+package lsp
+
+import "encoding/json"
+
+// A zero-based position in a document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+func (p Position) before(p2 Position) bool {
+	return p.Line < p2.Line || p.Line == p2.Line && p.Character < p2.Character
+}
+
+// A range of a document, the end is exclusive
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+func (r Range) contains(p Position) bool {
+	return !p.before(r.Start) && p.before(r.End)
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type DiagnosticSeverity int
+
+const (
+	SEVERITY_ERROR       DiagnosticSeverity = 1
+	SEVERITY_WARNING     DiagnosticSeverity = 2
+	SEVERITY_INFORMATION DiagnosticSeverity = 3
+	SEVERITY_HINT        DiagnosticSeverity = 4
+)
+
+type Diagnostic struct {
+	Range              Range                          `json:"range"`
+	Severity           DiagnosticSeverity             `json:"severity"`
+	Code               string                         `json:"code,omitempty"`
+	Source             string                         `json:"source"`
+	Message            string                         `json:"message"`
+	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type DiagnosticRelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+type SymbolKind int
+
+const (
+	SYMBOL_CLASS    SymbolKind = 5
+	SYMBOL_METHOD   SymbolKind = 6
+	SYMBOL_FIELD    SymbolKind = 8
+	SYMBOL_FUNCTION SymbolKind = 12
+	SYMBOL_VARIABLE SymbolKind = 13
+	SYMBOL_STRUCT   SymbolKind = 23
+)
+
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+type DidChangeTextDocumentParams struct {
+	TextDocument   TextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type DocumentSymbolParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type ServerCapabilities struct {
+	TextDocumentSync       int  `json:"textDocumentSync"`
+	DefinitionProvider     bool `json:"definitionProvider"`
+	HoverProvider          bool `json:"hoverProvider"`
+	DocumentSymbolProvider bool `json:"documentSymbolProvider"`
+}
+
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+	ServerInfo   struct {
+		Name string `json:"name"`
+	} `json:"serverInfo"`
+}
+
+// Documents are synchronized by sending their full content on every change
+const TEXT_DOCUMENT_SYNC_FULL = 1
+
+// JSON-RPC error codes
+const (
+	CODE_PARSE_ERROR            = -32700
+	CODE_INVALID_REQUEST        = -32600
+	CODE_METHOD_NOT_FOUND       = -32601
+	CODE_INVALID_PARAMS         = -32602
+	CODE_INTERNAL_ERROR         = -32603
+	CODE_SERVER_NOT_INITIALIZED = -32002
+)
+
+// A JSON-RPC request or notification, notifications have no ID
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+}
+
+type errorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   *ResponseError   `json:"error"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
@@ -0,0 +1,196 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const SERVER_NAME = "esacc"
+
+// A language server handling one client over a pair of streams. Messages are
+// handled one at a time, in the order in which they are received
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	docs        map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: make(map[string]*document, 8),
+	}
+}
+
+// Serves requests until the client sends the exit notification or closes the
+// input stream. An error is returned if the client exits without shutting the
+// server down first, as the protocol requires
+func (s *Server) Serve() error {
+	for {
+		body, err := readMessage(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.replyError(nil, &ResponseError{CODE_PARSE_ERROR, err.Error()})
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(req); err != nil {
+			return err
+		}
+	}
+}
+
+// Dispatches a single message. Errors are only returned if the output stream
+// is broken, failed requests are reported to the client instead
+func (s *Server) handle(req request) error {
+	if req.ID == nil {
+		return s.notify(req)
+	}
+
+	if !s.initialized && req.Method != "initialize" {
+		return s.replyError(req.ID, &ResponseError{CODE_SERVER_NOT_INITIALIZED, "server not initialized"})
+	}
+	if s.shutdown {
+		return s.replyError(req.ID, &ResponseError{CODE_INVALID_REQUEST, "server is shutting down"})
+	}
+
+	switch req.Method {
+	case "initialize":
+		s.initialized = true
+		result := InitializeResult{Capabilities: ServerCapabilities{
+			TextDocumentSync:       TEXT_DOCUMENT_SYNC_FULL,
+			DefinitionProvider:     true,
+			HoverProvider:          true,
+			DocumentSymbolProvider: true,
+		}}
+		result.ServerInfo.Name = SERVER_NAME
+		return s.reply(req.ID, result)
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(req.ID, nil)
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		return s.replyDocument(req, &params, &params.TextDocument, func(d *document) any {
+			if location := d.definition(params.Position); location != nil {
+				return location
+			}
+			return nil
+		})
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		return s.replyDocument(req, &params, &params.TextDocument, func(d *document) any {
+			if hover := d.hover(params.Position); hover != nil {
+				return hover
+			}
+			return nil
+		})
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		return s.replyDocument(req, &params, &params.TextDocument, func(d *document) any {
+			return d.symbols()
+		})
+	}
+	return s.replyError(req.ID, &ResponseError{
+		CODE_METHOD_NOT_FOUND,
+		fmt.Sprintf("method '%v' is not supported", req.Method),
+	})
+}
+
+// Decodes the params of a request about a document, and replies with the
+// result of calling f on the document
+func (s *Server) replyDocument(
+	req request,
+	params any,
+	id *TextDocumentIdentifier,
+	f func(d *document) any,
+) error {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return s.replyError(req.ID, &ResponseError{CODE_INVALID_PARAMS, err.Error()})
+	}
+	doc, ok := s.docs[id.URI]
+	if !ok {
+		return s.replyError(req.ID, &ResponseError{
+			CODE_INVALID_PARAMS,
+			fmt.Sprintf("document '%v' is not open", id.URI),
+		})
+	}
+	return s.reply(req.ID, f(doc))
+}
+
+// Handles a notification, notifications are never replied to
+func (s *Server) notify(req request) error {
+	if !s.initialized {
+		return nil
+	}
+
+	switch req.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if json.Unmarshal(req.Params, &params) != nil {
+			return nil
+		}
+		return s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if json.Unmarshal(req.Params, &params) != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+
+		// Synchronization is full, the last change holds the whole document
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.update(params.TextDocument.URI, text)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if json.Unmarshal(req.Params, &params) != nil {
+			return nil
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return s.publish(params.TextDocument.URI, nil)
+	}
+	return nil
+}
+
+// Reanalyzes a document and publishes its diagnostics
+func (s *Server) update(uri, text string) error {
+	doc := analyze(uri, text)
+	s.docs[uri] = doc
+	return s.publish(uri, doc.diagnostics)
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+func (s *Server) reply(id *json.RawMessage, result any) error {
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id *json.RawMessage, err *ResponseError) error {
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: id, Error: err})
+}
@@ -0,0 +1,377 @@
+package moon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// An instruction after assembly
+type Instruction struct {
+	Op   string
+	Regs []int  // Register operands, in the order they appear
+	K    int32  // The immediate operand, if any
+	Line int    // Line of the source file containing this instruction
+	Text string // The original line (without comments)
+}
+
+// An assembled MOON program, ready to be loaded into a Machine
+type Program struct {
+	Image        []byte                 // Initial contents of memory
+	Instructions map[int32]*Instruction // Keyed by address
+	Labels       map[string]int32
+	Entry        int32
+}
+
+// Number of register operands followed by whether there is an immediate
+// operand. Loads and stores are handled separately
+var formats = map[string]struct {
+	regs int
+	k    bool
+}{
+	"add": {3, false}, "sub": {3, false}, "mul": {3, false}, "div": {3, false},
+	"mod": {3, false}, "and": {3, false}, "or": {3, false}, "not": {2, false},
+	"ceq": {3, false}, "cne": {3, false}, "clt": {3, false}, "cle": {3, false},
+	"cgt": {3, false}, "cge": {3, false},
+
+	"addi": {2, true}, "subi": {2, true}, "muli": {2, true}, "divi": {2, true},
+	"modi": {2, true}, "andi": {2, true}, "ori": {2, true}, "ceqi": {2, true},
+	"cnei": {2, true}, "clti": {2, true}, "clei": {2, true}, "cgti": {2, true},
+	"cgei": {2, true},
+
+	"sl": {1, true}, "sr": {1, true},
+	"getc": {1, false}, "putc": {1, false},
+	"bz": {1, true}, "bnz": {1, true}, "j": {0, true}, "jr": {1, false},
+	"jl": {1, true}, "jlr": {2, false},
+	"nop": {0, false}, "hlt": {0, false},
+
+	// lw Ri, K(Rj)  sw K(Rj), Ri
+	"lw": {2, true}, "lb": {2, true}, "sw": {2, true}, "sb": {2, true},
+}
+
+type line struct {
+	num      int
+	text     string
+	label    string
+	op       string
+	operands []string
+	addr     int32
+}
+
+// Assembles MOON source code into a Program
+func Assemble(src io.Reader) (*Program, error) {
+	lines, err := scanLines(src)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := &Program{
+		Instructions: make(map[int32]*Instruction, len(lines)),
+		Labels:       make(map[string]int32, 64),
+		Entry:        -1,
+	}
+
+	// First pass, compute the address of every line and collect the labels
+	var addr int32
+	var entry bool
+	for _, l := range lines {
+		if l.op == "dw" || isInstruction(l.op) {
+			addr = align(addr)
+		}
+		if l.label != "" {
+			if _, ok := prog.Labels[l.label]; ok {
+				return nil, l.errorf("duplicate label '%v'", l.label)
+			}
+			prog.Labels[l.label] = addr
+		}
+		l.addr = addr
+
+		switch {
+		case l.op == "":
+		case l.op == "entry":
+			entry = true
+		case l.op == "align":
+			addr = align(addr)
+		case l.op == "org":
+			k, err := l.number(0)
+			if err != nil {
+				return nil, err
+			}
+			addr = k
+		case l.op == "res":
+			k, err := l.number(0)
+			if err != nil {
+				return nil, err
+			}
+			addr += k
+		case l.op == "dw":
+			addr += int32(WORD_SIZE * len(l.operands))
+		case l.op == "db":
+			n, err := l.bytes(nil)
+			if err != nil {
+				return nil, err
+			}
+			addr += int32(len(n))
+		case isInstruction(l.op):
+			if entry {
+				if prog.Entry >= 0 {
+					return nil, l.errorf("multiple entry points")
+				}
+				prog.Entry = addr
+				entry = false
+			}
+			addr += WORD_SIZE
+		default:
+			return nil, l.errorf("unknown operation '%v'", l.op)
+		}
+	}
+	if prog.Entry < 0 {
+		prog.Entry = 0
+	}
+
+	// Second pass, resolve operands and fill in the memory image
+	prog.Image = make([]byte, align(addr))
+	for _, l := range lines {
+		switch {
+		case l.op == "dw":
+			for i := range l.operands {
+				k, err := l.value(prog, i)
+				if err != nil {
+					return nil, err
+				}
+				binary.BigEndian.PutUint32(prog.Image[l.addr+int32(WORD_SIZE*i):], uint32(k))
+			}
+		case l.op == "db":
+			data, err := l.bytes(prog)
+			if err != nil {
+				return nil, err
+			}
+			copy(prog.Image[l.addr:], data)
+		case isInstruction(l.op):
+			instr, err := l.instruction(prog)
+			if err != nil {
+				return nil, err
+			}
+			prog.Instructions[l.addr] = instr
+		}
+	}
+	return prog, nil
+}
+
+func scanLines(src io.Reader) ([]*line, error) {
+	lines := make([]*line, 0, 1024)
+	scanner := bufio.NewScanner(src)
+	for n := 1; scanner.Scan(); n++ {
+		text := stripComment(scanner.Text())
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		l := &line{num: n, text: text}
+		rest := text
+		if !strings.HasPrefix(text, " ") && !strings.HasPrefix(text, "\t") {
+			fields := strings.Fields(text)
+			l.label = fields[0]
+			rest = strings.TrimSpace(text)[len(l.label):]
+			if !isSymbol(l.label) {
+				return nil, l.errorf("invalid label '%v'", l.label)
+			}
+		}
+
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			l.op = strings.ToLower(fields[0])
+			l.operands = splitOperands(strings.TrimSpace(rest)[len(fields[0]):])
+		}
+		lines = append(lines, l)
+	}
+	return lines, scanner.Err()
+}
+
+// Removes a '%' comment from the line, unless the '%' is inside a string
+func stripComment(text string) string {
+	var quoted bool
+	for i, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '%' && !quoted:
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func splitOperands(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	out := make([]string, 0, 3)
+	var quoted bool
+	var start int
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			out = append(out, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(out, strings.TrimSpace(s[start:]))
+}
+
+func (l *line) errorf(msg string, args ...any) error {
+	return &AssemblyError{Line: l.num, Text: strings.TrimSpace(l.text), Msg: fmt.Sprintf(msg, args...)}
+}
+
+// Parses a numeric operand that cannot refer to a label
+func (l *line) number(i int) (int32, error) {
+	if i >= len(l.operands) {
+		return 0, l.errorf("missing operand")
+	}
+	k, err := strconv.ParseInt(l.operands[i], 0, 32)
+	if err != nil {
+		return 0, l.errorf("invalid number '%v'", l.operands[i])
+	}
+	return int32(k), nil
+}
+
+// Parses an operand that may be a number or a label
+func (l *line) value(prog *Program, i int) (int32, error) {
+	if i >= len(l.operands) {
+		return 0, l.errorf("missing operand")
+	}
+	return l.resolve(prog, l.operands[i])
+}
+
+func (l *line) resolve(prog *Program, operand string) (int32, error) {
+	if k, err := strconv.ParseInt(operand, 0, 32); err == nil {
+		return int32(k), nil
+	}
+	if addr, ok := prog.Labels[operand]; ok {
+		return addr, nil
+	}
+	return 0, l.errorf("undefined symbol '%v'", operand)
+}
+
+// Returns the bytes defined by a db directive. If prog is nil, labels are not
+// resolved (we only need the length)
+func (l *line) bytes(prog *Program) ([]byte, error) {
+	out := make([]byte, 0, 16)
+	for _, operand := range l.operands {
+		if strings.HasPrefix(operand, `"`) {
+			s, err := strconv.Unquote(operand)
+			if err != nil {
+				return nil, l.errorf("invalid string %v", operand)
+			}
+			out = append(out, s...)
+			continue
+		}
+		var k int32
+		if prog != nil {
+			var err error
+			if k, err = l.resolve(prog, operand); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, byte(k))
+	}
+	return out, nil
+}
+
+func (l *line) instruction(prog *Program) (*Instruction, error) {
+	format := formats[l.op]
+	instr := &Instruction{Op: l.op, Line: l.num, Text: l.text}
+	operands := l.operands
+
+	// Loads and stores have a K(Rj) operand
+	switch l.op {
+	case "lw", "lb", "sw", "sb":
+		if len(operands) != 2 {
+			return nil, l.errorf("expected 2 operands")
+		}
+		reg, mem := operands[0], operands[1]
+		if l.op == "sw" || l.op == "sb" {
+			reg, mem = mem, reg
+		}
+		ri, err := l.register(reg)
+		if err != nil {
+			return nil, err
+		}
+		open, close := strings.IndexByte(mem, '('), strings.LastIndexByte(mem, ')')
+		if open < 0 || close != len(mem)-1 {
+			return nil, l.errorf("expected an operand of the form K(Rj)")
+		}
+		rj, err := l.register(mem[open+1 : close])
+		if err != nil {
+			return nil, err
+		}
+		k := int32(0)
+		if open > 0 {
+			if k, err = l.resolve(prog, mem[:open]); err != nil {
+				return nil, err
+			}
+		}
+		instr.Regs, instr.K = []int{ri, rj}, k
+		return instr, nil
+	}
+
+	expected := format.regs
+	if format.k {
+		expected++
+	}
+	if len(operands) != expected {
+		return nil, l.errorf("expected %v operands but found %v", expected, len(operands))
+	}
+	for _, operand := range operands[:format.regs] {
+		r, err := l.register(operand)
+		if err != nil {
+			return nil, err
+		}
+		instr.Regs = append(instr.Regs, r)
+	}
+	if format.k {
+		k, err := l.value(prog, format.regs)
+		if err != nil {
+			return nil, err
+		}
+		instr.K = k
+	}
+	return instr, nil
+}
+
+func (l *line) register(operand string) (int, error) {
+	operand = strings.ToLower(strings.TrimSpace(operand))
+	if strings.HasPrefix(operand, "r") {
+		if r, err := strconv.Atoi(operand[1:]); err == nil && 0 <= r && r < 16 {
+			return r, nil
+		}
+	}
+	return 0, l.errorf("invalid register '%v'", operand)
+}
+
+func isInstruction(op string) bool {
+	_, ok := formats[op]
+	return ok
+}
+
+func isSymbol(s string) bool {
+	for i, r := range s {
+		letter := ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+		if !(letter || r == '_' || (i > 0 && '0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func align(addr int32) int32 {
+	if rem := addr % WORD_SIZE; rem != 0 {
+		return addr + WORD_SIZE - rem
+	}
+	return addr
+}
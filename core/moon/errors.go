@@ -0,0 +1,35 @@
+package moon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrStepLimitExceeded = errors.New("moon: step limit exceeded")
+
+// Assembly error, reports the line that caused it
+type AssemblyError struct {
+	Line int
+	Text string
+	Msg  string
+}
+
+func (e *AssemblyError) Error() string {
+	return fmt.Sprintf("moon: line %v: %v: '%v'", e.Line, e.Msg, e.Text)
+}
+
+// Runtime error, reports the instruction that caused it
+type RuntimeError struct {
+	PC          int32
+	Instruction *Instruction
+	Msg         string
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Instruction == nil {
+		return fmt.Sprintf("moon: pc=%v: %v", e.PC, e.Msg)
+	}
+	return fmt.Sprintf("moon: pc=%v (line %v): %v: '%v'",
+		e.PC, e.Instruction.Line, e.Msg, strings.TrimSpace(e.Instruction.Text))
+}
@@ -0,0 +1,219 @@
+package moon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// A MOON processor, executes a Program
+type Machine struct {
+	Registers [16]int32
+	Memory    []byte
+	PC        int32
+	Steps     int // Number of instructions executed so far
+
+	// The program is killed after this many instructions, no limit if negative
+	MaxSteps int
+
+	prog *Program
+	in   *bufio.Reader
+	out  io.Writer
+}
+
+// Creates a machine with the program loaded into memory
+func NewMachine(prog *Program, in io.Reader, out io.Writer) *Machine {
+	m := &Machine{
+		Memory:   make([]byte, DEFAULT_MEMORY_SIZE),
+		PC:       prog.Entry,
+		MaxSteps: DEFAULT_MAX_STEPS,
+		prog:     prog,
+		in:       bufio.NewReader(in),
+		out:      out,
+	}
+	copy(m.Memory, prog.Image)
+	return m
+}
+
+// Assembles and runs a program
+func Run(src io.Reader, in io.Reader, out io.Writer) error {
+	prog, err := Assemble(src)
+	if err != nil {
+		return err
+	}
+	return NewMachine(prog, in, out).Run()
+}
+
+// Runs the program until it halts
+func (m *Machine) Run() error {
+	for {
+		halted, err := m.Step()
+		if err != nil || halted {
+			return err
+		}
+	}
+}
+
+// Executes a single instruction
+func (m *Machine) Step() (halted bool, err error) {
+	if m.MaxSteps >= 0 && m.Steps >= m.MaxSteps {
+		return true, ErrStepLimitExceeded
+	}
+	instr, ok := m.prog.Instructions[m.PC]
+	if !ok {
+		return true, &RuntimeError{PC: m.PC, Msg: "no instruction at this address"}
+	}
+	m.Steps++
+
+	fail := func(msg string, args ...any) (bool, error) {
+		return true, &RuntimeError{PC: m.PC, Instruction: instr, Msg: fmt.Sprintf(msg, args...)}
+	}
+
+	r := instr.Regs
+	reg := func(i int) int32 { return m.Registers[r[i]] }
+	set := func(i int, v int32) {
+		if r[i] != 0 {
+			m.Registers[r[i]] = v
+		}
+	}
+	next := m.PC + WORD_SIZE
+	k := instr.K
+
+	switch instr.Op {
+	case "lw", "sw", "lb", "sb":
+		addr := reg(1) + k
+		size := int32(1)
+		if instr.Op == "lw" || instr.Op == "sw" {
+			size = WORD_SIZE
+			if addr%WORD_SIZE != 0 {
+				return fail("unaligned memory access at address %v", addr)
+			}
+		}
+		if addr < 0 || int(addr+size) > len(m.Memory) {
+			return fail("memory access out of bounds at address %v", addr)
+		}
+		switch instr.Op {
+		case "lw":
+			set(0, int32(binary.BigEndian.Uint32(m.Memory[addr:])))
+		case "lb":
+			set(0, int32(m.Memory[addr]))
+		case "sw":
+			binary.BigEndian.PutUint32(m.Memory[addr:], uint32(reg(0)))
+		case "sb":
+			m.Memory[addr] = byte(reg(0))
+		}
+	case "add":
+		set(0, reg(1)+reg(2))
+	case "sub":
+		set(0, reg(1)-reg(2))
+	case "mul":
+		set(0, reg(1)*reg(2))
+	case "div", "mod":
+		if reg(2) == 0 {
+			return fail("division by zero")
+		}
+		if instr.Op == "div" {
+			set(0, reg(1)/reg(2))
+		} else {
+			set(0, reg(1)%reg(2))
+		}
+	case "and":
+		set(0, reg(1)&reg(2))
+	case "or":
+		set(0, reg(1)|reg(2))
+	case "not":
+		set(0, ^reg(1))
+	case "ceq":
+		set(0, b2i(reg(1) == reg(2)))
+	case "cne":
+		set(0, b2i(reg(1) != reg(2)))
+	case "clt":
+		set(0, b2i(reg(1) < reg(2)))
+	case "cle":
+		set(0, b2i(reg(1) <= reg(2)))
+	case "cgt":
+		set(0, b2i(reg(1) > reg(2)))
+	case "cge":
+		set(0, b2i(reg(1) >= reg(2)))
+	case "addi":
+		set(0, reg(1)+k)
+	case "subi":
+		set(0, reg(1)-k)
+	case "muli":
+		set(0, reg(1)*k)
+	case "divi", "modi":
+		if k == 0 {
+			return fail("division by zero")
+		}
+		if instr.Op == "divi" {
+			set(0, reg(1)/k)
+		} else {
+			set(0, reg(1)%k)
+		}
+	case "andi":
+		set(0, reg(1)&k)
+	case "ori":
+		set(0, reg(1)|k)
+	case "ceqi":
+		set(0, b2i(reg(1) == k))
+	case "cnei":
+		set(0, b2i(reg(1) != k))
+	case "clti":
+		set(0, b2i(reg(1) < k))
+	case "clei":
+		set(0, b2i(reg(1) <= k))
+	case "cgti":
+		set(0, b2i(reg(1) > k))
+	case "cgei":
+		set(0, b2i(reg(1) >= k))
+	case "sl":
+		set(0, reg(0)<<uint32(k))
+	case "sr":
+		set(0, int32(uint32(reg(0))>>uint32(k)))
+	case "getc":
+		c, err := m.in.ReadByte()
+		if err != nil {
+			c = 0 // End of input
+		}
+		set(0, int32(c))
+	case "putc":
+		if _, err := m.out.Write([]byte{byte(reg(0))}); err != nil {
+			return fail("%v", err)
+		}
+	case "bz":
+		if reg(0) == 0 {
+			next = k
+		}
+	case "bnz":
+		if reg(0) != 0 {
+			next = k
+		}
+	case "j":
+		next = k
+	case "jr":
+		next = reg(0)
+	case "jl":
+		set(0, next)
+		next = k
+	case "jlr":
+		target := reg(1)
+		set(0, next)
+		next = target
+	case "nop":
+	case "hlt":
+		return true, nil
+	default:
+		return fail("unknown instruction")
+	}
+
+	m.PC = next
+	return false, nil
+}
+
+func b2i(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,19 @@
+// Package moon implements the MOON processor used as the target of the
+// compiler: an assembler for MOON assembly code and a machine that executes the
+// assembled programs.
+//
+// MOON is a 32-bit machine with 16 registers, r0 always holds zero. Memory is
+// byte addressable and words are stored big-endian, word accesses must be
+// aligned. Input and output are done one byte at a time with getc and putc.
+package moon
+
+const (
+	WORD_SIZE = 4
+
+	// Default amount of memory available to a program, in bytes
+	DEFAULT_MEMORY_SIZE = 1 << 20
+
+	// Default number of instructions that a program may execute before it is
+	// killed. Use a negative number for no limit
+	DEFAULT_MAX_STEPS = 50_000_000
+)
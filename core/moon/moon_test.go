@@ -0,0 +1,206 @@
+package moon
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/internal/testutils"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		src    string
+		input  string
+		output string
+	}{
+		{
+			name: "hello world",
+			src: `
+			        entry
+			        addi    r1, r0, msg
+			loop    lb      r2, 0(r1)
+			        bz      r2, done
+			        putc    r2
+			        addi    r1, r1, 1
+			        j       loop
+			done    hlt
+			msg     db      "hello", 10, 0
+			`,
+			output: "hello\n",
+		},
+		{
+			name: "echo input until end of input",
+			src: `
+			        entry
+			loop    getc    r1
+			        bz      r1, done
+			        putc    r1
+			        j       loop
+			done    hlt
+			`,
+			input:  "abc",
+			output: "abc",
+		},
+		{
+			name: "words are big-endian",
+			src: `
+			        entry
+			        addi    r1, r0, 16961 % 0x4241, 'B' followed by 'A'
+			        sw      buf(r0), r1
+			        addi    r3, r0, buf
+			        lb      r2, 2(r3)
+			        putc    r2
+			        lb      r2, 3(r3)
+			        putc    r2
+			        hlt
+			buf     dw      0
+			`,
+			output: "BA",
+		},
+		{
+			name: "r0 is always zero",
+			src: `
+			        entry
+			        addi    r0, r0, 5
+			        addi    r1, r0, 48
+			        putc    r1
+			        hlt
+			`,
+			output: "0",
+		},
+		{
+			name: "subroutine calls and branches",
+			src: `
+			        entry
+			        addi    r1, r0, 65
+			loop    jl      r15, out
+			        addi    r1, r1, 1
+			        clti    r2, r1, 70
+			        bnz     r2, loop
+			        hlt
+			out     putc    r1
+			        jr      r15
+			`,
+			output: "ABCDE",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out := new(bytes.Buffer)
+			src := testutils.TrimLeading(tc.src, "\t\t\t")
+			if err := Run(strings.NewReader(src), strings.NewReader(tc.input), out); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if actual := out.String(); actual != tc.output {
+				t.Errorf("Expected output %q but got %q", tc.output, actual)
+			}
+		})
+	}
+}
+
+func TestAssemble_Errors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{
+			name: "undefined symbol",
+			src:  "        j       nowhere",
+			msg:  "undefined symbol 'nowhere'",
+		},
+		{
+			name: "unknown operation",
+			src:  "        frob    r1, r2",
+			msg:  "unknown operation 'frob'",
+		},
+		{
+			name: "invalid register",
+			src:  "        add     r1, r2, r16",
+			msg:  "invalid register 'r16'",
+		},
+		{
+			name: "duplicate label",
+			src:  "a       nop\na       nop",
+			msg:  "duplicate label 'a'",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Assemble(strings.NewReader(tc.src))
+			var asmErr *AssemblyError
+			if !errors.As(err, &asmErr) {
+				t.Fatalf("Expected an AssemblyError but got %v", err)
+			}
+			if asmErr.Msg != tc.msg {
+				t.Errorf("Expected error %q but got %q", tc.msg, asmErr.Msg)
+			}
+		})
+	}
+}
+
+func TestMachine_RuntimeErrors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{
+			name: "unaligned word access",
+			src:  "        lw      r1, 2(r0)\n        hlt",
+			msg:  "unaligned memory access at address 2",
+		},
+		{
+			name: "out of bounds",
+			src:  "        sw      -4(r0), r1\n        hlt",
+			msg:  "memory access out of bounds at address -4",
+		},
+		{
+			name: "division by zero",
+			src:  "        divi    r1, r1, 0\n        hlt",
+			msg:  "division by zero",
+		},
+		{
+			name: "running off the end of the program",
+			src:  "        nop",
+			msg:  "no instruction at this address",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := Run(strings.NewReader(tc.src), strings.NewReader(""), new(bytes.Buffer))
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) {
+				t.Fatalf("Expected a RuntimeError but got %v", err)
+			}
+			if runtimeErr.Msg != tc.msg {
+				t.Errorf("Expected error %q but got %q", tc.msg, runtimeErr.Msg)
+			}
+		})
+	}
+}
+
+func TestMachine_StepLimit(t *testing.T) {
+	t.Parallel()
+	prog, err := Assemble(strings.NewReader("loop    j       loop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMachine(prog, strings.NewReader(""), new(bytes.Buffer))
+	m.MaxSteps = 100
+	if err := m.Run(); err != ErrStepLimitExceeded {
+		t.Fatalf("Expected %v but got %v", ErrStepLimitExceeded, err)
+	}
+	if m.Steps != 100 {
+		t.Errorf("Expected the machine to stop after 100 steps but it ran %v", m.Steps)
+	}
+}
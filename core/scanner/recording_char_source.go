@@ -0,0 +1,51 @@
+package scanner
+
+import "strings"
+
+// A CharSource that remembers the characters read from it, so that the text
+// the scanner throws away (whitespace, and anything that it can't turn into a
+// token) can be recovered. Use RecordingCharSource.Take to obtain the text
+// that was read since the last call.
+//
+// A character that is backed up is forgotten. A scanner may also back up after
+// reading the end of the input, in which case no character is forgotten
+type RecordingCharSource struct {
+	CharSource
+	text []rune
+	eof  int // The number of times the end of the input was read and not backed up
+}
+
+func Recording(src CharSource) *RecordingCharSource {
+	return &RecordingCharSource{CharSource: src}
+}
+
+func (s *RecordingCharSource) NextChar() (rune, error) {
+	r, err := s.CharSource.NextChar()
+	if err != nil {
+		s.eof++
+	} else {
+		s.text = append(s.text, r)
+	}
+	return r, err
+}
+
+func (s *RecordingCharSource) BackupChar() (rune, error) {
+	r, err := s.CharSource.BackupChar()
+	switch {
+	case s.eof > 0:
+		s.eof--
+	case err == nil && len(s.text) > 0:
+		s.text = s.text[:len(s.text)-1]
+	}
+	return r, err
+}
+
+// Returns the text read since the last call to Take
+func (s *RecordingCharSource) Take() string {
+	var out strings.Builder
+	for _, r := range s.text {
+		out.WriteRune(r)
+	}
+	s.text = s.text[:0]
+	return out.String()
+}
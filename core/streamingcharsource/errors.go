@@ -0,0 +1,19 @@
+package streamingcharsource
+
+import "fmt"
+
+type StreamingError struct{ Err error }
+
+func (e *StreamingError) Error() string { return fmt.Sprintf("failed to stream: %v", e.Err) }
+func (e *StreamingError) Unwrap() error { return e.Err }
+
+type EndOfCharSourceError struct{ Err error }
+
+func (e *EndOfCharSourceError) Error() string { return fmt.Sprintf("no more chars: %v", e.Err) }
+func (e *EndOfCharSourceError) Unwrap() error { return e.Err }
+
+type BackupLimitError struct{ Depth int }
+
+func (e *BackupLimitError) Error() string {
+	return fmt.Sprintf("cannot back up more than %v chars", e.Depth)
+}
@@ -0,0 +1,124 @@
+package streamingcharsource
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// The number of characters that can be backed up by default. The scanner backs
+// up at most twice per token (see tabledrivenscanner.Table.NeedsDoubleBackup)
+const DEFAULT_DEPTH = 2
+
+// A character source that decodes an io.Reader as it is read, instead of
+// reading all of it into memory first. Assumes the io.Reader is UTF-8 encoded.
+//
+// The most recently read characters are kept in a ring buffer, so that a
+// bounded number of them can be backed up. The line and column of each of them
+// are kept alongside, so that backing up over a newline restores the column
+type StreamingCharSource struct {
+	reader *bufio.Reader
+	ring   []char
+	head   int // The slot of the ring that the next character is read into
+	size   int // The number of characters in the ring
+	backed int // The number of characters in the ring that have been backed up
+	line   int
+	column int
+}
+
+// A character, and the position that the source was at before reading it
+type char struct {
+	r      rune
+	line   int
+	column int
+}
+
+// Streams the reader, allowing DEFAULT_DEPTH characters to be backed up
+func StreamingReader(reader io.Reader) *StreamingCharSource {
+	return StreamingReaderDepth(reader, DEFAULT_DEPTH)
+}
+
+// Streams the reader, allowing depth characters to be backed up
+func StreamingReaderDepth(reader io.Reader, depth int) *StreamingCharSource {
+	if depth < 1 {
+		depth = 1
+	}
+	return &StreamingCharSource{
+		reader: bufio.NewReader(reader),
+		ring:   make([]char, depth),
+		line:   1,
+		column: 1,
+	}
+}
+
+// Reads the next character in the input
+func (s *StreamingCharSource) NextChar() (rune, error) {
+	// Characters that have been backed up are read again from the ring
+	if s.backed > 0 {
+		c := s.ring[s.slot(s.backed)]
+		s.backed--
+		s.count(c.r)
+		return c.r, nil
+	}
+
+	r, size, err := s.reader.ReadRune()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, &EndOfCharSourceError{err}
+		}
+		return 0, &StreamingError{err}
+	}
+	if r == utf8.RuneError && size == 1 {
+		return 0, fmt.Errorf("StreamingCharSource: RuneError from utf8 lib")
+	}
+
+	s.ring[s.head] = char{r, s.line, s.column}
+	s.head = (s.head + 1) % len(s.ring)
+	if s.size < len(s.ring) {
+		s.size++
+	}
+	s.count(r)
+	return r, nil
+}
+
+// Back up one character in the input in case we have just read the next
+// character in order to resolve ambiguity. Fails if the characters that are
+// still in the ring have all been backed up
+func (s *StreamingCharSource) BackupChar() (rune, error) {
+	if s.backed == s.size {
+		if s.size < len(s.ring) {
+			return 0, &EndOfCharSourceError{io.EOF}
+		}
+		return 0, &BackupLimitError{len(s.ring)}
+	}
+	s.backed++
+	c := s.ring[s.slot(s.backed)]
+	s.line, s.column = c.line, c.column
+	return c.r, nil
+}
+
+// Reports the current line number
+func (s *StreamingCharSource) Line() int {
+	return s.line
+}
+
+// Reports the current column number
+func (s *StreamingCharSource) Column() int {
+	return s.column
+}
+
+// The slot of the ring holding the nth most recently read character
+func (s *StreamingCharSource) slot(n int) int {
+	return (s.head - n + len(s.ring)) % len(s.ring)
+}
+
+func (s *StreamingCharSource) count(r rune) {
+	if r == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+}
@@ -0,0 +1,140 @@
+package streamingcharsource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+)
+
+const abcdefg = "abcdefg"
+
+func TestNextChar(t *testing.T) {
+	t.Parallel()
+	src := StreamingReader(strings.NewReader(abcdefg))
+	for _, expected := range abcdefg {
+		actual, err := src.NextChar()
+		if err != nil || actual != expected {
+			t.Fatalf(
+				"Expected src.NextChar() to return '%s' but got '%s'",
+				string(expected), string(actual))
+		}
+	}
+	if _, err := src.NextChar(); !errors.As(err, new(*EndOfCharSourceError)) {
+		t.Fatalf("Expected an EndOfCharSourceError but got '%v'", err)
+	}
+}
+
+func TestBackupChar(t *testing.T) {
+	t.Parallel()
+	src := StreamingReaderDepth(strings.NewReader(abcdefg), 3)
+	for range abcdefg {
+		if _, err := src.NextChar(); err != nil {
+			t.Fatalf("Failed to grab next char: %v", err)
+		}
+	}
+
+	// Only the last 3 chars can be backed up
+	for _, expected := range "gfe" {
+		if actual, err := src.BackupChar(); err != nil {
+			t.Fatalf("Should have been able to back up here: %v", err)
+		} else if actual != expected {
+			t.Fatalf("Expected to read '%v' but got '%v'", string(expected), string(actual))
+		}
+	}
+	if _, err := src.BackupChar(); !errors.As(err, new(*BackupLimitError)) {
+		t.Fatalf("Expected a BackupLimitError but got '%v'", err)
+	}
+
+	// The chars are read again from the ring
+	for _, expected := range "efg" {
+		if actual, err := src.NextChar(); err != nil || actual != expected {
+			t.Fatalf("Expected to read '%v' but got '%v' (%v)", string(expected), string(actual), err)
+		}
+	}
+}
+
+func TestBackupCharEndOfFile(t *testing.T) {
+	t.Parallel()
+	src := StreamingReader(strings.NewReader(""))
+	if _, err := src.BackupChar(); !errors.As(err, new(*EndOfCharSourceError)) {
+		t.Fatalf("Expected an EndOfCharSourceError but got '%v'", err)
+	}
+}
+
+func TestLineAndColumn(t *testing.T) {
+	t.Parallel()
+	src := StreamingReader(strings.NewReader("ab\ncd"))
+	type position struct{ line, column int }
+	expected := []position{{1, 2}, {1, 3}, {2, 1}, {2, 2}, {2, 3}}
+	for _, e := range expected {
+		src.NextChar()
+		if actual := (position{src.Line(), src.Column()}); actual != e {
+			t.Fatalf("Expected %+v but got %+v", e, actual)
+		}
+	}
+
+	// Backing up over the newline restores the column of the previous line
+	src.BackupChar()
+	src.BackupChar()
+	if actual := (position{src.Line(), src.Column()}); actual != (position{2, 1}) {
+		t.Fatalf("Expected to be at the start of line 2 but got %+v", actual)
+	}
+	src = StreamingReader(strings.NewReader("ab\ncd"))
+	for i := 0; i < 3; i++ {
+		src.NextChar()
+	}
+	src.BackupChar()
+	if actual := (position{src.Line(), src.Column()}); actual != (position{1, 3}) {
+		t.Fatalf("Expected to be at the end of line 1 but got %+v", actual)
+	}
+}
+
+// The scanner should produce the same tokens from a stream as it does from a
+// chugged source, even when the reader hands out one byte at a time
+func TestScannerMatchesChugging(t *testing.T) {
+	t.Parallel()
+	files, err := filepath.Glob("../../resources/src/*.src")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to find test sources: %v", err)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := scanAll(chuggingcharsource.MustChuggingReader(strings.NewReader(string(data))))
+			actual := scanAll(StreamingReader(iotest.OneByteReader(strings.NewReader(string(data)))))
+			if len(expected) != len(actual) {
+				t.Fatalf("Expected %v tokens but got %v", len(expected), len(actual))
+			}
+			for i := range expected {
+				if expected[i] != actual[i] {
+					t.Fatalf("Expected token %v to be %v but got %v", i, expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+func scanAll(src scanner.CharSource) []token.Token {
+	var tokens []token.Token
+	scnr := compositetable.NewTableDrivenScanner(src)
+	for {
+		tok, err := scnr.NextToken()
+		if err != nil {
+			return tokens
+		}
+		tokens = append(tokens, tok)
+	}
+}
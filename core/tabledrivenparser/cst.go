@@ -0,0 +1,204 @@
+package tabledrivenparser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/token"
+)
+
+// Enables the lossless CST mode. The scanner of the parser must read its
+// characters from src. While parsing, the CST is built from the derivation
+// that the parser emits (the same rules that it gives to rulec) and the tokens
+// that it matches, and the text between the tokens is recovered from src. Call
+// TableDrivenParser.CST after parsing to obtain it.
+//
+// The comments that a CommentlessScanner filters are kept in the CST as
+// trivia, and so are the tokens that the parser skips while recovering from a
+// syntax error. Printing the CST always gives back the exact source, even if
+// the parse fails
+func (t *TableDrivenParser) RecordCST(src *scanner.RecordingCharSource) {
+	trivia := &triviaScanner{src: src}
+	if comments, ok := t.scnr.(*scanner.CommentlessScanner); ok {
+		trivia.Scanner = comments.Scanner
+		trivia.comments = comments.Ignoring
+		comments.Scanner = trivia
+	} else {
+		trivia.Scanner = t.scnr
+		t.scnr = trivia
+	}
+	root := &token.CSTNode{Kind: t.table.Start()}
+	t.cst = &cstBuilder{
+		trivia: trivia,
+		isSym:  func(k token.Kind) bool { return !t.isSemAction(k) && k != token.EPSILON },
+		root:   root,
+		stack:  []*token.CSTNode{root},
+	}
+}
+
+// Returns the CST built by the parser, or an empty CST if RecordCST was not
+// called. The rest of the source is read, so that it can be attached to the
+// CST as trivia
+func (t *TableDrivenParser) CST() token.CST {
+	if t.cst == nil {
+		return token.CST{}
+	}
+	return token.CST{Root: t.cst.root, Trailing: t.cst.trivia.rest()}
+}
+
+// Mirrors the grammar symbols on the stack of the parser with the nodes of
+// the CST that they will fill
+type cstBuilder struct {
+	trivia *triviaScanner
+	isSym  func(k token.Kind) bool // Excludes the semantic actions
+	root   *token.CSTNode
+	stack  []*token.CSTNode
+}
+
+// Adds the children of the nonterminal expanded by the rule
+func (b *cstBuilder) expand(rule token.Rule) {
+	n := b.find(rule.LHS)
+	if n == nil {
+		return
+	}
+	r := rule
+	n.Rule = &r
+	for _, k := range rule.RHS {
+		if b.isSym(k) {
+			n.Children = append(n.Children, &token.CSTNode{Kind: k})
+		}
+	}
+	for i := len(n.Children) - 1; i >= 0; i-- {
+		b.stack = append(b.stack, n.Children[i])
+	}
+}
+
+// Fills the terminal matched by the token
+func (b *cstBuilder) match(tok token.Token) {
+	n := b.find(tok.Id)
+	if n == nil {
+		return
+	}
+	n.Token = &tok
+	n.Text, n.Leading = b.trivia.take(tok)
+}
+
+// Pops the nodes of the symbols that the parser discarded without matching
+// or expanding them, up to the node of the symbol on top of the parser's
+// stack. Returns nil if there is no such node
+func (b *cstBuilder) find(k token.Kind) *token.CSTNode {
+	for i := len(b.stack) - 1; i >= 0; i-- {
+		if n := b.stack[i]; n.Kind == k {
+			b.stack = b.stack[:i]
+			return n
+		}
+	}
+	return nil
+}
+
+// A token read from the scanner along with the text that precedes it
+type scannedToken struct {
+	tok     token.Token
+	text    string
+	leading []token.Trivia
+}
+
+// Sits beneath the CommentlessScanner of the parser, and records the text of
+// every token along with the trivia that precedes it
+type triviaScanner struct {
+	scanner.Scanner
+	src      *scanner.RecordingCharSource
+	comments []token.Kind
+	done     bool
+
+	pending []token.Trivia // Trivia that has not been attached to a token yet
+	tokens  []scannedToken // Tokens that have not been matched yet
+}
+
+func (s *triviaScanner) NextToken() (token.Token, error) {
+	tok, err := s.Scanner.NextToken()
+	text := s.src.Take()
+	if err != nil {
+		s.done = true
+		s.pending = append(s.pending, splitTrivia(text)...)
+		return tok, err
+	}
+
+	// The lexeme ends the text that was read, the rest is whitespace
+	lexeme := string(tok.Lexeme)
+	if !strings.HasSuffix(text, lexeme) {
+		lexeme = ""
+	}
+	s.pending = append(s.pending, splitTrivia(text[:len(text)-len(lexeme)])...)
+	if s.isComment(tok.Id) {
+		s.pending = append(s.pending, token.Trivia{Kind: tok.Id, Text: lexeme})
+		return tok, err
+	}
+	s.tokens = append(s.tokens, scannedToken{tok: tok, text: lexeme, leading: s.pending})
+	s.pending = nil
+	return tok, err
+}
+
+// Returns the text and leading trivia of a matched token. The tokens that were
+// read before it and never matched were skipped by the parser, they become
+// trivia. A token that the parser made up has no text and no trivia
+func (s *triviaScanner) take(tok token.Token) (string, []token.Trivia) {
+	for i, scanned := range s.tokens {
+		if scanned.tok != tok {
+			continue
+		}
+		leading := skipped(s.tokens[:i])
+		leading = append(leading, scanned.leading...)
+		s.tokens = s.tokens[i+1:]
+		return scanned.text, leading
+	}
+	return "", nil
+}
+
+// Reads the rest of the source, and returns all the trivia and tokens that
+// have not been attached to the CST
+func (s *triviaScanner) rest() []token.Trivia {
+	for !s.done {
+		s.NextToken()
+	}
+	trailing := append(skipped(s.tokens), s.pending...)
+	s.tokens, s.pending = nil, nil
+	return trailing
+}
+
+func (s *triviaScanner) isComment(k token.Kind) bool {
+	for _, c := range s.comments {
+		if c == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Turns tokens that were skipped by the parser into trivia
+func skipped(tokens []scannedToken) []token.Trivia {
+	trivia := make([]token.Trivia, 0, 2*len(tokens))
+	for _, scanned := range tokens {
+		trivia = append(trivia, scanned.leading...)
+		trivia = append(trivia, token.Trivia{Kind: scanned.tok.Id, Text: scanned.text})
+	}
+	return trivia
+}
+
+// Splits text that the scanner did not turn into a token into the whitespace
+// that it starts with, and the rest
+func splitTrivia(text string) []token.Trivia {
+	trivia := make([]token.Trivia, 0, 2)
+	end := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsSpace(r) })
+	if end < 0 {
+		end = len(text)
+	}
+	if end > 0 {
+		trivia = append(trivia, token.Trivia{Kind: token.TRIVIA_WHITESPACE, Text: text[:end]})
+	}
+	if end < len(text) {
+		trivia = append(trivia, token.Trivia{Kind: token.TRIVIA_UNSCANNED, Text: text[end:]})
+	}
+	return trivia
+}
@@ -20,13 +20,13 @@ func (e *NoRuleError) Error() string {
 }
 
 type UnterminatedSentence struct {
-	expectedSymbols []token.Kind
+	ExpectedSymbols []token.Kind
 }
 
 func (e *UnterminatedSentence) Error() string {
 	return fmt.Sprintf(
 		"got EOF in the middle of a sentence, expected to find symbols for %v",
-		e.expectedSymbols)
+		e.ExpectedSymbols)
 }
 
 type ParserError struct {
@@ -86,6 +86,36 @@ func (e *UnexpectedTokenError) Unwrap() error {
 	return e.Err
 }
 
+// A syntax error that the parser repaired by inserting or deleting tokens. The
+// parse continues as if the source had contained the repaired tokens
+type RepairError struct {
+	Err      *UnexpectedTokenError // The syntax error that was repaired
+	Inserted []token.Token         // The tokens inserted in front of Err.Token
+	Deleted  []token.Token         // The tokens deleted, starting at Err.Token
+}
+
+func (e *RepairError) Error() string {
+	fix := func(verb string, toks []token.Token) string {
+		s := make([]string, 0, len(toks))
+		for _, tok := range toks {
+			if tok.Lexeme == "" {
+				s = append(s, fmt.Sprintf("'%v'", tok.Id))
+			} else {
+				s = append(s, fmt.Sprintf("'%v'", tok.Lexeme))
+			}
+		}
+		return fmt.Sprintf("%v %v", verb, strings.Join(s, " "))
+	}
+	if len(e.Inserted) > 0 {
+		return fmt.Sprintf("%v: %v", e.Err, fix("inserted", e.Inserted))
+	}
+	return fmt.Sprintf("%v: %v", e.Err, fix("deleted", e.Deleted))
+}
+
+func (e *RepairError) Unwrap() error {
+	return e.Err
+}
+
 // Use for determining possible expected tokens, parser tables may return errors
 // that implement this interface, in which case the TableDrivenParser may use
 // the LookupPossibilities.Possibilities() method to ascertain more information
@@ -0,0 +1,141 @@
+package tabledrivenparser
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// Pops the symbol on top of the stack that skipErrors3 is discarding, and
+// completes it with a placeholder so that the semantic stack stays consistent
+func (t *TableDrivenParser) skip(lookahead token.Token) {
+	t.complete(t.pop(), lookahead)
+}
+
+// Pops the rest of the stack once the parse has stopped early, completing
+// every symbol with a placeholder. Nonterminals that have EPSILON rules derive
+// the empty sentence, executing the semantic actions of their EPSILON rule
+func (t *TableDrivenParser) completeStack() {
+	for !t.empty() {
+		t.complete(t.pop(), t.prev)
+	}
+}
+
+// Executes the semantic actions of the symbol as if it had been parsed from
+// the cheapest sentence that it derives (see placeholderRules). The sentence is
+// made of synthetic tokens placed at the position of the token `at`, the leaves
+// made from them become FINAL_ERROR nodes
+func (t *TableDrivenParser) complete(x token.Kind, at token.Token) {
+	switch {
+	case x == "":
+		return
+	case t.isSemAction(x):
+		t.executeSemanticAction(x, t.prev)
+	case t.table.IsTerminal(x):
+		tok := insertedToken(x, at)
+		t.addSynthetic(tok)
+		t.prev = tok
+	default:
+		if r, ok := t.placeholderRules()[x]; ok {
+			for _, k := range r.RHS {
+				t.complete(k, at)
+			}
+		}
+	}
+}
+
+// Picks the rule of every nonterminal that derives the cheapest sentence.
+// Identifiers and literals cost 1 and other tokens cost 2, so that
+// placeholders are made of FINAL_ERROR leaves where possible. The rules of a
+// nonterminal are found by looking it up with the terminals of its FIRST and
+// FOLLOW sets, and nonterminals that have EPSILON rules may always derive the
+// empty sentence
+func (t *TableDrivenParser) placeholderRules() map[token.Kind]token.Rule {
+	if t.cheapest != nil {
+		return t.cheapest
+	}
+
+	// Collect the rules of the nonterminals that are reachable from the start
+	rules := make(map[token.Kind][]token.Rule, 256)
+	order := make([]token.Kind, 0, 256)
+	for queue := []token.Kind{t.table.Start()}; len(queue) > 0; queue = queue[1:] {
+		a := queue[0]
+		if _, seen := rules[a]; seen {
+			continue
+		}
+		rules[a] = []token.Rule{}
+		order = append(order, a)
+
+		seen := make(map[string]struct{}, 8)
+		for _, k := range sortedSet(union(t.first(a), t.follow(a))) {
+			r, err := t.table.Lookup(a, k)
+			if k == token.EPSILON || err != nil {
+				continue
+			}
+			if _, ok := seen[fmt.Sprint(r.RHS)]; ok {
+				continue
+			}
+			seen[fmt.Sprint(r.RHS)] = struct{}{}
+			rules[a] = append(rules[a], r)
+			for _, s := range r.RHS {
+				if t.table.IsNonterminal(s) {
+					queue = append(queue, s)
+				}
+			}
+		}
+
+		// The EPSILON rule is missing from the table if the nonterminal is
+		// only followed by the end of the input, where it is popped without
+		// executing its semantic actions
+		if t.table.HasEpsilonRule(a) {
+			rules[a] = append(rules[a], token.Rule{LHS: a})
+		}
+	}
+
+	// Find the cheapest rules by fixpoint, a rule only replaces another if it
+	// is strictly cheaper, so the chosen rules can't derive themselves
+	costs := make(map[token.Kind]int, len(rules))
+	for a := range rules {
+		costs[a] = math.MaxInt32
+	}
+	cost := func(r token.Rule) int {
+		c := 0
+		for _, k := range r.RHS {
+			switch {
+			case t.isSemAction(k):
+			case t.table.IsNonterminal(k):
+				c += costs[k]
+			default:
+				if _, ok := token.Spelling(k); ok {
+					c += 2
+				} else {
+					c++
+				}
+			}
+		}
+		return c
+	}
+	t.cheapest = make(map[token.Kind]token.Rule, len(rules))
+	for changed := true; changed; {
+		changed = false
+		for _, a := range order {
+			for _, r := range rules[a] {
+				if c := cost(r); c < costs[a] {
+					costs[a] = c
+					t.cheapest[a] = r
+					changed = true
+				}
+			}
+		}
+	}
+	return t.cheapest
+}
+
+func sortedSet(set token.KindSet) []token.Kind {
+	kinds := make([]token.Kind, 0, len(set))
+	for k := range set {
+		kinds = append(kinds, k)
+	}
+	return sorted(kinds)
+}
@@ -0,0 +1,235 @@
+package tabledrivenparser
+
+import (
+	"sort"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// If repairs cause problems, disable them here and the parser goes straight to
+// skipErrors3 on every syntax error
+var REPAIR_ENABLED = true
+
+const (
+	// The number of tokens following a repair that must parse for the repair
+	// to be accepted
+	REPAIR_WINDOW = 3
+
+	// The most tokens that a repair may delete
+	REPAIR_MAX_DELETIONS = 2
+)
+
+// A candidate repair: insert a token in front of the lookahead, or delete a
+// number of tokens starting at the lookahead
+type repairCandidate struct {
+	insert  token.Kind
+	deletes int
+}
+
+// Tries to repair the syntax error by inserting a single token in front of
+// the lookahead, or by deleting up to REPAIR_MAX_DELETIONS tokens starting at
+// the lookahead. The tokens that may be inserted are the terminal on top of
+// the stack, or the FIRST set of the nonterminal on top of the stack (and its
+// FOLLOW set if it can derive EPSILON). A repair is only accepted if the next
+// REPAIR_WINDOW tokens of the input parse after it.
+//
+// Cheaper repairs are tried first: inserting the terminal on top of the stack,
+// then deleting one token, then inserting the other candidates, then deleting
+// more tokens. Tokens that have no fixed spelling (identifiers and literals)
+// are inserted last. The leaves that the semantic actions make from inserted
+// tokens become FINAL_ERROR nodes.
+//
+// On success, the RepairError is emitted and the new lookahead is returned
+func (t *TableDrivenParser) repair(cause *UnexpectedTokenError) (token.Token, bool) {
+	if !REPAIR_ENABLED {
+		return cause.Token, false
+	}
+
+	lookahead := cause.Token
+	ahead, end := t.peek(REPAIR_WINDOW + REPAIR_MAX_DELETIONS)
+	input := append([]token.Token{lookahead}, ahead...)
+
+	for _, c := range t.repairCandidates() {
+		if c.insert != "" {
+			inserted := insertedToken(c.insert, lookahead)
+			tokens, ends := window(input, end)
+			if !t.accepts(append([]token.Token{inserted}, tokens...), ends) {
+				continue
+			}
+			t.addSynthetic(inserted)
+			t.pending = append([]token.Token{lookahead}, t.pending...)
+			t.emitError(&RepairError{Err: cause, Inserted: []token.Token{inserted}}, lookahead)
+			return inserted, true
+		}
+
+		// A deletion must leave a token to continue from
+		if c.deletes >= len(input) {
+			continue
+		}
+		if tokens, ends := window(input[c.deletes:], end); !t.accepts(tokens, ends) {
+			continue
+		}
+		t.pending = t.pending[c.deletes:]
+		t.emitError(&RepairError{Err: cause, Deleted: input[:c.deletes]}, lookahead)
+		return input[c.deletes], true
+	}
+	return lookahead, false
+}
+
+// Lists the repairs to try, cheapest first
+func (t *TableDrivenParser) repairCandidates() []repairCandidate {
+	candidates := make([]repairCandidate, 0, 32)
+	x := t.top()
+	if t.table.IsTerminal(x) {
+		candidates = append(candidates, repairCandidate{insert: x})
+	}
+	candidates = append(candidates, repairCandidate{deletes: 1})
+
+	if !t.table.IsTerminal(x) {
+		expected := t.first(x)
+		if contains(expected, token.EPSILON) {
+			expected = union(expected, t.follow(x))
+		}
+		var spelled, unspelled []token.Kind
+		for k := range expected {
+			if k == token.EPSILON {
+				continue
+			}
+			if _, ok := token.Spelling(k); ok {
+				spelled = append(spelled, k)
+			} else {
+				unspelled = append(unspelled, k)
+			}
+		}
+		for _, ks := range [][]token.Kind{sorted(spelled), sorted(unspelled)} {
+			for _, k := range ks {
+				candidates = append(candidates, repairCandidate{insert: k})
+			}
+		}
+	}
+
+	for n := 2; n <= REPAIR_MAX_DELETIONS; n++ {
+		candidates = append(candidates, repairCandidate{deletes: n})
+	}
+	return candidates
+}
+
+// Checks whether the tokens parse from the current stack, without modifying
+// the stack or executing any semantic actions. If end is true, then the input
+// ends after the tokens and the rest of the stack must be able to derive
+// EPSILON
+func (t *TableDrivenParser) accepts(tokens []token.Token, end bool) bool {
+	stack := make([]token.Kind, len(t.stack), len(t.stack)+64)
+	copy(stack, t.stack)
+	pop := func() { stack = stack[:len(stack)-1] }
+
+	for i := 0; i < len(tokens); {
+		if len(stack) == 0 {
+			return false
+		}
+		x := stack[len(stack)-1]
+		switch {
+		case t.isSemAction(x):
+			pop()
+		case t.table.IsTerminal(x):
+			if x != tokens[i].Id {
+				return false
+			}
+			pop()
+			i++
+		default:
+			l, err := t.table.Lookup(x, tokens[i].Id)
+			if err != nil {
+				return false
+			}
+			pop()
+			for j := len(l.RHS) - 1; j >= 0; j-- {
+				stack = append(stack, l.RHS[j])
+			}
+		}
+	}
+
+	if !end {
+		return true
+	}
+	for _, x := range stack {
+		if !t.isSemAction(x) && !t.table.HasEpsilonRule(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reads ahead up to n tokens past the lookahead without consuming them,
+// returns true if the input ends after the returned tokens
+func (t *TableDrivenParser) peek(n int) ([]token.Token, bool) {
+	for len(t.pending) < n && t.pendingErr == nil {
+		tok, err := t.scnr.NextToken()
+		if err != nil {
+			t.pendingErr = err
+			break
+		}
+		t.pending = append(t.pending, tok)
+	}
+	if len(t.pending) < n {
+		return t.pending, true
+	}
+	return t.pending[:n], false
+}
+
+// Returns the next token, either one that was read ahead by a repair or a new
+// one from the scanner
+func (t *TableDrivenParser) next() (token.Token, error) {
+	if len(t.pending) > 0 {
+		tok := t.pending[0]
+		t.pending = t.pending[1:]
+		return tok, nil
+	}
+	if t.pendingErr != nil {
+		return token.Token{}, t.pendingErr
+	}
+	return t.scnr.NextToken()
+}
+
+// Remembers the leaf that a semantic action made from a made up token, it
+// does not come from the source and becomes a FINAL_ERROR node
+func (t *TableDrivenParser) markErrorLeaf(prev token.Token) {
+	if _, ok := t.synthetic[prev]; !ok || len(t.semStack) == 0 {
+		return
+	}
+	top := t.semStack[len(t.semStack)-1]
+	if top.Token != prev || len(top.Children) > 0 {
+		return
+	}
+	if l := len(t.errorNodes); l == 0 || t.errorNodes[l-1] != top {
+		t.errorNodes = append(t.errorNodes, top)
+	}
+}
+
+func (t *TableDrivenParser) addSynthetic(tok token.Token) {
+	if t.synthetic == nil {
+		t.synthetic = make(map[token.Token]struct{})
+	}
+	t.synthetic[tok] = struct{}{}
+}
+
+// Creates a token for a repair, placed at the position of the token that it
+// is inserted in front of
+func insertedToken(kind token.Kind, at token.Token) token.Token {
+	lexeme, _ := token.Spelling(kind)
+	return token.Token{Id: kind, Lexeme: lexeme, Line: at.Line, Column: at.Column}
+}
+
+// Cuts the input down to the tokens that a repair is checked against. The
+// input only ends after the window if it isn't cut
+func window(input []token.Token, end bool) ([]token.Token, bool) {
+	if len(input) > REPAIR_WINDOW {
+		return input[:REPAIR_WINDOW], false
+	}
+	return input, end
+}
+
+func sorted(kinds []token.Kind) []token.Kind {
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
@@ -26,6 +26,22 @@ type TableDrivenParser struct {
 	ast token.AST // Intermediate Representation created by the parser
 	err error     // An error registered by the parser
 
+	// Set if a semantic action fails after a syntax error, the semantic stack
+	// can't be used after that
+	abandoned bool
+
+	prev       token.Token   // The last token matched, given to the semantic actions
+	pending    []token.Token // Tokens read ahead of the lookahead by a repair
+	pendingErr error         // The error that ended the read ahead, if any
+
+	// Tokens that the parser made up to repair or recover from syntax errors,
+	// and the leaves that the semantic actions made from them
+	synthetic  map[token.Token]struct{}
+	errorNodes []*token.ASTNode
+	cheapest   map[token.Kind]token.Rule // See placeholderRules
+
+	cst *cstBuilder // Only set in the lossless CST mode, see RecordCST
+
 	stack    []token.Kind     // Nonterminal stack
 	semStack []*token.ASTNode // Semantic stack
 
@@ -90,21 +106,37 @@ func NewParserNoDefaultComments(
 	return NewParserNoComments(scnr, table, errc, rulec, token.Comments()...)
 }
 
+// Returns the AST built by the semantic actions. After syntax errors, the AST
+// is partial: the leaves made from tokens that the parser made up are
+// FINAL_ERROR nodes, and the declarations and statements that contain them are
+// wrapped in FINAL_ERROR nodes (see token.WrapErrors). If the semantic actions
+// could not build a tree, the root is a FINAL_ERROR node holding whatever was
+// left on the semantic stack
 func (t *TableDrivenParser) AST() token.AST {
-	if t.err != nil {
-		return token.AST{}
-	}
-
 	if t.ast.Root != nil {
 		return t.ast
 	}
 
 	l := len(t.semStack)
-	if l != 1 {
-		panic(fmt.Errorf("stack should be [PROG] but got %v", t.semStack))
+	if l == 0 && t.err == nil {
+		return t.ast // The grammar has no semantic actions, there is no tree
+	}
+	if l != 1 || t.abandoned {
+		if t.err == nil {
+			panic(fmt.Errorf("stack should be [PROG] but got %v", t.semStack))
+		}
+		t.ast.Root = &token.ASTNode{Type: token.FINAL_ERROR, Children: t.semStack}
+		return t.ast
 	}
 	top := t.semStack[l-1]
 	t.ast.Root = top
+
+	// Retype the nodes last, so that the semantic actions see the types they
+	// expect while the tree is being built
+	for _, n := range t.errorNodes {
+		n.Type = token.FINAL_ERROR
+	}
+	token.WrapErrors(t.ast.Root)
 	return t.ast
 }
 
@@ -117,53 +149,67 @@ func (t *TableDrivenParser) Parse() bool {
 	}
 
 	t.push(t.table.Start())
-	a, err := t.scnr.NextToken()
+	a, err := t.next()
 	if err != nil {
 		t.err = err
 		t.emitError(t.err, token.Token{})
 		return false
 	}
 
-	for prev := a; !t.empty(); {
+	eof := false
+	for t.prev = a; !t.empty(); {
 		x := t.top()
 
 		// Check semantic action first
 		if t.isSemAction(x) {
-			t.executeSemanticAction(x, prev)
+			t.executeSemanticAction(x, t.prev)
 			t.pop()
 
 		} else if t.table.IsTerminal(x) { // Check terminals second
 			if x == a.Id {
 				t.pop()
-				prev = a
-				a, err = t.scnr.NextToken()
+				t.prev = a
+				if t.cst != nil {
+					t.cst.match(a)
+				}
+				a, err = t.next()
 				if err != nil {
+					eof = true
+
 					// This error will probably be EOF, in any case we can't
 					// continue with no tokens. EOF does not need to be
 					// registered on the parser, eat the error
 					if errors.Is(err, io.EOF) {
+						// If the input ends in the middle of a construct, the
+						// rest of the stack is left for the placeholders
+						if !t.nullable() {
+							t.emitError(t.unterminatedSentenceError(), t.prev)
+							break
+						}
+
 						// Pop stack symbols that have EPSILON rules, and
 						// process remaining semantic actions
 						for !t.empty() {
-							x = t.top()
-							if t.isSemAction(x) {
-								t.executeSemanticAction(x, prev)
-							} else if !t.table.HasEpsilonRule(x) {
-								t.emitError(t.unterminatedSentenceError(), prev)
-								break
+							if x = t.pop(); t.isSemAction(x) {
+								t.executeSemanticAction(x, t.prev)
 							}
-							t.pop()
 						}
 					} else {
-						t.emitError(err, prev)
+						t.emitError(err, t.prev)
 					}
 					break
 				}
 			} else {
-				t.emitError(&UnexpectedTokenError{Token: a, Instead: x}, a)
+				e := &UnexpectedTokenError{Token: a, Instead: x}
+				if repaired, ok := t.repair(e); ok {
+					a = repaired
+					continue
+				}
+				t.emitError(e, a)
 				aa, err := t.skipErrors3(a)
 				a = aa
 				if err != nil {
+					eof = true
 					break
 				}
 			}
@@ -178,16 +224,28 @@ func (t *TableDrivenParser) Parse() bool {
 				if errr, ok := err.(LookupPossibilities); ok {
 					e.InsteadSlice = errr.Possibilities()
 				}
+				if repaired, ok := t.repair(e); ok {
+					a = repaired
+					continue
+				}
 				t.emitError(e, a)
 				aa, err := t.skipErrors3(a)
 				a = aa
 				if err != nil {
+					eof = true
 					break
 				}
 			}
 		}
 	}
 
+	// The start symbol has been derived, the input must end here
+	if !eof && t.empty() {
+		t.emitError(&UnexpectedTokenError{Token: a}, a)
+	}
+
+	// The parse stopped early, finish the semantic stack with placeholders
+	t.completeStack()
 	return t.err == nil && t.empty()
 }
 
@@ -207,7 +265,7 @@ func (t *TableDrivenParser) skipErrors3(lookahead token.Token) (token.Token, err
 	statementCloserEnabled := STATEMENT_CLOSER_ENABLED
 
 	if contains(t.follow(t.top()), lookahead.Id) || lookahead.Id == "" {
-		t.pop()
+		t.skip(lookahead)
 		return lookahead, nil
 	}
 
@@ -237,7 +295,7 @@ func (t *TableDrivenParser) skipErrors3(lookahead token.Token) (token.Token, err
 
 		// Check the pops
 		if contains(syncTokensPop, lookahead.Id) {
-			t.pop()
+			t.skip(lookahead)
 			break
 		}
 
@@ -253,13 +311,17 @@ func (t *TableDrivenParser) skipErrors3(lookahead token.Token) (token.Token, err
 					// 	"skipErrors() closing statement: expected %v", statement[i]),
 					// 	token.Token{})
 
-					t.pop()
+					t.skip(lookahead)
 				}
 				return lookahead, nil
 			}
 		}
 
-		l, err := t.scnr.NextToken()
+		l, err := t.next()
+		if errors.Is(err, io.EOF) {
+			t.emitError(t.unterminatedSentenceError(), lookahead)
+			return l, t.err
+		}
 		if err != nil {
 			t.emitError(fmt.Errorf("skipErrors() failed to scan: %w", err), lookahead)
 			return l, t.err
@@ -370,9 +432,10 @@ func (t *TableDrivenParser) isSemAction(symbol token.Kind) bool {
 }
 
 func (t *TableDrivenParser) executeSemanticAction(x token.Kind, previousToken token.Token) {
-	// If an error has been registered, just eat the action - the stack will be
-	// corrupted and an ast is not possible to be generated due to syntax errors
-	if t.err != nil {
+	// If the parser has skipped part of the input, just eat the action - the
+	// stack will be corrupted and an ast is not possible to be generated due
+	// to syntax errors
+	if t.abandoned {
 		return
 	}
 
@@ -384,6 +447,11 @@ func (t *TableDrivenParser) executeSemanticAction(x token.Kind, previousToken to
 	defer func() {
 		caught := recover()
 		if caught != nil && caught != errNoActionFound {
+			if t.err != nil {
+				// The placeholders did not fit, give up on the semantic stack
+				t.abandoned = true
+				return
+			}
 			switch caught.(type) {
 			case error, string:
 				panic(fmt.Errorf("action = %v, stack = %v", x, t.semStack))
@@ -394,6 +462,7 @@ func (t *TableDrivenParser) executeSemanticAction(x token.Kind, previousToken to
 
 	if ok {
 		action(&t.semStack, previousToken)
+		t.markErrorLeaf(previousToken)
 		return
 	}
 
@@ -412,6 +481,16 @@ func (t *TableDrivenParser) empty() bool {
 	return len(t.stack) == 0
 }
 
+// Returns true if the symbols left on the stack can all derive EPSILON
+func (t *TableDrivenParser) nullable() bool {
+	for _, x := range t.stack {
+		if !t.isSemAction(x) && !t.table.HasEpsilonRule(x) {
+			return false
+		}
+	}
+	return true
+}
+
 func (t *TableDrivenParser) emitError(err error, tok token.Token) {
 	t.err = err
 	if t.errc != nil {
@@ -424,19 +503,34 @@ func (t *TableDrivenParser) emitError(err error, tok token.Token) {
 }
 
 func (t *TableDrivenParser) emitRule(rule token.Rule) {
+	if t.cst != nil {
+		t.cst.expand(rule)
+	}
 	if t.rulec != nil {
 		t.rulec(rule)
 	}
 }
 
+// Reports the end of the input in the middle of a construct. The expected
+// symbols are the terminals that could have come next, as with the LR parser
 func (t *TableDrivenParser) unterminatedSentenceError() error {
-	remainingSymbols := make([]token.Kind, 0, len(t.stack))
+	expected := make(token.KindSet)
 	for i := len(t.stack) - 1; i >= 0; i-- {
-		top := t.stack[i]
-
-		remainingSymbols = append(remainingSymbols, top)
+		x := t.stack[i]
+		if t.isSemAction(x) {
+			continue
+		}
+		if t.table.IsTerminal(x) {
+			expected[x] = struct{}{}
+			break
+		}
+		expected = union(expected, t.first(x))
+		if !t.table.HasEpsilonRule(x) {
+			break
+		}
 	}
-	return &UnterminatedSentence{remainingSymbols}
+	delete(expected, token.EPSILON)
+	return &UnterminatedSentence{sortedSet(expected)}
 }
 
 func (t *TableDrivenParser) first(symbol token.Kind) token.KindSet {
@@ -0,0 +1,411 @@
+package compositetable
+
+//
+// CODEGEN - DO NOT MODIFY
+//
+// TOOL: generate/lexgen
+// SPEC: ../../../generate/lexical.lex
+//
+// This file was generated by a tool, it should not be modified by hand. Instead,
+// modify the spec file listed above and rerun the codegen tool.
+//
+
+import (
+	t "github.com/obonobo/esac/core/tabledrivenscanner"
+	"github.com/obonobo/esac/core/token"
+)
+
+// A factory function for creating the default implementation table that is used
+// by the ESAC compiler
+func TABLE() *CompositeTable {
+	return &CompositeTable{
+		Start:               t.START,
+		UnterminatedComment: t.UNTERMINATEDCOMMENT,
+
+		Transitions: map[Key]t.State{
+			{1, t.ANY}:    2,
+			{1, t.LETTER}: 21,
+			{1, '\x00'}:   1,
+			{1, '\t'}:     1,
+			{1, '\n'}:     1,
+			{1, '\r'}:     1,
+			{1, ' '}:      1,
+			{1, '!'}:      3,
+			{1, '"'}:      4,
+			{1, '&'}:      5,
+			{1, '('}:      6,
+			{1, ')'}:      7,
+			{1, '*'}:      8,
+			{1, '+'}:      9,
+			{1, ','}:      10,
+			{1, '-'}:      11,
+			{1, '.'}:      12,
+			{1, '/'}:      13,
+			{1, '0'}:      14,
+			{1, '1'}:      15,
+			{1, '2'}:      15,
+			{1, '3'}:      15,
+			{1, '4'}:      15,
+			{1, '5'}:      15,
+			{1, '6'}:      15,
+			{1, '7'}:      15,
+			{1, '8'}:      15,
+			{1, '9'}:      15,
+			{1, ':'}:      16,
+			{1, ';'}:      17,
+			{1, '<'}:      18,
+			{1, '='}:      19,
+			{1, '>'}:      20,
+			{1, '['}:      22,
+			{1, ']'}:      23,
+			{1, '_'}:      24,
+			{1, '{'}:      25,
+			{1, '|'}:      26,
+			{1, '}'}:      27,
+
+			{4, t.EOF}: 51,
+			{4, t.ANY}: 4,
+			{4, '\n'}:  51,
+			{4, '"'}:   28,
+			{4, '\\'}:  29,
+
+			{11, t.ANY}: 52,
+			{11, '>'}:   30,
+
+			{13, t.ANY}: 53,
+			{13, '*'}:   31,
+			{13, '/'}:   32,
+
+			{14, t.ANY}:    54,
+			{14, t.LETTER}: 24,
+			{14, '.'}:      33,
+			{14, '0'}:      34,
+			{14, '1'}:      34,
+			{14, '2'}:      34,
+			{14, '3'}:      34,
+			{14, '4'}:      34,
+			{14, '5'}:      34,
+			{14, '6'}:      34,
+			{14, '7'}:      34,
+			{14, '8'}:      34,
+			{14, '9'}:      34,
+			{14, '_'}:      24,
+
+			{15, t.ANY}:    54,
+			{15, t.LETTER}: 24,
+			{15, '.'}:      33,
+			{15, '0'}:      15,
+			{15, '1'}:      15,
+			{15, '2'}:      15,
+			{15, '3'}:      15,
+			{15, '4'}:      15,
+			{15, '5'}:      15,
+			{15, '6'}:      15,
+			{15, '7'}:      15,
+			{15, '8'}:      15,
+			{15, '9'}:      15,
+			{15, '_'}:      24,
+
+			{16, t.ANY}: 55,
+			{16, ':'}:   35,
+
+			{18, t.ANY}: 56,
+			{18, '='}:   36,
+			{18, '>'}:   37,
+
+			{19, t.ANY}: 57,
+			{19, '='}:   38,
+
+			{20, t.ANY}: 58,
+			{20, '='}:   39,
+
+			{21, t.ANY}:    59,
+			{21, t.LETTER}: 21,
+			{21, '0'}:      21,
+			{21, '1'}:      21,
+			{21, '2'}:      21,
+			{21, '3'}:      21,
+			{21, '4'}:      21,
+			{21, '5'}:      21,
+			{21, '6'}:      21,
+			{21, '7'}:      21,
+			{21, '8'}:      21,
+			{21, '9'}:      21,
+			{21, '_'}:      21,
+
+			{24, t.ANY}:    60,
+			{24, t.LETTER}: 24,
+			{24, '0'}:      24,
+			{24, '1'}:      24,
+			{24, '2'}:      24,
+			{24, '3'}:      24,
+			{24, '4'}:      24,
+			{24, '5'}:      24,
+			{24, '6'}:      24,
+			{24, '7'}:      24,
+			{24, '8'}:      24,
+			{24, '9'}:      24,
+			{24, '_'}:      24,
+
+			{29, t.EOF}: 51,
+			{29, t.ANY}: 40,
+			{29, '\n'}:  51,
+			{29, '"'}:   4,
+			{29, '\\'}:  4,
+			{29, 'n'}:   4,
+
+			{32, t.ANY}: 32,
+			{32, '\n'}:  41,
+
+			{33, t.ANY}: 61,
+			{33, '0'}:   42,
+			{33, '1'}:   42,
+			{33, '2'}:   42,
+			{33, '3'}:   42,
+			{33, '4'}:   42,
+			{33, '5'}:   42,
+			{33, '6'}:   42,
+			{33, '7'}:   42,
+			{33, '8'}:   42,
+			{33, '9'}:   42,
+
+			{34, t.ANY}: 61,
+			{34, '.'}:   34,
+			{34, '0'}:   34,
+			{34, '1'}:   34,
+			{34, '2'}:   34,
+			{34, '3'}:   34,
+			{34, '4'}:   34,
+			{34, '5'}:   34,
+			{34, '6'}:   34,
+			{34, '7'}:   34,
+			{34, '8'}:   34,
+			{34, '9'}:   34,
+			{34, 'e'}:   34,
+
+			{40, t.EOF}: 51,
+			{40, t.ANY}: 40,
+			{40, '\n'}:  51,
+			{40, '"'}:   43,
+			{40, '\\'}:  44,
+
+			{42, t.ANY}: 62,
+			{42, '0'}:   45,
+			{42, '1'}:   42,
+			{42, '2'}:   42,
+			{42, '3'}:   42,
+			{42, '4'}:   42,
+			{42, '5'}:   42,
+			{42, '6'}:   42,
+			{42, '7'}:   42,
+			{42, '8'}:   42,
+			{42, '9'}:   42,
+			{42, 'e'}:   46,
+
+			{44, t.EOF}: 51,
+			{44, t.ANY}: 40,
+			{44, '\n'}:  51,
+
+			{45, t.ANY}: 61,
+			{45, '0'}:   45,
+			{45, '1'}:   42,
+			{45, '2'}:   42,
+			{45, '3'}:   42,
+			{45, '4'}:   42,
+			{45, '5'}:   42,
+			{45, '6'}:   42,
+			{45, '7'}:   42,
+			{45, '8'}:   42,
+			{45, '9'}:   42,
+
+			{46, t.ANY}: 63,
+			{46, '+'}:   47,
+			{46, '-'}:   47,
+			{46, '0'}:   48,
+			{46, '1'}:   49,
+			{46, '2'}:   49,
+			{46, '3'}:   49,
+			{46, '4'}:   49,
+			{46, '5'}:   49,
+			{46, '6'}:   49,
+			{46, '7'}:   49,
+			{46, '8'}:   49,
+			{46, '9'}:   49,
+
+			{47, t.ANY}: 61,
+			{47, '0'}:   48,
+			{47, '1'}:   49,
+			{47, '2'}:   49,
+			{47, '3'}:   49,
+			{47, '4'}:   49,
+			{47, '5'}:   49,
+			{47, '6'}:   49,
+			{47, '7'}:   49,
+			{47, '8'}:   49,
+			{47, '9'}:   49,
+
+			{48, t.ANY}: 62,
+			{48, '0'}:   50,
+			{48, '1'}:   50,
+			{48, '2'}:   50,
+			{48, '3'}:   50,
+			{48, '4'}:   50,
+			{48, '5'}:   50,
+			{48, '6'}:   50,
+			{48, '7'}:   50,
+			{48, '8'}:   50,
+			{48, '9'}:   50,
+
+			{49, t.ANY}: 62,
+			{49, '0'}:   49,
+			{49, '1'}:   49,
+			{49, '2'}:   49,
+			{49, '3'}:   49,
+			{49, '4'}:   49,
+			{49, '5'}:   49,
+			{49, '6'}:   49,
+			{49, '7'}:   49,
+			{49, '8'}:   49,
+			{49, '9'}:   49,
+
+			{50, t.ANY}: 61,
+			{50, '0'}:   50,
+			{50, '1'}:   50,
+			{50, '2'}:   50,
+			{50, '3'}:   50,
+			{50, '4'}:   50,
+			{50, '5'}:   50,
+			{50, '6'}:   50,
+			{50, '7'}:   50,
+			{50, '8'}:   50,
+			{50, '9'}:   50,
+		},
+
+		StackTransitions: map[Key]t.State{
+			{1, '*'}: 65,
+			{1, '/'}: 64,
+
+			{64, '*'}: 31,
+			{64, '/'}: 64,
+
+			{65, '*'}: 65,
+			{65, '/'}: 66,
+		},
+
+		Tokens: map[t.State]token.Kind{
+			2:                     token.INVALIDCHAR,
+			3:                     token.NOT,
+			5:                     token.AND,
+			6:                     token.OPENPAR,
+			7:                     token.CLOSEPAR,
+			8:                     token.MULT,
+			9:                     token.PLUS,
+			10:                    token.COMMA,
+			12:                    token.DOT,
+			17:                    token.SEMI,
+			22:                    token.OPENSQBR,
+			23:                    token.CLOSESQBR,
+			25:                    token.OPENCUBR,
+			26:                    token.OR,
+			27:                    token.CLOSECUBR,
+			28:                    token.STRINGLIT,
+			30:                    token.ARROW,
+			31:                    token.OPENBLOCK,
+			35:                    token.COLONCOLON,
+			36:                    token.LEQ,
+			37:                    token.NOTEQ,
+			38:                    token.EQ,
+			39:                    token.GEQ,
+			41:                    token.INLINECMT,
+			43:                    token.INVALIDESCAPE,
+			51:                    token.UNTERMINATEDSTRING,
+			52:                    token.MINUS,
+			53:                    token.DIV,
+			54:                    token.INTNUM,
+			55:                    token.COLON,
+			56:                    token.LT,
+			57:                    token.ASSIGN,
+			58:                    token.GT,
+			59:                    token.ID,
+			60:                    token.INVALIDID,
+			61:                    token.INVALIDNUM,
+			62:                    token.FLOATNUM,
+			63:                    token.FLOATNUM,
+			66:                    token.CLOSEBLOCK,
+			67:                    token.BLOCKCMT,
+			t.UNTERMINATEDCOMMENT: token.UNTERMINATEDCOMMENT,
+		},
+
+		Comments: map[token.Kind]t.State{
+			token.BLOCKCMT: 67,
+		},
+
+		NeedBackup:       map[t.State]struct{}{51: {}, 52: {}, 53: {}, 54: {}, 55: {}, 56: {}, 57: {}, 58: {}, 59: {}, 60: {}, 61: {}, 62: {}},
+		NeedDoubleBackup: map[t.State]struct{}{63: {}},
+		PopStates:        map[t.State]struct{}{65: {}, 66: {}},
+		PushStates:       map[t.State]struct{}{31: {}, 64: {}},
+
+		Letters: map[rune]struct{}{
+			'A': {},
+			'B': {},
+			'C': {},
+			'D': {},
+			'E': {},
+			'F': {},
+			'G': {},
+			'H': {},
+			'I': {},
+			'J': {},
+			'K': {},
+			'L': {},
+			'M': {},
+			'N': {},
+			'O': {},
+			'P': {},
+			'Q': {},
+			'R': {},
+			'S': {},
+			'T': {},
+			'U': {},
+			'V': {},
+			'W': {},
+			'X': {},
+			'Y': {},
+			'Z': {},
+			'a': {},
+			'b': {},
+			'c': {},
+			'd': {},
+			'e': {},
+			'f': {},
+			'g': {},
+			'h': {},
+			'i': {},
+			'j': {},
+			'k': {},
+			'l': {},
+			'm': {},
+			'n': {},
+			'o': {},
+			'p': {},
+			'q': {},
+			'r': {},
+			's': {},
+			't': {},
+			'u': {},
+			'v': {},
+			'w': {},
+			'x': {},
+			'y': {},
+			'z': {},
+		},
+
+		Whitespace: map[rune]struct{}{
+			'\x00': {},
+			'\t':   {},
+			'\n':   {},
+			'\r':   {},
+			' ':    {},
+		},
+	}
+}
@@ -3,6 +3,7 @@ package tabledrivenscanner
 import "github.com/obonobo/esac/core/token"
 
 const (
+	EOF    rune = -3 // Represents the end of the input, falls back to ANY
 	ANY    rune = -2 // Represents any character
 	LETTER rune = -1 // Represents expression [aA-zZ]
 )
@@ -54,10 +54,10 @@ func (t *TableDrivenScanner) NextToken() (token.Token, error) {
 				return *tt, nil
 			}
 
-			// If there is an ANY transition available, then we can take it,
-			// otherwise return the error
+			// If there is an EOF (or ANY) transition available, then we can
+			// take it, otherwise return the error
 			if len(t.lexeme.s) > 0 {
-				state = t.table.Next(state, ANY)
+				state = t.table.Next(state, EOF)
 				if state == NOSTATE {
 					return token.Token{}, t.err
 				}
@@ -31,6 +31,10 @@ func (a AST) Print(fh io.Writer) {
 type Meta struct {
 	Record      *SymbolTableRecord
 	SymbolTable SymbolTable
+
+	// The temporary holding the value of an expression, assigned by the
+	// MemoryLayoutVisitor
+	Temp *SymbolTableRecord
 }
 
 func (m Meta) String() string {
@@ -49,13 +53,64 @@ type ASTNode struct {
 	Meta Meta
 }
 
+// Visits the children of the node and then the node itself. FINAL_ERROR nodes
+// and their subtrees are skipped
 func (n *ASTNode) Accept(v Visitor) {
+	if n.Type == FINAL_ERROR {
+		return
+	}
 	for _, child := range n.Children {
 		child.Accept(v)
 	}
 	v.Visit(n)
 }
 
+// The nodes whose children are declarations, members, or statements. These
+// children are the constructs that WrapErrors wraps
+var errorBoundaries = map[Kind]struct{}{
+	FINAL_STRUCT_OR_IMPL_OR_FUNC_LIST: {},
+	FINAL_FUNC_DEF_LIST:               {},
+	FINAL_MEMBERS:                     {},
+	FINAL_FUNC_BODY:                   {},
+	FINAL_STATBLOCK:                   {},
+}
+
+// Wraps every declaration, member, and statement that contains a FINAL_ERROR
+// node in a FINAL_ERROR node, which keeps the original subtree as its only
+// child. Visitors skip FINAL_ERROR nodes, so they can still analyze the rest of
+// a partial AST without running into the placeholders
+func WrapErrors(root *ASTNode) {
+	wrapErrors(root)
+}
+
+// Returns true if the subtree contains a FINAL_ERROR node that has not been
+// wrapped yet. Besides the leaves, the parser retypes the nodes that it built
+// from tokens it made up, e.g. the Assign of a missing '=', which keep their
+// children
+func wrapErrors(n *ASTNode) bool {
+	if n == nil {
+		return false
+	}
+	if n.Type == FINAL_ERROR {
+		return true
+	}
+	_, boundary := errorBoundaries[n.Type]
+	found := false
+	for i, child := range n.Children {
+		if !wrapErrors(child) {
+			continue
+		}
+		if boundary {
+			if child.Type != FINAL_ERROR {
+				n.Children[i] = &ASTNode{Type: FINAL_ERROR, Children: []*ASTNode{child}}
+			}
+		} else {
+			found = true
+		}
+	}
+	return found
+}
+
 func (n *ASTNode) StringSubtree(depth int) string {
 	out := new(bytes.Buffer)
 	n.PrintSubtree(out, depth)
@@ -0,0 +1,332 @@
+package token
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The version of the JSON and s-expression schemas of the AST. It must be
+// incremented whenever a change to the schemas could break a consumer
+const AST_SCHEMA_VERSION = 1
+
+// The JSON schema of the AST, e.g.:
+//
+//	{
+//	  "version": 1,
+//	  "root": {
+//	    "type": "Id",
+//	    "token": {"id": "id", "lexeme": "main", "line": 2, "column": 6},
+//	    "children": []
+//	  }
+//	}
+//
+// The token is omitted if the node has none, and the root is null if the AST
+// is empty. The Meta of the nodes is not part of the schema
+type astJson struct {
+	Version int       `json:"version"`
+	Root    *nodeJson `json:"root"`
+}
+
+type nodeJson struct {
+	Type     Kind        `json:"type"`
+	Token    *tokenJson  `json:"token,omitempty"`
+	Children []*nodeJson `json:"children"`
+}
+
+type tokenJson struct {
+	Id     Kind   `json:"id"`
+	Lexeme Lexeme `json:"lexeme"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func (a AST) MarshalJSON() ([]byte, error) {
+	return json.Marshal(astJson{Version: AST_SCHEMA_VERSION, Root: toJson(a.Root)})
+}
+
+func (a *AST) UnmarshalJSON(data []byte) error {
+	var doc astJson
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Version != AST_SCHEMA_VERSION {
+		return unsupportedVersion(doc.Version)
+	}
+	root, err := fromJson(doc.Root)
+	if err != nil {
+		return err
+	}
+	a.Root = root
+	return nil
+}
+
+// Writes the AST in the JSON schema, indented
+func (a AST) PrintJson(fh io.Writer) error {
+	enc := json.NewEncoder(fh)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Reads an AST written in the JSON schema
+func DecodeASTJson(r io.Reader) (AST, error) {
+	var ast AST
+	err := json.NewDecoder(r).Decode(&ast)
+	return ast, err
+}
+
+func toJson(n *ASTNode) *nodeJson {
+	if n == nil {
+		return nil
+	}
+	out := &nodeJson{Type: n.Type, Children: make([]*nodeJson, 0, len(n.Children))}
+	if n.Token.Id != "" {
+		out.Token = &tokenJson{n.Token.Id, n.Token.Lexeme, n.Token.Line, n.Token.Column}
+	}
+	for _, child := range n.Children {
+		out.Children = append(out.Children, toJson(child))
+	}
+	return out
+}
+
+func fromJson(n *nodeJson) (*ASTNode, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.Type == "" {
+		return nil, fmt.Errorf("AST node is missing its type")
+	}
+	out := &ASTNode{Type: n.Type}
+	if n.Token != nil {
+		out.Token = Token{n.Token.Id, n.Token.Lexeme, n.Token.Line, n.Token.Column}
+	}
+	for _, child := range n.Children {
+		c, err := fromJson(child)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return nil, fmt.Errorf("AST node '%v' has a null child", n.Type)
+		}
+		out.Children = append(out.Children, c)
+	}
+	return out, nil
+}
+
+// Writes the AST in the s-expression schema, which mirrors the JSON schema:
+//
+//	(ast 1
+//	  (node "FuncDef"
+//	    (node "Id" (token "id" "main" 2 6))))
+//
+// Types, kinds, and lexemes are quoted strings, escaped as in Go. An empty AST
+// is written as (ast 1)
+func (a AST) PrintSexpr(fh io.Writer) error {
+	w := bufio.NewWriter(fh)
+	fmt.Fprintf(w, "(ast %v", AST_SCHEMA_VERSION)
+	if a.Root != nil {
+		printSexpr(w, a.Root, 1)
+	}
+	fmt.Fprintln(w, ")")
+	return w.Flush()
+}
+
+func printSexpr(w io.Writer, n *ASTNode, depth int) {
+	fmt.Fprintf(w, "\n%v(node %v", strings.Repeat("  ", depth), strconv.Quote(string(n.Type)))
+	if n.Token.Id != "" {
+		fmt.Fprintf(w, " (token %v %v %v %v)",
+			strconv.Quote(string(n.Token.Id)), strconv.Quote(string(n.Token.Lexeme)),
+			n.Token.Line, n.Token.Column)
+	}
+	for _, child := range n.Children {
+		printSexpr(w, child, depth+1)
+	}
+	fmt.Fprint(w, ")")
+}
+
+// Reads an AST written in the s-expression schema
+func DecodeASTSexpr(r io.Reader) (AST, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return AST{}, err
+	}
+	d := &sexprDecoder{src: src}
+	ast, err := d.ast()
+	if err != nil {
+		return AST{}, fmt.Errorf("invalid AST s-expression at offset %v: %w", d.i, err)
+	}
+	return ast, nil
+}
+
+// A recursive descent parser for the s-expression schema
+type sexprDecoder struct {
+	src []byte
+	i   int
+}
+
+func (d *sexprDecoder) ast() (AST, error) {
+	if err := d.open("ast"); err != nil {
+		return AST{}, err
+	}
+	version, err := d.int()
+	if err != nil {
+		return AST{}, err
+	}
+	if version != AST_SCHEMA_VERSION {
+		return AST{}, unsupportedVersion(version)
+	}
+
+	var ast AST
+	if !d.closing() {
+		if ast.Root, err = d.node(); err != nil {
+			return AST{}, err
+		}
+	}
+	if err := d.close(); err != nil {
+		return AST{}, err
+	}
+	if d.skipSpace(); d.i < len(d.src) {
+		return AST{}, fmt.Errorf("unexpected text after the AST")
+	}
+	return ast, nil
+}
+
+func (d *sexprDecoder) node() (*ASTNode, error) {
+	if err := d.open("node"); err != nil {
+		return nil, err
+	}
+	typ, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+	n := &ASTNode{Type: Kind(typ)}
+	if d.peekHead("token") {
+		if n.Token, err = d.token(); err != nil {
+			return nil, err
+		}
+	}
+	for !d.closing() {
+		child, err := d.node()
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+	}
+	return n, d.close()
+}
+
+func (d *sexprDecoder) token() (Token, error) {
+	if err := d.open("token"); err != nil {
+		return Token{}, err
+	}
+	id, err := d.string()
+	if err != nil {
+		return Token{}, err
+	}
+	lexeme, err := d.string()
+	if err != nil {
+		return Token{}, err
+	}
+	line, err := d.int()
+	if err != nil {
+		return Token{}, err
+	}
+	column, err := d.int()
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Kind(id), Lexeme(lexeme), line, column}, d.close()
+}
+
+// Reads an opening parenthesis followed by the head of the list
+func (d *sexprDecoder) open(head string) error {
+	if d.skipSpace(); d.i >= len(d.src) || d.src[d.i] != '(' {
+		return fmt.Errorf("expected '(%v'", head)
+	}
+	d.i++
+	if d.symbol() != head {
+		return fmt.Errorf("expected '(%v'", head)
+	}
+	return nil
+}
+
+func (d *sexprDecoder) close() error {
+	if !d.closing() {
+		return fmt.Errorf("expected ')'")
+	}
+	d.i++
+	return nil
+}
+
+func (d *sexprDecoder) closing() bool {
+	d.skipSpace()
+	return d.i < len(d.src) && d.src[d.i] == ')'
+}
+
+// Checks whether the next list starts with the head, without consuming it
+func (d *sexprDecoder) peekHead(head string) bool {
+	start := d.i
+	defer func() { d.i = start }()
+	if d.skipSpace(); d.i >= len(d.src) || d.src[d.i] != '(' {
+		return false
+	}
+	d.i++
+	return d.symbol() == head
+}
+
+func (d *sexprDecoder) symbol() string {
+	d.skipSpace()
+	start := d.i
+	for d.i < len(d.src) && d.src[d.i] != '(' && d.src[d.i] != ')' &&
+		d.src[d.i] != '"' && !unicode.IsSpace(rune(d.src[d.i])) {
+		d.i++
+	}
+	return string(d.src[start:d.i])
+}
+
+func (d *sexprDecoder) int() (int, error) {
+	s := d.symbol()
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("expected an integer but got '%v'", s)
+	}
+	return i, nil
+}
+
+func (d *sexprDecoder) string() (string, error) {
+	if d.skipSpace(); d.i >= len(d.src) || d.src[d.i] != '"' {
+		return "", fmt.Errorf("expected a string")
+	}
+	end := d.i + 1
+	for end < len(d.src) && d.src[end] != '"' {
+		if d.src[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(d.src) {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s, err := strconv.Unquote(string(d.src[d.i : end+1]))
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", d.src[d.i:end+1])
+	}
+	d.i = end + 1
+	return s, nil
+}
+
+func (d *sexprDecoder) skipSpace() {
+	d.i += len(d.src[d.i:]) - len(bytes.TrimLeftFunc(d.src[d.i:], unicode.IsSpace))
+}
+
+func unsupportedVersion(version int) error {
+	return fmt.Errorf(
+		"unsupported AST schema version %v, should be %v",
+		version, AST_SCHEMA_VERSION)
+}
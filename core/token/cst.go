@@ -0,0 +1,120 @@
+package token
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// A run of whitespace between tokens
+	TRIVIA_WHITESPACE Kind = "whitespace"
+
+	// Text that the scanner did not turn into a token, e.g.: an inline comment
+	// that is cut off by the end of the input
+	TRIVIA_UNSCANNED Kind = "unscanned"
+)
+
+// Concrete Syntax Tree created by a parser. Unlike the AST, the CST keeps every
+// token of the source along with the text between the tokens, so the exact
+// source can be printed back with CST.Print
+type CST struct {
+	Root *CSTNode
+
+	// The trivia that follows the last token of the source
+	Trailing []Trivia
+}
+
+// Writes the source that the CST was built from
+func (c CST) Print(fh io.Writer) {
+	c.Root.PrintSource(fh)
+	printTrivia(fh, c.Trailing)
+}
+
+// Returns the source that the CST was built from
+func (c CST) String() string {
+	out := new(bytes.Buffer)
+	c.Print(out)
+	return out.String()
+}
+
+func (c CST) TreeString() string {
+	out := new(bytes.Buffer)
+	c.PrintTree(out)
+	return out.String()
+}
+
+// Prints the structure of the CST, along with the trivia of every token
+func (c CST) PrintTree(fh io.Writer) {
+	c.Root.PrintSubtree(fh, 0)
+	for _, t := range c.Trailing {
+		fmt.Fprintf(fh, "~ %v\n", t)
+	}
+}
+
+// Text of the source that the parser does not see. The Kind is
+// TRIVIA_WHITESPACE, TRIVIA_UNSCANNED, the kind of a comment, or the kind of a
+// token that the parser skipped while recovering from a syntax error
+type Trivia struct {
+	Kind Kind
+	Text string
+}
+
+func (t Trivia) String() string {
+	return fmt.Sprintf("%v %q", t.Kind, t.Text)
+}
+
+// A single node of the CST. A nonterminal node has a child for every symbol of
+// the rule that expanded it, semantic actions and EPSILON excluded. A node with
+// no rule and no children is a nonterminal that was popped by the parser
+// without being expanded.
+//
+// A terminal node holds the token that it matched, and the trivia that comes
+// before the token. If the token was inserted by the parser to repair a syntax
+// error, then its Text is empty
+type CSTNode struct {
+	Kind     Kind
+	Rule     *Rule
+	Children []*CSTNode
+
+	Token   *Token
+	Text    string
+	Leading []Trivia
+}
+
+// Writes the source text covered by the node
+func (n *CSTNode) PrintSource(fh io.Writer) {
+	if n == nil {
+		return
+	}
+	printTrivia(fh, n.Leading)
+	io.WriteString(fh, n.Text)
+	for _, child := range n.Children {
+		child.PrintSource(fh)
+	}
+}
+
+func (n *CSTNode) PrintSubtree(fh io.Writer, depth int) {
+	if n == nil {
+		return
+	}
+	pref := strings.Repeat("| ", depth)
+	for _, t := range n.Leading {
+		fmt.Fprintf(fh, "%v~ %v\n", pref, t)
+	}
+	if n.Token != nil {
+		fmt.Fprintf(fh, "%v%v: %q\n", pref, n.Kind, n.Text)
+	} else {
+		fmt.Fprintf(fh, "%v%v\n", pref, n.Kind)
+	}
+	for _, child := range n.Children {
+		child.PrintSubtree(fh, depth+1)
+	}
+}
+
+func printTrivia(fh io.Writer, trivia []Trivia) {
+	for _, t := range trivia {
+		io.WriteString(fh, t.Text)
+	}
+}
@@ -0,0 +1,184 @@
+package token
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writes the AST as a Graphviz DOT graph. Leaves are labelled with the lexeme
+// and position of their token, and FINAL_ERROR nodes are drawn in red
+func (a AST) PrintDot(fh io.Writer) error {
+	w := bufio.NewWriter(fh)
+	fmt.Fprintln(w, "digraph AST {")
+	fmt.Fprintln(w, "\tnode [shape=box, fontname=monospace];")
+	ids := 0
+	var walk func(n *ASTNode) int
+	walk = func(n *ASTNode) int {
+		id := ids
+		ids++
+		label := string(n.Type)
+		if n.Token.Id != "" {
+			label += "\n" + tokenLabel(n.Token)
+		}
+		attrs := ""
+		if n.Type == FINAL_ERROR {
+			attrs = ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(w, "\tn%v [label=%v%v];\n", id, dotQuote(label), attrs)
+		for _, child := range n.Children {
+			fmt.Fprintf(w, "\tn%v -> n%v;\n", id, walk(child))
+		}
+		return id
+	}
+	if a.Root != nil {
+		walk(a.Root)
+	}
+	fmt.Fprintln(w, "}")
+	return w.Flush()
+}
+
+// Writes the symbol tables reachable from the table as a Graphviz DOT graph.
+// Every table is a node listing its records, records that link a table point
+// to it, and dashed and bold edges point to the parent and the inherited
+// tables of a table respectively
+func WriteSymbolTablesDot(fh io.Writer, table SymbolTable) error {
+	w := bufio.NewWriter(fh)
+	fmt.Fprintln(w, "digraph SymbolTables {")
+	fmt.Fprintln(w, "\tnode [shape=box, fontname=monospace];")
+
+	ids := make(map[SymbolTable]int, 64)
+	order := make([]SymbolTable, 0, 64)
+	id := func(t SymbolTable) int {
+		if _, ok := ids[t]; !ok {
+			ids[t] = len(ids)
+			order = append(order, t)
+		}
+		return ids[t]
+	}
+
+	if table != nil {
+		id(table)
+	}
+	for i := 0; i < len(order); i++ {
+		t := order[i]
+		var label strings.Builder
+		fmt.Fprintf(&label, "table: %v\n", t.Id())
+		for _, record := range t.Entries() {
+			label.WriteString(recordLabel(record) + "\\l")
+		}
+		fmt.Fprintf(w, "\tt%v [label=%v];\n", i, dotQuote(label.String()))
+
+		for _, record := range t.Entries() {
+			if record.Link != nil && record.Kind != FINAL_IMPL_DEF {
+				fmt.Fprintf(w, "\tt%v -> t%v [label=%v];\n", i, id(record.Link), dotQuote(record.Name))
+			}
+		}
+		if t.Parent() != nil {
+			fmt.Fprintf(w, "\tt%v -> t%v [label=\"parent\", style=dashed];\n", i, id(t.Parent()))
+		}
+		for _, inherited := range t.Inherited() {
+			fmt.Fprintf(w, "\tt%v -> t%v [label=\"inherits\", style=bold];\n", i, id(inherited))
+		}
+	}
+	fmt.Fprintln(w, "}")
+	return w.Flush()
+}
+
+// Describes a record in the same terms as WriteOutSymbolTables, along with the
+// line on which it is declared
+func recordLabel(r SymbolTableRecord) string {
+	var label string
+	switch r.Kind {
+	case FINAL_STRUCT_DECL:
+		label = "class " + r.Name
+	case FINAL_IMPL_DEF:
+		label = "impl " + r.Name
+	case FINAL_FUNC_DEF, FINAL_FUNC_DECL:
+		label = "function " + r.Name + " " + outSignature(r)
+	case FINAL_FOR:
+		label = "scope " + r.Name
+	case FINAL_FUNC_DEF_PARAM:
+		label = "param " + r.Name + ": " + outType(r.Type)
+	case FINAL_VAR_DECL:
+		kind := "local"
+		if r.Type.Privacy != "" {
+			kind = "data"
+		}
+		label = kind + " " + r.Name + ": " + outType(r.Type)
+	case RECORD_RETURN_VALUE:
+		label = "return " + r.Name + ": " + outType(r.Type)
+	default:
+		label = strings.ToLower(string(r.Kind)) + " " + r.Name + ": " + outType(r.Type)
+	}
+	if r.Type.Privacy != "" {
+		label += " " + string(r.Type.Privacy)
+	}
+	if r.Type.Token.Line > 0 {
+		label += fmt.Sprintf(" (line %v)", r.Type.Token.Line)
+	}
+	return label
+}
+
+// Writes the parse tree of a leftmost derivation, the rules that the
+// TableDrivenParser gives to rulec, as a Graphviz DOT graph. The terminals of
+// the tree are labelled with the tokens that they matched, which are taken in
+// order from tokens, skipping the tokens that don't match. Semantic actions
+// are left out.
+//
+// The first rule expands the start symbol. A nonterminal is expanded by the
+// next rule if the LHS of the rule is the nonterminal, otherwise it is left as a leaf (e.g.: if the parser popped it
+// at the end of the input, or while recovering from a syntax error)
+func WriteDerivationDot(fh io.Writer, rules []Rule, tokens []Token) error {
+	w := bufio.NewWriter(fh)
+	fmt.Fprintln(w, "digraph Derivation {")
+	fmt.Fprintln(w, "\tnode [fontname=monospace];")
+
+	ids, next := 0, 0
+	var expand func(k Kind) int
+	expand = func(k Kind) int {
+		id := ids
+		ids++
+		if next < len(rules) && rules[next].LHS == k {
+			r := rules[next]
+			next++
+			fmt.Fprintf(w, "\td%v [label=%v, shape=ellipse];\n", id, dotQuote(string(k)))
+			for _, s := range r.RHS {
+				if !IsSemAction(s) {
+					fmt.Fprintf(w, "\td%v -> d%v;\n", id, expand(s))
+				}
+			}
+			return id
+		}
+
+		// Tokens in front of the matching one were skipped by the parser
+		label := string(k)
+		for i, tok := range tokens {
+			if tok.Id == k {
+				label += "\n" + tokenLabel(tok)
+				tokens = tokens[i+1:]
+				break
+			}
+		}
+		fmt.Fprintf(w, "\td%v [label=%v, shape=box];\n", id, dotQuote(label))
+		return id
+	}
+	if len(rules) > 0 {
+		expand(rules[0].LHS)
+	}
+	fmt.Fprintln(w, "}")
+	return w.Flush()
+}
+
+func tokenLabel(tok Token) string {
+	return fmt.Sprintf("'%v' %v:%v", tok.Lexeme, tok.Line, tok.Column)
+}
+
+// Quotes a string as a DOT ID. Escape sequences that are already in the
+// string, such as '\l', are kept
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
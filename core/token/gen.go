@@ -79,16 +79,21 @@ const (
 	OPENINLINE  Kind = "openinline"  // Start of an inline comment '//'
 	OPENBLOCK   Kind = "openblock"   // Start of a block comment '/*'
 
-	ID        Kind = "id"       // Identifier 'exampleId_123'
-	INTNUM    Kind = "intnum"   // Integer '123'
-	EMPTY_DIM Kind = "emptydim" // An empty array dimension e.g.: 'integer[]'
-	FLOATNUM  Kind = "floatnum" // Floating-point number '1.23'
+	ID        Kind = "id"        // Identifier 'exampleId_123'
+	INTNUM    Kind = "intnum"    // Integer '123'
+	EMPTY_DIM Kind = "emptydim"  // An empty array dimension e.g.: 'integer[]'
+	FLOATNUM  Kind = "floatnum"  // Floating-point number '1.23'
+	STRINGLIT Kind = "stringlit" // String literal '"hello"'
 
 	IF       Kind = "if"       // Reserved word 'if'
 	THEN     Kind = "then"     // Reserved word 'then'
 	ELSE     Kind = "else"     // Reserved word 'else'
 	INTEGER  Kind = "integer"  // Reserved word 'integer'
 	FLOAT    Kind = "float"    // Reserved word 'float'
+	STRING   Kind = "string"   // Reserved word 'string'
+	BOOL     Kind = "bool"     // Reserved word 'bool'
+	TRUE     Kind = "true"     // Reserved word 'true'
+	FALSE    Kind = "false"    // Reserved word 'false'
 	VOID     Kind = "void"     // Reserved word 'void'
 	PUBLIC   Kind = "public"   // Reserved word 'public'
 	PRIVATE  Kind = "private"  // Reserved word 'private'
@@ -96,6 +101,9 @@ const (
 	VAR      Kind = "var"      // Reserved word 'var'
 	STRUCT   Kind = "struct"   // Reserved word 'struct'
 	WHILE    Kind = "while"    // Reserved word 'while'
+	FOR      Kind = "for"      // Reserved word 'for'
+	BREAK    Kind = "break"    // Reserved word 'break'
+	CONTINUE Kind = "continue" // Reserved word 'continue'
 	READ     Kind = "read"     // Reserved word 'read'
 	WRITE    Kind = "write"    // Reserved word 'write'
 	RETURN   Kind = "return"   // Reserved word 'return'
@@ -108,6 +116,8 @@ const (
 	INVALIDNUM          Kind = "invalidnum"          // Error token
 	INVALIDCHAR         Kind = "invalidchar"         // Error token
 	UNTERMINATEDCOMMENT Kind = "unterminatedcomment" // Error token
+	UNTERMINATEDSTRING  Kind = "unterminatedstring"  // Error token
+	INVALIDESCAPE       Kind = "invalidescape"       // Error token
 )
 
 func Comments() []Kind {
@@ -129,9 +139,11 @@ const (
 	ASSIGNSTAT                        Kind = "<assignStat>"
 	ASSIGNSTATORFUNCCALL              Kind = "<assignStatOrFuncCall>"
 	ASSIGNSTATORFUNCCALL_DISAMBIGUATE Kind = "<assignStatOrFuncCall-disambiguate>"
+	ELSE_DISAMBIGUATE                 Kind = "<else-disambiguate>"
 	EXPR                              Kind = "<expr>"
 	FACTOR                            Kind = "<factor>"
 	FLOATNUMM                         Kind = "<floatNumm>"
+	FORINIT                           Kind = "<forInit>"
 	FPARAMS                           Kind = "<fParams>"
 	FPARAMSTAIL                       Kind = "<fParamsTail>"
 	FUNCBODY                          Kind = "<funcBody>"
@@ -141,6 +153,7 @@ const (
 	FUNCTIONCALL                      Kind = "<functionCall>"
 	FUNCTIONCALL_DISAMBIGUATE         Kind = "<functionCall-disambiguate>"
 	IDD                               Kind = "<idd>"
+	IF_TAIL                           Kind = "<if-tail>"
 	IMPLDEF                           Kind = "<implDef>"
 	INDICE                            Kind = "<indice>"
 	INTNUMM                           Kind = "<intNumm>"
@@ -152,7 +165,6 @@ const (
 	NOTT                              Kind = "<nott>"
 	OPT_STRUCTDECL2                   Kind = "<opt-structDecl2>"
 	PROG                              Kind = "<prog>"
-	RELEXPR                           Kind = "<relExpr>"
 	RELOP                             Kind = "<relOp>"
 	REPT_APARAMS1                     Kind = "<rept-aParams1>"
 	REPT_FPARAMS3                     Kind = "<rept-fParams3>"
@@ -172,6 +184,7 @@ const (
 	START                             Kind = "<START>"
 	STATBLOCK                         Kind = "<statBlock>"
 	STATEMENT                         Kind = "<statement>"
+	STRINGLITT                        Kind = "<stringLitt>"
 	STRUCTDECL                        Kind = "<structDecl>"
 	STRUCTORIMPLORFUNC                Kind = "<structOrImplOrFunc>"
 	TERM                              Kind = "<term>"
@@ -203,9 +216,11 @@ var NONTERMINALS = func() KindSet {
 		ASSIGNSTAT:                        {},
 		ASSIGNSTATORFUNCCALL:              {},
 		ASSIGNSTATORFUNCCALL_DISAMBIGUATE: {},
+		ELSE_DISAMBIGUATE:                 {},
 		EXPR:                              {},
 		FACTOR:                            {},
 		FLOATNUMM:                         {},
+		FORINIT:                           {},
 		FPARAMS:                           {},
 		FPARAMSTAIL:                       {},
 		FUNCBODY:                          {},
@@ -215,6 +230,7 @@ var NONTERMINALS = func() KindSet {
 		FUNCTIONCALL:                      {},
 		FUNCTIONCALL_DISAMBIGUATE:         {},
 		IDD:                               {},
+		IF_TAIL:                           {},
 		IMPLDEF:                           {},
 		INDICE:                            {},
 		INTNUMM:                           {},
@@ -226,7 +242,6 @@ var NONTERMINALS = func() KindSet {
 		NOTT:                              {},
 		OPT_STRUCTDECL2:                   {},
 		PROG:                              {},
-		RELEXPR:                           {},
 		RELOP:                             {},
 		REPT_APARAMS1:                     {},
 		REPT_FPARAMS3:                     {},
@@ -246,6 +261,7 @@ var NONTERMINALS = func() KindSet {
 		START:                             {},
 		STATBLOCK:                         {},
 		STATEMENT:                         {},
+		STRINGLITT:                        {},
 		STRUCTDECL:                        {},
 		STRUCTORIMPLORFUNC:                {},
 		TERM:                              {},
@@ -273,6 +289,10 @@ const (
 	SEM_ARITH_EXPR_MAKENODE            Kind = "(SEM-ARITH-EXPR-MAKENODE)"
 	SEM_ASSIGNOP_MAKENODE              Kind = "(SEM-ASSIGNOP-MAKENODE)"
 	SEM_ASSIGN_MAKEFAMILY              Kind = "(SEM-ASSIGN-MAKEFAMILY)"
+	SEM_BOOLLIT_MAKENODE               Kind = "(SEM-BOOLLIT-MAKENODE)"
+	SEM_BOOL_MAKENODE                  Kind = "(SEM-BOOL-MAKENODE)"
+	SEM_BREAK_MAKENODE                 Kind = "(SEM-BREAK-MAKENODE)"
+	SEM_CONTINUE_MAKENODE              Kind = "(SEM-CONTINUE-MAKENODE)"
 	SEM_DIMLIST_MAKEFAMILY             Kind = "(SEM-DIMLIST-MAKEFAMILY)"
 	SEM_DIM_EMPTY_MAKENODE             Kind = "(SEM-DIM-EMPTY-MAKENODE)"
 	SEM_DIM_MAKENODE                   Kind = "(SEM-DIM-MAKENODE)"
@@ -282,6 +302,9 @@ const (
 	SEM_FACTOR_MAKENODE                Kind = "(SEM-FACTOR-MAKENODE)"
 	SEM_FLOATNUM_MAKENODE              Kind = "(SEM-FLOATNUM-MAKENODE)"
 	SEM_FLOAT_MAKENODE                 Kind = "(SEM-FLOAT-MAKENODE)"
+	SEM_FOR_INIT_MAKEFAMILY            Kind = "(SEM-FOR-INIT-MAKEFAMILY)"
+	SEM_FOR_MAKEFAMILY                 Kind = "(SEM-FOR-MAKEFAMILY)"
+	SEM_FOR_MAKENODE                   Kind = "(SEM-FOR-MAKENODE)"
 	SEM_FPARAM_LIST_MAKEFAMILY         Kind = "(SEM-FPARAM-LIST-MAKEFAMILY)"
 	SEM_FPARAM_MAKEFAMILY              Kind = "(SEM-FPARAM-MAKEFAMILY)"
 	SEM_FUNCDEFLIST_MAKEFAMILY         Kind = "(SEM-FUNCDEFLIST-MAKEFAMILY)"
@@ -295,6 +318,7 @@ const (
 	SEM_GT_MAKENODE                    Kind = "(SEM-GT-MAKENODE)"
 	SEM_ID_MAKENODE                    Kind = "(SEM-ID-MAKENODE)"
 	SEM_IF_MAKEFAMILY                  Kind = "(SEM-IF-MAKEFAMILY)"
+	SEM_IF_MAKENODE                    Kind = "(SEM-IF-MAKENODE)"
 	SEM_IMPL_DEF_MAKEFAMILY            Kind = "(SEM-IMPL-DEF-MAKEFAMILY)"
 	SEM_INDEXLIST_MAKEFAMILY           Kind = "(SEM-INDEXLIST-MAKEFAMILY)"
 	SEM_INDEX_MAKENODE                 Kind = "(SEM-INDEX-MAKENODE)"
@@ -328,6 +352,8 @@ const (
 	SEM_STATBLOCK_FRESH                Kind = "(SEM-STATBLOCK-FRESH)"
 	SEM_STATBLOCK_MAKEFAMILY           Kind = "(SEM-STATBLOCK-MAKEFAMILY)"
 	SEM_STATEMENT_MAKEFAMILY           Kind = "(SEM-STATEMENT-MAKEFAMILY)"
+	SEM_STRINGLIT_MAKENODE             Kind = "(SEM-STRINGLIT-MAKENODE)"
+	SEM_STRING_MAKENODE                Kind = "(SEM-STRING-MAKENODE)"
 	SEM_STRUCT_DECL_MAKEFAMILY         Kind = "(SEM-STRUCT-DECL-MAKEFAMILY)"
 	SEM_SUBJECT_MAKEFAMILY             Kind = "(SEM-SUBJECT-MAKEFAMILY)"
 	SEM_TERM_MAKENODE                  Kind = "(SEM-TERM-MAKENODE)"
@@ -336,6 +362,7 @@ const (
 	SEM_VAR_DECL_MAKEFAMILY            Kind = "(SEM-VAR-DECL-MAKEFAMILY)"
 	SEM_VOID_MAKENODE                  Kind = "(SEM-VOID-MAKENODE)"
 	SEM_WHILE_MAKEFAMILY               Kind = "(SEM-WHILE-MAKEFAMILY)"
+	SEM_WHILE_MAKENODE                 Kind = "(SEM-WHILE-MAKENODE)"
 	SEM_WRITE_MAKEFAMILY               Kind = "(SEM-WRITE-MAKEFAMILY)"
 )
 
@@ -347,6 +374,10 @@ var SEMANTIC_ACTIONS = func() KindSet {
 		SEM_ARITH_EXPR_MAKENODE:            {},
 		SEM_ASSIGNOP_MAKENODE:              {},
 		SEM_ASSIGN_MAKEFAMILY:              {},
+		SEM_BOOLLIT_MAKENODE:               {},
+		SEM_BOOL_MAKENODE:                  {},
+		SEM_BREAK_MAKENODE:                 {},
+		SEM_CONTINUE_MAKENODE:              {},
 		SEM_DIMLIST_MAKEFAMILY:             {},
 		SEM_DIM_EMPTY_MAKENODE:             {},
 		SEM_DIM_MAKENODE:                   {},
@@ -356,6 +387,9 @@ var SEMANTIC_ACTIONS = func() KindSet {
 		SEM_FACTOR_MAKENODE:                {},
 		SEM_FLOATNUM_MAKENODE:              {},
 		SEM_FLOAT_MAKENODE:                 {},
+		SEM_FOR_INIT_MAKEFAMILY:            {},
+		SEM_FOR_MAKEFAMILY:                 {},
+		SEM_FOR_MAKENODE:                   {},
 		SEM_FPARAM_LIST_MAKEFAMILY:         {},
 		SEM_FPARAM_MAKEFAMILY:              {},
 		SEM_FUNCDEFLIST_MAKEFAMILY:         {},
@@ -369,6 +403,7 @@ var SEMANTIC_ACTIONS = func() KindSet {
 		SEM_GT_MAKENODE:                    {},
 		SEM_ID_MAKENODE:                    {},
 		SEM_IF_MAKEFAMILY:                  {},
+		SEM_IF_MAKENODE:                    {},
 		SEM_IMPL_DEF_MAKEFAMILY:            {},
 		SEM_INDEXLIST_MAKEFAMILY:           {},
 		SEM_INDEX_MAKENODE:                 {},
@@ -402,6 +437,8 @@ var SEMANTIC_ACTIONS = func() KindSet {
 		SEM_STATBLOCK_FRESH:                {},
 		SEM_STATBLOCK_MAKEFAMILY:           {},
 		SEM_STATEMENT_MAKEFAMILY:           {},
+		SEM_STRINGLIT_MAKENODE:             {},
+		SEM_STRING_MAKENODE:                {},
 		SEM_STRUCT_DECL_MAKEFAMILY:         {},
 		SEM_SUBJECT_MAKEFAMILY:             {},
 		SEM_TERM_MAKENODE:                  {},
@@ -410,6 +447,7 @@ var SEMANTIC_ACTIONS = func() KindSet {
 		SEM_VAR_DECL_MAKEFAMILY:            {},
 		SEM_VOID_MAKENODE:                  {},
 		SEM_WHILE_MAKEFAMILY:               {},
+		SEM_WHILE_MAKENODE:                 {},
 		SEM_WRITE_MAKEFAMILY:               {},
 	}
 }
@@ -457,6 +495,22 @@ var SEM_DISPATCH = map[Kind]SemanticAction{
 		defaultSemActionOrOverride(SEM_ASSIGN_MAKEFAMILY, tok, stack)
 	},
 
+	SEM_BOOLLIT_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_BOOLLIT_MAKENODE, tok, stack)
+	},
+
+	SEM_BOOL_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_BOOL_MAKENODE, tok, stack)
+	},
+
+	SEM_BREAK_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_BREAK_MAKENODE, tok, stack)
+	},
+
+	SEM_CONTINUE_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_CONTINUE_MAKENODE, tok, stack)
+	},
+
 	SEM_DIMLIST_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
 		defaultSemActionOrOverride(SEM_DIMLIST_MAKEFAMILY, tok, stack)
 	},
@@ -493,6 +547,18 @@ var SEM_DISPATCH = map[Kind]SemanticAction{
 		defaultSemActionOrOverride(SEM_FLOAT_MAKENODE, tok, stack)
 	},
 
+	SEM_FOR_INIT_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_FOR_INIT_MAKEFAMILY, tok, stack)
+	},
+
+	SEM_FOR_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_FOR_MAKEFAMILY, tok, stack)
+	},
+
+	SEM_FOR_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_FOR_MAKENODE, tok, stack)
+	},
+
 	SEM_FPARAM_LIST_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
 		defaultSemActionOrOverride(SEM_FPARAM_LIST_MAKEFAMILY, tok, stack)
 	},
@@ -545,6 +611,10 @@ var SEM_DISPATCH = map[Kind]SemanticAction{
 		defaultSemActionOrOverride(SEM_IF_MAKEFAMILY, tok, stack)
 	},
 
+	SEM_IF_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_IF_MAKENODE, tok, stack)
+	},
+
 	SEM_IMPL_DEF_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
 		defaultSemActionOrOverride(SEM_IMPL_DEF_MAKEFAMILY, tok, stack)
 	},
@@ -677,6 +747,14 @@ var SEM_DISPATCH = map[Kind]SemanticAction{
 		defaultSemActionOrOverride(SEM_STATEMENT_MAKEFAMILY, tok, stack)
 	},
 
+	SEM_STRINGLIT_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_STRINGLIT_MAKENODE, tok, stack)
+	},
+
+	SEM_STRING_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_STRING_MAKENODE, tok, stack)
+	},
+
 	SEM_STRUCT_DECL_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
 		defaultSemActionOrOverride(SEM_STRUCT_DECL_MAKEFAMILY, tok, stack)
 	},
@@ -709,6 +787,10 @@ var SEM_DISPATCH = map[Kind]SemanticAction{
 		defaultSemActionOrOverride(SEM_WHILE_MAKEFAMILY, tok, stack)
 	},
 
+	SEM_WHILE_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		defaultSemActionOrOverride(SEM_WHILE_MAKENODE, tok, stack)
+	},
+
 	SEM_WRITE_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
 		defaultSemActionOrOverride(SEM_WRITE_MAKEFAMILY, tok, stack)
 	},
@@ -732,6 +814,9 @@ var TERMINALS = func() KindSet {
 		INTEGER:   {},
 		INTNUM:    {},
 		WHILE:     {},
+		FOR:       {},
+		BREAK:     {},
+		CONTINUE:  {},
 		ID:        {},
 		EQ:        {},
 		VOID:      {},
@@ -742,6 +827,11 @@ var TERMINALS = func() KindSet {
 		THEN:      {},
 		STRUCT:    {},
 		FLOATNUM:  {},
+		STRINGLIT: {},
+		STRING:    {},
+		BOOL:      {},
+		TRUE:      {},
+		FALSE:     {},
 		WRITE:     {},
 		GT:        {},
 		PLUS:      {},
@@ -803,11 +893,13 @@ var RULES = func() Rules {
 		ASSIGNSTAT:                        []Rule{{ASSIGNSTAT, []Kind{VARIABLE, ASSIGNOP, EXPR, SEM_ASSIGN_MAKEFAMILY}}},
 		ASSIGNSTATORFUNCCALL_DISAMBIGUATE: []Rule{{ASSIGNSTATORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, MORE_ASSIGN}}, {ASSIGNSTATORFUNCCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, MORE_FUNC}}},
 		ASSIGNSTATORFUNCCALL:              []Rule{{ASSIGNSTATORFUNCCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, ASSIGNSTATORFUNCCALL_DISAMBIGUATE}}},
+		ELSE_DISAMBIGUATE:                 []Rule{{ELSE_DISAMBIGUATE, []Kind{SEM_STATBLOCK_FRESH, IF, SEM_IF_MAKENODE, OPENPAR, EXPR, CLOSEPAR, THEN, STATBLOCK, IF_TAIL, SEM_STATBLOCK_MAKEFAMILY, SEM_IF_MAKEFAMILY}}, {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}}},
 		EXPR:                              []Rule{{EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}}},
 		FPARAMS:                           []Rule{{FPARAMS, []Kind{IDD, COLON, TYPE, REPT_FPARAMS3, SEM_DIMLIST_MAKEFAMILY, SEM_FPARAM_MAKEFAMILY, SEM_FPARAM_LIST_MAKEFAMILY, REPT_FPARAMS4}}, {FPARAMS, []Kind{EPSILON, SEM_FPARAM_LIST_MAKEFAMILY}}},
 		FPARAMSTAIL:                       []Rule{{FPARAMSTAIL, []Kind{COMMA, IDD, COLON, TYPE, REPT_FPARAMSTAIL4, SEM_DIMLIST_MAKEFAMILY, SEM_FPARAM_MAKEFAMILY, SEM_FPARAM_LIST_MAKEFAMILY}}},
-		FACTOR:                            []Rule{{FACTOR, []Kind{VARORFUNCCALL, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{INTNUMM, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{FLOATNUMM, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{OPENPAR, ARITHEXPR, CLOSEPAR, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{NOTT, FACTOR, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{SIGN, FACTOR, SEM_FACTOR_MAKENODE}}},
+		FACTOR:                            []Rule{{FACTOR, []Kind{VARORFUNCCALL, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{INTNUMM, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{FLOATNUMM, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{STRINGLITT, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{TRUE, SEM_BOOLLIT_MAKENODE, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{FALSE, SEM_BOOLLIT_MAKENODE, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{OPENPAR, EXPR, CLOSEPAR, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{NOTT, FACTOR, SEM_FACTOR_MAKENODE}}, {FACTOR, []Kind{SIGN, FACTOR, SEM_FACTOR_MAKENODE}}},
 		FLOATNUMM:                         []Rule{{FLOATNUMM, []Kind{FLOATNUM, SEM_FLOATNUM_MAKENODE}}},
+		FORINIT:                           []Rule{{FORINIT, []Kind{SEM_STATBLOCK_FRESH, LET, IDD, COLON, TYPE, SEM_DIMLIST_MAKEFAMILY, SEM_VAR_DECL_MAKEFAMILY, ASSIGNOP, EXPR, SEM_FOR_INIT_MAKEFAMILY}}, {FORINIT, []Kind{SEM_STATBLOCK_FRESH, ASSIGNSTAT, SEM_STATBLOCK_MAKEFAMILY}}},
 		FUNCBODY:                          []Rule{{FUNCBODY, []Kind{OPENCUBR, REPT_FUNCBODY1, CLOSECUBR}}},
 		FUNCDECL:                          []Rule{{FUNCDECL, []Kind{FUNCHEAD, SEMI, SEM_FUNC_DECL_MAKEFAMILY}}},
 		FUNCDEF:                           []Rule{{FUNCDEF, []Kind{FUNCHEAD, FUNCBODY, SEM_FUNC_DEF_MAKEFAMILY}}},
@@ -815,6 +907,7 @@ var RULES = func() Rules {
 		FUNCTIONCALL_DISAMBIGUATE:         []Rule{{FUNCTIONCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, ANOTHER_FUNCTIONCALL}}, {FUNCTIONCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, DOT, FUNCTIONCALL}}},
 		FUNCTIONCALL:                      []Rule{{FUNCTIONCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, FUNCTIONCALL_DISAMBIGUATE}}},
 		IDD:                               []Rule{{IDD, []Kind{ID, SEM_ID_MAKENODE}}},
+		IF_TAIL:                           []Rule{{IF_TAIL, []Kind{ELSE, ELSE_DISAMBIGUATE}}, {IF_TAIL, []Kind{SEMI, SEM_IF_MAKEFAMILY}}},
 		IMPLDEF:                           []Rule{{IMPLDEF, []Kind{IMPL, IDD, OPENCUBR, REPT_IMPLDEF3, CLOSECUBR, SEM_IMPL_DEF_MAKEFAMILY}}},
 		INDICE:                            []Rule{{INDICE, []Kind{OPENSQBR, ARITHEXPR, CLOSESQBR, SEM_INDEX_MAKENODE, SEM_INDEXLIST_MAKEFAMILY}}},
 		INTNUMM:                           []Rule{{INTNUMM, []Kind{INTNUM, SEM_INTNUM_MAKENODE}}},
@@ -826,7 +919,6 @@ var RULES = func() Rules {
 		NOTT:                              []Rule{{NOTT, []Kind{NOT, SEM_NOT_MAKENODE}}},
 		OPT_STRUCTDECL2:                   []Rule{{OPT_STRUCTDECL2, []Kind{INHERITS, IDD, SEM_INHERITS_MAKEFAMILY, REPT_OPT_STRUCTDECL22}}, {OPT_STRUCTDECL2, []Kind{EPSILON}}},
 		PROG:                              []Rule{{PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}}},
-		RELEXPR:                           []Rule{{RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}}},
 		RELOP:                             []Rule{{RELOP, []Kind{EQ, SEM_EQ_MAKENODE}}, {RELOP, []Kind{NOTEQ, SEM_NEQ_MAKENODE}}, {RELOP, []Kind{LT, SEM_LT_MAKENODE}}, {RELOP, []Kind{GT, SEM_GT_MAKENODE}}, {RELOP, []Kind{LEQ, SEM_LEQ_MAKENODE}}, {RELOP, []Kind{GEQ, SEM_GEQ_MAKENODE}}},
 		REPT_APARAMS1:                     []Rule{{REPT_APARAMS1, []Kind{APARAMSTAIL, REPT_APARAMS1}}, {REPT_APARAMS1, []Kind{EPSILON}}},
 		REPT_FPARAMS3:                     []Rule{{REPT_FPARAMS3, []Kind{ARRAYSIZE, REPT_FPARAMS3}}, {REPT_FPARAMS3, []Kind{EPSILON}}},
@@ -844,11 +936,12 @@ var RULES = func() Rules {
 		RIGHTREC_TERM:                     []Rule{{RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}}, {RIGHTREC_TERM, []Kind{EPSILON}}},
 		SIGN:                              []Rule{{SIGN, []Kind{PLUS, SEM_POSITIVE_MAKENODE}}, {SIGN, []Kind{MINUS, SEM_NEGATIVE_MAKENODE}}},
 		STATBLOCK:                         []Rule{{STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, OPENCUBR, REPT_STATBLOCK1, CLOSECUBR}}, {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}}, {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, EPSILON}}},
-		STATEMENT:                         []Rule{{STATEMENT, []Kind{ASSIGNSTATORFUNCCALL}}, {STATEMENT, []Kind{IF, OPENPAR, RELEXPR, CLOSEPAR, THEN, STATBLOCK, ELSE, STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}}, {STATEMENT, []Kind{WHILE, OPENPAR, RELEXPR, CLOSEPAR, STATBLOCK, SEMI, SEM_WHILE_MAKEFAMILY}}, {STATEMENT, []Kind{READ, OPENPAR, VARIABLE, CLOSEPAR, SEMI, SEM_READ_MAKEFAMILY}}, {STATEMENT, []Kind{WRITE, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_WRITE_MAKEFAMILY}}, {STATEMENT, []Kind{RETURN, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_RETURN_MAKEFAMILY}}},
+		STATEMENT:                         []Rule{{STATEMENT, []Kind{ASSIGNSTATORFUNCCALL}}, {STATEMENT, []Kind{IF, SEM_IF_MAKENODE, OPENPAR, EXPR, CLOSEPAR, THEN, STATBLOCK, IF_TAIL}}, {STATEMENT, []Kind{WHILE, SEM_WHILE_MAKENODE, OPENPAR, EXPR, CLOSEPAR, STATBLOCK, SEMI, SEM_WHILE_MAKEFAMILY}}, {STATEMENT, []Kind{FOR, SEM_FOR_MAKENODE, OPENPAR, FORINIT, SEMI, EXPR, SEMI, ASSIGNSTAT, CLOSEPAR, STATBLOCK, SEMI, SEM_FOR_MAKEFAMILY}}, {STATEMENT, []Kind{BREAK, SEM_BREAK_MAKENODE, SEMI}}, {STATEMENT, []Kind{CONTINUE, SEM_CONTINUE_MAKENODE, SEMI}}, {STATEMENT, []Kind{READ, OPENPAR, VARIABLE, CLOSEPAR, SEMI, SEM_READ_MAKEFAMILY}}, {STATEMENT, []Kind{WRITE, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_WRITE_MAKEFAMILY}}, {STATEMENT, []Kind{RETURN, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_RETURN_MAKEFAMILY}}},
+		STRINGLITT:                        []Rule{{STRINGLITT, []Kind{STRINGLIT, SEM_STRINGLIT_MAKENODE}}},
 		STRUCTDECL:                        []Rule{{STRUCTDECL, []Kind{STRUCT, IDD, SEM_INHERITS_FRESH, OPT_STRUCTDECL2, OPENCUBR, REPT_STRUCTDECL4, CLOSECUBR, SEMI, SEM_STRUCT_DECL_MAKEFAMILY}}},
 		STRUCTORIMPLORFUNC:                []Rule{{STRUCTORIMPLORFUNC, []Kind{STRUCTDECL}}, {STRUCTORIMPLORFUNC, []Kind{IMPLDEF}}, {STRUCTORIMPLORFUNC, []Kind{FUNCDEF}}},
 		TERM:                              []Rule{{TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}}},
-		TYPE:                              []Rule{{TYPE, []Kind{INTEGER, SEM_INTEGER_MAKENODE, SEM_TYPE_MAKEFAMILY}}, {TYPE, []Kind{FLOAT, SEM_FLOAT_MAKENODE, SEM_TYPE_MAKEFAMILY}}, {TYPE, []Kind{IDD, SEM_TYPE_MAKEFAMILY}}},
+		TYPE:                              []Rule{{TYPE, []Kind{INTEGER, SEM_INTEGER_MAKENODE, SEM_TYPE_MAKEFAMILY}}, {TYPE, []Kind{FLOAT, SEM_FLOAT_MAKENODE, SEM_TYPE_MAKEFAMILY}}, {TYPE, []Kind{STRING, SEM_STRING_MAKENODE, SEM_TYPE_MAKEFAMILY}}, {TYPE, []Kind{BOOL, SEM_BOOL_MAKENODE, SEM_TYPE_MAKEFAMILY}}, {TYPE, []Kind{IDD, SEM_TYPE_MAKEFAMILY}}},
 		VARDECL:                           []Rule{{VARDECL, []Kind{LET, IDD, COLON, TYPE, REPT_VARDECL4, SEMI, SEM_VAR_DECL_MAKEFAMILY}}},
 		VARDECLORSTAT:                     []Rule{{VARDECLORSTAT, []Kind{VARDECL}}, {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}}},
 		VARORFUNCCALL_DISAMBIGUATE:        []Rule{{VARORFUNCCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, ANOTHER}}, {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}}},
@@ -877,10 +970,15 @@ var FIRSTS = func() map[Kind]KindSet {
 		OPENSQBR:                          {OPENSQBR: {}},
 		CLOSESQBR:                         {CLOSESQBR: {}},
 		AND:                               {AND: {}},
+		BOOL:                              {BOOL: {}},
+		BREAK:                             {BREAK: {}},
+		CONTINUE:                          {CONTINUE: {}},
 		ELSE:                              {ELSE: {}},
 		EQ:                                {EQ: {}},
+		FALSE:                             {FALSE: {}},
 		FLOAT:                             {FLOAT: {}},
 		FLOATNUM:                          {FLOATNUM: {}},
+		FOR:                               {FOR: {}},
 		FUNC:                              {FUNC: {}},
 		GEQ:                               {GEQ: {}},
 		GT:                                {GT: {}},
@@ -900,21 +998,24 @@ var FIRSTS = func() map[Kind]KindSet {
 		PUBLIC:                            {PUBLIC: {}},
 		READ:                              {READ: {}},
 		RETURN:                            {RETURN: {}},
+		STRING:                            {STRING: {}},
+		STRINGLIT:                         {STRINGLIT: {}},
 		STRUCT:                            {STRUCT: {}},
 		THEN:                              {THEN: {}},
+		TRUE:                              {TRUE: {}},
 		VOID:                              {VOID: {}},
 		WHILE:                             {WHILE: {}},
 		WRITE:                             {WRITE: {}},
 		OPENCUBR:                          {OPENCUBR: {}},
 		CLOSECUBR:                         {CLOSECUBR: {}},
 		START:                             {FUNC: {}, IMPL: {}, STRUCT: {}, EPSILON: {}},
-		APARAMS:                           {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, EPSILON: {}},
+		APARAMS:                           {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}, EPSILON: {}},
 		APARAMSTAIL:                       {COMMA: {}},
 		ADDOP:                             {PLUS: {}, MINUS: {}, OR: {}},
 		ANOTHER_FUNCTIONCALL:              {DOT: {}, EPSILON: {}},
 		ANOTHER_VARIABLE:                  {DOT: {}, EPSILON: {}},
 		ANOTHER:                           {DOT: {}, EPSILON: {}},
-		ARITHEXPR:                         {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		ARITHEXPR:                         {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		ARITHORRELEXPR_DISAMBIGUATE:       {EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, EPSILON: {}},
 		ARRAYSIZE_FACTORIZED:              {CLOSESQBR: {}, INTNUM: {}},
 		ARRAYSIZE:                         {OPENSQBR: {}},
@@ -922,11 +1023,13 @@ var FIRSTS = func() map[Kind]KindSet {
 		ASSIGNSTAT:                        {ID: {}},
 		ASSIGNSTATORFUNCCALL_DISAMBIGUATE: {OPENPAR: {}, DOT: {}, ASSIGN: {}, OPENSQBR: {}},
 		ASSIGNSTATORFUNCCALL:              {ID: {}},
-		EXPR:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		ELSE_DISAMBIGUATE:                 {SEMI: {}, BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}},
+		EXPR:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		FPARAMS:                           {ID: {}, EPSILON: {}},
 		FPARAMSTAIL:                       {COMMA: {}},
-		FACTOR:                            {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		FACTOR:                            {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		FLOATNUMM:                         {FLOATNUM: {}},
+		FORINIT:                           {ID: {}, LET: {}},
 		FUNCBODY:                          {OPENCUBR: {}},
 		FUNCDECL:                          {FUNC: {}},
 		FUNCDEF:                           {FUNC: {}},
@@ -934,6 +1037,7 @@ var FIRSTS = func() map[Kind]KindSet {
 		FUNCTIONCALL_DISAMBIGUATE:         {OPENPAR: {}, DOT: {}, OPENSQBR: {}},
 		FUNCTIONCALL:                      {ID: {}},
 		IDD:                               {ID: {}},
+		IF_TAIL:                           {SEMI: {}, ELSE: {}},
 		IMPLDEF:                           {IMPL: {}},
 		INDICE:                            {OPENSQBR: {}},
 		INTNUMM:                           {INTNUM: {}},
@@ -945,31 +1049,31 @@ var FIRSTS = func() map[Kind]KindSet {
 		NOTT:                              {NOT: {}},
 		OPT_STRUCTDECL2:                   {INHERITS: {}, EPSILON: {}},
 		PROG:                              {FUNC: {}, IMPL: {}, STRUCT: {}, EPSILON: {}},
-		RELEXPR:                           {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
 		RELOP:                             {EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}},
 		REPT_APARAMS1:                     {COMMA: {}, EPSILON: {}},
 		REPT_FPARAMS3:                     {OPENSQBR: {}, EPSILON: {}},
 		REPT_FPARAMS4:                     {COMMA: {}, EPSILON: {}},
 		REPT_FPARAMSTAIL4:                 {OPENSQBR: {}, EPSILON: {}},
-		REPT_FUNCBODY1:                    {ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, EPSILON: {}},
+		REPT_FUNCBODY1:                    {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, EPSILON: {}},
 		REPT_IMPLDEF3:                     {FUNC: {}, EPSILON: {}},
 		REPT_OPT_STRUCTDECL22:             {COMMA: {}, EPSILON: {}},
 		REPT_PROG0:                        {FUNC: {}, IMPL: {}, STRUCT: {}, EPSILON: {}},
-		REPT_STATBLOCK1:                   {ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, EPSILON: {}},
+		REPT_STATBLOCK1:                   {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, EPSILON: {}},
 		REPT_STRUCTDECL4:                  {PRIVATE: {}, PUBLIC: {}, EPSILON: {}},
 		REPT_VARDECL4:                     {OPENSQBR: {}, EPSILON: {}},
-		RETURNTYPE:                        {FLOAT: {}, ID: {}, INTEGER: {}, VOID: {}},
+		RETURNTYPE:                        {BOOL: {}, FLOAT: {}, ID: {}, INTEGER: {}, STRING: {}, VOID: {}},
 		RIGHTREC_ARITHEXPR:                {PLUS: {}, MINUS: {}, OR: {}, EPSILON: {}},
 		RIGHTREC_TERM:                     {MULT: {}, DIV: {}, AND: {}, EPSILON: {}},
 		SIGN:                              {PLUS: {}, MINUS: {}},
-		STATBLOCK:                         {ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, EPSILON: {}},
-		STATEMENT:                         {ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}},
+		STATBLOCK:                         {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, EPSILON: {}},
+		STATEMENT:                         {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}},
+		STRINGLITT:                        {STRINGLIT: {}},
 		STRUCTDECL:                        {STRUCT: {}},
 		STRUCTORIMPLORFUNC:                {FUNC: {}, IMPL: {}, STRUCT: {}},
-		TERM:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		TYPE:                              {FLOAT: {}, ID: {}, INTEGER: {}},
+		TERM:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		TYPE:                              {BOOL: {}, FLOAT: {}, ID: {}, INTEGER: {}, STRING: {}},
 		VARDECL:                           {LET: {}},
-		VARDECLORSTAT:                     {ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}},
+		VARDECLORSTAT:                     {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}},
 		VARORFUNCCALL_DISAMBIGUATE:        {OPENPAR: {}, DOT: {}, OPENSQBR: {}, EPSILON: {}},
 		VARORFUNCCALL:                     {ID: {}},
 		VARIABLE_DISAMBIGUATE:             {OPENPAR: {}, DOT: {}, OPENSQBR: {}, EPSILON: {}},
@@ -982,55 +1086,63 @@ var FIRSTS = func() map[Kind]KindSet {
 
 var FOLLOWS = func() map[Kind]KindSet {
 	return map[Kind]KindSet{
-		OPENPAR:                           {OPENPAR: {}, CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, ASSIGN: {}, CLOSESQBR: {}, AND: {}, EQ: {}, FLOATNUM: {}, GEQ: {}, GT: {}, ID: {}, IF: {}, INTNUM: {}, LEQ: {}, LET: {}, LT: {}, NOTEQ: {}, NOT: {}, OR: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
-		CLOSEPAR:                          {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, ARROW: {}, DOT: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, ID: {}, IF: {}, LEQ: {}, LET: {}, LT: {}, NOTEQ: {}, OR: {}, READ: {}, RETURN: {}, THEN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
-		MULT:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		PLUS:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		COMMA:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		MINUS:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		ARROW:                             {FLOAT: {}, ID: {}, INTEGER: {}, VOID: {}},
+		OPENPAR:                           {OPENPAR: {}, CLOSEPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, LET: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		CLOSEPAR:                          {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, ARROW: {}, DOT: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, BREAK: {}, CONTINUE: {}, EQ: {}, FOR: {}, GEQ: {}, GT: {}, ID: {}, IF: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}, READ: {}, RETURN: {}, THEN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}},
+		MULT:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		PLUS:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		COMMA:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		MINUS:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		ARROW:                             {BOOL: {}, FLOAT: {}, ID: {}, INTEGER: {}, STRING: {}, VOID: {}},
 		DOT:                               {ID: {}},
-		DIV:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		COLON:                             {FLOAT: {}, ID: {}, INTEGER: {}},
-		SEMI:                              {SEMI: {}, FUNC: {}, ID: {}, IF: {}, IMPL: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, STRUCT: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
-		ASSIGN:                            {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		OPENSQBR:                          {OPENPAR: {}, PLUS: {}, MINUS: {}, CLOSESQBR: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		DIV:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		COLON:                             {BOOL: {}, FLOAT: {}, ID: {}, INTEGER: {}, STRING: {}},
+		SEMI:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FALSE: {}, FLOATNUM: {}, FOR: {}, FUNC: {}, ID: {}, IF: {}, IMPL: {}, INTNUM: {}, LET: {}, NOT: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, STRINGLIT: {}, STRUCT: {}, TRUE: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		ASSIGN:                            {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		OPENSQBR:                          {OPENPAR: {}, PLUS: {}, MINUS: {}, CLOSESQBR: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		CLOSESQBR:                         {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
-		AND:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		ELSE:                              {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
-		EQ:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		FLOAT:                             {CLOSEPAR: {}, COMMA: {}, SEMI: {}, OPENSQBR: {}, ID: {}, IF: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
+		AND:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		BOOL:                              {CLOSEPAR: {}, COMMA: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, OPENCUBR: {}},
+		BREAK:                             {SEMI: {}},
+		CONTINUE:                          {SEMI: {}},
+		ELSE:                              {SEMI: {}, BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}},
+		EQ:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		FALSE:                             {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
+		FLOAT:                             {CLOSEPAR: {}, COMMA: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, OPENCUBR: {}},
 		FLOATNUM:                          {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
+		FOR:                               {OPENPAR: {}},
 		FUNC:                              {ID: {}},
-		GEQ:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		GT:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		ID:                                {OPENPAR: {}, CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, COLON: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, CLOSESQBR: {}, AND: {}, EQ: {}, FUNC: {}, GEQ: {}, GT: {}, ID: {}, IF: {}, IMPL: {}, INHERITS: {}, LEQ: {}, LET: {}, LT: {}, NOTEQ: {}, OR: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, STRUCT: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
+		GEQ:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		GT:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		ID:                                {OPENPAR: {}, CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, COLON: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, INHERITS: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}, OPENCUBR: {}},
 		IF:                                {OPENPAR: {}},
 		IMPL:                              {ID: {}},
 		INHERITS:                          {ID: {}},
 		INTNUM:                            {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
-		INTEGER:                           {CLOSEPAR: {}, COMMA: {}, SEMI: {}, OPENSQBR: {}, ID: {}, IF: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
-		LEQ:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		INTEGER:                           {CLOSEPAR: {}, COMMA: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, OPENCUBR: {}},
+		LEQ:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		LET:                               {ID: {}},
-		LT:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		NOTEQ:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		NOT:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		OR:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		LT:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		NOTEQ:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		NOT:                               {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		OR:                                {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		PRIVATE:                           {FUNC: {}, LET: {}},
 		PUBLIC:                            {FUNC: {}, LET: {}},
 		READ:                              {OPENPAR: {}},
 		RETURN:                            {OPENPAR: {}},
+		STRING:                            {CLOSEPAR: {}, COMMA: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, OPENCUBR: {}},
+		STRINGLIT:                         {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		STRUCT:                            {ID: {}},
-		THEN:                              {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
+		THEN:                              {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}},
+		TRUE:                              {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		VOID:                              {SEMI: {}, OPENCUBR: {}},
 		WHILE:                             {OPENPAR: {}},
 		WRITE:                             {OPENPAR: {}},
-		OPENCUBR:                          {SEMI: {}, FUNC: {}, ID: {}, IF: {}, IMPL: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, STRUCT: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
-		CLOSECUBR:                         {SEMI: {}, FUNC: {}, IMPL: {}, STRUCT: {}, CLOSECUBR: {}},
+		OPENCUBR:                          {BREAK: {}, CONTINUE: {}, FOR: {}, FUNC: {}, ID: {}, IF: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		CLOSECUBR:                         {SEMI: {}, ELSE: {}, FUNC: {}, IMPL: {}, STRUCT: {}, CLOSECUBR: {}},
 		START:                             {},
 		APARAMS:                           {CLOSEPAR: {}},
 		APARAMSTAIL:                       {CLOSEPAR: {}, COMMA: {}},
-		ADDOP:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		ADDOP:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		ANOTHER_FUNCTIONCALL:              {},
 		ANOTHER_VARIABLE:                  {CLOSEPAR: {}, ASSIGN: {}},
 		ANOTHER:                           {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
@@ -1038,35 +1150,37 @@ var FOLLOWS = func() map[Kind]KindSet {
 		ARITHORRELEXPR_DISAMBIGUATE:       {CLOSEPAR: {}, COMMA: {}, SEMI: {}},
 		ARRAYSIZE_FACTORIZED:              {CLOSEPAR: {}, COMMA: {}, SEMI: {}, OPENSQBR: {}},
 		ARRAYSIZE:                         {CLOSEPAR: {}, COMMA: {}, SEMI: {}, OPENSQBR: {}},
-		ASSIGNOP:                          {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		ASSIGNSTAT:                        {},
-		ASSIGNSTATORFUNCCALL_DISAMBIGUATE: {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
-		ASSIGNSTATORFUNCCALL:              {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		ASSIGNOP:                          {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		ASSIGNSTAT:                        {CLOSEPAR: {}, SEMI: {}},
+		ASSIGNSTATORFUNCCALL_DISAMBIGUATE: {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		ASSIGNSTATORFUNCCALL:              {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		ELSE_DISAMBIGUATE:                 {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
 		EXPR:                              {CLOSEPAR: {}, COMMA: {}, SEMI: {}},
 		FPARAMS:                           {CLOSEPAR: {}},
 		FPARAMSTAIL:                       {CLOSEPAR: {}, COMMA: {}},
 		FACTOR:                            {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		FLOATNUMM:                         {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
+		FORINIT:                           {SEMI: {}},
 		FUNCBODY:                          {FUNC: {}, IMPL: {}, STRUCT: {}, CLOSECUBR: {}},
 		FUNCDECL:                          {PRIVATE: {}, PUBLIC: {}, CLOSECUBR: {}},
 		FUNCDEF:                           {FUNC: {}, IMPL: {}, STRUCT: {}, CLOSECUBR: {}},
 		FUNCHEAD:                          {SEMI: {}, OPENCUBR: {}},
 		FUNCTIONCALL_DISAMBIGUATE:         {},
 		FUNCTIONCALL:                      {},
-		IDD:                               {OPENPAR: {}, CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, COLON: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, CLOSESQBR: {}, AND: {}, EQ: {}, FUNC: {}, GEQ: {}, GT: {}, ID: {}, IF: {}, IMPL: {}, INHERITS: {}, LEQ: {}, LET: {}, LT: {}, NOTEQ: {}, OR: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, STRUCT: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
+		IDD:                               {OPENPAR: {}, CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, COLON: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, INHERITS: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}, OPENCUBR: {}},
+		IF_TAIL:                           {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
 		IMPLDEF:                           {FUNC: {}, IMPL: {}, STRUCT: {}},
 		INDICE:                            {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		INTNUMM:                           {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		MEMBERDECL:                        {PRIVATE: {}, PUBLIC: {}, CLOSECUBR: {}},
-		MORE_ASSIGN:                       {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
-		MORE_FUNC:                         {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		MORE_ASSIGN:                       {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		MORE_FUNC:                         {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
 		MORE_INDICE:                       {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, SEMI: {}, ASSIGN: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
-		MULTOP:                            {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		NOTT:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		MULTOP:                            {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		NOTT:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		OPT_STRUCTDECL2:                   {OPENCUBR: {}},
 		PROG:                              {},
-		RELEXPR:                           {CLOSEPAR: {}},
-		RELOP:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
+		RELOP:                             {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
 		REPT_APARAMS1:                     {CLOSEPAR: {}},
 		REPT_FPARAMS3:                     {CLOSEPAR: {}, COMMA: {}},
 		REPT_FPARAMS4:                     {CLOSEPAR: {}},
@@ -1081,22 +1195,23 @@ var FOLLOWS = func() map[Kind]KindSet {
 		RETURNTYPE:                        {SEMI: {}, OPENCUBR: {}},
 		RIGHTREC_ARITHEXPR:                {CLOSEPAR: {}, COMMA: {}, SEMI: {}, CLOSESQBR: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}},
 		RIGHTREC_TERM:                     {CLOSEPAR: {}, PLUS: {}, COMMA: {}, MINUS: {}, SEMI: {}, CLOSESQBR: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
-		SIGN:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}},
-		STATBLOCK:                         {SEMI: {}},
-		STATEMENT:                         {SEMI: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		SIGN:                              {OPENPAR: {}, PLUS: {}, MINUS: {}, FALSE: {}, FLOATNUM: {}, ID: {}, INTNUM: {}, NOT: {}, STRINGLIT: {}, TRUE: {}},
+		STATBLOCK:                         {SEMI: {}, ELSE: {}},
+		STATEMENT:                         {SEMI: {}, BREAK: {}, CONTINUE: {}, ELSE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		STRINGLITT:                        {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		STRUCTDECL:                        {FUNC: {}, IMPL: {}, STRUCT: {}},
 		STRUCTORIMPLORFUNC:                {FUNC: {}, IMPL: {}, STRUCT: {}},
 		TERM:                              {CLOSEPAR: {}, PLUS: {}, COMMA: {}, MINUS: {}, SEMI: {}, CLOSESQBR: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
-		TYPE:                              {CLOSEPAR: {}, COMMA: {}, SEMI: {}, OPENSQBR: {}, ID: {}, IF: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, OPENCUBR: {}, CLOSECUBR: {}},
-		VARDECL:                           {ID: {}, IF: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
-		VARDECLORSTAT:                     {ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		TYPE:                              {CLOSEPAR: {}, COMMA: {}, SEMI: {}, ASSIGN: {}, OPENSQBR: {}, OPENCUBR: {}},
+		VARDECL:                           {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, PRIVATE: {}, PUBLIC: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
+		VARDECLORSTAT:                     {BREAK: {}, CONTINUE: {}, FOR: {}, ID: {}, IF: {}, LET: {}, READ: {}, RETURN: {}, WHILE: {}, WRITE: {}, CLOSECUBR: {}},
 		VARORFUNCCALL_DISAMBIGUATE:        {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		VARORFUNCCALL:                     {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DIV: {}, SEMI: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}},
 		VARIABLE_DISAMBIGUATE:             {CLOSEPAR: {}, ASSIGN: {}},
 		VARIABLE:                          {CLOSEPAR: {}, ASSIGN: {}},
 		VISIBILITY:                        {FUNC: {}, LET: {}},
 		VOIDD:                             {SEMI: {}, OPENCUBR: {}},
-		EPSILON:                           {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, SEMI: {}, ASSIGN: {}, CLOSESQBR: {}, AND: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}, OPENCUBR: {}, CLOSECUBR: {}},
+		EPSILON:                           {CLOSEPAR: {}, MULT: {}, PLUS: {}, COMMA: {}, MINUS: {}, DOT: {}, DIV: {}, SEMI: {}, ASSIGN: {}, CLOSESQBR: {}, AND: {}, ELSE: {}, EQ: {}, GEQ: {}, GT: {}, LEQ: {}, LT: {}, NOTEQ: {}, OR: {}, OPENCUBR: {}, CLOSECUBR: {}},
 	}
 }
 
@@ -1113,10 +1228,13 @@ var TABLE = func() map[Key]Rule {
 		{APARAMS, OPENPAR}:                            {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, PLUS}:                               {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, MINUS}:                              {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
+		{APARAMS, FALSE}:                              {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, FLOATNUM}:                           {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, ID}:                                 {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, INTNUM}:                             {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, NOT}:                                {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
+		{APARAMS, STRINGLIT}:                          {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
+		{APARAMS, TRUE}:                               {APARAMS, []Kind{EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY, REPT_APARAMS1}},
 		{APARAMS, CLOSEPAR}:                           {APARAMS, []Kind{EPSILON, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY}},
 		{APARAMSTAIL, COMMA}:                          {APARAMSTAIL, []Kind{COMMA, EXPR, SEM_FUNC_CALL_PARAM_MAKENODE, SEM_FUNC_CALL_PARAMLIST_MAKEFAMILY}},
 		{ADDOP, PLUS}:                                 {ADDOP, []Kind{PLUS, SEM_PLUS_MAKENODE}},
@@ -1146,10 +1264,13 @@ var TABLE = func() map[Key]Rule {
 		{ARITHEXPR, OPENPAR}:                          {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHEXPR, PLUS}:                             {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHEXPR, MINUS}:                            {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
+		{ARITHEXPR, FALSE}:                            {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHEXPR, FLOATNUM}:                         {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHEXPR, ID}:                               {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHEXPR, INTNUM}:                           {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHEXPR, NOT}:                              {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
+		{ARITHEXPR, STRINGLIT}:                        {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
+		{ARITHEXPR, TRUE}:                             {ARITHEXPR, []Kind{TERM, RIGHTREC_ARITHEXPR, SEM_ARITH_EXPR_MAKENODE}},
 		{ARITHORRELEXPR_DISAMBIGUATE, EQ}:             {ARITHORRELEXPR_DISAMBIGUATE, []Kind{RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
 		{ARITHORRELEXPR_DISAMBIGUATE, GEQ}:            {ARITHORRELEXPR_DISAMBIGUATE, []Kind{RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
 		{ARITHORRELEXPR_DISAMBIGUATE, GT}:             {ARITHORRELEXPR_DISAMBIGUATE, []Kind{RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
@@ -1169,219 +1290,258 @@ var TABLE = func() map[Key]Rule {
 		{ASSIGNSTATORFUNCCALL_DISAMBIGUATE, OPENSQBR}: {ASSIGNSTATORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, MORE_ASSIGN}},
 		{ASSIGNSTATORFUNCCALL_DISAMBIGUATE, OPENPAR}:  {ASSIGNSTATORFUNCCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, MORE_FUNC}},
 		{ASSIGNSTATORFUNCCALL, ID}:                    {ASSIGNSTATORFUNCCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, ASSIGNSTATORFUNCCALL_DISAMBIGUATE}},
-		{EXPR, OPENPAR}:                               {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{EXPR, PLUS}:                                  {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{EXPR, MINUS}:                                 {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{EXPR, FLOATNUM}:                              {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{EXPR, ID}:                                    {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{EXPR, INTNUM}:                                {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{EXPR, NOT}:                                   {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
-		{FPARAMS, ID}:                                 {FPARAMS, []Kind{IDD, COLON, TYPE, REPT_FPARAMS3, SEM_DIMLIST_MAKEFAMILY, SEM_FPARAM_MAKEFAMILY, SEM_FPARAM_LIST_MAKEFAMILY, REPT_FPARAMS4}},
-		{FPARAMS, CLOSEPAR}:                           {FPARAMS, []Kind{EPSILON, SEM_FPARAM_LIST_MAKEFAMILY}},
-		{FPARAMSTAIL, COMMA}:                          {FPARAMSTAIL, []Kind{COMMA, IDD, COLON, TYPE, REPT_FPARAMSTAIL4, SEM_DIMLIST_MAKEFAMILY, SEM_FPARAM_MAKEFAMILY, SEM_FPARAM_LIST_MAKEFAMILY}},
-		{FACTOR, ID}:                                  {FACTOR, []Kind{VARORFUNCCALL, SEM_FACTOR_MAKENODE}},
-		{FACTOR, INTNUM}:                              {FACTOR, []Kind{INTNUMM, SEM_FACTOR_MAKENODE}},
-		{FACTOR, FLOATNUM}:                            {FACTOR, []Kind{FLOATNUMM, SEM_FACTOR_MAKENODE}},
-		{FACTOR, OPENPAR}:                             {FACTOR, []Kind{OPENPAR, ARITHEXPR, CLOSEPAR, SEM_FACTOR_MAKENODE}},
-		{FACTOR, NOT}:                                 {FACTOR, []Kind{NOTT, FACTOR, SEM_FACTOR_MAKENODE}},
-		{FACTOR, PLUS}:                                {FACTOR, []Kind{SIGN, FACTOR, SEM_FACTOR_MAKENODE}},
-		{FACTOR, MINUS}:                               {FACTOR, []Kind{SIGN, FACTOR, SEM_FACTOR_MAKENODE}},
-		{FLOATNUMM, FLOATNUM}:                         {FLOATNUMM, []Kind{FLOATNUM, SEM_FLOATNUM_MAKENODE}},
-		{FUNCBODY, OPENCUBR}:                          {FUNCBODY, []Kind{OPENCUBR, REPT_FUNCBODY1, CLOSECUBR}},
-		{FUNCDECL, FUNC}:                              {FUNCDECL, []Kind{FUNCHEAD, SEMI, SEM_FUNC_DECL_MAKEFAMILY}},
-		{FUNCDEF, FUNC}:                               {FUNCDEF, []Kind{FUNCHEAD, FUNCBODY, SEM_FUNC_DEF_MAKEFAMILY}},
-		{FUNCHEAD, FUNC}:                              {FUNCHEAD, []Kind{FUNC, IDD, OPENPAR, FPARAMS, CLOSEPAR, ARROW, RETURNTYPE}},
-		{FUNCTIONCALL_DISAMBIGUATE, OPENPAR}:          {FUNCTIONCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, ANOTHER_FUNCTIONCALL}},
-		{FUNCTIONCALL_DISAMBIGUATE, DOT}:              {FUNCTIONCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, DOT, FUNCTIONCALL}},
-		{FUNCTIONCALL_DISAMBIGUATE, OPENSQBR}:         {FUNCTIONCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, DOT, FUNCTIONCALL}},
-		{FUNCTIONCALL, ID}:                            {FUNCTIONCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, FUNCTIONCALL_DISAMBIGUATE}},
-		{IDD, ID}:                                     {IDD, []Kind{ID, SEM_ID_MAKENODE}},
-		{IMPLDEF, IMPL}:                               {IMPLDEF, []Kind{IMPL, IDD, OPENCUBR, REPT_IMPLDEF3, CLOSECUBR, SEM_IMPL_DEF_MAKEFAMILY}},
-		{INDICE, OPENSQBR}:                            {INDICE, []Kind{OPENSQBR, ARITHEXPR, CLOSESQBR, SEM_INDEX_MAKENODE, SEM_INDEXLIST_MAKEFAMILY}},
-		{INTNUMM, INTNUM}:                             {INTNUMM, []Kind{INTNUM, SEM_INTNUM_MAKENODE}},
-		{MEMBERDECL, FUNC}:                            {MEMBERDECL, []Kind{FUNCDECL}},
-		{MEMBERDECL, LET}:                             {MEMBERDECL, []Kind{VARDECL}},
-		{MORE_ASSIGN, DOT}:                            {MORE_ASSIGN, []Kind{DOT, ASSIGNSTATORFUNCCALL}},
-		{MORE_ASSIGN, ASSIGN}:                         {MORE_ASSIGN, []Kind{ASSIGNOP, EXPR, SEMI, SEM_ASSIGN_MAKEFAMILY}},
-		{MORE_FUNC, DOT}:                              {MORE_FUNC, []Kind{DOT, ASSIGNSTATORFUNCCALL}},
-		{MORE_FUNC, SEMI}:                             {MORE_FUNC, []Kind{SEMI}},
-		{MORE_INDICE, OPENSQBR}:                       {MORE_INDICE, []Kind{INDICE, MORE_INDICE}},
-		{MORE_INDICE, CLOSEPAR}:                       {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, MULT}:                           {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, PLUS}:                           {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, COMMA}:                          {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, MINUS}:                          {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, DOT}:                            {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, DIV}:                            {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, SEMI}:                           {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, ASSIGN}:                         {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, CLOSESQBR}:                      {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, AND}:                            {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, EQ}:                             {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, GEQ}:                            {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, GT}:                             {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, LEQ}:                            {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, LT}:                             {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, NOTEQ}:                          {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MORE_INDICE, OR}:                             {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
-		{MULTOP, MULT}:                                {MULTOP, []Kind{MULT, SEM_MULT_MAKENODE}},
-		{MULTOP, DIV}:                                 {MULTOP, []Kind{DIV, SEM_DIV_MAKENODE}},
-		{MULTOP, AND}:                                 {MULTOP, []Kind{AND, SEM_AND_MAKENODE}},
-		{NOTT, NOT}:                                   {NOTT, []Kind{NOT, SEM_NOT_MAKENODE}},
-		{OPT_STRUCTDECL2, INHERITS}:                   {OPT_STRUCTDECL2, []Kind{INHERITS, IDD, SEM_INHERITS_MAKEFAMILY, REPT_OPT_STRUCTDECL22}},
-		{OPT_STRUCTDECL2, OPENCUBR}:                   {OPT_STRUCTDECL2, []Kind{EPSILON}},
-		{PROG, FUNC}:                                  {PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}},
-		{PROG, IMPL}:                                  {PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}},
-		{PROG, STRUCT}:                                {PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}},
-		{RELEXPR, OPENPAR}:                            {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELEXPR, PLUS}:                               {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELEXPR, MINUS}:                              {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELEXPR, FLOATNUM}:                           {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELEXPR, ID}:                                 {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELEXPR, INTNUM}:                             {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELEXPR, NOT}:                                {RELEXPR, []Kind{ARITHEXPR, RELOP, ARITHEXPR, SEM_REL_MAKEFAMILY, SEM_REL_EXPR_MAKENODE}},
-		{RELOP, EQ}:                                   {RELOP, []Kind{EQ, SEM_EQ_MAKENODE}},
-		{RELOP, NOTEQ}:                                {RELOP, []Kind{NOTEQ, SEM_NEQ_MAKENODE}},
-		{RELOP, LT}:                                   {RELOP, []Kind{LT, SEM_LT_MAKENODE}},
-		{RELOP, GT}:                                   {RELOP, []Kind{GT, SEM_GT_MAKENODE}},
-		{RELOP, LEQ}:                                  {RELOP, []Kind{LEQ, SEM_LEQ_MAKENODE}},
-		{RELOP, GEQ}:                                  {RELOP, []Kind{GEQ, SEM_GEQ_MAKENODE}},
-		{REPT_APARAMS1, COMMA}:                        {REPT_APARAMS1, []Kind{APARAMSTAIL, REPT_APARAMS1}},
-		{REPT_APARAMS1, CLOSEPAR}:                     {REPT_APARAMS1, []Kind{EPSILON}},
-		{REPT_FPARAMS3, OPENSQBR}:                     {REPT_FPARAMS3, []Kind{ARRAYSIZE, REPT_FPARAMS3}},
-		{REPT_FPARAMS3, CLOSEPAR}:                     {REPT_FPARAMS3, []Kind{EPSILON}},
-		{REPT_FPARAMS3, COMMA}:                        {REPT_FPARAMS3, []Kind{EPSILON}},
-		{REPT_FPARAMS4, COMMA}:                        {REPT_FPARAMS4, []Kind{FPARAMSTAIL, REPT_FPARAMS4}},
-		{REPT_FPARAMS4, CLOSEPAR}:                     {REPT_FPARAMS4, []Kind{EPSILON}},
-		{REPT_FPARAMSTAIL4, OPENSQBR}:                 {REPT_FPARAMSTAIL4, []Kind{ARRAYSIZE, REPT_FPARAMSTAIL4}},
-		{REPT_FPARAMSTAIL4, CLOSEPAR}:                 {REPT_FPARAMSTAIL4, []Kind{EPSILON}},
-		{REPT_FPARAMSTAIL4, COMMA}:                    {REPT_FPARAMSTAIL4, []Kind{EPSILON}},
-		{REPT_FUNCBODY1, ID}:                          {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, IF}:                          {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, LET}:                         {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, READ}:                        {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, RETURN}:                      {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, WHILE}:                       {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, WRITE}:                       {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
-		{REPT_FUNCBODY1, CLOSECUBR}:                   {REPT_FUNCBODY1, []Kind{EPSILON, SEM_FUNC_BODY_MAKEFAMILY}},
-		{REPT_IMPLDEF3, FUNC}:                         {REPT_IMPLDEF3, []Kind{FUNCDEF, SEM_FUNCDEFLIST_MAKEFAMILY, REPT_IMPLDEF3}},
-		{REPT_IMPLDEF3, CLOSECUBR}:                    {REPT_IMPLDEF3, []Kind{EPSILON, SEM_FUNCDEFLIST_MAKEFAMILY}},
-		{REPT_OPT_STRUCTDECL22, COMMA}:                {REPT_OPT_STRUCTDECL22, []Kind{COMMA, IDD, SEM_INHERITS_MAKEFAMILY, REPT_OPT_STRUCTDECL22}},
-		{REPT_OPT_STRUCTDECL22, OPENCUBR}:             {REPT_OPT_STRUCTDECL22, []Kind{EPSILON}},
-		{REPT_PROG0, FUNC}:                            {REPT_PROG0, []Kind{STRUCTORIMPLORFUNC, SEM_REPT_PROG0_MAKESIBLING, REPT_PROG0}},
-		{REPT_PROG0, IMPL}:                            {REPT_PROG0, []Kind{STRUCTORIMPLORFUNC, SEM_REPT_PROG0_MAKESIBLING, REPT_PROG0}},
-		{REPT_PROG0, STRUCT}:                          {REPT_PROG0, []Kind{STRUCTORIMPLORFUNC, SEM_REPT_PROG0_MAKESIBLING, REPT_PROG0}},
-		{REPT_STATBLOCK1, ID}:                         {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
-		{REPT_STATBLOCK1, IF}:                         {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
-		{REPT_STATBLOCK1, READ}:                       {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
-		{REPT_STATBLOCK1, RETURN}:                     {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
-		{REPT_STATBLOCK1, WHILE}:                      {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
-		{REPT_STATBLOCK1, WRITE}:                      {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
-		{REPT_STATBLOCK1, CLOSECUBR}:                  {REPT_STATBLOCK1, []Kind{EPSILON}},
-		{REPT_STRUCTDECL4, PRIVATE}:                   {REPT_STRUCTDECL4, []Kind{VISIBILITY, MEMBERDECL, SEM_MEMBER_MAKEFAMILY, SEM_MEMBERS_MAKEFAMILY, REPT_STRUCTDECL4}},
-		{REPT_STRUCTDECL4, PUBLIC}:                    {REPT_STRUCTDECL4, []Kind{VISIBILITY, MEMBERDECL, SEM_MEMBER_MAKEFAMILY, SEM_MEMBERS_MAKEFAMILY, REPT_STRUCTDECL4}},
-		{REPT_STRUCTDECL4, CLOSECUBR}:                 {REPT_STRUCTDECL4, []Kind{EPSILON, SEM_MEMBERS_MAKEFAMILY}},
-		{REPT_VARDECL4, OPENSQBR}:                     {REPT_VARDECL4, []Kind{ARRAYSIZE, REPT_VARDECL4}},
-		{REPT_VARDECL4, SEMI}:                         {REPT_VARDECL4, []Kind{EPSILON, SEM_DIMLIST_MAKEFAMILY}},
-		{RETURNTYPE, FLOAT}:                           {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
-		{RETURNTYPE, ID}:                              {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
-		{RETURNTYPE, INTEGER}:                         {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
-		{RETURNTYPE, VOID}:                            {RETURNTYPE, []Kind{VOIDD, SEM_RETURNTYPE_MAKEFAMILY}},
-		{RIGHTREC_ARITHEXPR, PLUS}:                    {RIGHTREC_ARITHEXPR, []Kind{ADDOP, TERM, SEM_ADDOP_MAKEFAMILY, RIGHTREC_ARITHEXPR}},
-		{RIGHTREC_ARITHEXPR, MINUS}:                   {RIGHTREC_ARITHEXPR, []Kind{ADDOP, TERM, SEM_ADDOP_MAKEFAMILY, RIGHTREC_ARITHEXPR}},
-		{RIGHTREC_ARITHEXPR, OR}:                      {RIGHTREC_ARITHEXPR, []Kind{ADDOP, TERM, SEM_ADDOP_MAKEFAMILY, RIGHTREC_ARITHEXPR}},
-		{RIGHTREC_ARITHEXPR, CLOSEPAR}:                {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, COMMA}:                   {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, SEMI}:                    {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, CLOSESQBR}:               {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, EQ}:                      {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, GEQ}:                     {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, GT}:                      {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, LEQ}:                     {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, LT}:                      {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_ARITHEXPR, NOTEQ}:                   {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
-		{RIGHTREC_TERM, MULT}:                         {RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}},
-		{RIGHTREC_TERM, DIV}:                          {RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}},
-		{RIGHTREC_TERM, AND}:                          {RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}},
-		{RIGHTREC_TERM, CLOSEPAR}:                     {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, PLUS}:                         {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, COMMA}:                        {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, MINUS}:                        {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, SEMI}:                         {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, CLOSESQBR}:                    {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, EQ}:                           {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, GEQ}:                          {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, GT}:                           {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, LEQ}:                          {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, LT}:                           {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, NOTEQ}:                        {RIGHTREC_TERM, []Kind{EPSILON}},
-		{RIGHTREC_TERM, OR}:                           {RIGHTREC_TERM, []Kind{EPSILON}},
-		{SIGN, PLUS}:                                  {SIGN, []Kind{PLUS, SEM_POSITIVE_MAKENODE}},
-		{SIGN, MINUS}:                                 {SIGN, []Kind{MINUS, SEM_NEGATIVE_MAKENODE}},
-		{STATBLOCK, OPENCUBR}:                         {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, OPENCUBR, REPT_STATBLOCK1, CLOSECUBR}},
-		{STATBLOCK, ID}:                               {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
-		{STATBLOCK, IF}:                               {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
-		{STATBLOCK, READ}:                             {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
-		{STATBLOCK, RETURN}:                           {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
-		{STATBLOCK, WHILE}:                            {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
-		{STATBLOCK, WRITE}:                            {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
-		{STATBLOCK, SEMI}:                             {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, EPSILON}},
-		{STATEMENT, ID}:                               {STATEMENT, []Kind{ASSIGNSTATORFUNCCALL}},
-		{STATEMENT, IF}:                               {STATEMENT, []Kind{IF, OPENPAR, RELEXPR, CLOSEPAR, THEN, STATBLOCK, ELSE, STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
-		{STATEMENT, WHILE}:                            {STATEMENT, []Kind{WHILE, OPENPAR, RELEXPR, CLOSEPAR, STATBLOCK, SEMI, SEM_WHILE_MAKEFAMILY}},
-		{STATEMENT, READ}:                             {STATEMENT, []Kind{READ, OPENPAR, VARIABLE, CLOSEPAR, SEMI, SEM_READ_MAKEFAMILY}},
-		{STATEMENT, WRITE}:                            {STATEMENT, []Kind{WRITE, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_WRITE_MAKEFAMILY}},
-		{STATEMENT, RETURN}:                           {STATEMENT, []Kind{RETURN, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_RETURN_MAKEFAMILY}},
-		{STRUCTDECL, STRUCT}:                          {STRUCTDECL, []Kind{STRUCT, IDD, SEM_INHERITS_FRESH, OPT_STRUCTDECL2, OPENCUBR, REPT_STRUCTDECL4, CLOSECUBR, SEMI, SEM_STRUCT_DECL_MAKEFAMILY}},
-		{STRUCTORIMPLORFUNC, STRUCT}:                  {STRUCTORIMPLORFUNC, []Kind{STRUCTDECL}},
-		{STRUCTORIMPLORFUNC, IMPL}:                    {STRUCTORIMPLORFUNC, []Kind{IMPLDEF}},
-		{STRUCTORIMPLORFUNC, FUNC}:                    {STRUCTORIMPLORFUNC, []Kind{FUNCDEF}},
-		{TERM, OPENPAR}:                               {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TERM, PLUS}:                                  {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TERM, MINUS}:                                 {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TERM, FLOATNUM}:                              {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TERM, ID}:                                    {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TERM, INTNUM}:                                {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TERM, NOT}:                                   {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
-		{TYPE, INTEGER}:                               {TYPE, []Kind{INTEGER, SEM_INTEGER_MAKENODE, SEM_TYPE_MAKEFAMILY}},
-		{TYPE, FLOAT}:                                 {TYPE, []Kind{FLOAT, SEM_FLOAT_MAKENODE, SEM_TYPE_MAKEFAMILY}},
-		{TYPE, ID}:                                    {TYPE, []Kind{IDD, SEM_TYPE_MAKEFAMILY}},
-		{VARDECL, LET}:                                {VARDECL, []Kind{LET, IDD, COLON, TYPE, REPT_VARDECL4, SEMI, SEM_VAR_DECL_MAKEFAMILY}},
-		{VARDECLORSTAT, LET}:                          {VARDECLORSTAT, []Kind{VARDECL}},
-		{VARDECLORSTAT, ID}:                           {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
-		{VARDECLORSTAT, IF}:                           {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
-		{VARDECLORSTAT, READ}:                         {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
-		{VARDECLORSTAT, RETURN}:                       {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
-		{VARDECLORSTAT, WHILE}:                        {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
-		{VARDECLORSTAT, WRITE}:                        {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
-		{VARORFUNCCALL_DISAMBIGUATE, OPENPAR}:         {VARORFUNCCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, DOT}:             {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, OPENSQBR}:        {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, CLOSEPAR}:        {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, MULT}:            {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, PLUS}:            {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, COMMA}:           {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, MINUS}:           {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, DIV}:             {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, SEMI}:            {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, CLOSESQBR}:       {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, AND}:             {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, EQ}:              {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, GEQ}:             {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, GT}:              {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, LEQ}:             {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, LT}:              {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, NOTEQ}:           {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL_DISAMBIGUATE, OR}:              {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
-		{VARORFUNCCALL, ID}:                           {VARORFUNCCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, VARORFUNCCALL_DISAMBIGUATE}},
-		{VARIABLE_DISAMBIGUATE, OPENPAR}:              {VARIABLE_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, DOT, VARIABLE}},
-		{VARIABLE_DISAMBIGUATE, DOT}:                  {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
-		{VARIABLE_DISAMBIGUATE, OPENSQBR}:             {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
-		{VARIABLE_DISAMBIGUATE, CLOSEPAR}:             {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
-		{VARIABLE_DISAMBIGUATE, ASSIGN}:               {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
-		{VARIABLE, ID}:                                {VARIABLE, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, VARIABLE_DISAMBIGUATE}},
-		{VISIBILITY, PUBLIC}:                          {VISIBILITY, []Kind{PUBLIC, SEM_PUBLIC_MAKENODE}},
-		{VISIBILITY, PRIVATE}:                         {VISIBILITY, []Kind{PRIVATE, SEM_PRIVATE_MAKENODE}},
-		{VOIDD, VOID}:                                 {VOIDD, []Kind{VOID, SEM_VOID_MAKENODE}},
+		// Ambiguous, resolved in favour of the rule starting with 'if'
+		{ELSE_DISAMBIGUATE, IF}:                 {ELSE_DISAMBIGUATE, []Kind{SEM_STATBLOCK_FRESH, IF, SEM_IF_MAKENODE, OPENPAR, EXPR, CLOSEPAR, THEN, STATBLOCK, IF_TAIL, SEM_STATBLOCK_MAKEFAMILY, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, SEMI}:               {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, BREAK}:              {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, CONTINUE}:           {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, FOR}:                {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, ID}:                 {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, READ}:               {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, RETURN}:             {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, WHILE}:              {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, WRITE}:              {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{ELSE_DISAMBIGUATE, OPENCUBR}:           {ELSE_DISAMBIGUATE, []Kind{STATBLOCK, SEMI, SEM_IF_MAKEFAMILY}},
+		{EXPR, OPENPAR}:                         {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, PLUS}:                            {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, MINUS}:                           {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, FALSE}:                           {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, FLOATNUM}:                        {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, ID}:                              {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, INTNUM}:                          {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, NOT}:                             {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, STRINGLIT}:                       {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{EXPR, TRUE}:                            {EXPR, []Kind{ARITHEXPR, ARITHORRELEXPR_DISAMBIGUATE, SEM_EXPR_MAKENODE}},
+		{FPARAMS, ID}:                           {FPARAMS, []Kind{IDD, COLON, TYPE, REPT_FPARAMS3, SEM_DIMLIST_MAKEFAMILY, SEM_FPARAM_MAKEFAMILY, SEM_FPARAM_LIST_MAKEFAMILY, REPT_FPARAMS4}},
+		{FPARAMS, CLOSEPAR}:                     {FPARAMS, []Kind{EPSILON, SEM_FPARAM_LIST_MAKEFAMILY}},
+		{FPARAMSTAIL, COMMA}:                    {FPARAMSTAIL, []Kind{COMMA, IDD, COLON, TYPE, REPT_FPARAMSTAIL4, SEM_DIMLIST_MAKEFAMILY, SEM_FPARAM_MAKEFAMILY, SEM_FPARAM_LIST_MAKEFAMILY}},
+		{FACTOR, ID}:                            {FACTOR, []Kind{VARORFUNCCALL, SEM_FACTOR_MAKENODE}},
+		{FACTOR, INTNUM}:                        {FACTOR, []Kind{INTNUMM, SEM_FACTOR_MAKENODE}},
+		{FACTOR, FLOATNUM}:                      {FACTOR, []Kind{FLOATNUMM, SEM_FACTOR_MAKENODE}},
+		{FACTOR, STRINGLIT}:                     {FACTOR, []Kind{STRINGLITT, SEM_FACTOR_MAKENODE}},
+		{FACTOR, TRUE}:                          {FACTOR, []Kind{TRUE, SEM_BOOLLIT_MAKENODE, SEM_FACTOR_MAKENODE}},
+		{FACTOR, FALSE}:                         {FACTOR, []Kind{FALSE, SEM_BOOLLIT_MAKENODE, SEM_FACTOR_MAKENODE}},
+		{FACTOR, OPENPAR}:                       {FACTOR, []Kind{OPENPAR, EXPR, CLOSEPAR, SEM_FACTOR_MAKENODE}},
+		{FACTOR, NOT}:                           {FACTOR, []Kind{NOTT, FACTOR, SEM_FACTOR_MAKENODE}},
+		{FACTOR, PLUS}:                          {FACTOR, []Kind{SIGN, FACTOR, SEM_FACTOR_MAKENODE}},
+		{FACTOR, MINUS}:                         {FACTOR, []Kind{SIGN, FACTOR, SEM_FACTOR_MAKENODE}},
+		{FLOATNUMM, FLOATNUM}:                   {FLOATNUMM, []Kind{FLOATNUM, SEM_FLOATNUM_MAKENODE}},
+		{FORINIT, LET}:                          {FORINIT, []Kind{SEM_STATBLOCK_FRESH, LET, IDD, COLON, TYPE, SEM_DIMLIST_MAKEFAMILY, SEM_VAR_DECL_MAKEFAMILY, ASSIGNOP, EXPR, SEM_FOR_INIT_MAKEFAMILY}},
+		{FORINIT, ID}:                           {FORINIT, []Kind{SEM_STATBLOCK_FRESH, ASSIGNSTAT, SEM_STATBLOCK_MAKEFAMILY}},
+		{FUNCBODY, OPENCUBR}:                    {FUNCBODY, []Kind{OPENCUBR, REPT_FUNCBODY1, CLOSECUBR}},
+		{FUNCDECL, FUNC}:                        {FUNCDECL, []Kind{FUNCHEAD, SEMI, SEM_FUNC_DECL_MAKEFAMILY}},
+		{FUNCDEF, FUNC}:                         {FUNCDEF, []Kind{FUNCHEAD, FUNCBODY, SEM_FUNC_DEF_MAKEFAMILY}},
+		{FUNCHEAD, FUNC}:                        {FUNCHEAD, []Kind{FUNC, IDD, OPENPAR, FPARAMS, CLOSEPAR, ARROW, RETURNTYPE}},
+		{FUNCTIONCALL_DISAMBIGUATE, OPENPAR}:    {FUNCTIONCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, ANOTHER_FUNCTIONCALL}},
+		{FUNCTIONCALL_DISAMBIGUATE, DOT}:        {FUNCTIONCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, DOT, FUNCTIONCALL}},
+		{FUNCTIONCALL_DISAMBIGUATE, OPENSQBR}:   {FUNCTIONCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, DOT, FUNCTIONCALL}},
+		{FUNCTIONCALL, ID}:                      {FUNCTIONCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, FUNCTIONCALL_DISAMBIGUATE}},
+		{IDD, ID}:                               {IDD, []Kind{ID, SEM_ID_MAKENODE}},
+		{IF_TAIL, ELSE}:                         {IF_TAIL, []Kind{ELSE, ELSE_DISAMBIGUATE}},
+		{IF_TAIL, SEMI}:                         {IF_TAIL, []Kind{SEMI, SEM_IF_MAKEFAMILY}},
+		{IMPLDEF, IMPL}:                         {IMPLDEF, []Kind{IMPL, IDD, OPENCUBR, REPT_IMPLDEF3, CLOSECUBR, SEM_IMPL_DEF_MAKEFAMILY}},
+		{INDICE, OPENSQBR}:                      {INDICE, []Kind{OPENSQBR, ARITHEXPR, CLOSESQBR, SEM_INDEX_MAKENODE, SEM_INDEXLIST_MAKEFAMILY}},
+		{INTNUMM, INTNUM}:                       {INTNUMM, []Kind{INTNUM, SEM_INTNUM_MAKENODE}},
+		{MEMBERDECL, FUNC}:                      {MEMBERDECL, []Kind{FUNCDECL}},
+		{MEMBERDECL, LET}:                       {MEMBERDECL, []Kind{VARDECL}},
+		{MORE_ASSIGN, DOT}:                      {MORE_ASSIGN, []Kind{DOT, ASSIGNSTATORFUNCCALL}},
+		{MORE_ASSIGN, ASSIGN}:                   {MORE_ASSIGN, []Kind{ASSIGNOP, EXPR, SEMI, SEM_ASSIGN_MAKEFAMILY}},
+		{MORE_FUNC, DOT}:                        {MORE_FUNC, []Kind{DOT, ASSIGNSTATORFUNCCALL}},
+		{MORE_FUNC, SEMI}:                       {MORE_FUNC, []Kind{SEMI}},
+		{MORE_INDICE, OPENSQBR}:                 {MORE_INDICE, []Kind{INDICE, MORE_INDICE}},
+		{MORE_INDICE, CLOSEPAR}:                 {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, MULT}:                     {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, PLUS}:                     {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, COMMA}:                    {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, MINUS}:                    {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, DOT}:                      {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, DIV}:                      {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, SEMI}:                     {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, ASSIGN}:                   {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, CLOSESQBR}:                {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, AND}:                      {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, EQ}:                       {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, GEQ}:                      {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, GT}:                       {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, LEQ}:                      {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, LT}:                       {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, NOTEQ}:                    {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MORE_INDICE, OR}:                       {MORE_INDICE, []Kind{EPSILON, SEM_INDEXLIST_MAKEFAMILY}},
+		{MULTOP, MULT}:                          {MULTOP, []Kind{MULT, SEM_MULT_MAKENODE}},
+		{MULTOP, DIV}:                           {MULTOP, []Kind{DIV, SEM_DIV_MAKENODE}},
+		{MULTOP, AND}:                           {MULTOP, []Kind{AND, SEM_AND_MAKENODE}},
+		{NOTT, NOT}:                             {NOTT, []Kind{NOT, SEM_NOT_MAKENODE}},
+		{OPT_STRUCTDECL2, INHERITS}:             {OPT_STRUCTDECL2, []Kind{INHERITS, IDD, SEM_INHERITS_MAKEFAMILY, REPT_OPT_STRUCTDECL22}},
+		{OPT_STRUCTDECL2, OPENCUBR}:             {OPT_STRUCTDECL2, []Kind{EPSILON}},
+		{PROG, FUNC}:                            {PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}},
+		{PROG, IMPL}:                            {PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}},
+		{PROG, STRUCT}:                          {PROG, []Kind{REPT_PROG0, SEM_PROG_MAKE_NODE}},
+		{RELOP, EQ}:                             {RELOP, []Kind{EQ, SEM_EQ_MAKENODE}},
+		{RELOP, NOTEQ}:                          {RELOP, []Kind{NOTEQ, SEM_NEQ_MAKENODE}},
+		{RELOP, LT}:                             {RELOP, []Kind{LT, SEM_LT_MAKENODE}},
+		{RELOP, GT}:                             {RELOP, []Kind{GT, SEM_GT_MAKENODE}},
+		{RELOP, LEQ}:                            {RELOP, []Kind{LEQ, SEM_LEQ_MAKENODE}},
+		{RELOP, GEQ}:                            {RELOP, []Kind{GEQ, SEM_GEQ_MAKENODE}},
+		{REPT_APARAMS1, COMMA}:                  {REPT_APARAMS1, []Kind{APARAMSTAIL, REPT_APARAMS1}},
+		{REPT_APARAMS1, CLOSEPAR}:               {REPT_APARAMS1, []Kind{EPSILON}},
+		{REPT_FPARAMS3, OPENSQBR}:               {REPT_FPARAMS3, []Kind{ARRAYSIZE, REPT_FPARAMS3}},
+		{REPT_FPARAMS3, CLOSEPAR}:               {REPT_FPARAMS3, []Kind{EPSILON}},
+		{REPT_FPARAMS3, COMMA}:                  {REPT_FPARAMS3, []Kind{EPSILON}},
+		{REPT_FPARAMS4, COMMA}:                  {REPT_FPARAMS4, []Kind{FPARAMSTAIL, REPT_FPARAMS4}},
+		{REPT_FPARAMS4, CLOSEPAR}:               {REPT_FPARAMS4, []Kind{EPSILON}},
+		{REPT_FPARAMSTAIL4, OPENSQBR}:           {REPT_FPARAMSTAIL4, []Kind{ARRAYSIZE, REPT_FPARAMSTAIL4}},
+		{REPT_FPARAMSTAIL4, CLOSEPAR}:           {REPT_FPARAMSTAIL4, []Kind{EPSILON}},
+		{REPT_FPARAMSTAIL4, COMMA}:              {REPT_FPARAMSTAIL4, []Kind{EPSILON}},
+		{REPT_FUNCBODY1, BREAK}:                 {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, CONTINUE}:              {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, FOR}:                   {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, ID}:                    {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, IF}:                    {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, LET}:                   {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, READ}:                  {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, RETURN}:                {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, WHILE}:                 {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, WRITE}:                 {REPT_FUNCBODY1, []Kind{VARDECLORSTAT, SEM_FUNC_BODY_MAKEFAMILY, REPT_FUNCBODY1}},
+		{REPT_FUNCBODY1, CLOSECUBR}:             {REPT_FUNCBODY1, []Kind{EPSILON, SEM_FUNC_BODY_MAKEFAMILY}},
+		{REPT_IMPLDEF3, FUNC}:                   {REPT_IMPLDEF3, []Kind{FUNCDEF, SEM_FUNCDEFLIST_MAKEFAMILY, REPT_IMPLDEF3}},
+		{REPT_IMPLDEF3, CLOSECUBR}:              {REPT_IMPLDEF3, []Kind{EPSILON, SEM_FUNCDEFLIST_MAKEFAMILY}},
+		{REPT_OPT_STRUCTDECL22, COMMA}:          {REPT_OPT_STRUCTDECL22, []Kind{COMMA, IDD, SEM_INHERITS_MAKEFAMILY, REPT_OPT_STRUCTDECL22}},
+		{REPT_OPT_STRUCTDECL22, OPENCUBR}:       {REPT_OPT_STRUCTDECL22, []Kind{EPSILON}},
+		{REPT_PROG0, FUNC}:                      {REPT_PROG0, []Kind{STRUCTORIMPLORFUNC, SEM_REPT_PROG0_MAKESIBLING, REPT_PROG0}},
+		{REPT_PROG0, IMPL}:                      {REPT_PROG0, []Kind{STRUCTORIMPLORFUNC, SEM_REPT_PROG0_MAKESIBLING, REPT_PROG0}},
+		{REPT_PROG0, STRUCT}:                    {REPT_PROG0, []Kind{STRUCTORIMPLORFUNC, SEM_REPT_PROG0_MAKESIBLING, REPT_PROG0}},
+		{REPT_STATBLOCK1, BREAK}:                {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, CONTINUE}:             {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, FOR}:                  {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, ID}:                   {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, IF}:                   {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, READ}:                 {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, RETURN}:               {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, WHILE}:                {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, WRITE}:                {REPT_STATBLOCK1, []Kind{STATEMENT, SEM_STATBLOCK_MAKEFAMILY, REPT_STATBLOCK1}},
+		{REPT_STATBLOCK1, CLOSECUBR}:            {REPT_STATBLOCK1, []Kind{EPSILON}},
+		{REPT_STRUCTDECL4, PRIVATE}:             {REPT_STRUCTDECL4, []Kind{VISIBILITY, MEMBERDECL, SEM_MEMBER_MAKEFAMILY, SEM_MEMBERS_MAKEFAMILY, REPT_STRUCTDECL4}},
+		{REPT_STRUCTDECL4, PUBLIC}:              {REPT_STRUCTDECL4, []Kind{VISIBILITY, MEMBERDECL, SEM_MEMBER_MAKEFAMILY, SEM_MEMBERS_MAKEFAMILY, REPT_STRUCTDECL4}},
+		{REPT_STRUCTDECL4, CLOSECUBR}:           {REPT_STRUCTDECL4, []Kind{EPSILON, SEM_MEMBERS_MAKEFAMILY}},
+		{REPT_VARDECL4, OPENSQBR}:               {REPT_VARDECL4, []Kind{ARRAYSIZE, REPT_VARDECL4}},
+		{REPT_VARDECL4, SEMI}:                   {REPT_VARDECL4, []Kind{EPSILON, SEM_DIMLIST_MAKEFAMILY}},
+		{RETURNTYPE, BOOL}:                      {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
+		{RETURNTYPE, FLOAT}:                     {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
+		{RETURNTYPE, ID}:                        {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
+		{RETURNTYPE, INTEGER}:                   {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
+		{RETURNTYPE, STRING}:                    {RETURNTYPE, []Kind{TYPE, SEM_RETURNTYPE_MAKEFAMILY}},
+		{RETURNTYPE, VOID}:                      {RETURNTYPE, []Kind{VOIDD, SEM_RETURNTYPE_MAKEFAMILY}},
+		{RIGHTREC_ARITHEXPR, PLUS}:              {RIGHTREC_ARITHEXPR, []Kind{ADDOP, TERM, SEM_ADDOP_MAKEFAMILY, RIGHTREC_ARITHEXPR}},
+		{RIGHTREC_ARITHEXPR, MINUS}:             {RIGHTREC_ARITHEXPR, []Kind{ADDOP, TERM, SEM_ADDOP_MAKEFAMILY, RIGHTREC_ARITHEXPR}},
+		{RIGHTREC_ARITHEXPR, OR}:                {RIGHTREC_ARITHEXPR, []Kind{ADDOP, TERM, SEM_ADDOP_MAKEFAMILY, RIGHTREC_ARITHEXPR}},
+		{RIGHTREC_ARITHEXPR, CLOSEPAR}:          {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, COMMA}:             {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, SEMI}:              {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, CLOSESQBR}:         {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, EQ}:                {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, GEQ}:               {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, GT}:                {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, LEQ}:               {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, LT}:                {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_ARITHEXPR, NOTEQ}:             {RIGHTREC_ARITHEXPR, []Kind{EPSILON}},
+		{RIGHTREC_TERM, MULT}:                   {RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}},
+		{RIGHTREC_TERM, DIV}:                    {RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}},
+		{RIGHTREC_TERM, AND}:                    {RIGHTREC_TERM, []Kind{MULTOP, FACTOR, SEM_MULTOP_MAKEFAMILY, RIGHTREC_TERM}},
+		{RIGHTREC_TERM, CLOSEPAR}:               {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, PLUS}:                   {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, COMMA}:                  {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, MINUS}:                  {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, SEMI}:                   {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, CLOSESQBR}:              {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, EQ}:                     {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, GEQ}:                    {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, GT}:                     {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, LEQ}:                    {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, LT}:                     {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, NOTEQ}:                  {RIGHTREC_TERM, []Kind{EPSILON}},
+		{RIGHTREC_TERM, OR}:                     {RIGHTREC_TERM, []Kind{EPSILON}},
+		{SIGN, PLUS}:                            {SIGN, []Kind{PLUS, SEM_POSITIVE_MAKENODE}},
+		{SIGN, MINUS}:                           {SIGN, []Kind{MINUS, SEM_NEGATIVE_MAKENODE}},
+		{STATBLOCK, OPENCUBR}:                   {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, OPENCUBR, REPT_STATBLOCK1, CLOSECUBR}},
+		{STATBLOCK, BREAK}:                      {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, CONTINUE}:                   {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, FOR}:                        {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, ID}:                         {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, IF}:                         {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, READ}:                       {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, RETURN}:                     {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, WHILE}:                      {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, WRITE}:                      {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, STATEMENT, SEM_STATBLOCK_MAKEFAMILY}},
+		{STATBLOCK, SEMI}:                       {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, EPSILON}},
+		{STATBLOCK, ELSE}:                       {STATBLOCK, []Kind{SEM_STATBLOCK_FRESH, EPSILON}},
+		{STATEMENT, ID}:                         {STATEMENT, []Kind{ASSIGNSTATORFUNCCALL}},
+		{STATEMENT, IF}:                         {STATEMENT, []Kind{IF, SEM_IF_MAKENODE, OPENPAR, EXPR, CLOSEPAR, THEN, STATBLOCK, IF_TAIL}},
+		{STATEMENT, WHILE}:                      {STATEMENT, []Kind{WHILE, SEM_WHILE_MAKENODE, OPENPAR, EXPR, CLOSEPAR, STATBLOCK, SEMI, SEM_WHILE_MAKEFAMILY}},
+		{STATEMENT, FOR}:                        {STATEMENT, []Kind{FOR, SEM_FOR_MAKENODE, OPENPAR, FORINIT, SEMI, EXPR, SEMI, ASSIGNSTAT, CLOSEPAR, STATBLOCK, SEMI, SEM_FOR_MAKEFAMILY}},
+		{STATEMENT, BREAK}:                      {STATEMENT, []Kind{BREAK, SEM_BREAK_MAKENODE, SEMI}},
+		{STATEMENT, CONTINUE}:                   {STATEMENT, []Kind{CONTINUE, SEM_CONTINUE_MAKENODE, SEMI}},
+		{STATEMENT, READ}:                       {STATEMENT, []Kind{READ, OPENPAR, VARIABLE, CLOSEPAR, SEMI, SEM_READ_MAKEFAMILY}},
+		{STATEMENT, WRITE}:                      {STATEMENT, []Kind{WRITE, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_WRITE_MAKEFAMILY}},
+		{STATEMENT, RETURN}:                     {STATEMENT, []Kind{RETURN, OPENPAR, EXPR, CLOSEPAR, SEMI, SEM_RETURN_MAKEFAMILY}},
+		{STRINGLITT, STRINGLIT}:                 {STRINGLITT, []Kind{STRINGLIT, SEM_STRINGLIT_MAKENODE}},
+		{STRUCTDECL, STRUCT}:                    {STRUCTDECL, []Kind{STRUCT, IDD, SEM_INHERITS_FRESH, OPT_STRUCTDECL2, OPENCUBR, REPT_STRUCTDECL4, CLOSECUBR, SEMI, SEM_STRUCT_DECL_MAKEFAMILY}},
+		{STRUCTORIMPLORFUNC, STRUCT}:            {STRUCTORIMPLORFUNC, []Kind{STRUCTDECL}},
+		{STRUCTORIMPLORFUNC, IMPL}:              {STRUCTORIMPLORFUNC, []Kind{IMPLDEF}},
+		{STRUCTORIMPLORFUNC, FUNC}:              {STRUCTORIMPLORFUNC, []Kind{FUNCDEF}},
+		{TERM, OPENPAR}:                         {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, PLUS}:                            {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, MINUS}:                           {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, FALSE}:                           {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, FLOATNUM}:                        {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, ID}:                              {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, INTNUM}:                          {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, NOT}:                             {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, STRINGLIT}:                       {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TERM, TRUE}:                            {TERM, []Kind{FACTOR, RIGHTREC_TERM, SEM_TERM_MAKENODE}},
+		{TYPE, INTEGER}:                         {TYPE, []Kind{INTEGER, SEM_INTEGER_MAKENODE, SEM_TYPE_MAKEFAMILY}},
+		{TYPE, FLOAT}:                           {TYPE, []Kind{FLOAT, SEM_FLOAT_MAKENODE, SEM_TYPE_MAKEFAMILY}},
+		{TYPE, STRING}:                          {TYPE, []Kind{STRING, SEM_STRING_MAKENODE, SEM_TYPE_MAKEFAMILY}},
+		{TYPE, BOOL}:                            {TYPE, []Kind{BOOL, SEM_BOOL_MAKENODE, SEM_TYPE_MAKEFAMILY}},
+		{TYPE, ID}:                              {TYPE, []Kind{IDD, SEM_TYPE_MAKEFAMILY}},
+		{VARDECL, LET}:                          {VARDECL, []Kind{LET, IDD, COLON, TYPE, REPT_VARDECL4, SEMI, SEM_VAR_DECL_MAKEFAMILY}},
+		{VARDECLORSTAT, LET}:                    {VARDECLORSTAT, []Kind{VARDECL}},
+		{VARDECLORSTAT, BREAK}:                  {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, CONTINUE}:               {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, FOR}:                    {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, ID}:                     {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, IF}:                     {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, READ}:                   {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, RETURN}:                 {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, WHILE}:                  {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARDECLORSTAT, WRITE}:                  {VARDECLORSTAT, []Kind{STATEMENT, SEM_STATEMENT_MAKEFAMILY}},
+		{VARORFUNCCALL_DISAMBIGUATE, OPENPAR}:   {VARORFUNCCALL_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, DOT}:       {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, OPENSQBR}:  {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, CLOSEPAR}:  {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, MULT}:      {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, PLUS}:      {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, COMMA}:     {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, MINUS}:     {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, DIV}:       {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, SEMI}:      {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, CLOSESQBR}: {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, AND}:       {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, EQ}:        {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, GEQ}:       {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, GT}:        {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, LEQ}:       {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, LT}:        {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, NOTEQ}:     {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL_DISAMBIGUATE, OR}:        {VARORFUNCCALL_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER}},
+		{VARORFUNCCALL, ID}:                     {VARORFUNCCALL, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, VARORFUNCCALL_DISAMBIGUATE}},
+		{VARIABLE_DISAMBIGUATE, OPENPAR}:        {VARIABLE_DISAMBIGUATE, []Kind{OPENPAR, APARAMS, CLOSEPAR, SEM_FUNC_CALL_MAKEFAMILY, DOT, VARIABLE}},
+		{VARIABLE_DISAMBIGUATE, DOT}:            {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
+		{VARIABLE_DISAMBIGUATE, OPENSQBR}:       {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
+		{VARIABLE_DISAMBIGUATE, CLOSEPAR}:       {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
+		{VARIABLE_DISAMBIGUATE, ASSIGN}:         {VARIABLE_DISAMBIGUATE, []Kind{MORE_INDICE, SEM_VARIABLE_MAKEFAMILY, ANOTHER_VARIABLE}},
+		{VARIABLE, ID}:                          {VARIABLE, []Kind{SEM_SUBJECT_MAKEFAMILY, IDD, VARIABLE_DISAMBIGUATE}},
+		{VISIBILITY, PUBLIC}:                    {VISIBILITY, []Kind{PUBLIC, SEM_PUBLIC_MAKENODE}},
+		{VISIBILITY, PRIVATE}:                   {VISIBILITY, []Kind{PRIVATE, SEM_PRIVATE_MAKENODE}},
+		{VOIDD, VOID}:                           {VOIDD, []Kind{VOID, SEM_VOID_MAKENODE}},
 	}
 }
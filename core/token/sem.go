@@ -24,9 +24,13 @@ const (
 	FINAL_VOID    Kind = "Void"
 	FINAL_INTEGER Kind = "Integer"
 	FINAL_FLOAT   Kind = "Float"
+	FINAL_STRING  Kind = "String"
+	FINAL_BOOL    Kind = "Bool"
 
-	FINAL_INTNUM   Kind = "IntNum"
-	FINAL_FLOATNUM Kind = "FloatNum"
+	FINAL_INTNUM    Kind = "IntNum"
+	FINAL_FLOATNUM  Kind = "FloatNum"
+	FINAL_STRINGLIT Kind = "StringLit"
+	FINAL_BOOLLIT   Kind = "BoolLit"
 
 	FINAL_FUNC_DEF_PARAM     Kind = "Param"
 	FINAL_FUNC_DEF_PARAMLIST Kind = "ParamList"
@@ -46,6 +50,10 @@ const (
 	FINAL_READ   Kind = "Read"
 	FINAL_RETURN Kind = "Return"
 
+	FINAL_FOR      Kind = "For"
+	FINAL_BREAK    Kind = "Break"
+	FINAL_CONTINUE Kind = "Continue"
+
 	FINAL_ASSIGN Kind = "Assign(=)"
 
 	FINAL_EXPR       Kind = "Expr"
@@ -101,6 +109,10 @@ const (
 	FINAL_MEMBERS  Kind = "Members"
 	FINAL_PRIVATE  Kind = "Private"
 	FINAL_PUBLIC   Kind = "Public"
+
+	// Marks a node that does not come from the source, e.g.: a leaf made from
+	// an identifier that the parser inserted to repair a syntax error
+	FINAL_ERROR Kind = "Error"
 )
 
 // Use operandTypes to describe what the top of the stack should look like. Each
@@ -302,6 +314,14 @@ var exprOperandTypes = []Kind{
 	// LITERALS
 	FINAL_INTNUM,
 	FINAL_FLOATNUM,
+	FINAL_STRINGLIT,
+	FINAL_BOOLLIT,
+}
+
+// The condition of an if, while, or for statement is any expression
+var conditionTypes = []Kind{
+	FINAL_ARITH_EXPR,
+	FINAL_REL_EXPR,
 }
 
 var relOpTypes = []Kind{
@@ -316,6 +336,9 @@ var relOpTypes = []Kind{
 var statementTypes = []Kind{
 	FINAL_IF,
 	FINAL_WHILE,
+	FINAL_FOR,
+	FINAL_BREAK,
+	FINAL_CONTINUE,
 	FINAL_READ,
 	FINAL_WRITE,
 	FINAL_RETURN,
@@ -414,16 +437,86 @@ var semDisptachOverride = map[Kind]SemanticAction{
 		pushTop(stack, FINAL_STATBLOCK, Token{})
 	},
 
-	// Consumes: RelExpr, StatBlock, StatBlock
+	SEM_IF_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_IF, tok)
+	},
+
+	// The else block is optional, an else if chain is an else block holding a
+	// single If
+	//
+	// Consumes: If, ArithExpr or RelExpr, StatBlock, (StatBlock)
 	// Produces: If
 	SEM_IF_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
-		eat(stack, 3, FINAL_IF, FINAL_REL_EXPR, FINAL_STATBLOCK, FINAL_STATBLOCK)
+		l := lengthOrPanic(stack, 3)
+		if l >= 4 && (*stack)[l-3].Type != FINAL_IF {
+			pullUp(stack, 0, FINAL_IF, conditionTypes, FINAL_STATBLOCK, FINAL_STATBLOCK)
+			return
+		}
+		pullUp(stack, 0, FINAL_IF, conditionTypes, FINAL_STATBLOCK)
 	},
 
-	// Consumes: RelExpr, StatBlock
+	SEM_WHILE_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_WHILE, tok)
+	},
+
+	// Consumes: While, ArithExpr or RelExpr, StatBlock
 	// Produces: While
 	SEM_WHILE_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
-		eat(stack, 2, FINAL_WHILE, FINAL_REL_EXPR, FINAL_STATBLOCK)
+		pullUp(stack, 0, FINAL_WHILE, conditionTypes, FINAL_STATBLOCK)
+	},
+
+	// The init clause of a for loop that declares its variable, the
+	// declaration is kept in the block so that the loop variable is recorded
+	// in the symbol table of the function
+	//
+	// Consumes: StatBlock, VarDecl, AssignOp, ArithExpr or RelExpr
+	// Produces: StatBlock
+	SEM_FOR_INIT_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
+		lengthOrPanic(stack, 4)
+		topn := topN(stack, 4)
+		TypeCheck(topn[0], FINAL_STATBLOCK)
+		TypeCheck(topn[1], FINAL_VAR_DECL)
+		TypeCheck(topn[2], FINAL_ASSIGN)
+		TypeCheck(topn[3], FINAL_ARITH_EXPR, FINAL_REL_EXPR)
+
+		block, decl, assign, expr := topn[0], topn[1], topn[2], topn[3]
+		id := decl.Children[0]
+		assign.Children = []*ASTNode{
+			{
+				Type: FINAL_VARIABLE,
+				Children: []*ASTNode{
+					{Type: FINAL_SUBJECT},
+					{Type: FINAL_ID, Token: id.Token},
+					{Type: FINAL_INDEXLIST, Children: []*ASTNode{}},
+				},
+			},
+			expr,
+		}
+		block.Children = append(block.Children, decl, assign)
+		transform(stack, 4, block)
+	},
+
+	SEM_FOR_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_FOR, tok)
+	},
+
+	// Consumes: For, StatBlock, ArithExpr or RelExpr, Assign, StatBlock
+	// Produces: For
+	SEM_FOR_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
+		pullUp(stack, 0,
+			FINAL_FOR,
+			FINAL_STATBLOCK,
+			conditionTypes,
+			FINAL_ASSIGN,
+			FINAL_STATBLOCK)
+	},
+
+	SEM_BREAK_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_BREAK, tok)
+	},
+
+	SEM_CONTINUE_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_CONTINUE, tok)
 	},
 
 	// Consumes: (StatBlock, some statement) or (some statement) or ()
@@ -548,6 +641,14 @@ var semDisptachOverride = map[Kind]SemanticAction{
 		pushTop(stack, FINAL_FLOATNUM, tok)
 	},
 
+	SEM_STRINGLIT_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_STRINGLIT, tok)
+	},
+
+	SEM_BOOLLIT_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_BOOLLIT, tok)
+	},
+
 	SEM_TERM_MAKENODE: func(stack *[]*ASTNode, tok Token) {
 		// ? noop for now
 
@@ -578,14 +679,20 @@ var semDisptachOverride = map[Kind]SemanticAction{
 		switch top := top(stack); top.Type {
 		case FINAL_INTNUM,
 			FINAL_FLOATNUM,
+			FINAL_STRINGLIT,
+			FINAL_BOOLLIT,
 			FINAL_ARITH_EXPR,
+			FINAL_REL_EXPR,
 			FINAL_VARIABLE,
 			FINAL_FUNC_CALL:
 			wrapTop(stack,
 				FINAL_FACTOR,
 				FINAL_INTNUM,
 				FINAL_FLOATNUM,
+				FINAL_STRINGLIT,
+				FINAL_BOOLLIT,
 				FINAL_ARITH_EXPR,
+				FINAL_REL_EXPR,
 				FINAL_VARIABLE,
 				FINAL_FUNC_CALL)
 
@@ -672,6 +779,14 @@ var semDisptachOverride = map[Kind]SemanticAction{
 		pushTop(stack, FINAL_FLOAT, tok)
 	},
 
+	SEM_STRING_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_STRING, tok)
+	},
+
+	SEM_BOOL_MAKENODE: func(stack *[]*ASTNode, tok Token) {
+		pushTop(stack, FINAL_BOOL, tok)
+	},
+
 	SEM_VOID_MAKENODE: func(stack *[]*ASTNode, tok Token) {
 		pushTop(stack, FINAL_VOID, tok)
 	},
@@ -679,7 +794,7 @@ var semDisptachOverride = map[Kind]SemanticAction{
 	SEM_TYPE_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
 		wrapTop(stack,
 			FINAL_TYPE,
-			FINAL_INTEGER, FINAL_FLOAT, FINAL_ID)
+			FINAL_INTEGER, FINAL_FLOAT, FINAL_STRING, FINAL_BOOL, FINAL_ID)
 	},
 
 	SEM_RETURNTYPE_MAKEFAMILY: func(stack *[]*ASTNode, tok Token) {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/obonobo/esac/util"
@@ -25,6 +26,14 @@ const (
 	DIMENSION_ANY = -666
 )
 
+// Kinds of the records that the MemoryLayoutVisitor adds to function tables.
+// They describe the parts of a stack frame that are not declared in source
+const (
+	RECORD_RETURN_VALUE Kind = "ReturnValue"
+	RECORD_SELF         Kind = "Self"
+	RECORD_TEMP         Kind = "TempVar"
+)
+
 // A SymbolTable lists identifiers that may be referred to within a scope.
 //
 // WARNING: SymbolTables are recursive and their records may link other
@@ -146,6 +155,12 @@ type SymbolTableRecord struct {
 	Type   Type
 	Link   SymbolTable
 	Parent SymbolTable
+
+	// Filled in by the MemoryLayoutVisitor. Size is in bytes, for functions it
+	// is the size of the stack frame. Offset is relative to the start of the
+	// enclosing stack frame or struct
+	Size   int
+	Offset int
 }
 
 func (r SymbolTableRecord) Equal(r2 SymbolTableRecord) bool {
@@ -281,28 +296,34 @@ func WritePrettySymbolTable(w io.Writer, t SymbolTable) {
 
 		entries := t.Entries()
 
-		printouts := make([][4]string, 0, len(entries))
+		printouts := make([][6]string, 0, len(entries))
 		for _, record := range entries {
-			printouts = append(printouts, [4]string{
+			// Records that have not been laid out have no size
+			size, offset := DEFAULT, DEFAULT
+			if record.Size > 0 {
+				size, offset = strconv.Itoa(record.Size), strconv.Itoa(record.Offset)
+			}
+			printouts = append(printouts, [6]string{
 				util.Or(record.Name, DEFAULT),
 				util.Or(string(record.Kind), DEFAULT),
 				util.Or(record.Type.String(), DEFAULT),
+				size,
+				offset,
 				util.Or(formatLink(record.Link), DEFAULT),
 			})
 		}
 
 		// Compute table column widths
-		cols := [4]int{4, 4, 4, 4}
+		cols := [6]int{4, 4, 4, 4, 6, 4}
 		for _, record := range printouts {
-			cols[0] = util.Max(cols[0], len(record[0]))
-			cols[1] = util.Max(cols[1], len(record[1]))
-			cols[2] = util.Max(cols[2], len(record[2]))
-			cols[3] = util.Max(cols[3], len(record[3]))
+			for i, col := range record {
+				cols[i] = util.Max(cols[i], len(col))
+			}
 		}
 
 		line := fmt.Sprintf("%v+%v+\n",
 			prefix.String(),
-			strings.Repeat("-", util.Sum(cols[:]...)+11))
+			strings.Repeat("-", util.Sum(cols[:]...)+17))
 
 		printLine := func() { fmt.Fprintf(w, "%v", line) }
 
@@ -314,20 +335,22 @@ func WritePrettySymbolTable(w io.Writer, t SymbolTable) {
 			return fmt.Sprintf("%-*v", util.Max(n, MIN_PADDING), s)
 		}
 
-		printRow := func(c1, c2, c3, c4 string) {
+		printRow := func(c1, c2, c3, c4, c5, c6 string) {
 			fmt.Fprintf(w,
-				"%v| %v | %v | %v | %v |\n",
+				"%v| %v | %v | %v | %v | %v | %v |\n",
 				prefix.String(),
 				pad(cols[0], c1),
 				pad(cols[1], c2),
 				pad(cols[2], c3),
-				pad(cols[3], c4))
+				pad(cols[3], c4),
+				pad(cols[4], c5),
+				pad(cols[5], c6))
 		}
 
 		// Print the table headers
 		fmt.Fprintf(w, "%v%v\n", prefix.String(), centerPad(len(line), t.Id()))
 		printLine()
-		printRow("Name", "Kind", "Type", "Link")
+		printRow("Name", "Kind", "Type", "Size", "Offset", "Link")
 		printLine()
 
 		// Toggle below if you want to print all
@@ -341,7 +364,8 @@ func WritePrettySymbolTable(w io.Writer, t SymbolTable) {
 					(!AVOID_VAR_DECL_LINKS || record.Kind != FINAL_VAR_DECL) {
 					nested = append(nested, record.Link)
 				}
-				printRow(printout[0], printout[1], printout[2], printout[3])
+				printRow(printout[0], printout[1], printout[2],
+					printout[3], printout[4], printout[5])
 			}
 			printLine()
 
@@ -367,7 +391,8 @@ func WritePrettySymbolTable(w io.Writer, t SymbolTable) {
 						nested = append(nested, record.Link)
 					}
 				}
-				printRow(printout[0], printout[1], printout[2], printout[3])
+				printRow(printout[0], printout[1], printout[2],
+					printout[3], printout[4], printout[5])
 			}
 			printLine()
 
@@ -388,6 +413,32 @@ func formatLink(t SymbolTable) string {
 	return fmt.Sprintf("⊙---> %v", t.Id())
 }
 
+// Brings the variables declared in a scope into vars, a map of variables by
+// name, the returned function takes them out of vars again. The back ends use
+// it for the scopes of for loops, whose variables never shadow other variables
+// (the SymTabVisitor forbids it)
+func EnterScope[T any](
+	vars map[string]T,
+	table SymbolTable,
+	value func(record SymbolTableRecord) T,
+) (exit func()) {
+	if table == nil {
+		return func() {}
+	}
+	var names []string
+	for _, entry := range table.Entries() {
+		if entry.Kind == FINAL_VAR_DECL {
+			vars[entry.Name] = value(entry)
+			names = append(names, entry.Name)
+		}
+	}
+	return func() {
+		for _, name := range names {
+			delete(vars, name)
+		}
+	}
+}
+
 // Performs a lookup on the symbol table, searching any attached parent tables
 // or inherited parent tables.
 func DeepLookup(table SymbolTable, id string) []*SymbolTableRecord {
@@ -415,3 +466,164 @@ func DeepLookup(table SymbolTable, id string) []*SymbolTableRecord {
 	// Otherwise, this member does not exist within the given scope
 	return nil
 }
+
+// Writes the symbol table in the format of the `.outsymboltables` files, i.e.
+// nested boxes of 'class', 'function', 'data', 'param', and 'local' entries
+// along with their sizes and offsets
+func WriteOutSymbolTables(w io.Writer, t SymbolTable) {
+	for _, line := range outSymbolTableLines(t, "global") {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// Renders a table as a box, each line of the box has the same width
+func outSymbolTableLines(t SymbolTable, title string) []string {
+	const INDENT = "    "
+
+	type row struct {
+		cells  []string
+		nested []string
+	}
+
+	rows := make([]row, 0, len(t.Entries()))
+	if t.Parent() != nil && isStructTable(t) {
+		inherits := make([]string, 0, len(t.Inherited()))
+		for _, inherited := range t.Inherited() {
+			inherits = append(inherits, inherited.Id())
+		}
+		rows = append(rows, row{cells: []string{"inherit", util.Or(strings.Join(inherits, ", "), "none")}})
+	}
+
+	for _, record := range t.Entries() {
+		size, offset := strconv.Itoa(record.Size), strconv.Itoa(record.Offset)
+		switch record.Kind {
+		case FINAL_STRUCT_DECL:
+			var nested []string
+			if record.Link != nil {
+				nested = outSymbolTableLines(record.Link, record.Name)
+			}
+			rows = append(rows, row{[]string{"class", record.Name, "", size}, nested})
+		case FINAL_IMPL_DEF:
+			// The impl links the same table as the struct, it has already been
+			// printed
+		case FINAL_FUNC_DEF, FINAL_FUNC_DECL:
+			cells := []string{"function", record.Name, outSignature(record), size, ""}
+			if record.Type.Privacy != "" {
+				cells = append(cells, string(record.Type.Privacy))
+			}
+			var nested []string
+			if record.Link != nil {
+				prefix := "::"
+				if title != "global" {
+					prefix = title + "::"
+				}
+				nested = outSymbolTableLines(record.Link, prefix+record.Name)
+			}
+			rows = append(rows, row{cells, nested})
+		case FINAL_FOR:
+			var nested []string
+			if record.Link != nil {
+				nested = outSymbolTableLines(record.Link, title+"::"+record.Name)
+			}
+			rows = append(rows, row{[]string{"scope", record.Name, "", size, offset}, nested})
+		case FINAL_FUNC_DEF_PARAM:
+			rows = append(rows, row{cells: []string{
+				"param", record.Name, outType(record.Type), size, offset}})
+		case FINAL_VAR_DECL:
+			kind := "local"
+			if record.Type.Privacy != "" {
+				kind = "data"
+			}
+			cells := []string{kind, record.Name, outType(record.Type), size, offset}
+			if record.Type.Privacy != "" {
+				cells = append(cells, string(record.Type.Privacy))
+			}
+			rows = append(rows, row{cells: cells})
+		case RECORD_RETURN_VALUE:
+			rows = append(rows, row{cells: []string{
+				"return", record.Name, outType(record.Type), size, offset}})
+		default:
+			rows = append(rows, row{cells: []string{
+				strings.ToLower(string(record.Kind)), record.Name, outType(record.Type), size, offset}})
+		}
+	}
+
+	// Compute column widths, and the width of the box
+	cols := make([]int, 0, 4)
+	for _, r := range rows {
+		for i, cell := range r.cells {
+			if i == len(cols) {
+				cols = append(cols, 0)
+			}
+			cols[i] = util.Max(cols[i], len(cell))
+		}
+	}
+
+	header := fmt.Sprintf(" table: %v ", title)
+	width := len(header)
+	formatted := make([]string, len(rows))
+	for i, r := range rows {
+		// Trailing empty cells are not printed
+		for len(r.cells) > 0 && r.cells[len(r.cells)-1] == "" {
+			r.cells = r.cells[:len(r.cells)-1]
+		}
+		cells := make([]string, len(r.cells))
+		for j, cell := range r.cells {
+			cells[j] = fmt.Sprintf("%-*v", cols[j], cell)
+		}
+		formatted[i] = " " + strings.Join(cells, " | ") + " "
+		width = util.Max(width, len(formatted[i]))
+		for _, line := range r.nested {
+			width = util.Max(width, len(INDENT)+len(line)+2)
+		}
+	}
+
+	border := strings.Repeat("=", width+2)
+	boxed := func(s string) string { return fmt.Sprintf("|%-*v|", width, s) }
+	lines := []string{border, boxed(header), border}
+	for i, r := range rows {
+		lines = append(lines, boxed(formatted[i]))
+		for _, line := range r.nested {
+			lines = append(lines, boxed(INDENT+line))
+		}
+	}
+	return append(lines, border)
+}
+
+// Formats the signature of a function record, e.g. '(integer[],integer):void'
+func outSignature(record SymbolTableRecord) string {
+	params := make([]string, 0, 8)
+	if record.Link != nil {
+		for _, e := range record.Link.Entries() {
+			if e.Kind == FINAL_FUNC_DEF_PARAM {
+				params = append(params, outType(e.Type))
+			}
+		}
+	}
+	return fmt.Sprintf("(%v):%v", strings.Join(params, ","), outType(record.Type))
+}
+
+// Formats a type without privacy, unknown dimensions are printed as '[]'
+func outType(t Type) string {
+	var builder strings.Builder
+	builder.WriteString(string(t.Token.Lexeme))
+	for _, dim := range t.Dimlist {
+		if dim == DIMENSION_ANY {
+			builder.WriteString("[]")
+		} else {
+			fmt.Fprintf(&builder, "[%v]", dim)
+		}
+	}
+	return builder.String()
+}
+
+// Returns true if the table is the table of a struct, i.e. it is linked by a
+// 'struct' record in its parent
+func isStructTable(t SymbolTable) bool {
+	for _, e := range t.Parent().Search(t.Id()) {
+		if e.Kind == FINAL_STRUCT_DECL {
+			return true
+		}
+	}
+	return false
+}
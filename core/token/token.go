@@ -2,6 +2,8 @@ package token
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/obonobo/esac/util"
 )
@@ -33,6 +35,10 @@ var reservedWords = map[Kind]struct{}{
 	ELSE:     {},
 	INTEGER:  {},
 	FLOAT:    {},
+	STRING:   {},
+	BOOL:     {},
+	TRUE:     {},
+	FALSE:    {},
 	VOID:     {},
 	PUBLIC:   {},
 	PRIVATE:  {},
@@ -40,6 +46,9 @@ var reservedWords = map[Kind]struct{}{
 	VAR:      {},
 	STRUCT:   {},
 	WHILE:    {},
+	FOR:      {},
+	BREAK:    {},
+	CONTINUE: {},
 	READ:     {},
 	WRITE:    {},
 	RETURN:   {},
@@ -54,6 +63,8 @@ var errorSymbols = map[Kind]struct{}{
 	INVALIDCHAR:         {},
 	INVALIDID:           {},
 	UNTERMINATEDCOMMENT: {},
+	UNTERMINATEDSTRING:  {},
+	INVALIDESCAPE:       {},
 }
 
 func IsReservedWord(s Kind) bool {
@@ -71,6 +82,46 @@ func ReservedWords() []Kind {
 	return setToSlice(reservedWords)
 }
 
+// The text of the tokens that are always spelled the same way
+var spellings = map[Kind]Lexeme{
+	ASSIGN:     "=",
+	ARROW:      "->",
+	EQ:         "==",
+	PLUS:       "+",
+	MINUS:      "-",
+	MULT:       "*",
+	DIV:        "/",
+	LT:         "<",
+	NOTEQ:      "<>",
+	LEQ:        "<=",
+	GT:         ">",
+	GEQ:        ">=",
+	OR:         "|",
+	AND:        "&",
+	NOT:        "!",
+	OPENPAR:    "(",
+	CLOSEPAR:   ")",
+	OPENCUBR:   "{",
+	CLOSECUBR:  "}",
+	OPENSQBR:   "[",
+	CLOSESQBR:  "]",
+	DOT:        ".",
+	COMMA:      ",",
+	SEMI:       ";",
+	COLON:      ":",
+	COLONCOLON: "::",
+}
+
+// Returns the lexeme of a token of this kind, returns false if tokens of this
+// kind have no fixed spelling, e.g.: identifiers and literals
+func Spelling(k Kind) (Lexeme, bool) {
+	if IsReservedWord(k) {
+		return Lexeme(k), true
+	}
+	l, ok := spellings[k]
+	return l, ok
+}
+
 func IsError(s Kind) bool {
 	_, ok := errorSymbols[s]
 	return ok
@@ -81,6 +132,29 @@ func ErrorTokens() []Kind {
 	return setToSlice(errorSymbols)
 }
 
+// Returns the text of a string literal: its lexeme without the quotes, and with
+// its escape sequences ('\n', '\"', and '\\') replaced
+func StringLitText(lexeme Lexeme) string {
+	text := strings.TrimSuffix(strings.TrimPrefix(string(lexeme), `"`), `"`)
+	return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(text)
+}
+
+// Returns the first escape sequence of a string that is not '\n', '\"', or
+// '\\', or an empty string if there is none
+func InvalidEscape(lexeme Lexeme) string {
+	text := string(lexeme)
+	for i := 0; i+1 < len(text); i++ {
+		if text[i] != '\\' {
+			continue
+		}
+		if next, size := utf8.DecodeRuneInString(text[i+1:]); next != 'n' && next != '"' && next != '\\' {
+			return text[i : i+1+size]
+		}
+		i++
+	}
+	return ""
+}
+
 func setToSlice(set map[Kind]struct{}) []Kind {
 	ret := make([]Kind, 0, len(set))
 	for k := range set {
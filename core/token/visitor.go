@@ -33,8 +33,12 @@ var DEFAULT_VISITOR_METHODS = map[Kind]Visit{
 	FINAL_VOID:                        func(node *ASTNode) {},
 	FINAL_INTEGER:                     func(node *ASTNode) {},
 	FINAL_FLOAT:                       func(node *ASTNode) {},
+	FINAL_STRING:                      func(node *ASTNode) {},
+	FINAL_BOOL:                        func(node *ASTNode) {},
 	FINAL_INTNUM:                      func(node *ASTNode) {},
 	FINAL_FLOATNUM:                    func(node *ASTNode) {},
+	FINAL_STRINGLIT:                   func(node *ASTNode) {},
+	FINAL_BOOLLIT:                     func(node *ASTNode) {},
 	FINAL_FUNC_DEF_PARAM:              func(node *ASTNode) {},
 	FINAL_FUNC_DEF_PARAMLIST:          func(node *ASTNode) {},
 	FINAL_FUNC_BODY:                   func(node *ASTNode) {},
@@ -44,6 +48,9 @@ var DEFAULT_VISITOR_METHODS = map[Kind]Visit{
 	FINAL_WRITE:                       func(node *ASTNode) {},
 	FINAL_IF:                          func(node *ASTNode) {},
 	FINAL_WHILE:                       func(node *ASTNode) {},
+	FINAL_FOR:                         func(node *ASTNode) {},
+	FINAL_BREAK:                       func(node *ASTNode) {},
+	FINAL_CONTINUE:                    func(node *ASTNode) {},
 	FINAL_READ:                        func(node *ASTNode) {},
 	FINAL_RETURN:                      func(node *ASTNode) {},
 	FINAL_ASSIGN:                      func(node *ASTNode) {},
@@ -0,0 +1,1056 @@
+package visitors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/util"
+)
+
+// Register conventions for the generated code. r0 is always zero in MOON, r1-r9
+// are scratch registers that never hold a value across the evaluation of a
+// subexpression, r14 points at the base of the current stack frame, and r15
+// holds return addresses
+const (
+	FRAME_REGISTER = "r14"
+	LINK_REGISTER  = "r15"
+)
+
+const (
+	// The largest and smallest values that fit in the 16-bit immediate operand
+	// of a MOON instruction
+	MOON_IMMEDIATE_MAX = 1<<15 - 1
+	MOON_IMMEDIATE_MIN = -(1 << 15)
+)
+
+// MOON has no floating point instructions, so floats are lowered to fixed point
+// numbers: a float x is held in a single word as the integer x * 1000, rounded.
+// The value occupies the first word of the FLOAT_SIZE slot that the
+// MemoryLayoutVisitor allocates for it. The runtime routines that read and
+// write floats assume this scale
+const FIXED_POINT_SCALE = 1000
+
+// Frame sizes are only known once a whole function has been generated (the
+// temporaries are allocated as we go), so the instructions that need the frame
+// size are written with this placeholder which is patched at the end
+const frameSizePlaceholder = "{{FRAMESIZE}}"
+
+// Generates MOON assembly code for a program that has been decorated by the
+// SymTabVisitor, SemCheckVisitor, and MemoryLayoutVisitor. Code is generated in
+// one go when the Prog node is visited, nothing is written to the output if an
+// error was emitted.
+//
+// Stack frames and structs are laid out as described by the
+// MemoryLayoutVisitor. The values of expressions are stored in the temporaries
+// that it has allocated, any other temporaries needed by the generated code
+// (e.g. computed addresses) are placed after them at the end of the frame.
+//
+// A caller writes the arguments directly into the frame of the callee (found at
+// r14 + caller frame size), bumps r14 and jumps. The callee is responsible for
+// saving and restoring r15.
+//
+// Floats are fixed point numbers with three decimal places (see
+// FIXED_POINT_SCALE). Their magnitude must stay below 2147483, products and
+// quotients overflow sooner, when the operands are larger than about 2147.
+type CodeGenVisitor struct {
+	token.DispatchVisitor
+	out    io.Writer
+	errout func(e *VisitorError)
+
+	global  token.SymbolTable
+	funcs   map[token.SymbolTable]*moonFunc
+	structs *StructLayouts
+	labels  map[string]int
+	data    bytes.Buffer
+	failed  bool
+}
+
+func NewCodeGenVisitor(out io.Writer, errout func(e *VisitorError)) *CodeGenVisitor {
+	vis := &CodeGenVisitor{
+		out:    out,
+		errout: errout,
+		funcs:  make(map[token.SymbolTable]*moonFunc, 64),
+		labels: make(map[string]int, 64),
+	}
+	vis.DispatchVisitor = token.DispatchVisitor{Dispatch: map[token.Kind]token.Visit{
+		token.FINAL_PROG: vis.generateProgram,
+	}}
+	return vis
+}
+
+// A function or method that code is generated for
+type moonFunc struct {
+	label string
+	node  *token.ASTNode
+	table token.SymbolTable
+	owner token.SymbolTable // The struct table for methods, nil otherwise
+	ret   token.Type
+	frame *frame
+}
+
+// The stack frame of a function
+type frame struct {
+	size   int // Size of the frame computed by the MemoryLayoutVisitor
+	self   int // Offset of the self pointer, -1 for free functions
+	slots  map[string]slot
+	params []slot
+	temps  int
+}
+
+type slot struct {
+	offset int
+	typ    token.Type
+	ref    bool // The slot holds the address of the value (array params)
+}
+
+// The result of evaluating an expression, always stored in a temporary
+type value struct {
+	offset int
+	typ    token.Type
+	addr   bool // The temporary holds the address of an aggregate value
+}
+
+func (vis *CodeGenVisitor) generateProgram(node *token.ASTNode) {
+	vis.global = node.Meta.SymbolTable
+	if vis.global == nil {
+		vis.logCodeGenError(node.Token, "program has no symbol table")
+		return
+	}
+	vis.structs = NewStructLayouts(vis.global)
+
+	var main *moonFunc
+	for _, def := range vis.funcDefs(node) {
+		if def.owner == nil && def.node.Children[0].Token.Lexeme == "main" {
+			main = def
+		}
+	}
+	if main == nil {
+		vis.logCodeGenError(node.Token, "no 'main' function has been defined")
+	}
+	if vis.failed {
+		return
+	}
+
+	code := new(bytes.Buffer)
+	fmt.Fprintln(code, "% generated by esac")
+	emit(code, "", "entry")
+	emit(code, "", "addi", FRAME_REGISTER, "r0", "stack")
+	emit(code, "", "jl", LINK_REGISTER, main.label)
+	emit(code, "", "hlt")
+	for _, def := range vis.funcDefs(node) {
+		fmt.Fprintln(code)
+		vis.generateFunction(code, def)
+	}
+	fmt.Fprintln(code)
+	code.WriteString(MOON_RUNTIME)
+	fmt.Fprintln(code)
+	fmt.Fprintln(code, "% data")
+	code.Write(vis.data.Bytes())
+	emit(code, "", "align")
+	emit(code, "stack", "res", strconv.Itoa(WORD_SIZE))
+
+	if vis.failed {
+		return
+	}
+	vis.out.Write(code.Bytes())
+}
+
+// Collects all function definitions of the program in source order, assigning
+// them labels and frames the first time around
+func (vis *CodeGenVisitor) funcDefs(prog *token.ASTNode) []*moonFunc {
+	defs := make([]*moonFunc, 0, 64)
+	add := func(node *token.ASTNode, owner token.SymbolTable) {
+		def, ok := vis.funcs[node.Meta.SymbolTable]
+		if !ok {
+			def = vis.newMoonFunc(node, owner)
+		}
+		defs = append(defs, def)
+	}
+
+	for _, child := range prog.Children[0].Children {
+		switch child.Type {
+		case token.FINAL_FUNC_DEF:
+			add(child, nil)
+		case token.FINAL_IMPL_DEF:
+			for _, method := range child.Children[1].Children {
+				if method.Type == token.FINAL_ERROR {
+					continue
+				}
+				add(method, child.Meta.SymbolTable)
+			}
+		}
+	}
+	return defs
+}
+
+func (vis *CodeGenVisitor) newMoonFunc(node *token.ASTNode, owner token.SymbolTable) *moonFunc {
+	id := string(node.Children[0].Token.Lexeme)
+	name := id
+	if owner != nil {
+		name = owner.Id() + "_" + id
+	}
+
+	def := &moonFunc{
+		label: vis.label("f_" + name),
+		node:  node,
+		table: node.Meta.SymbolTable,
+		owner: owner,
+		ret:   functionReturnType(node.Meta.SymbolTable),
+	}
+	vis.funcs[def.table] = def
+	def.frame = vis.layoutFrame(def)
+	return def
+}
+
+// Collects the slots of a frame from the records of the function table
+func (vis *CodeGenVisitor) layoutFrame(def *moonFunc) *frame {
+	f := &frame{
+		self:  -1,
+		slots: make(map[string]slot, 32),
+		size:  FRAME_RETURN_VALUE_OFFSET,
+	}
+
+	for _, entry := range def.table.Entries() {
+		f.size = util.Max(f.size, entry.Offset+entry.Size)
+		s := slot{offset: entry.Offset, typ: entry.Type}
+		switch entry.Kind {
+		case token.RECORD_SELF:
+			f.self = entry.Offset
+			continue
+		case token.FINAL_FUNC_DEF_PARAM:
+			s.ref = len(entry.Type.Dimlist) > 0
+			f.params = append(f.params, s)
+		case token.FINAL_VAR_DECL:
+		default:
+			continue
+		}
+		vis.elementSize(entry.Type, entry.Type.Token)
+		f.slots[entry.Name] = s
+	}
+	if def.ret.Type != token.FINAL_VOID {
+		vis.sizeOf(def.ret, def.ret.Token)
+	}
+	return f
+}
+
+// Returns the size of a value of the given type in bytes
+func (vis *CodeGenVisitor) sizeOf(typ token.Type, at token.Token) int {
+	size, err := vis.structs.SizeOf(typ)
+	if err != nil {
+		vis.logCodeGenError(at, "%v", err)
+	}
+	return size
+}
+
+func (vis *CodeGenVisitor) elementSize(typ token.Type, at token.Token) int {
+	size, err := vis.structs.ElementSize(typ)
+	if err != nil {
+		vis.logCodeGenError(at, "%v", err)
+	}
+	return size
+}
+
+func (vis *CodeGenVisitor) layoutOf(typ token.Type, at token.Token) *StructLayout {
+	layout, err := vis.structs.Of(typ)
+	if err != nil {
+		vis.logCodeGenError(at, "%v", err)
+	}
+	return layout
+}
+
+func (vis *CodeGenVisitor) layoutStruct(table token.SymbolTable, at token.Token) *StructLayout {
+	layout, err := vis.structs.Struct(table)
+	if err != nil {
+		vis.logCodeGenError(at, "%v", err)
+	}
+	return layout
+}
+
+// Creates a unique label based on the provided name
+func (vis *CodeGenVisitor) label(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	n := vis.labels[name]
+	vis.labels[name]++
+	if n == 0 {
+		return name
+	}
+	return fmt.Sprintf("%v_%v", name, n)
+}
+
+// Returns the label for a constant word in the data section
+func (vis *CodeGenVisitor) constant(val int) string {
+	label := vis.label("lit")
+	emit(&vis.data, label, "dw", strconv.Itoa(val))
+	return label
+}
+
+// Returns the label of a null-terminated string in the data section, strings
+// are held in registers and in memory as the address of their first byte
+func (vis *CodeGenVisitor) stringConstant(text string) string {
+	label := vis.label("str")
+	bytes := make([]string, 0, len(text)+1)
+	for _, b := range []byte(text) {
+		bytes = append(bytes, strconv.Itoa(int(b)))
+	}
+	emit(&vis.data, label, "db", append(bytes, "0")...)
+	emit(&vis.data, "", "align")
+	return label
+}
+
+func (vis *CodeGenVisitor) logErr(e *VisitorError) {
+	vis.failed = true
+	if vis.errout != nil {
+		vis.errout(e)
+	}
+}
+
+func (vis *CodeGenVisitor) logCodeGenError(at token.Token, msg string, args ...any) {
+	vis.logErr(&VisitorError{Wrap: &CodeGenError{
+		Msg:   fmt.Sprintf(msg, args...),
+		Token: at,
+	}})
+}
+
+// Writes a single line of assembly
+func emit(w io.Writer, label, op string, args ...string) {
+	line := fmt.Sprintf("%-15v %-7v %v", label, op, strings.Join(args, ", "))
+	fmt.Fprintln(w, strings.TrimRight(line, " "))
+}
+
+// Formats a K(Rj) operand
+func at(offset int, register string) string {
+	return fmt.Sprintf("%v(%v)", offset, register)
+}
+
+// Generates the code for the body of a single function
+type funcGen struct {
+	vis   *CodeGenVisitor
+	def   *moonFunc
+	code  *bytes.Buffer
+	loops []loop // The enclosing loops, innermost last
+}
+
+// The jump targets of break and continue statements
+type loop struct {
+	breakLabel    string
+	continueLabel string
+}
+
+func (vis *CodeGenVisitor) generateFunction(out io.Writer, def *moonFunc) {
+	g := &funcGen{vis: vis, def: def, code: new(bytes.Buffer)}
+	fmt.Fprintf(g.code, "%% func %v -> %v\n", def.table.Id(), def.ret.StringPrivacy(false))
+	g.emitLabel(def.label, "sw", at(FRAME_RETURN_ADDRESS_OFFSET, FRAME_REGISTER), LINK_REGISTER)
+	for _, statement := range childrenWithoutVarDecls(def.node.Children[3].Children) {
+		g.statement(statement)
+	}
+	g.epilogue()
+
+	// Now that all temporaries have been allocated, we can fill in the size
+	frameSize := strconv.Itoa(def.frame.size + def.frame.temps)
+	io.WriteString(out, strings.ReplaceAll(g.code.String(), frameSizePlaceholder, frameSize))
+}
+
+func (g *funcGen) emit(op string, args ...string) {
+	emit(g.code, "", op, args...)
+}
+
+func (g *funcGen) emitLabel(label, op string, args ...string) {
+	emit(g.code, label, op, args...)
+}
+
+// Allocates a temporary of the given size in the frame, returns its offset
+func (g *funcGen) temp(size int) int {
+	offset := g.def.frame.size + g.def.frame.temps
+	g.def.frame.temps += size
+	return offset
+}
+
+func (g *funcGen) epilogue() {
+	g.emit("lw", LINK_REGISTER, at(FRAME_RETURN_ADDRESS_OFFSET, FRAME_REGISTER))
+	g.emit("jr", LINK_REGISTER)
+}
+
+// Loads the contents of a temporary into a register
+func (g *funcGen) load(register string, v value) {
+	g.emit("lw", register, at(v.offset, FRAME_REGISTER))
+}
+
+// Stores a register into a fresh temporary
+func (g *funcGen) save(register string, typ token.Type, addr bool) value {
+	v := value{offset: g.temp(WORD_SIZE), typ: typ, addr: addr}
+	g.emit("sw", at(v.offset, FRAME_REGISTER), register)
+	return v
+}
+
+// Stores a register into the temporary that the MemoryLayoutVisitor has
+// allocated for an expression, or into a fresh temporary if there is none
+func (g *funcGen) result(register string, node *token.ASTNode, typ token.Type) value {
+	temp := node.Meta.Temp
+	if temp == nil || temp.Size != WORD_SIZE {
+		return g.save(register, typ, false)
+	}
+	g.emit("sw", at(temp.Offset, FRAME_REGISTER), register)
+	return value{offset: temp.Offset, typ: typ}
+}
+
+// Returns the offset of the temporary that the MemoryLayoutVisitor has
+// allocated for an aggregate expression, or allocates a fresh one
+func (g *funcGen) resultBlock(node *token.ASTNode, size int) int {
+	if temp := node.Meta.Temp; temp != nil && temp.Size == size {
+		return temp.Offset
+	}
+	return g.temp(size)
+}
+
+func (g *funcGen) block(statements []*token.ASTNode) {
+	for _, statement := range statements {
+		g.statement(statement)
+	}
+}
+
+func (g *funcGen) statement(node *token.ASTNode) {
+	switch node.Type {
+	case token.FINAL_ASSIGN:
+		g.assign(node)
+	case token.FINAL_IF:
+		g.ifStatement(node)
+	case token.FINAL_WHILE:
+		g.whileStatement(node)
+	case token.FINAL_FOR:
+		g.forStatement(node)
+	case token.FINAL_BREAK, token.FINAL_CONTINUE:
+		g.jump(node)
+	case token.FINAL_READ:
+		g.read(node)
+	case token.FINAL_WRITE:
+		g.write(node)
+	case token.FINAL_RETURN:
+		g.returnStatement(node)
+	case token.FINAL_FUNC_CALL:
+		g.call(node)
+	default:
+		g.vis.logCodeGenError(node.Token, "unsupported statement %v", node.Type)
+	}
+}
+
+func (g *funcGen) assign(node *token.ASTNode) {
+	dst := g.address(node.Children[0])
+	src := g.expr(node.Children[1])
+	g.store(dst, src, node.Children[0].Children[1].Token)
+}
+
+// Stores the value src at the address held by dst
+func (g *funcGen) store(dst, src value, at token.Token) {
+	if !isAggregate(dst.typ) {
+		if !g.scalar(src, at) {
+			return
+		}
+		g.load("r1", src)
+		g.load("r2", dst)
+		g.emit("sw", "0(r2)", "r1")
+		return
+	}
+
+	if !src.addr {
+		g.vis.logCodeGenError(at, "cannot assign a value of type '%v' to '%v'", src.typ, dst.typ)
+		return
+	}
+
+	typ := dst.typ
+	if hasUnknownDimension(typ) {
+		typ = src.typ
+	}
+	g.load("r1", src)
+	g.load("r2", dst)
+	g.copy("r1", 0, "r2", 0, g.vis.sizeOf(typ, at))
+}
+
+func (g *funcGen) ifStatement(node *token.ASTNode) {
+	cond := g.expr(node.Children[0])
+	g.scalar(cond, node.Token)
+	g.load("r1", cond)
+	if len(node.Children) < 3 {
+		endLabel := g.vis.label("endif")
+		g.emit("bz", "r1", endLabel)
+		g.block(node.Children[1].Children)
+		g.emitLabel(endLabel, "nop")
+		return
+	}
+
+	elseLabel, endLabel := g.vis.label("else"), g.vis.label("endif")
+	g.emit("bz", "r1", elseLabel)
+	g.block(node.Children[1].Children)
+	g.emit("j", endLabel)
+	g.emitLabel(elseLabel, "nop")
+	g.block(node.Children[2].Children)
+	g.emitLabel(endLabel, "nop")
+}
+
+func (g *funcGen) whileStatement(node *token.ASTNode) {
+	topLabel, endLabel := g.vis.label("while"), g.vis.label("endwhile")
+	g.emitLabel(topLabel, "nop")
+	cond := g.expr(node.Children[0])
+	g.scalar(cond, node.Token)
+	g.load("r1", cond)
+	g.emit("bz", "r1", endLabel)
+	g.loopBody(node.Children[1], loop{breakLabel: endLabel, continueLabel: topLabel})
+	g.emit("j", topLabel)
+	g.emitLabel(endLabel, "nop")
+}
+
+func (g *funcGen) forStatement(node *token.ASTNode) {
+	defer token.EnterScope(g.def.frame.slots, node.Meta.SymbolTable, func(entry token.SymbolTableRecord) slot {
+		g.vis.elementSize(entry.Type, entry.Type.Token)
+		return slot{offset: entry.Offset, typ: entry.Type}
+	})()
+	topLabel, stepLabel, endLabel := g.vis.label("for"), g.vis.label("forstep"), g.vis.label("endfor")
+	g.block(childrenWithoutVarDecls(node.Children[0].Children))
+	g.emitLabel(topLabel, "nop")
+	cond := g.expr(node.Children[1])
+	g.scalar(cond, node.Token)
+	g.load("r1", cond)
+	g.emit("bz", "r1", endLabel)
+	g.loopBody(node.Children[3], loop{breakLabel: endLabel, continueLabel: stepLabel})
+	g.emitLabel(stepLabel, "nop")
+	g.assign(node.Children[2])
+	g.emit("j", topLabel)
+	g.emitLabel(endLabel, "nop")
+}
+
+func (g *funcGen) loopBody(body *token.ASTNode, lp loop) {
+	g.loops = append(g.loops, lp)
+	g.block(body.Children)
+	g.loops = g.loops[:len(g.loops)-1]
+}
+
+// Translates a break or continue statement to a jump out of the innermost loop
+func (g *funcGen) jump(node *token.ASTNode) {
+	if len(g.loops) == 0 {
+		g.vis.logCodeGenError(node.Token, "'%v' is not inside a loop", node.Token.Lexeme)
+		return
+	}
+	lp := g.loops[len(g.loops)-1]
+	label := lp.continueLabel
+	if node.Type == token.FINAL_BREAK {
+		label = lp.breakLabel
+	}
+	g.emit("j", label)
+}
+
+func (g *funcGen) read(node *token.ASTNode) {
+	variable := node.Children[0]
+	if variable.Type != token.FINAL_VARIABLE {
+		g.vis.logCodeGenError(node.Token, "read expects a variable")
+		return
+	}
+	dst := g.address(variable)
+	if isAggregate(dst.typ) || !isType(dst.typ, token.FINAL_INTEGER, token.FINAL_FLOAT, token.FINAL_BOOL) {
+		g.vis.logCodeGenError(variable.Children[1].Token,
+			"read is only supported for integer, float, and bool variables")
+		return
+	}
+	if dst.typ.Type == token.FINAL_FLOAT {
+		g.emit("jl", LINK_REGISTER, "getfloat")
+	} else {
+		g.emit("jl", LINK_REGISTER, "getint")
+	}
+	if dst.typ.Type == token.FINAL_BOOL {
+		g.emit("cnei", "r1", "r1", "0") // Any non-zero integer is true
+	}
+	g.load("r2", dst)
+	g.emit("sw", "0(r2)", "r1")
+}
+
+func (g *funcGen) write(node *token.ASTNode) {
+	v := g.expr(node.Children[0])
+	if !g.scalar(v, node.Token) {
+		return
+	}
+	g.load("r1", v)
+	switch v.typ.Type {
+	case token.FINAL_STRING:
+		g.emit("jl", LINK_REGISTER, "putstr")
+	case token.FINAL_BOOL:
+		g.emit("jl", LINK_REGISTER, "putbool")
+	case token.FINAL_FLOAT:
+		g.emit("jl", LINK_REGISTER, "putfloat")
+	default:
+		g.emit("jl", LINK_REGISTER, "putint")
+	}
+}
+
+func (g *funcGen) returnStatement(node *token.ASTNode) {
+	v := g.expr(node.Children[0])
+	if isAggregate(g.def.ret) {
+		if !v.addr {
+			g.vis.logCodeGenError(node.Token, "cannot return '%v' from function returning '%v'",
+				v.typ, g.def.ret)
+			return
+		}
+		g.load("r1", v)
+		g.copy("r1", 0, FRAME_REGISTER, FRAME_RETURN_VALUE_OFFSET,
+			g.vis.sizeOf(g.def.ret, node.Token))
+	} else if g.scalar(v, node.Token) {
+		g.load("r1", v)
+		g.emit("sw", at(FRAME_RETURN_VALUE_OFFSET, FRAME_REGISTER), "r1")
+	}
+	g.epilogue()
+}
+
+// Evaluates an expression into a temporary
+func (g *funcGen) expr(node *token.ASTNode) value {
+	switch node.Type {
+	case token.FINAL_ARITH_EXPR,
+		token.FINAL_REL_EXPR,
+		token.FINAL_EXPR,
+		token.FINAL_INDEX,
+		token.FINAL_FUNC_CALL_PARAM:
+		return g.expr(node.Children[0])
+	case token.FINAL_FACTOR:
+		if len(node.Children) == 2 {
+			return g.unary(node)
+		}
+		return g.expr(node.Children[0])
+	case token.FINAL_PLUS,
+		token.FINAL_MINUS,
+		token.FINAL_MULT,
+		token.FINAL_DIV,
+		token.FINAL_AND,
+		token.FINAL_OR,
+		token.FINAL_EQ,
+		token.FINAL_NEQ,
+		token.FINAL_LT,
+		token.FINAL_GT,
+		token.FINAL_LEQ,
+		token.FINAL_GEQ:
+		return g.binary(node)
+	case token.FINAL_INTNUM:
+		return g.intLiteral(node)
+	case token.FINAL_STRINGLIT:
+		label := g.vis.stringConstant(token.StringLitText(node.Token.Lexeme))
+		g.emit("addi", "r1", "r0", label)
+		return g.result("r1", node, token.Type{Type: token.FINAL_STRING, Token: node.Token})
+	case token.FINAL_BOOLLIT:
+		bit := "0"
+		if node.Token.Id == token.TRUE {
+			bit = "1"
+		}
+		g.emit("addi", "r1", "r0", bit)
+		return g.result("r1", node, token.Type{Type: token.FINAL_BOOL, Token: node.Token})
+	case token.FINAL_FLOATNUM:
+		return g.floatLiteral(node)
+	case token.FINAL_VARIABLE:
+		dst := g.address(node)
+		if isAggregate(dst.typ) {
+			return dst
+		}
+		g.load("r1", dst)
+		g.emit("lw", "r1", "0(r1)")
+		return g.save("r1", dst.typ, false)
+	case token.FINAL_FUNC_CALL:
+		v := g.call(node)
+		if v.typ.Type == token.FINAL_VOID {
+			g.vis.logCodeGenError(node.Children[1].Token,
+				"function '%v' does not return a value", node.Children[1].Token.Lexeme)
+		}
+		return v
+	}
+	g.vis.logCodeGenError(node.Token, "unsupported expression %v", node.Type)
+	return value{}
+}
+
+func (g *funcGen) intLiteral(node *token.ASTNode) value {
+	val, err := strconv.ParseInt(string(node.Token.Lexeme), 10, 32)
+	if err != nil {
+		g.vis.logCodeGenError(node.Token, "integer literal %v is out of range", node.Token.Lexeme)
+	}
+	if MOON_IMMEDIATE_MIN <= val && val <= MOON_IMMEDIATE_MAX {
+		g.emit("addi", "r1", "r0", strconv.Itoa(int(val)))
+	} else {
+		g.emit("lw", "r1", g.vis.constant(int(val))+"(r0)")
+	}
+	return g.result("r1", node, token.Type{Type: token.FINAL_INTEGER, Token: node.Token})
+}
+
+// Loads a float literal as a fixed point number
+func (g *funcGen) floatLiteral(node *token.ASTNode) value {
+	x, err := strconv.ParseFloat(string(node.Token.Lexeme), 64)
+	val := math.Round(x * FIXED_POINT_SCALE)
+	if err != nil || val < math.MinInt32 || val > math.MaxInt32 {
+		g.vis.logCodeGenError(node.Token, "float literal %v is out of range", node.Token.Lexeme)
+		return value{}
+	}
+	if MOON_IMMEDIATE_MIN <= val && val <= MOON_IMMEDIATE_MAX {
+		g.emit("addi", "r1", "r0", strconv.Itoa(int(val)))
+	} else {
+		g.emit("lw", "r1", g.vis.constant(int(val))+"(r0)")
+	}
+	return g.result("r1", node, token.Type{Type: token.FINAL_FLOAT, Token: node.Token})
+}
+
+func (g *funcGen) unary(node *token.ASTNode) value {
+	op, operand := node.Children[0], g.expr(node.Children[1])
+	if !g.scalar(operand, op.Token) {
+		return value{}
+	}
+	g.load("r1", operand)
+	switch op.Type {
+	case token.FINAL_NEGATIVE:
+		g.emit("sub", "r1", "r0", "r1")
+	case token.FINAL_NOT:
+		g.emit("ceqi", "r1", "r1", "0")
+	}
+	return g.result("r1", node, operand.typ)
+}
+
+var binaryInstructions = map[token.Kind]string{
+	token.FINAL_PLUS:  "add",
+	token.FINAL_MINUS: "sub",
+	token.FINAL_MULT:  "mul",
+	token.FINAL_DIV:   "div",
+	token.FINAL_AND:   "and",
+	token.FINAL_OR:    "or",
+	token.FINAL_EQ:    "ceq",
+	token.FINAL_NEQ:   "cne",
+	token.FINAL_LT:    "clt",
+	token.FINAL_GT:    "cgt",
+	token.FINAL_LEQ:   "cle",
+	token.FINAL_GEQ:   "cge",
+}
+
+func (g *funcGen) binary(node *token.ASTNode) value {
+	left := g.expr(node.Children[0])
+	right := g.expr(node.Children[1])
+	if !g.scalar(left, node.Token) || !g.scalar(right, node.Token) {
+		return value{}
+	}
+
+	g.load("r1", left)
+	g.load("r2", right)
+	if node.Type == token.FINAL_AND || node.Type == token.FINAL_OR {
+		// MOON's and/or are bitwise, so the operands are normalized first
+		g.emit("cnei", "r1", "r1", "0")
+		g.emit("cnei", "r2", "r2", "0")
+	}
+	float := left.typ.Type == token.FINAL_FLOAT
+	switch {
+	case float && node.Type == token.FINAL_MULT:
+		g.fixedMul()
+	case float && node.Type == token.FINAL_DIV:
+		g.fixedDiv()
+	default:
+		g.emit(binaryInstructions[node.Type], "r3", "r1", "r2")
+	}
+
+	switch node.Type {
+	case token.FINAL_PLUS, token.FINAL_MINUS, token.FINAL_MULT, token.FINAL_DIV:
+		typ := token.Type{Type: token.FINAL_INTEGER, Token: node.Token}
+		if float {
+			typ.Type = token.FINAL_FLOAT
+		}
+		return g.result("r3", node, typ)
+	}
+	return g.result("r3", node, token.Type{Type: token.FINAL_BOOL, Token: node.Token})
+}
+
+// Multiplies the fixed point numbers in r1 and r2 into r3. The product is
+// computed as (r1 / scale) * r2 + (r1 % scale) * r2 / scale, so that it does
+// not overflow before it is scaled back. Clobbers r4
+func (g *funcGen) fixedMul() {
+	scale := strconv.Itoa(FIXED_POINT_SCALE)
+	g.emit("divi", "r3", "r1", scale)
+	g.emit("modi", "r4", "r1", scale)
+	g.emit("mul", "r3", "r3", "r2")
+	g.emit("mul", "r4", "r4", "r2")
+	g.emit("divi", "r4", "r4", scale)
+	g.emit("add", "r3", "r3", "r4")
+}
+
+// Divides the fixed point number in r1 by the one in r2 into r3. The quotient
+// is computed as (r1 / r2) * scale + (r1 % r2) * scale / r2. Clobbers r4
+func (g *funcGen) fixedDiv() {
+	scale := strconv.Itoa(FIXED_POINT_SCALE)
+	g.emit("div", "r3", "r1", "r2")
+	g.emit("mod", "r4", "r1", "r2")
+	g.emit("muli", "r3", "r3", scale)
+	g.emit("muli", "r4", "r4", scale)
+	g.emit("div", "r4", "r4", "r2")
+	g.emit("add", "r3", "r3", "r4")
+}
+
+// Checks that a value is an integer (or a float, string, or bool) that can be
+// held in a register
+func (g *funcGen) scalar(v value, at token.Token) bool {
+	switch {
+	case v.typ.Type == "":
+		return false // An error has already been logged
+	case isAggregate(v.typ):
+		g.vis.logCodeGenError(at, "expected an integer but found '%v'", v.typ)
+		return false
+	}
+	return true
+}
+
+// Computes the address of a variable into a temporary
+func (g *funcGen) address(node *token.ASTNode) value {
+	subject, id, indices := node.Children[0], node.Children[1], node.Children[2].Children
+	name := string(id.Token.Lexeme)
+
+	var base value
+	if len(subject.Children) == 0 {
+		if s, ok := g.def.frame.slots[name]; ok {
+			if s.ref {
+				g.emit("lw", "r1", at(s.offset, FRAME_REGISTER))
+			} else {
+				g.emit("addi", "r1", FRAME_REGISTER, strconv.Itoa(s.offset))
+			}
+			base = g.save("r1", s.typ, true)
+		} else if g.def.owner != nil {
+			layout := g.vis.layoutStruct(g.def.owner, id.Token)
+			if layout == nil {
+				return value{}
+			}
+			member, ok := layout.Members[name]
+			if !ok {
+				g.vis.logCodeGenError(id.Token, "cannot resolve variable '%v'", name)
+				return value{}
+			}
+			g.emit("lw", "r1", at(g.def.frame.self, FRAME_REGISTER))
+			base = g.offsetBy("r1", member.Offset, member.Type)
+		} else {
+			g.vis.logCodeGenError(id.Token, "cannot resolve variable '%v'", name)
+			return value{}
+		}
+	} else {
+		object, layout := g.object(subject.Children[0], id.Token)
+		if layout == nil {
+			return value{}
+		}
+		member, ok := layout.Members[name]
+		if !ok {
+			g.vis.logCodeGenError(id.Token, "'%v' has no data member '%v'", object.typ, name)
+			return value{}
+		}
+		g.load("r1", object)
+		base = g.offsetBy("r1", member.Offset, member.Type)
+	}
+
+	return g.index(base, indices, id.Token)
+}
+
+// Adds a constant offset to the address in register, saving it as a temporary
+func (g *funcGen) offsetBy(register string, offset int, typ token.Type) value {
+	if offset != 0 {
+		g.emit("addi", register, register, strconv.Itoa(offset))
+	}
+	return g.save(register, typ, true)
+}
+
+// Evaluates the subject of a member access, producing the address of a struct
+func (g *funcGen) object(node *token.ASTNode, at token.Token) (value, *StructLayout) {
+	var object value
+	switch node.Type {
+	case token.FINAL_VARIABLE:
+		object = g.address(node)
+	case token.FINAL_FUNC_CALL:
+		object = g.call(node)
+	default:
+		g.vis.logCodeGenError(at, "unsupported subject %v", node.Type)
+		return value{}, nil
+	}
+	if object.typ.Type == "" {
+		return value{}, nil
+	}
+	if object.typ.Type != token.FINAL_ID || len(object.typ.Dimlist) > 0 || !object.addr {
+		g.vis.logCodeGenError(at, "'%v' is not a struct", object.typ)
+		return value{}, nil
+	}
+	return object, g.vis.layoutOf(object.typ, at)
+}
+
+// Applies an index list to the address of an array
+func (g *funcGen) index(base value, indices []*token.ASTNode, at token.Token) value {
+	if len(indices) == 0 {
+		return base
+	}
+
+	dims := base.typ.Dimlist
+	if len(indices) > len(dims) {
+		g.vis.logCodeGenError(at, "too many subscripts for '%v'", base.typ)
+		return value{}
+	}
+
+	subscripts := make([]value, 0, len(indices))
+	for _, index := range indices {
+		subscript := g.expr(index)
+		if !g.scalar(subscript, at) {
+			return value{}
+		}
+		subscripts = append(subscripts, subscript)
+	}
+
+	element := token.Type{Type: base.typ.Type, Token: base.typ.Token}
+	g.load("r1", base)
+	for i, subscript := range subscripts {
+		stride := g.vis.sizeOf(token.Type{
+			Type:    element.Type,
+			Token:   element.Token,
+			Dimlist: dims[i+1:],
+		}, at)
+		g.load("r2", subscript)
+		g.emit("muli", "r2", "r2", strconv.Itoa(stride))
+		g.emit("add", "r1", "r1", "r2")
+	}
+
+	element.Dimlist = dims[len(indices):]
+	return g.save("r1", element, true)
+}
+
+// Generates a function call, the result is placed in a temporary
+func (g *funcGen) call(node *token.ASTNode) value {
+	subject, id := node.Children[0], node.Children[1]
+	var callee *moonFunc
+	if record := node.Meta.Record; record != nil {
+		callee = g.vis.funcs[record.Link]
+	}
+	if callee == nil {
+		g.vis.logCodeGenError(id.Token, "cannot resolve function '%v'", id.Token.Lexeme)
+		return value{}
+	}
+
+	// Methods receive a pointer to the struct they have been called on. When
+	// the method has been inherited, the pointer needs to be adjusted so that
+	// it points at the part of the struct that the method knows about
+	self := -1
+	if callee.owner != nil {
+		var object value
+		var layout *StructLayout
+		if len(subject.Children) > 0 {
+			object, layout = g.object(subject.Children[0], id.Token)
+			if layout == nil {
+				return value{}
+			}
+			g.load("r1", object)
+		} else if g.def.owner != nil {
+			layout = g.vis.layoutStruct(g.def.owner, id.Token)
+			g.emit("lw", "r1", at(g.def.frame.self, FRAME_REGISTER))
+		}
+		offset, ok := 0, false
+		if layout != nil {
+			offset, ok = layout.Bases[callee.owner]
+		}
+		if !ok {
+			g.vis.logCodeGenError(id.Token, "cannot call method '%v' here", id.Token.Lexeme)
+			return value{}
+		}
+		self = g.offsetBy("r1", offset, token.Type{}).offset
+	}
+
+	args := node.Children[2].Children
+	params := callee.frame.params
+	if len(args) != len(params) {
+		g.vis.logCodeGenError(id.Token, "wrong number of arguments for '%v'", id.Token.Lexeme)
+		return value{}
+	}
+	values := make([]value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, g.expr(arg))
+	}
+
+	// Copy the arguments into the frame of the callee
+	g.emit("addi", "r2", FRAME_REGISTER, frameSizePlaceholder)
+	if self >= 0 {
+		g.emit("lw", "r1", at(self, FRAME_REGISTER))
+		g.emit("sw", at(callee.frame.self, "r2"), "r1")
+	}
+	for i, param := range params {
+		arg := values[i]
+		switch {
+		case param.ref || !isAggregate(param.typ):
+			if param.ref != arg.addr || (!param.ref && !g.scalar(arg, id.Token)) {
+				g.vis.logCodeGenError(id.Token, "cannot pass '%v' as '%v'", arg.typ, param.typ)
+				continue
+			}
+			g.load("r1", arg)
+			g.emit("sw", at(param.offset, "r2"), "r1")
+		default:
+			if !arg.addr {
+				g.vis.logCodeGenError(id.Token, "cannot pass '%v' as '%v'", arg.typ, param.typ)
+				continue
+			}
+			g.load("r1", arg)
+			g.copy("r1", 0, "r2", param.offset, g.vis.sizeOf(param.typ, id.Token))
+		}
+	}
+
+	g.emit("addi", FRAME_REGISTER, FRAME_REGISTER, frameSizePlaceholder)
+	g.emit("jl", LINK_REGISTER, callee.label)
+
+	ret := callee.ret
+	switch {
+	case ret.Type == token.FINAL_VOID:
+		g.emit("subi", FRAME_REGISTER, FRAME_REGISTER, frameSizePlaceholder)
+		return value{typ: ret}
+	case isAggregate(ret):
+		// The frame of the callee will be clobbered by the next call, so we
+		// copy aggregates out of it right away
+		size := g.vis.sizeOf(ret, id.Token)
+		g.emit("addi", "r1", FRAME_REGISTER, strconv.Itoa(FRAME_RETURN_VALUE_OFFSET))
+		g.emit("subi", FRAME_REGISTER, FRAME_REGISTER, frameSizePlaceholder)
+		block := g.resultBlock(node, size)
+		g.copy("r1", 0, FRAME_REGISTER, block, size)
+		g.emit("addi", "r1", FRAME_REGISTER, strconv.Itoa(block))
+		return g.save("r1", ret, true)
+	default:
+		g.emit("lw", "r1", at(FRAME_RETURN_VALUE_OFFSET, FRAME_REGISTER))
+		g.emit("subi", FRAME_REGISTER, FRAME_REGISTER, frameSizePlaceholder)
+		return g.result("r1", node, ret)
+	}
+}
+
+// Copies size bytes from src to dst. Registers r6-r9 are clobbered
+func (g *funcGen) copy(src string, srcOffset int, dst string, dstOffset, size int) {
+	const UNROLL = 16 * WORD_SIZE
+	if size <= UNROLL {
+		for i := 0; i < size; i += WORD_SIZE {
+			g.emit("lw", "r7", at(srcOffset+i, src))
+			g.emit("sw", at(dstOffset+i, dst), "r7")
+		}
+		return
+	}
+
+	loop := g.vis.label("copy")
+	g.emit("addi", "r8", src, strconv.Itoa(srcOffset))
+	g.emit("addi", "r9", dst, strconv.Itoa(dstOffset))
+	g.emit("addi", "r7", "r0", strconv.Itoa(size/WORD_SIZE))
+	g.emitLabel(loop, "lw", "r6", "0(r8)")
+	g.emit("sw", "0(r9)", "r6")
+	g.emit("addi", "r8", "r8", strconv.Itoa(WORD_SIZE))
+	g.emit("addi", "r9", "r9", strconv.Itoa(WORD_SIZE))
+	g.emit("subi", "r7", "r7", "1")
+	g.emit("bnz", "r7", loop)
+}
+
+// Structs and arrays can't be held in a register
+func isAggregate(typ token.Type) bool {
+	return len(typ.Dimlist) > 0 || typ.Type == token.FINAL_ID
+}
+
+func hasUnknownDimension(typ token.Type) bool {
+	for _, dim := range typ.Dimlist {
+		if dim == token.DIMENSION_ANY {
+			return true
+		}
+	}
+	return false
+}
@@ -211,11 +211,120 @@ func (e *ImplMissingStructError) Error() string {
 		MALFORMED_TYPE, e.Impl.Meta.Record.Name)
 }
 
-type TypeCheckError struct {
-	Msg  string
+type UndefinedTypeError struct {
+	Type token.Token
 	Wrap error
 }
 
+func (e *UndefinedTypeError) Error() string {
+	return fmt.Sprintf(
+		"type '%v' is not valid, no such type has been defined (line %v)",
+		e.Type.Lexeme, e.Type.Line)
+}
+
+func (e *UndefinedTypeError) Unwrap() error {
+	return e.Wrap
+}
+
+// Emitted as a warning
+type OverloadedError struct {
+	Scope     string
+	Name      string
+	Overloads []token.SymbolTableRecord
+	Wrap      error
+}
+
+func (e *OverloadedError) Error() string {
+	out := make([]string, 0, len(e.Overloads))
+	for _, overload := range e.Overloads {
+		out = append(out, formatMethodId(overload))
+	}
+	return fmt.Sprintf(
+		"'%v::%v' has been overloaded %v times: %v",
+		e.Scope, e.Name, len(e.Overloads), strings.Join(out, ", "))
+}
+
+func (e *OverloadedError) Unwrap() error {
+	return e.Wrap
+}
+
+// Emitted as a warning
+type ShadowedMemberError struct {
+	Parent, Child               string
+	ParentMembers, ChildMembers []token.SymbolTableRecord
+	Wrap                        error
+}
+
+func (e *ShadowedMemberError) Error() string {
+	return fmt.Sprintf(
+		"shadowing: parent member(s) %v shadowed by %v",
+		formatMembers(e.Parent, e.ParentMembers),
+		formatMembers(e.Child, e.ChildMembers))
+}
+
+func (e *ShadowedMemberError) Unwrap() error {
+	return e.Wrap
+}
+
+func formatMembers(name string, members []token.SymbolTableRecord) string {
+	out := make([]string, 0, len(members))
+	for _, member := range members {
+		memberOut := member.Name
+		if member.Kind == token.FINAL_FUNC_DEF ||
+			member.Kind == token.FINAL_FUNC_DECL {
+			memberOut = formatMethodId(member)
+		}
+		out = append(out, fmt.Sprintf("'%v::%v'", name, memberOut))
+	}
+	return strings.Join(out, ", ")
+}
+
+type CircularInheritanceError struct {
+	Struct   *token.ASTNode
+	Inherits string
+	Wrap     error
+}
+
+func (e *CircularInheritanceError) Error() string {
+	return fmt.Sprintf(""+
+		"%v: circular inheritance, struct '%v' cannot inherit from '%v' "+
+		"because '%v' already inherits from '%v' (line %v)",
+		MALFORMED_TYPE, safeId(e.Struct), e.Inherits,
+		e.Inherits, safeId(e.Struct), idNode(e.Struct).Token.Line)
+}
+
+func (e *CircularInheritanceError) Unwrap() error {
+	return e.Wrap
+}
+
+// Distinguishes the checks performed by the SemCheckVisitor
+type TypeCheck int
+
+const (
+	CHECK_OTHER            TypeCheck = iota
+	CHECK_NODE_KIND                  // A node of the AST is not of the expected kind
+	CHECK_UNDEFINED_ID               // An identifier is not in scope
+	CHECK_OPERANDS                   // The operands of an operator have different types
+	CHECK_NO_MEMBERS                 // A member is accessed on a value that has none
+	CHECK_NO_MATCHING_CALL           // No definition matches the arguments of a call
+	CHECK_INDEX_COUNT                // A variable has more subscripts than dimensions
+	CHECK_INDEX_TYPE                 // A subscript is not an integer
+	CHECK_ASSIGN                     // The sides of an assignment have different types
+	CHECK_RETURN                     // A function returns a value of the wrong type
+	CHECK_STRING_OPERAND             // An operator is applied to a string
+	CHECK_LOOP                       // A break or continue is not inside a loop
+	CHECK_CONDITION                  // The condition of a statement is not a bool
+	CHECK_LOGICAL_OPERAND            // A logical operator is applied to a value that is not a bool
+	CHECK_BOOL_OPERAND               // An arithmetic or ordering operator is applied to a bool
+)
+
+type TypeCheckError struct {
+	Msg   string
+	Check TypeCheck
+	Token token.Token // The offending token, if it is known
+	Wrap  error
+}
+
 func (e *TypeCheckError) Error() string {
 	if e.Msg != "" {
 		return e.Msg
@@ -226,3 +335,49 @@ func (e *TypeCheckError) Error() string {
 func (e *TypeCheckError) Unwrap() error {
 	return e.Wrap
 }
+
+// Emitted by the MemoryLayoutVisitor when the size of a value cannot be
+// determined
+type MemoryLayoutError struct {
+	Msg   string
+	Token token.Token
+	Wrap  error
+}
+
+func (e *MemoryLayoutError) Error() string {
+	msg := e.Msg
+	if msg == "" && e.Wrap != nil {
+		msg = e.Wrap.Error()
+	}
+	if e.Token.Line > 0 {
+		return fmt.Sprintf("layout: %v (line %v)", msg, e.Token.Line)
+	}
+	return fmt.Sprintf("layout: %v", msg)
+}
+
+func (e *MemoryLayoutError) Unwrap() error {
+	return e.Wrap
+}
+
+// Emitted by the CodeGenVisitor when a program cannot be translated, e.g. when
+// it makes use of a feature that the target does not support
+type CodeGenError struct {
+	Msg   string
+	Token token.Token
+	Wrap  error
+}
+
+func (e *CodeGenError) Error() string {
+	msg := e.Msg
+	if msg == "" && e.Wrap != nil {
+		msg = e.Wrap.Error()
+	}
+	if e.Token.Line > 0 {
+		return fmt.Sprintf("codegen: %v (line %v)", msg, e.Token.Line)
+	}
+	return fmt.Sprintf("codegen: %v", msg)
+}
+
+func (e *CodeGenError) Unwrap() error {
+	return e.Wrap
+}
@@ -0,0 +1,415 @@
+package visitors
+
+import (
+	"fmt"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// Sizes of the basic types, in bytes
+const (
+	WORD_SIZE    = 4
+	INTEGER_SIZE = WORD_SIZE
+	FLOAT_SIZE   = 2 * WORD_SIZE
+	STRING_SIZE  = WORD_SIZE // Strings are immutable, only a reference is stored
+	BOOL_SIZE    = WORD_SIZE
+)
+
+const (
+	// Every frame starts with the saved return address followed by the return
+	// value slot
+	FRAME_RETURN_ADDRESS_OFFSET = 0
+	FRAME_RETURN_VALUE_OFFSET   = WORD_SIZE
+)
+
+// Name of the record holding the return value of a function. It is a reserved
+// word so it can never clash with an identifier
+const RETURN_VALUE_NAME = "return"
+
+// Name of the record holding the pointer to the struct a method has been called
+// on
+const SELF_NAME = "self"
+
+// Computes the memory layout of a program that has been decorated by the
+// SymTabVisitor (and ideally the SemCheckVisitor, which resolves the types of
+// function calls). The layout is computed in one go when the Prog node is
+// visited.
+//
+// Every struct record is annotated with the size of the struct, and every data
+// member with its size and offset within the struct. Inherited structs are laid
+// out first, in order of declaration, followed by the data members of the
+// struct itself.
+//
+// Every function record is annotated with the size of the stack frame of the
+// function, and every record in the function table with its size and offset
+// within the frame. The frame is laid out like so:
+//
+//	0       return address
+//	4       return value (absent for void functions)
+//	...     self pointer (methods only)
+//	...     parameters (arrays are passed by reference, structs by value)
+//	...     local variables
+//	...     temporaries
+//
+// Records are added to the function table for the return value, the self
+// pointer, and the temporaries. A temporary is allocated for every expression
+// that computes a new value: literals, operators, and function calls. The
+// temporary is attached to the expression node as node.Meta.Temp.
+type MemoryLayoutVisitor struct {
+	token.DispatchVisitor
+	errout func(e *VisitorError)
+	global token.SymbolTable
+
+	// Sizes of the structs that have been laid out so far, a negative size
+	// marks a struct that is currently being laid out
+	structs map[token.SymbolTable]int
+}
+
+func NewMemoryLayoutVisitor(errout func(e *VisitorError)) *MemoryLayoutVisitor {
+	vis := &MemoryLayoutVisitor{
+		errout:  errout,
+		structs: make(map[token.SymbolTable]int, 64),
+	}
+	vis.DispatchVisitor = token.DispatchVisitor{Dispatch: map[token.Kind]token.Visit{
+		token.FINAL_PROG: vis.layoutProgram,
+	}}
+	return vis
+}
+
+func (vis *MemoryLayoutVisitor) layoutProgram(node *token.ASTNode) {
+	vis.global = node.Meta.SymbolTable
+	if vis.global == nil {
+		return
+	}
+
+	// Structs first, the frames of functions depend on their sizes
+	forEachRecord(vis.global, func(record *token.SymbolTableRecord) {
+		if record.Link != nil &&
+			(record.Kind == token.FINAL_STRUCT_DECL || record.Kind == token.FINAL_IMPL_DEF) {
+			record.Size = vis.layoutStruct(record.Link, record.Type.Token)
+		}
+	})
+
+	for _, child := range node.Children[0].Children {
+		switch child.Type {
+		case token.FINAL_FUNC_DEF:
+			vis.layoutFunction(child, nil)
+		case token.FINAL_IMPL_DEF:
+			for _, method := range child.Children[1].Children {
+				if method.Type == token.FINAL_ERROR {
+					continue
+				}
+				vis.layoutFunction(method, child.Meta.SymbolTable)
+			}
+		}
+	}
+}
+
+// Lays out the data members of a struct, returns the size of the struct
+func (vis *MemoryLayoutVisitor) layoutStruct(table token.SymbolTable, at token.Token) int {
+	if size, ok := vis.structs[table]; ok {
+		if size < 0 {
+			vis.logLayoutError(at, "struct '%v' contains itself", table.Id())
+			return 0
+		}
+		return size
+	}
+	vis.structs[table] = -1
+
+	var size int
+	for _, inherited := range table.Inherited() {
+		size += vis.layoutStruct(inherited, at)
+	}
+	forEachRecord(table, func(record *token.SymbolTableRecord) {
+		if record.Kind != token.FINAL_VAR_DECL {
+			return
+		}
+		record.Offset = size
+		record.Size = vis.sizeOf(record.Type)
+		size += record.Size
+	})
+
+	vis.structs[table] = size
+	return size
+}
+
+// Lays out the stack frame of a function, owner is the table of the struct for
+// methods
+func (vis *MemoryLayoutVisitor) layoutFunction(node *token.ASTNode, owner token.SymbolTable) {
+	table := node.Meta.SymbolTable
+	if table == nil {
+		return
+	}
+
+	size := FRAME_RETURN_VALUE_OFFSET
+	var records []token.SymbolTableRecord
+	if ret := functionReturnType(table); ret.Type != "" && ret.Type != token.FINAL_VOID {
+		ret.Privacy = ""
+		records = append(records, token.SymbolTableRecord{
+			Name:   RETURN_VALUE_NAME,
+			Kind:   token.RECORD_RETURN_VALUE,
+			Type:   ret,
+			Size:   vis.sizeOf(ret),
+			Offset: size,
+		})
+		size += records[len(records)-1].Size
+	}
+	if owner != nil {
+		records = append(records, token.SymbolTableRecord{
+			Name:   SELF_NAME,
+			Kind:   token.RECORD_SELF,
+			Type:   structType(owner),
+			Size:   WORD_SIZE,
+			Offset: size,
+		})
+		size += WORD_SIZE
+	}
+
+	forEachRecord(table, func(record *token.SymbolTableRecord) {
+		switch record.Kind {
+		case token.FINAL_FUNC_DEF_PARAM:
+			if len(record.Type.Dimlist) > 0 {
+				record.Size = WORD_SIZE // Arrays are passed by reference
+			} else {
+				record.Size = vis.sizeOf(record.Type)
+			}
+		case token.FINAL_VAR_DECL:
+			record.Size = vis.sizeOf(record.Type)
+		default:
+			return
+		}
+		record.Offset = size
+		size += record.Size
+	})
+	size = vis.layoutForScopes(table, size)
+
+	// The records are only inserted once the existing records have been
+	// updated, inserting may move the records of the table around
+	table.Prepend(records...)
+
+	temps := 0
+	var allocate func(node *token.ASTNode)
+	allocate = func(node *token.ASTNode) {
+		for _, child := range node.Children {
+			allocate(child)
+		}
+		typ, ok := vis.tempType(node)
+		if !ok {
+			return
+		}
+		temps++
+		record := token.SymbolTableRecord{
+			Name:   fmt.Sprintf("$t%v", temps),
+			Kind:   token.RECORD_TEMP,
+			Type:   typ,
+			Size:   vis.sizeOf(typ),
+			Offset: size,
+		}
+		size += record.Size
+		table.Insert(record)
+		node.Meta.Temp = table.Search(record.Name)[0]
+	}
+	allocate(node.Children[3])
+
+	// Functions of a malformed impl may not have a parent
+	if parent := table.Parent(); parent != nil {
+		for _, record := range parent.Search(string(node.Children[0].Token.Lexeme)) {
+			if record.Link == table {
+				record.Size = size
+			}
+		}
+	}
+}
+
+// Gives the variables of every for loop nested in a scope slots of their own,
+// starting at offset size. The record linking a loop spans the slots of the
+// loop and of its nested loops. Returns the offset following the last slot
+func (vis *MemoryLayoutVisitor) layoutForScopes(table token.SymbolTable, size int) int {
+	forEachRecord(table, func(record *token.SymbolTableRecord) {
+		if record.Kind != token.FINAL_FOR || record.Link == nil {
+			return
+		}
+		record.Offset = size
+		forEachRecord(record.Link, func(local *token.SymbolTableRecord) {
+			if local.Kind != token.FINAL_VAR_DECL {
+				return
+			}
+			local.Size = vis.sizeOf(local.Type)
+			local.Offset = size
+			size += local.Size
+		})
+		size = vis.layoutForScopes(record.Link, size)
+		record.Size = size - record.Offset
+	})
+	return size
+}
+
+// Returns the type of the value computed by an expression node, ok is false if
+// the node does not compute a new value
+func (vis *MemoryLayoutVisitor) tempType(node *token.ASTNode) (typ token.Type, ok bool) {
+	switch node.Type {
+	case token.FINAL_INTNUM:
+		return basicType(token.FINAL_INTEGER, node.Token), true
+	case token.FINAL_FLOATNUM:
+		return basicType(token.FINAL_FLOAT, node.Token), true
+	case token.FINAL_STRINGLIT:
+		return basicType(token.FINAL_STRING, node.Token), true
+	case token.FINAL_BOOLLIT:
+		return basicType(token.FINAL_BOOL, node.Token), true
+	case token.FINAL_FACTOR:
+		if len(node.Children) != 2 {
+			return token.Type{}, false
+		}
+		return vis.exprType(node.Children[1]), true
+	case token.FINAL_PLUS, token.FINAL_MINUS, token.FINAL_MULT, token.FINAL_DIV:
+		left, right := vis.exprType(node.Children[0]), vis.exprType(node.Children[1])
+		if left.Type == token.FINAL_FLOAT || right.Type == token.FINAL_FLOAT {
+			return basicType(token.FINAL_FLOAT, node.Token), true
+		}
+		return basicType(token.FINAL_INTEGER, node.Token), true
+	case token.FINAL_AND,
+		token.FINAL_OR,
+		token.FINAL_EQ,
+		token.FINAL_NEQ,
+		token.FINAL_LT,
+		token.FINAL_GT,
+		token.FINAL_LEQ,
+		token.FINAL_GEQ:
+		return basicType(token.FINAL_BOOL, node.Token), true
+	case token.FINAL_FUNC_CALL:
+		record := node.Meta.Record
+		if record == nil || record.Type.Type == "" || record.Type.Type == token.FINAL_VOID {
+			return token.Type{}, false
+		}
+		typ = record.Type
+		typ.Privacy = ""
+		return typ, true
+	}
+	return token.Type{}, false
+}
+
+// Returns the type of an expression, as far as can be determined from the
+// annotations on the AST
+func (vis *MemoryLayoutVisitor) exprType(node *token.ASTNode) token.Type {
+	if node.Meta.Temp != nil {
+		return node.Meta.Temp.Type
+	}
+	switch node.Type {
+	case token.FINAL_VARIABLE:
+		if node.Meta.Record == nil {
+			return token.Type{}
+		}
+		typ := node.Meta.Record.Type
+		indices := len(node.Children[2].Children)
+		if indices <= len(typ.Dimlist) {
+			typ.Dimlist = typ.Dimlist[indices:]
+		}
+		return typ
+	case token.FINAL_FACTOR,
+		token.FINAL_ARITH_EXPR,
+		token.FINAL_REL_EXPR,
+		token.FINAL_EXPR,
+		token.FINAL_TERM:
+		if len(node.Children) > 0 {
+			return vis.exprType(node.Children[len(node.Children)-1])
+		}
+	}
+	return token.Type{}
+}
+
+// Returns the size of a value of the given type in bytes
+func (vis *MemoryLayoutVisitor) sizeOf(typ token.Type) int {
+	var size int
+	switch typ.Type {
+	case token.FINAL_INTEGER:
+		size = INTEGER_SIZE
+	case token.FINAL_FLOAT:
+		size = FLOAT_SIZE
+	case token.FINAL_STRING:
+		size = STRING_SIZE
+	case token.FINAL_BOOL:
+		size = BOOL_SIZE
+	case token.FINAL_ID:
+		table := lookupStructTable(vis.global, string(typ.Token.Lexeme))
+		if table == nil {
+			return 0 // The semantic checks report undefined types
+		}
+		size = vis.layoutStruct(table, typ.Token)
+	default:
+		return 0
+	}
+
+	for _, dim := range typ.Dimlist {
+		if dim == token.DIMENSION_ANY {
+			vis.logLayoutError(typ.Token, "cannot determine the size of type '%v'",
+				typ.StringSimple())
+			return 0
+		}
+		size *= dim
+	}
+	return size
+}
+
+func (vis *MemoryLayoutVisitor) logLayoutError(at token.Token, msg string, args ...any) {
+	if vis.errout != nil {
+		vis.errout(&VisitorError{Wrap: &MemoryLayoutError{
+			Msg:   fmt.Sprintf(msg, args...),
+			Token: at,
+		}})
+	}
+}
+
+// Returns the table of the struct with the given name
+func lookupStructTable(global token.SymbolTable, name string) token.SymbolTable {
+	if global == nil {
+		return nil
+	}
+	for _, record := range global.Search(name) {
+		if record.Link != nil &&
+			(record.Kind == token.FINAL_STRUCT_DECL || record.Kind == token.FINAL_IMPL_DEF) {
+			return record.Link
+		}
+	}
+	return nil
+}
+
+// Returns the type of a struct given its table
+func structType(table token.SymbolTable) token.Type {
+	return token.Type{
+		Type:  token.FINAL_ID,
+		Token: token.Token{Id: token.ID, Lexeme: token.Lexeme(table.Id())},
+	}
+}
+
+// Returns the integer, float, string, or bool type, at is used for its line
+// number
+func basicType(kind token.Kind, at token.Token) token.Type {
+	id := token.INTEGER
+	switch kind {
+	case token.FINAL_FLOAT:
+		id = token.FLOAT
+	case token.FINAL_STRING:
+		id = token.STRING
+	case token.FINAL_BOOL:
+		id = token.BOOL
+	}
+	return token.Type{
+		Type:  kind,
+		Token: token.Token{Id: id, Lexeme: token.Lexeme(id), Line: at.Line, Column: at.Column},
+	}
+}
+
+// Calls f on every record of the table, in order, except that records sharing
+// a name are visited together. The records can be modified in place
+func forEachRecord(table token.SymbolTable, f func(record *token.SymbolTableRecord)) {
+	seen := make(map[string]bool, len(table.Entries()))
+	for _, entry := range table.Entries() {
+		if seen[entry.Name] {
+			continue
+		}
+		seen[entry.Name] = true
+		for _, record := range table.Search(entry.Name) {
+			f(record)
+		}
+	}
+}
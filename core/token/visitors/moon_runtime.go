@@ -0,0 +1,170 @@
+package visitors
+
+// Routines that are appended to every program generated by the CodeGenVisitor.
+// All routines are leaves: they take their argument / return their result in
+// r1, clobber r1-r4, and return through r15
+//
+//   - putint: writes the integer in r1 to stdout followed by a newline
+//   - putstr: writes the null-terminated string at the address in r1 to stdout
+//     followed by a newline, a null address is the empty string
+//   - putbool: writes 'true' if r1 is non-zero, 'false' otherwise, followed by
+//     a newline
+//   - putfloat: writes the fixed point number in r1 to stdout with as few
+//     decimals as possible, followed by a newline
+//   - getint: reads an integer from stdin into r1, skipping leading whitespace
+//   - getfloat: reads a decimal number from stdin into r1 as a fixed point
+//     number, skipping leading whitespace. Digits past the third decimal are
+//     dropped
+//
+// The float routines hard-code a FIXED_POINT_SCALE of 1000
+const MOON_RUNTIME = `% runtime
+putint          cgei    r3, r1, 0
+                bnz     r3, putint1
+                addi    r4, r0, 45
+                putc    r4
+                sub     r1, r0, r1
+putint1         addi    r2, r0, putintbuf
+putint2         modi    r4, r1, 10
+                addi    r4, r4, 48
+                sb      0(r2), r4
+                addi    r2, r2, 1
+                divi    r1, r1, 10
+                bnz     r1, putint2
+putint3         subi    r2, r2, 1
+                lb      r4, 0(r2)
+                putc    r4
+                cgti    r3, r2, putintbuf
+                bnz     r3, putint3
+                addi    r4, r0, 10
+                putc    r4
+                jr      r15
+putintbuf       res     12
+
+putstr          bz      r1, putstr2
+putstr1         lb      r4, 0(r1)
+                bz      r4, putstr2
+                putc    r4
+                addi    r1, r1, 1
+                j       putstr1
+putstr2         addi    r4, r0, 10
+                putc    r4
+                jr      r15
+
+putfloat        cgei    r3, r1, 0
+                bnz     r3, putfloat1
+                addi    r4, r0, 45
+                putc    r4
+                sub     r1, r0, r1
+putfloat1       modi    r3, r1, 1000
+                sw      putfloatfrac(r0), r3
+                divi    r1, r1, 1000
+                addi    r2, r0, putintbuf
+putfloat2       modi    r4, r1, 10
+                addi    r4, r4, 48
+                sb      0(r2), r4
+                addi    r2, r2, 1
+                divi    r1, r1, 10
+                bnz     r1, putfloat2
+putfloat3       subi    r2, r2, 1
+                lb      r4, 0(r2)
+                putc    r4
+                cgti    r3, r2, putintbuf
+                bnz     r3, putfloat3
+                lw      r1, putfloatfrac(r0)
+                bz      r1, putfloat5
+                addi    r4, r0, 46
+                putc    r4
+                addi    r2, r0, 100
+putfloat4       div     r4, r1, r2
+                addi    r4, r4, 48
+                putc    r4
+                mod     r1, r1, r2
+                divi    r2, r2, 10
+                bnz     r1, putfloat4
+putfloat5       addi    r4, r0, 10
+                putc    r4
+                jr      r15
+putfloatfrac    res     4
+
+putbool         bz      r1, putbool1
+                addi    r1, r0, putbooltrue
+                j       putstr
+putbool1        addi    r1, r0, putboolfalse
+                j       putstr
+putbooltrue     db      116, 114, 117, 101, 0
+putboolfalse    db      102, 97, 108, 115, 101, 0
+                align
+
+getint          addi    r1, r0, 0
+                addi    r3, r0, 0
+getint1         getc    r2
+                ceqi    r4, r2, 32
+                bnz     r4, getint1
+                ceqi    r4, r2, 10
+                bnz     r4, getint1
+                ceqi    r4, r2, 13
+                bnz     r4, getint1
+                ceqi    r4, r2, 9
+                bnz     r4, getint1
+                ceqi    r4, r2, 45
+                bz      r4, getint2
+                addi    r3, r0, 1
+                getc    r2
+getint2         clti    r4, r2, 48
+                bnz     r4, getint3
+                cgti    r4, r2, 57
+                bnz     r4, getint3
+                muli    r1, r1, 10
+                subi    r2, r2, 48
+                add     r1, r1, r2
+                getc    r2
+                j       getint2
+getint3         bz      r3, getint4
+                sub     r1, r0, r1
+getint4         jr      r15
+
+getfloat        addi    r1, r0, 0
+                addi    r3, r0, 0
+getfloat1       getc    r2
+                ceqi    r4, r2, 32
+                bnz     r4, getfloat1
+                ceqi    r4, r2, 10
+                bnz     r4, getfloat1
+                ceqi    r4, r2, 13
+                bnz     r4, getfloat1
+                ceqi    r4, r2, 9
+                bnz     r4, getfloat1
+                ceqi    r4, r2, 45
+                bz      r4, getfloat2
+                addi    r3, r0, 1
+                getc    r2
+getfloat2       clti    r4, r2, 48
+                bnz     r4, getfloat3
+                cgti    r4, r2, 57
+                bnz     r4, getfloat3
+                muli    r1, r1, 10
+                subi    r2, r2, 48
+                add     r1, r1, r2
+                getc    r2
+                j       getfloat2
+getfloat3       muli    r1, r1, 1000
+                sw      getfloatneg(r0), r3
+                ceqi    r4, r2, 46
+                bz      r4, getfloat5
+                addi    r3, r0, 100
+getfloat4       getc    r2
+                clti    r4, r2, 48
+                bnz     r4, getfloat5
+                cgti    r4, r2, 57
+                bnz     r4, getfloat5
+                subi    r2, r2, 48
+                mul     r2, r2, r3
+                add     r1, r1, r2
+                divi    r3, r3, 10
+                j       getfloat4
+getfloat5       lw      r3, getfloatneg(r0)
+                bz      r3, getfloat6
+                sub     r1, r0, r1
+getfloat6       jr      r15
+getfloatneg     res     4
+`
@@ -15,6 +15,7 @@ type record struct {
 type SemCheckVisitor struct {
 	token.DispatchVisitor
 	errout func(e *VisitorError)
+	loops  int // The number of loops enclosing the statement being checked
 }
 
 func NewSemCheckVisitor(errout func(e *VisitorError)) *SemCheckVisitor {
@@ -43,12 +44,18 @@ func (vis *SemCheckVisitor) doCheck(table token.SymbolTable, node *token.ASTNode
 		vis.typeCheckIf(table, node)
 	case token.FINAL_WHILE:
 		vis.typeCheckWhile(table, node)
+	case token.FINAL_FOR:
+		vis.typeCheckFor(table, node)
+	case token.FINAL_BREAK, token.FINAL_CONTINUE:
+		vis.assertInLoop(node)
 	case token.FINAL_READ:
 		vis.typeCheckRead(table, node)
 	case token.FINAL_WRITE:
 		vis.typeCheckWrite(table, node)
 	case token.FINAL_FUNC_CALL:
 		vis.typeCheckFunctionCall(table, node)
+	case token.FINAL_ERROR:
+		// A statement that could not be parsed, skipped like Accept does
 	default:
 		vis.typeCheck(table, node)
 	}
@@ -58,7 +65,7 @@ func (vis *SemCheckVisitor) typeCheck(table token.SymbolTable, node *token.ASTNo
 	switch child := node.Children[0]; child.Type {
 	case token.FINAL_FACTOR:
 		return vis.typeCheck(table, child)
-	case token.FINAL_ARITH_EXPR:
+	case token.FINAL_ARITH_EXPR, token.FINAL_REL_EXPR:
 		return vis.typeCheck(table, child)
 	case token.FINAL_VARIABLE:
 		return vis.typeCheckVariable(table, child)
@@ -80,14 +87,19 @@ func (vis *SemCheckVisitor) typeCheck(table token.SymbolTable, node *token.ASTNo
 		token.FINAL_GT,
 		token.FINAL_GEQ:
 		return vis.typeCheckComparison(table, child)
-	case token.FINAL_NOT:
-		return vis.typeCheckUnaryOperator(table, child)
-	case token.FINAL_NEGATIVE, token.FINAL_POSITIVE:
+	case token.FINAL_NOT, token.FINAL_NEGATIVE, token.FINAL_POSITIVE:
 		return vis.typeCheckSigned(table, node)
 	case token.FINAL_INTNUM:
 		return token.Type{Type: token.FINAL_INTEGER, Token: child.Token}
 	case token.FINAL_FLOATNUM:
 		return token.Type{Type: token.FINAL_FLOAT, Token: child.Token}
+	case token.FINAL_STRINGLIT:
+		return token.Type{Type: token.FINAL_STRING, Token: child.Token}
+	case token.FINAL_BOOLLIT:
+		return token.Type{Type: token.FINAL_BOOL, Token: child.Token}
+	case token.FINAL_ERROR:
+		// A placeholder for an expression that could not be parsed
+		return token.Type{}
 	default:
 		panic(fmt.Errorf("not implemented! %v", child))
 	}
@@ -98,30 +110,88 @@ func (vis *SemCheckVisitor) typeCheckSigned(
 	node *token.ASTNode,
 ) token.Type {
 	// This will be a Factor with two children
-	value := node.Children[1]
-	return vis.typeCheck(table, value)
+	operator := node.Children[0]
+	value := vis.typeCheck(table, node.Children[1])
+	if operator.Type == token.FINAL_NOT {
+		vis.assertLogicalOperand(operator, value)
+		return token.Type{Type: token.FINAL_BOOL, Token: operator.Token}
+	}
+	vis.assertNotString(operator, value)
+	vis.assertNotBool(operator, value)
+	return value
 }
 
-// If statement has 3 parts: relExpr, statBlock, statBlock
+// If statement has 2 or 3 parts: condition, statBlock, and an optional else
+// statBlock
 func (vis *SemCheckVisitor) typeCheckIf(table token.SymbolTable, node *token.ASTNode) {
-	relExpr := node.Children[0]
+	condition := node.Children[0]
 	statBlock1 := node.Children[1].Children
-	statBlock2 := node.Children[2].Children
 
-	vis.assertRelExpr(relExpr, fmt.Sprintf("if expression (line %v): ", node.Token.Line))
-	vis.typeCheck(table, relExpr)
+	vis.typeCheckCondition(table, node, condition)
 	vis.typeCheckBlock(table, statBlock1)
-	vis.typeCheckBlock(table, statBlock2)
+	if len(node.Children) > 2 {
+		vis.typeCheckBlock(table, node.Children[2].Children)
+	}
 }
 
-// While has 2 parts: relExpr, statBlock
+// While has 2 parts: condition, statBlock
 func (vis *SemCheckVisitor) typeCheckWhile(table token.SymbolTable, node *token.ASTNode) {
-	relExpr := node.Children[0]
+	condition := node.Children[0]
 	statBlock := node.Children[1].Children
 
-	vis.assertRelExpr(relExpr, fmt.Sprintf("while expression (line %v): ", node.Token.Line))
-	vis.typeCheck(table, relExpr)
-	vis.typeCheckBlock(table, statBlock)
+	vis.typeCheckCondition(table, node, condition)
+	vis.typeCheckLoopBody(table, statBlock)
+}
+
+// For has 4 parts: statBlock (the init clause), condition, assign, statBlock.
+// The loop is checked within its own scope, which holds the loop variables
+func (vis *SemCheckVisitor) typeCheckFor(table token.SymbolTable, node *token.ASTNode) {
+	if node.Meta.SymbolTable != nil {
+		table = node.Meta.SymbolTable
+	}
+	init := childrenWithoutVarDecls(node.Children[0].Children)
+	condition := node.Children[1]
+	step := node.Children[2]
+	statBlock := node.Children[3].Children
+
+	vis.typeCheckBlock(table, init)
+	vis.typeCheckCondition(table, node, condition)
+	vis.typeCheckAssign(table, step)
+	vis.typeCheckLoopBody(table, statBlock)
+}
+
+// The condition of an if, while, or for statement must be a bool, integers are
+// not implicitly converted
+func (vis *SemCheckVisitor) typeCheckCondition(
+	table token.SymbolTable,
+	statement, condition *token.ASTNode,
+) {
+	typ := vis.typeCheck(table, condition)
+	if typ.Type == "" || isBool(typ) {
+		return
+	}
+	at := typ.Token
+	if at.Line == 0 {
+		at = tokenOf(condition)
+	}
+	vis.logTypeCheckError(CHECK_CONDITION, at, fmt.Sprintf(""+
+		"typecheck: %v condition must be of type %v but has type %v (line %v)",
+		statement.Token.Lexeme, token.FINAL_BOOL, typ.Type, statement.Token.Line))
+}
+
+func (vis *SemCheckVisitor) typeCheckLoopBody(table token.SymbolTable, statements []*token.ASTNode) {
+	vis.loops++
+	defer func() { vis.loops-- }()
+	vis.typeCheckBlock(table, statements)
+}
+
+// Break and continue statements may only appear in the body of a loop
+func (vis *SemCheckVisitor) assertInLoop(node *token.ASTNode) {
+	if vis.loops == 0 {
+		vis.logTypeCheckError(CHECK_LOOP, node.Token, fmt.Sprintf(
+			"typecheck: '%v' is not inside a loop (line %v)",
+			node.Token.Lexeme, node.Token.Line))
+	}
 }
 
 func (vis *SemCheckVisitor) typeCheckRead(table token.SymbolTable, node *token.ASTNode) {
@@ -132,7 +202,7 @@ func (vis *SemCheckVisitor) typeCheckRead(table token.SymbolTable, node *token.A
 
 func (vis *SemCheckVisitor) assertVariable(node *token.ASTNode, msgPrefix ...string) {
 	if !isTypeNode(node, token.FINAL_VARIABLE) {
-		vis.logTypeCheckError(fmt.Sprintf("%vexpected %v but got %v",
+		vis.logTypeCheckError(CHECK_NODE_KIND, tokenOf(node), fmt.Sprintf("%vexpected %v but got %v",
 			strings.Join(msgPrefix, ""), token.FINAL_VARIABLE, node.Type))
 	}
 }
@@ -150,25 +220,19 @@ func (vis *SemCheckVisitor) assertExpr(node *token.ASTNode, msgPrefix ...string)
 	}, ", ")
 
 	if !isTypeNode(node, token.FINAL_REL_EXPR, token.FINAL_ARITH_EXPR) {
-		vis.logTypeCheckError(fmt.Sprintf("%vexpected %v but got %v",
+		vis.logTypeCheckError(CHECK_NODE_KIND, tokenOf(node), fmt.Sprintf("%vexpected %v but got %v",
 			strings.Join(msgPrefix, ""), or, node.Type))
 	}
 }
 
-func (vis *SemCheckVisitor) assertRelExpr(node *token.ASTNode, msgPrefix ...string) {
-	if !isTypeNode(node, token.FINAL_REL_EXPR) {
-		vis.logTypeCheckError(fmt.Sprintf("%vexpected %v but got %v",
-			strings.Join(msgPrefix, ""), token.FINAL_REL_EXPR, node.Type))
-	}
-}
-
-// For operators like <, >, ==, <>, etc.
+// For operators like <, >, ==, <>, etc. Comparisons yield a bool, bools may
+// only be compared for equality
 func (vis *SemCheckVisitor) typeCheckComparison(
 	table token.SymbolTable,
 	node *token.ASTNode,
 ) token.Type {
 	ret := token.Type{
-		Type:    token.FINAL_INTEGER, // We will say that booleans are integers
+		Type:    token.FINAL_BOOL,
 		Token:   node.Token,
 		Dimlist: []int{},
 	}
@@ -177,42 +241,86 @@ func (vis *SemCheckVisitor) typeCheckComparison(
 	// but they also must be of a specific set of types that are supported in
 	// comparison operations. There is no operator overloading, and custom types
 	// cannot be used in comparison operations
-	left := vis.typeCheck(table, node.Children[0])
-	right := vis.typeCheck(table, node.Children[1])
+	left := vis.typeCheckOperand(table, node.Children[0])
+	right := vis.typeCheckOperand(table, node.Children[1])
 	if !left.EqualsNoPrivacy(right) {
 		vis.emitBinaryOperatorTypeMismatchError(node, left, right)
+	} else {
+		vis.assertNotString(node, left)
+		if node.Type != token.FINAL_EQ && node.Type != token.FINAL_NEQ {
+			vis.assertNotBool(node, left)
+		}
 	}
 	return ret
 }
 
-// For operators like NOT
-func (vis *SemCheckVisitor) typeCheckUnaryOperator(
-	table token.SymbolTable,
-	node *token.ASTNode,
-) token.Type {
-	t := vis.typeCheck(table, node.Children[0])
-	return replaceToken(t, node)
-}
-
 // The type of a binary operator expression is equal to the type of both
-// operands. Used for operators like AND, OR, PLUS, MINUS, etc.
+// operands. Used for operators like PLUS, MINUS, etc. The operands of AND and
+// OR must be bools
 func (vis *SemCheckVisitor) typeCheckBinaryOperator(
 	table token.SymbolTable,
 	node *token.ASTNode,
 ) token.Type {
-	left := vis.typeCheck(table, node.Children[0])
-	right := vis.typeCheck(table, node.Children[1])
+	left := vis.typeCheckOperand(table, node.Children[0])
+	right := vis.typeCheckOperand(table, node.Children[1])
+	if node.Type == token.FINAL_AND || node.Type == token.FINAL_OR {
+		vis.assertLogicalOperand(node, left)
+		vis.assertLogicalOperand(node, right)
+		return token.Type{Type: token.FINAL_BOOL, Token: node.Token, Dimlist: []int{}}
+	}
+
 	if !left.EqualsNoPrivacy(right) {
 		vis.emitBinaryOperatorTypeMismatchError(node, left, right)
+	} else {
+		vis.assertNotString(node, left)
+		vis.assertNotBool(node, left)
 	}
 	return replaceToken(left, node)
 }
 
+// Operands may be nested operators rather than a Factor or ArithExpr. Where
+// typeCheck checks the first child of a node, this checks the node itself
+func (vis *SemCheckVisitor) typeCheckOperand(
+	table token.SymbolTable,
+	node *token.ASTNode,
+) token.Type {
+	return vis.typeCheck(table, &token.ASTNode{Children: []*token.ASTNode{node}})
+}
+
+// Strings can be stored, passed around, and written, but no operator can be
+// applied to them
+func (vis *SemCheckVisitor) assertNotString(operator *token.ASTNode, operand token.Type) {
+	if isType(operand, token.FINAL_STRING) {
+		vis.logTypeCheckError(CHECK_STRING_OPERAND, operator.Token, fmt.Sprintf(""+
+			"typecheck: operator %v cannot be applied to strings (line %v)",
+			operator.Type, operator.Token.Line))
+	}
+}
+
+// Logical operators are only applied to bools
+func (vis *SemCheckVisitor) assertLogicalOperand(operator *token.ASTNode, operand token.Type) {
+	if operand.Type != "" && !isBool(operand) {
+		vis.logTypeCheckError(CHECK_LOGICAL_OPERAND, operator.Token, fmt.Sprintf(""+
+			"typecheck: operator %v expects operands of type %v but got %v (line %v)",
+			operator.Type, token.FINAL_BOOL, operand.Type, operator.Token.Line))
+	}
+}
+
+// Bools can be stored, compared for equality, and combined with logical
+// operators, but not used in arithmetic or ordered
+func (vis *SemCheckVisitor) assertNotBool(operator *token.ASTNode, operand token.Type) {
+	if isType(operand, token.FINAL_BOOL) {
+		vis.logTypeCheckError(CHECK_BOOL_OPERAND, operator.Token, fmt.Sprintf(""+
+			"typecheck: operator %v cannot be applied to bools (line %v)",
+			operator.Type, operator.Token.Line))
+	}
+}
+
 func (vis *SemCheckVisitor) emitBinaryOperatorTypeMismatchError(
 	node *token.ASTNode,
 	left, right token.Type,
 ) {
-	vis.logTypeCheckError(fmt.Sprintf(""+
+	vis.logTypeCheckError(CHECK_OPERANDS, tokenOf(node), fmt.Sprintf(""+
 		"typecheck: type mismatch for operator %v, "+
 		"left operands has type %v and right operand has type %v",
 		node.Type, left.Type, right.Type))
@@ -269,6 +377,23 @@ func (vis *SemCheckVisitor) obtainSubjectRecord(
 		return nil
 	}
 
+	// Function calls and struct params do not link to the table of their
+	// type, so we have to go find it ourselves
+	if rec := sub[0]; rec.Type.Type == token.FINAL_ID &&
+		(rec.Link == nil || rec.Kind == token.FINAL_FUNC_DEF) {
+		if structs := token.DeepLookup(table, string(rec.Type.Token.Lexeme)); len(structs) > 0 {
+			sub = structs
+		}
+	}
+
+	// Only structs have members, the subject needs a table for us to search
+	if sub[0].Link == nil {
+		vis.logTypeCheckError(CHECK_NO_MEMBERS, node.Children[1].Token, fmt.Sprintf(""+
+			"typecheck: '%v' has no members, cannot access '%v' (line %v)",
+			subId, node.Children[1].Token.Lexeme, node.Children[1].Token.Line))
+		return nil
+	}
+
 	return sub[0]
 }
 
@@ -293,13 +418,17 @@ func (vis *SemCheckVisitor) functionLookup(
 			params = append(params, string(param.Type))
 		}
 		paramsString := strings.Join(params, ", ")
-		vis.logTypeCheckError(fmt.Sprintf(""+
+		vis.logTypeCheckError(CHECK_NO_MATCHING_CALL, node.Children[1].Token, fmt.Sprintf(""+
 			"typecheck: none of the in-scope definitions for function '%v' "+
 			"match function call %v(%v) (line %v)",
 			id, id, paramsString, node.Children[1].Token.Line))
 		return token.Type{}
 	}
 
+	// Remember which overload was resolved, later phases (e.g. code
+	// generation) need to know exactly which function is being called
+	node.Meta.Record = funcDefCalled
+
 	return token.Type{
 		Type:    funcDefCalled.Type.Type,
 		Token:   node.Children[1].Token,
@@ -373,6 +502,7 @@ func (vis *SemCheckVisitor) variableLookup(
 	}
 
 	rec := found[0]
+	node.Meta.Record = rec
 	subscriptedType := vis.typeCheckDimensions(table, node, rec)
 	return replaceToken(subscriptedType, node.Children[1])
 }
@@ -394,7 +524,7 @@ func (vis *SemCheckVisitor) typeCheckDimensions(
 			variableToken = node.Token
 		}
 
-		vis.logTypeCheckError(fmt.Sprintf(""+
+		vis.logTypeCheckError(CHECK_INDEX_COUNT, variableToken, fmt.Sprintf(""+
 			"typecheck: invalid index list for variable %v on line %v, "+
 			"variable has %v dimensions but index list has %v subscript(s)",
 			record.Name, variableToken.Line, len(dimList), len(indexList)))
@@ -408,7 +538,11 @@ func (vis *SemCheckVisitor) typeCheckDimensions(
 
 		// Indexes should always have integer type, let's check that
 		if !isType(indexType, token.FINAL_INTEGER) {
-			vis.logTypeCheckError(fmt.Sprintf(""+
+			at := indexType.Token
+			if at.Line == 0 {
+				at = tokenOf(index)
+			}
+			vis.logTypeCheckError(CHECK_INDEX_TYPE, at, fmt.Sprintf(""+
 				"typecheck: index expressions must be integers, "+
 				"index #%v for variable '%v' on line %v has type %v",
 				i+1,
@@ -435,11 +569,12 @@ func (vis *SemCheckVisitor) typeCheckAssign(table token.SymbolTable, node *token
 	lhs := vis.typeCheck(table, node)
 	rhs := vis.typeCheck(table, node.Children[1])
 	if !lhs.EqualsNoPrivacy(rhs) {
-		vis.logTypeCheckError(fmt.Sprintf(""+
+		at := tokenOf(node)
+		vis.logTypeCheckError(CHECK_ASSIGN, at, fmt.Sprintf(""+
 			"typecheck: mismatched return type for assignment statement "+
 			"in function '%v::%v' line %v "+
 			"left-hand side has type %v while right-hand side has type %v",
-			table.Parent().Id(), table.Id(), node.Children[0].Token.Line,
+			parentId(functionScope(table)), functionScope(table).Id(), at.Line,
 			lhs.Type, rhs.Type))
 	}
 }
@@ -448,9 +583,14 @@ func (vis *SemCheckVisitor) typeCheckReturn(table token.SymbolTable, node *token
 	expectedReturnType := functionReturnType(table)
 	actualReturnType := vis.typeCheck(table, node.Children[0])
 	if !expectedReturnType.EqualsNoPrivacy(actualReturnType) {
-		vis.logTypeCheckError(fmt.Sprintf(
+		tok := actualReturnType.Token
+		if tok.Line == 0 {
+			tok = tokenOf(node)
+		}
+		vis.logTypeCheckError(CHECK_RETURN, tok, fmt.Sprintf(
 			"typecheck: mismatched return type for '%v::%v', expected %v but found %v",
-			table.Parent().Id(), table.Id(), expectedReturnType, actualReturnType))
+			parentId(functionScope(table)), functionScope(table).Id(),
+			expectedReturnType, actualReturnType))
 	}
 }
 
@@ -465,7 +605,7 @@ func (vis *SemCheckVisitor) typeCheckSubject(
 }
 
 func (vis *SemCheckVisitor) emitLookupError(node *token.ASTNode) {
-	vis.logTypeCheckError(fmt.Sprintf(""+
+	vis.logTypeCheckError(CHECK_UNDEFINED_ID, node.Token, fmt.Sprintf(""+
 		"typecheck: id %v was not found within the current scope (line %v)",
 		node.Token.Lexeme, node.Token.Line))
 }
@@ -476,8 +616,8 @@ func (vis *SemCheckVisitor) logErr(e *VisitorError) {
 	}
 }
 
-func (vis *SemCheckVisitor) logTypeCheckError(msg string) {
-	vis.logErr(&VisitorError{Wrap: &TypeCheckError{Msg: msg}})
+func (vis *SemCheckVisitor) logTypeCheckError(check TypeCheck, tok token.Token, msg string) {
+	vis.logErr(&VisitorError{Wrap: &TypeCheckError{Msg: msg, Check: check, Token: tok}})
 }
 
 func (vis *SemCheckVisitor) typeCheckBlock(table token.SymbolTable, statements []*token.ASTNode) {
@@ -492,14 +632,15 @@ func (vis *SemCheckVisitor) attachVarDecl(node *token.ASTNode) {
 	if typee.Type != token.FINAL_ID {
 		return
 	}
+	// The record of a duplicate declaration is never added to a table
+	if node.Meta.Record == nil || node.Meta.Record.Parent == nil {
+		return
+	}
 	customType := typee.Token.Lexeme
 	found := token.DeepLookup(node.Meta.Record.Parent, string(customType))
 	if len(found) == 0 {
-		vis.logErr(&VisitorError{
-			Msg: fmt.Sprintf(""+
-				"type '%v' is not valid, no such type has been defined (line %v)",
-				customType, typee.Token.Line),
-		})
+		vis.logErr(&VisitorError{Wrap: &UndefinedTypeError{Type: typee.Token}})
+		return
 	}
 
 	// Update the record
@@ -519,17 +660,20 @@ func (vis *SemCheckVisitor) attachReturnTypeTable(node *token.ASTNode) {
 	customType := returnType.Token.Lexeme
 	found := token.DeepLookup(node.Meta.SymbolTable, string(customType))
 	if len(found) == 0 {
-		vis.logErr(&VisitorError{
-			Msg: fmt.Sprintf(""+
-				"type '%v' is not valid, no such type has been defined (line %v)",
-				customType, returnType.Token.Line),
-		})
+		vis.logErr(&VisitorError{Wrap: &UndefinedTypeError{Type: returnType.Token}})
 	}
 	// returnType.Meta.Record.Link =  found[0].Link
 	// returnType.Meta.SymbolTable = found[0].Link
 }
 
 func functionReturnType(functionTable token.SymbolTable) token.Type {
+	functionTable = functionScope(functionTable)
+
+	// Functions of a malformed impl may not have a parent
+	if functionTable.Parent() == nil {
+		return token.Type{}
+	}
+
 	// Find the entry for this table in its parent
 	for _, entry := range functionTable.Parent().Entries() {
 		if entry.Link == functionTable {
@@ -540,16 +684,42 @@ func functionReturnType(functionTable token.SymbolTable) token.Type {
 	return token.Type{}
 }
 
+// Returns the id of the parent of the table, or an empty string if the table
+// has no parent
+func parentId(table token.SymbolTable) string {
+	if parent := table.Parent(); parent != nil {
+		return parent.Id()
+	}
+	return ""
+}
+
 func childrenWithoutVarDecls(children []*token.ASTNode) []*token.ASTNode {
 	ret := make([]*token.ASTNode, 0, len(children))
 	for _, child := range children {
-		if child.Type != token.FINAL_VAR_DECL {
+		if child.Type != token.FINAL_VAR_DECL && child.Type != token.FINAL_ERROR {
 			ret = append(ret, child)
 		}
 	}
 	return ret
 }
 
+// Returns the token of the node, or the first token found beneath it if the
+// node has none. Errors are reported at this token
+func tokenOf(node *token.ASTNode) token.Token {
+	if node == nil {
+		return token.Token{}
+	}
+	if node.Token.Id != "" {
+		return node.Token
+	}
+	for _, child := range node.Children {
+		if tok := tokenOf(child); tok.Id != "" {
+			return tok
+		}
+	}
+	return token.Token{}
+}
+
 func replaceToken(typee token.Type, node *token.ASTNode) token.Type {
 	return token.Type{
 		Type:    typee.Type,
@@ -569,6 +739,11 @@ func isTypeNode(node *token.ASTNode, types ...token.Kind) bool {
 	return false
 }
 
+// Whether the type is a bool, not an array of them
+func isBool(typee token.Type) bool {
+	return isType(typee, token.FINAL_BOOL) && len(typee.Dimlist) == 0
+}
+
 func isType(typee token.Type, types ...token.Kind) bool {
 	for _, t := range types {
 		if typee.Type == t {
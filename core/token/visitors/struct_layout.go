@@ -0,0 +1,133 @@
+package visitors
+
+import (
+	"fmt"
+
+	"github.com/obonobo/esac/core/token"
+)
+
+// The layout of a struct, including all inherited members. The members of the
+// inherited structs are placed at the offset of the struct they come from
+type StructLayout struct {
+	Size    int
+	Members map[string]Member
+	Bases   map[token.SymbolTable]int // Offsets of all (transitive) parents
+}
+
+// A data member of a struct, the offset is relative to the start of the struct
+type Member struct {
+	Offset int
+	Type   token.Type
+}
+
+// Reads the layouts of the structs of a program from the sizes and offsets that
+// the MemoryLayoutVisitor stored in the symbol tables, for the back ends. The
+// layouts are cached
+type StructLayouts struct {
+	global  token.SymbolTable
+	layouts map[token.SymbolTable]*StructLayout
+}
+
+func NewStructLayouts(global token.SymbolTable) *StructLayouts {
+	return &StructLayouts{
+		global:  global,
+		layouts: make(map[token.SymbolTable]*StructLayout, 64),
+	}
+}
+
+// Returns the layout of the struct that has the given table
+func (s *StructLayouts) Struct(table token.SymbolTable) (*StructLayout, error) {
+	if layout, ok := s.layouts[table]; ok {
+		if layout == nil {
+			return nil, fmt.Errorf("struct '%v' contains itself", table.Id())
+		}
+		return layout, nil
+	}
+	s.layouts[table] = nil // Guards against recursive structs
+
+	layout := &StructLayout{
+		Members: make(map[string]Member, 16),
+		Bases:   map[token.SymbolTable]int{table: 0},
+	}
+	for _, record := range s.global.Search(table.Id()) {
+		if record.Link == table {
+			layout.Size = record.Size
+		}
+	}
+
+	// The inherited structs come first, in order of declaration
+	var offset int
+	for _, inherited := range table.Inherited() {
+		base, err := s.Struct(inherited)
+		if err != nil {
+			return nil, err
+		}
+		for t, o := range base.Bases {
+			if _, ok := layout.Bases[t]; !ok {
+				layout.Bases[t] = offset + o
+			}
+		}
+		for name, member := range base.Members {
+			if _, ok := layout.Members[name]; !ok {
+				member.Offset += offset
+				layout.Members[name] = member
+			}
+		}
+		offset += base.Size
+	}
+
+	for _, entry := range table.Entries() {
+		if entry.Kind == token.FINAL_VAR_DECL {
+			layout.Members[entry.Name] = Member{Offset: entry.Offset, Type: entry.Type}
+		}
+	}
+
+	s.layouts[table] = layout
+	return layout, nil
+}
+
+// Returns the layout of the struct named by the type
+func (s *StructLayouts) Of(typ token.Type) (*StructLayout, error) {
+	table := lookupStructTable(s.global, string(typ.Token.Lexeme))
+	if table == nil {
+		return nil, fmt.Errorf("type '%v' is not a struct", typ.Token.Lexeme)
+	}
+	return s.Struct(table)
+}
+
+// Returns the size of a value of the given type in bytes
+func (s *StructLayouts) SizeOf(typ token.Type) (int, error) {
+	size, err := s.ElementSize(typ)
+	if err != nil {
+		return 0, err
+	}
+	for _, dim := range typ.Dimlist {
+		if dim == token.DIMENSION_ANY {
+			return 0, fmt.Errorf("cannot determine the size of array type '%v'", typ)
+		}
+		size *= dim
+	}
+	return size, nil
+}
+
+// Returns the size of a value of the given type in bytes, ignoring the
+// dimensions of arrays
+func (s *StructLayouts) ElementSize(typ token.Type) (int, error) {
+	switch typ.Type {
+	case token.FINAL_INTEGER:
+		return INTEGER_SIZE, nil
+	case token.FINAL_FLOAT:
+		return FLOAT_SIZE, nil
+	case token.FINAL_STRING:
+		return STRING_SIZE, nil
+	case token.FINAL_BOOL:
+		return BOOL_SIZE, nil
+	case token.FINAL_ID:
+		layout, err := s.Of(typ)
+		if err != nil {
+			return 0, err
+		}
+		return layout.Size, nil
+	}
+	return 0, fmt.Errorf("cannot determine the size of type '%v'", typ)
+}
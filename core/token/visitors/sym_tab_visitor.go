@@ -3,7 +3,6 @@ package visitors
 import (
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/obonobo/esac/core/token"
 	"github.com/obonobo/esac/core/token/sym"
@@ -125,7 +124,9 @@ func NewSymTabVisitor(errout func(e *VisitorError)) *SymTabVisitor {
 
 		token.FINAL_FUNC_DEF: func(node *token.ASTNode) {
 			vis.parseFuncHead(node, token.FINAL_FUNC_DEF)
-			addChildren(vis, node, node.Children[3].Children)
+			body := node.Children[3].Children
+			addChildren(vis, node, body)
+			addForScopes(vis, node, body, new(int))
 		},
 
 		token.FINAL_FUNC_DEF_PARAM: func(node *token.ASTNode) {
@@ -495,6 +496,9 @@ func completeSymbolTableStruct(
 	structMethods := make(map[string]token.SymbolTableRecord, 64)
 	implDefitions := methods(partialStructTable)
 	for _, member := range node.Children[2].Children {
+		if member.Type == token.FINAL_ERROR {
+			continue
+		}
 		structMember := *member.Meta.Record
 
 		structKey := structMember.String()
@@ -598,6 +602,9 @@ func completeSymbolTableImpl(
 	implMethods := make(map[string]token.SymbolTableRecord, 64)
 	structMethods := methods(partialStructTable)
 	for _, member := range node.Children[1].Children {
+		if member.Type == token.FINAL_ERROR {
+			continue
+		}
 		implMember := *member.Meta.Record // Panic if nil
 
 		implKey := implMember.String()
@@ -757,6 +764,87 @@ func addChildren(vis *SymTabVisitor, node *token.ASTNode, children []*token.ASTN
 	}
 }
 
+// Name of the records that link the scope of a for loop. It is a reserved word
+// so it can never clash with an identifier, the loops after the first one of a
+// function are numbered e.g. 'for#2'
+const FOR_SCOPE_NAME = "for"
+
+// Gives every for loop in a block, including those of nested blocks, a scope of
+// its own holding the variables declared by its init clause. The scope is
+// linked from the enclosing scope so a loop variable is only visible within
+// its loop. Loops are numbered in the order that they appear in the function
+func addForScopes(vis *SymTabVisitor, scope *token.ASTNode, statements []*token.ASTNode, loops *int) {
+	for _, statement := range statements {
+		switch statement.Type {
+		case token.FINAL_FOR:
+			addForScope(vis, scope, statement, loops)
+		case token.FINAL_IF, token.FINAL_WHILE:
+			for _, child := range statement.Children {
+				if child.Type == token.FINAL_STATBLOCK {
+					addForScopes(vis, scope, child.Children, loops)
+				}
+			}
+		}
+	}
+}
+
+func addForScope(vis *SymTabVisitor, scope, node *token.ASTNode, loops *int) {
+	*loops++
+	name := FOR_SCOPE_NAME
+	if *loops > 1 {
+		name += "#" + strconv.Itoa(*loops)
+	}
+	node.Meta.SymbolTable = newSymbolTable(name, node, scope.Meta.SymbolTable)
+	scope.Meta.SymbolTable.Insert(token.SymbolTableRecord{
+		Name:   name,
+		Kind:   token.FINAL_FOR,
+		Link:   node.Meta.SymbolTable,
+		Parent: scope.Meta.SymbolTable,
+	})
+
+	for _, decl := range node.Children[0].Children {
+		if decl.Meta.Record == nil || shadowsEnclosingScope(vis, node, *decl.Meta.Record) {
+			continue
+		}
+		addChild(vis, node, decl)
+	}
+	addForScopes(vis, node, node.Children[3].Children, loops)
+}
+
+// Loop variables may not shadow the variables of the enclosing loops or of the
+// function
+func shadowsEnclosingScope(vis *SymTabVisitor, node *token.ASTNode, add token.SymbolTableRecord) bool {
+	for table := node.Meta.SymbolTable.Parent(); table != nil; table = table.Parent() {
+		if r := alreadyExists(table, add); r != nil {
+			vis.logErr(&VisitorError{Wrap: &DuplicateIdentifierError{
+				Name:   add.Name,
+				First:  r.Type.Token,
+				Second: add.Type.Token,
+			}})
+			return true
+		}
+		if !isForScope(table) {
+			return false
+		}
+	}
+	return false
+}
+
+// Returns true if the table is the scope of a for loop
+func isForScope(table token.SymbolTable) bool {
+	t, ok := table.(*NodeAwareSymbolTable)
+	return ok && t.node != nil && t.node.Type == token.FINAL_FOR
+}
+
+// Returns the table of the function that a scope belongs to, skipping over the
+// scopes of for loops
+func functionScope(table token.SymbolTable) token.SymbolTable {
+	for isForScope(table) {
+		table = table.Parent()
+	}
+	return table
+}
+
 func attachVarDecl(vis *SymTabVisitor, table token.SymbolTable) {
 	fmt.Println()
 }
@@ -774,16 +862,11 @@ func warnOverloads(vis *SymTabVisitor, table token.SymbolTable) {
 		if l < 2 {
 			continue
 		}
-		out := make([]string, 0, l)
-		for _, overload := range v {
-			out = append(out, formatMethodId(overload))
-		}
-		outt := strings.Join(out, ", ")
-		vis.logErr(&VisitorError{Wrap: &Warning{
-			Msg: fmt.Sprintf(
-				"'%v::%v' has been overloaded %v times: %v",
-				table.Id(), k, l, outt),
-		}})
+		vis.logErr(&VisitorError{Wrap: &Warning{Wrap: &OverloadedError{
+			Scope:     table.Id(),
+			Name:      k,
+			Overloads: v,
+		}}})
 	}
 }
 
@@ -794,14 +877,36 @@ func attachInherited(vis *SymTabVisitor, prog *token.ASTNode) {
 		inherits := inherits(structt)
 		inheritNodes := collectInherited(structs, inherits...)
 		for _, node := range inheritNodes {
-			if node != nil && node.Meta.SymbolTable != nil {
-				structt.Meta.SymbolTable.AddInherited(node.Meta.SymbolTable)
+			if node == nil || node.Meta.SymbolTable == nil {
+				continue
+			}
+			if inheritsFrom(node.Meta.SymbolTable, structt.Meta.SymbolTable) {
+				vis.logErr(&VisitorError{Wrap: &CircularInheritanceError{
+					Struct:   structt,
+					Inherits: id(node),
+				}})
+				continue
 			}
+			structt.Meta.SymbolTable.AddInherited(node.Meta.SymbolTable)
 		}
 		emitShadowedWarnings(vis, structt)
 	}
 }
 
+// Returns true if table is target, or if table inherits from target either
+// directly or through one of its ancestors
+func inheritsFrom(table, target token.SymbolTable) bool {
+	if table == target {
+		return true
+	}
+	for _, inherited := range table.Inherited() {
+		if inheritsFrom(inherited, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // Discovers and warns about any shadowed struct members
 func emitShadowedWarnings(vis *SymTabVisitor, node *token.ASTNode) {
 	if node.Meta.SymbolTable == nil {
@@ -824,21 +929,6 @@ func emitShadowedWarnings(vis *SymTabVisitor, node *token.ASTNode) {
 		return membersSet
 	}
 
-	membersOut := func(name string, members []token.SymbolTableRecord) string {
-		parentOut := make([]string, 0, len(members))
-		for _, member := range members {
-			memberOut := member.Name
-			if member.Kind == token.FINAL_FUNC_DEF ||
-				member.Kind == token.FINAL_FUNC_DECL {
-				memberOut = formatMethodId(member)
-			}
-
-			parentOut = append(parentOut,
-				fmt.Sprintf("'%v::%v'", name, memberOut))
-		}
-		return strings.Join(parentOut, ", ")
-	}
-
 	membersSet := createMembersSet(node.Meta.SymbolTable.Entries())
 
 	var recurse func(root token.SymbolTable)
@@ -852,13 +942,12 @@ func emitShadowedWarnings(vis *SymTabVisitor, node *token.ASTNode) {
 		parentMembersSet := createMembersSet(root.Entries())
 		for name, parentMembers := range parentMembersSet {
 			if shadows, ok := membersSet[name]; ok {
-				parentOutt := membersOut(root.Id(), parentMembers)
-				childOutt := membersOut(node.Meta.SymbolTable.Id(), shadows)
-				vis.logErr(&VisitorError{Wrap: &Warning{
-					Msg: fmt.Sprintf(
-						"shadowing: parent member(s) %v shadowed by %v",
-						parentOutt, childOutt),
-				}})
+				vis.logErr(&VisitorError{Wrap: &Warning{Wrap: &ShadowedMemberError{
+					Parent:        root.Id(),
+					Child:         node.Meta.SymbolTable.Id(),
+					ParentMembers: parentMembers,
+					ChildMembers:  shadows,
+				}}})
 
 				// Once we have reported the error, let's remove it from the
 				// membersSet
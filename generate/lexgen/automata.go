@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stands for every character that the spec does not mention explicitly
+const OTHER rune = -2
+
+// The rule that a state accepts, rules that are listed first in the spec have
+// precedence. A state that accepts nothing has a negative rule
+type accept struct {
+	rule int
+	kind string
+}
+
+var noAccept = accept{rule: -1}
+
+func (a accept) ok() bool {
+	return a.rule >= 0
+}
+
+// A nondeterministic finite automaton, built with Thompson's construction
+type nfa struct {
+	states []nstate
+}
+
+type nstate struct {
+	eps    []int
+	edges  []nedge
+	accept accept
+}
+
+type nedge struct {
+	set charset
+	to  int
+}
+
+// A piece of an NFA with a single entry and a single exit
+type fragment struct {
+	start, end int
+}
+
+func (n *nfa) state() int {
+	n.states = append(n.states, nstate{accept: noAccept})
+	return len(n.states) - 1
+}
+
+func (n *nfa) epsilon(from, to int) {
+	n.states[from].eps = append(n.states[from].eps, to)
+}
+
+func (n *nfa) symbol(set charset) fragment {
+	f := fragment{n.state(), n.state()}
+	n.states[f.start].edges = append(n.states[f.start].edges, nedge{set, f.end})
+	return f
+}
+
+func (n *nfa) concat(frags ...fragment) fragment {
+	for i := 1; i < len(frags); i++ {
+		n.epsilon(frags[i-1].end, frags[i].start)
+	}
+	return fragment{frags[0].start, frags[len(frags)-1].end}
+}
+
+func (n *nfa) union(frags ...fragment) fragment {
+	if len(frags) == 1 {
+		return frags[0]
+	}
+	f := fragment{n.state(), n.state()}
+	for _, frag := range frags {
+		n.epsilon(f.start, frag.start)
+		n.epsilon(frag.end, f.end)
+	}
+	return f
+}
+
+func (n *nfa) star(frag fragment) fragment {
+	f := n.optional(frag)
+	n.epsilon(frag.end, frag.start)
+	return f
+}
+
+func (n *nfa) plus(frag fragment) fragment {
+	f := fragment{n.state(), n.state()}
+	n.epsilon(f.start, frag.start)
+	n.epsilon(frag.end, f.end)
+	n.epsilon(frag.end, frag.start)
+	return f
+}
+
+func (n *nfa) optional(frag fragment) fragment {
+	f := fragment{n.state(), n.state()}
+	n.epsilon(f.start, frag.start)
+	n.epsilon(f.start, f.end)
+	n.epsilon(frag.end, f.end)
+	return f
+}
+
+// The characters that are mentioned by the edges of the NFA, in order. OTHER
+// comes first, it stands for the rest of the characters
+func (n *nfa) alphabet() []rune {
+	seen := map[rune]struct{}{}
+	for _, s := range n.states {
+		for _, e := range s.edges {
+			for c := range e.set.chars {
+				seen[c] = struct{}{}
+			}
+		}
+	}
+	alphabet := []rune{OTHER}
+	for c := range seen {
+		alphabet = append(alphabet, c)
+	}
+	sort.Slice(alphabet, func(i, j int) bool { return alphabet[i] < alphabet[j] })
+	return alphabet
+}
+
+func (n *nfa) closure(states []int) []int {
+	seen := make(map[int]struct{}, len(states))
+	stack := append([]int(nil), states...)
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		stack = append(stack, n.states[s].eps...)
+	}
+	closure := make([]int, 0, len(seen))
+	for s := range seen {
+		closure = append(closure, s)
+	}
+	sort.Ints(closure)
+	return closure
+}
+
+func (n *nfa) move(states []int, symbol rune) []int {
+	var moved []int
+	for _, s := range states {
+		for _, e := range n.states[s].edges {
+			if e.set.matches(symbol) {
+				moved = append(moved, e.to)
+			}
+		}
+	}
+	return moved
+}
+
+// A deterministic finite automaton. Its transitions are partial, a missing
+// transition leads to the dead state. State 0 is the start state
+type dfa struct {
+	alphabet    []rune
+	transitions []map[rune]int
+	accepts     []accept
+}
+
+// Converts the NFA into a DFA with the subset construction
+func (n *nfa) determinize(start int) *dfa {
+	d := &dfa{alphabet: n.alphabet()}
+	ids := map[string]int{}
+	var sets [][]int
+
+	add := func(set []int) int {
+		key := fmt.Sprint(set)
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		id := len(sets)
+		ids[key] = id
+		sets = append(sets, set)
+
+		a := noAccept
+		for _, s := range set {
+			if sa := n.states[s].accept; sa.ok() && (!a.ok() || sa.rule < a.rule) {
+				a = sa
+			}
+		}
+		d.accepts = append(d.accepts, a)
+		d.transitions = append(d.transitions, map[rune]int{})
+		return id
+	}
+
+	add(n.closure([]int{start}))
+	for i := 0; i < len(sets); i++ {
+		for _, symbol := range d.alphabet {
+			moved := n.move(sets[i], symbol)
+			if len(moved) == 0 {
+				continue
+			}
+			d.transitions[i][symbol] = add(n.closure(moved))
+		}
+	}
+	return d
+}
+
+// Minimizes the DFA by refining the partition of its states until the states
+// in each block can no longer be told apart (Moore's algorithm). States are
+// only equivalent if they accept the same kind of token
+func (d *dfa) minimize() *dfa {
+	block := make([]int, len(d.accepts))
+	blocks := map[string]int{}
+	for s, a := range d.accepts {
+		key := a.kind
+		if !a.ok() {
+			key = ""
+		}
+		if _, ok := blocks[key]; !ok {
+			blocks[key] = len(blocks)
+		}
+		block[s] = blocks[key]
+	}
+
+	for count := len(blocks); ; {
+		next := make([]int, len(block))
+		signatures := map[string]int{}
+		for s := range block {
+			sig := new(strings.Builder)
+			fmt.Fprint(sig, block[s])
+			for _, symbol := range d.alphabet {
+				to, ok := d.transitions[s][symbol]
+				if ok {
+					to = block[to]
+				} else {
+					to = -1
+				}
+				fmt.Fprintf(sig, ",%v", to)
+			}
+			if _, ok := signatures[sig.String()]; !ok {
+				signatures[sig.String()] = len(signatures)
+			}
+			next[s] = signatures[sig.String()]
+		}
+		block = next
+		if len(signatures) == count {
+			break
+		}
+		count = len(signatures)
+	}
+
+	// Renumber the blocks so that the start state stays state 0
+	renumber := map[int]int{block[0]: 0}
+	for _, b := range block {
+		if _, ok := renumber[b]; !ok {
+			renumber[b] = len(renumber)
+		}
+	}
+
+	minimal := &dfa{
+		alphabet:    d.alphabet,
+		transitions: make([]map[rune]int, len(renumber)),
+		accepts:     make([]accept, len(renumber)),
+	}
+	for s, b := range block {
+		b = renumber[b]
+		if minimal.transitions[b] != nil {
+			continue
+		}
+		minimal.accepts[b] = d.accepts[s]
+		minimal.transitions[b] = make(map[rune]int, len(d.transitions[s]))
+		for symbol, to := range d.transitions[s] {
+			minimal.transitions[b][symbol] = renumber[block[to]]
+		}
+	}
+	return minimal
+}
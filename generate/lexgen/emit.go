@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const HEADER = `package %v
+
+//
+// CODEGEN - DO NOT MODIFY
+//
+// TOOL: generate/lexgen
+// SPEC: %v
+//
+// This file was generated by a tool, it should not be modified by hand. Instead,
+// modify the spec file listed above and rerun the codegen tool.
+//
+
+import (
+	t "github.com/obonobo/esac/core/tabledrivenscanner"
+	"github.com/obonobo/esac/core/token"
+)
+
+`
+
+// Writes the table as a Go file that declares TABLE(), a factory function for
+// the CompositeTable
+func Emit(w io.Writer, table *Table, pkg, specfile string) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, HEADER, pkg, specfile)
+	fmt.Fprintln(buf, "// A factory function for creating the default implementation table that is used")
+	fmt.Fprintln(buf, "// by the ESAC compiler")
+	fmt.Fprintln(buf, "func TABLE() *CompositeTable {")
+	fmt.Fprintln(buf, "return &CompositeTable{")
+	fmt.Fprintln(buf, "Start: t.START,")
+	fmt.Fprintln(buf, "UnterminatedComment: t.UNTERMINATEDCOMMENT,")
+	fmt.Fprintln(buf)
+
+	emitTransitions(buf, "Transitions", table.Transitions)
+	emitTransitions(buf, "StackTransitions", table.StackTransitions)
+
+	fmt.Fprintln(buf, "Tokens: map[t.State]token.Kind{")
+	states := make([]int, 0, len(table.Tokens))
+	for s := range table.Tokens {
+		states = append(states, s)
+	}
+	sort.Ints(states)
+	for _, s := range states {
+		fmt.Fprintf(buf, "%v: token.%v,\n", s, table.Tokens[s])
+	}
+	fmt.Fprintln(buf, "t.UNTERMINATEDCOMMENT: token.UNTERMINATEDCOMMENT,")
+	fmt.Fprintln(buf, "},")
+	fmt.Fprintln(buf)
+
+	fmt.Fprintln(buf, "Comments: map[token.Kind]t.State{")
+	kinds := make([]string, 0, len(table.Comments))
+	for k := range table.Comments {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Fprintf(buf, "token.%v: %v,\n", k, table.Comments[k])
+	}
+	fmt.Fprintln(buf, "},")
+	fmt.Fprintln(buf)
+
+	emitStates(buf, "NeedBackup", table.NeedBackup)
+	emitStates(buf, "NeedDoubleBackup", table.NeedDoubleBackup)
+	emitStates(buf, "PopStates", table.PopStates)
+	emitStates(buf, "PushStates", table.PushStates)
+	emitChars(buf, "Letters", table.Letters)
+	emitChars(buf, "Whitespace", table.Whitespace)
+
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// The transitions of each state are grouped together
+func emitTransitions(w io.Writer, name string, ts []Transition) {
+	fmt.Fprintf(w, "%v: map[Key]t.State{\n", name)
+	for i, tr := range ts {
+		if i > 0 && ts[i-1].From != tr.From {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "{%v, %v}: %v,\n", tr.From, symbol(tr.Symbol), tr.To)
+	}
+	fmt.Fprintln(w, "},")
+	fmt.Fprintln(w)
+}
+
+func emitStates(w io.Writer, name string, states []int) {
+	fmt.Fprintf(w, "%v: map[t.State]struct{}{", name)
+	for i, s := range states {
+		if i > 0 {
+			fmt.Fprint(w, ", ")
+		}
+		fmt.Fprintf(w, "%v: {}", s)
+	}
+	fmt.Fprintln(w, "},")
+}
+
+func emitChars(w io.Writer, name string, chars []rune) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%v: map[rune]struct{}{\n", name)
+	for _, c := range chars {
+		fmt.Fprintf(w, "%v: {},\n", symbol(c))
+	}
+	fmt.Fprintln(w, "},")
+}
+
+func symbol(s rune) string {
+	switch s {
+	case OTHER:
+		return "t.ANY"
+	case LETTER:
+		return "t.LETTER"
+	case EOF:
+		return "t.EOF"
+	}
+	return strconv.QuoteRune(s)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedTableIsUpToDate(t *testing.T) {
+	spec, err := os.Open("../lexical.lex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spec.Close()
+
+	table, err := Generate(spec)
+	if err != nil {
+		t.Fatalf("Failed to generate the table: %v", err)
+	}
+	generated := new(bytes.Buffer)
+	if err := Emit(generated, table, "compositetable", "../../../generate/lexical.lex"); err != nil {
+		t.Fatalf("Failed to emit the table: %v", err)
+	}
+
+	committed, err := os.ReadFile("../../core/tabledrivenscanner/compositetable/gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated.Bytes(), committed) {
+		t.Errorf("The scanner table is out of date with the lexical spec, run `make grammar`")
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		spec   string
+		states int
+	}{
+		{
+			name:   "textbook",
+			spec:   "A (a|b)* a b b",
+			states: 4,
+		},
+		{
+			name:   "equivalent suffixes",
+			spec:   "A x y z | a y z",
+			states: 4,
+		},
+		{
+			name:   "kinds are never merged",
+			spec:   "A a\nB b",
+			states: 3,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := ParseSpec(strings.NewReader(tc.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, start, err := spec.NFA()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := len(n.determinize(start).minimize().accepts); actual != tc.states {
+				t.Errorf("Expected %v states but got %v", tc.states, actual)
+			}
+		})
+	}
+}
+
+func TestBuildTable(t *testing.T) {
+	table, err := Generate(strings.NewReader(strings.Join([]string{
+		`%whitespace [ ]`,
+		`%letters    [a-z]`,
+		`%block      "(*" "*)"`,
+		`%define     digit [0-9]`,
+		`FLOATNUM    {digit}+ (\. {digit}+)?`,
+		`ID          [a-z]+`,
+		`OPENPAR     "("`,
+		`INVALIDCHAR .`,
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// '1.' followed by anything but a digit backs up to '1'
+	if len(table.NeedDoubleBackup) != 1 || table.Tokens[table.NeedDoubleBackup[0]] != "FLOATNUM" {
+		t.Errorf("Expected a single FLOATNUM state with double backup but got %v", table.NeedDoubleBackup)
+	}
+
+	// The letters of an ID are grouped
+	found := false
+	for _, tr := range table.Transitions {
+		if tr.Symbol == LETTER {
+			found = true
+		} else if tr.Symbol >= 'a' && tr.Symbol <= 'z' {
+			t.Errorf("Expected letters to be grouped but got %+v", tr)
+		}
+	}
+	if !found {
+		t.Errorf("Expected a LETTER transition")
+	}
+
+	if _, ok := table.Comments[BLOCKCMT]; !ok || len(table.StackTransitions) == 0 {
+		t.Errorf("Expected block comment transitions but got %+v", table.StackTransitions)
+	}
+}
+
+func TestBuildTable_EOF(t *testing.T) {
+	table, err := Generate(strings.NewReader(strings.Join([]string{
+		`%whitespace [ ]`,
+		`STRINGLIT   \" [^"\n]* \"`,
+		`UNTERMINATEDSTRING \" [^"\n]*`,
+		`ID          [a-z]+`,
+		`INVALIDCHAR .`,
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only an unterminated string would keep going at the end of the input,
+	// an ID stops at any character that is not a letter
+	var eof []Transition
+	for _, tr := range table.Transitions {
+		if tr.Symbol == EOF {
+			eof = append(eof, tr)
+		}
+	}
+	if len(eof) != 1 || table.Tokens[eof[0].To] != "UNTERMINATEDSTRING" {
+		t.Errorf("Expected a single EOF transition to UNTERMINATEDSTRING but got %+v", eof)
+	}
+}
+
+func TestBuildTable_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{
+			name: "too much backup",
+			spec: "A a\nB a b c d\nX .",
+			err:  "'abc' followed by",
+		},
+		{
+			name: "unmatched characters",
+			spec: "A a",
+			err:  "no rule matches",
+		},
+		{
+			name: "undefined name",
+			spec: "A {nope}",
+			err:  "undefined name 'nope'",
+		},
+		{
+			name: "bad directive",
+			spec: "%nope\nA a",
+			err:  "line 1: unknown directive '%nope'",
+		},
+		{
+			name: "extended block opener",
+			spec: "%block \"/*\" \"*/\"\nA \"/**\"\nX .",
+			err:  "is the prefix of another token",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Generate(strings.NewReader(tc.spec))
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected an error containing %q but got %v", tc.err, err)
+			}
+		})
+	}
+}
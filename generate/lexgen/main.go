@@ -0,0 +1,125 @@
+// *****************************************************************************
+// LEXER GENERATOR
+//
+// NOTE: you should let `go generate` run this tool rather than running it
+// manually. Check out `../../Makefile` for more information on how to trigger a
+// rebuild of the scanner table.
+//
+// This tool consumes a lexical spec (regular expressions for each token kind)
+// and produces the CompositeTable that drives the TableDrivenScanner. The
+// rules are compiled into an NFA, which is turned into a DFA with the subset
+// construction and then minimized.
+//
+// Run like so: go run ./generate/lexgen [flags] <spec file>
+//
+// If no <spec file> is specified, reads from stdin.
+//
+// Flags:
+//  --out, -o
+//      Output file for generated code, defaults to stdout
+//
+//  --package, -p
+//      Package of the generated code, defaults to compositetable
+//
+// See `spec.go` for the format of the spec, and `../lexical.lex` for the spec
+// of the ESAC language.
+// *****************************************************************************
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+var USAGE = strings.TrimLeft(`
+Usage: %v [flags] <spec file>
+
+%v compiles a lexical spec into the CompositeTable of the table driven
+scanner. Each rule of the spec is a regular expression for a token kind, the
+rules are compiled into a minimal DFA which is written out as Go code.
+
+If no <spec file> is specified, reads from stdin.
+
+Flags:
+	--out, -o
+		Output file for generated code, defaults to stdout
+
+	--package, -p
+		Package of the generated code, defaults to compositetable
+`, "\n\r\t ")
+
+func main() {
+	config := struct {
+		out, pkg   string
+		file, prog string
+	}{}
+
+	config.prog = path.Base(os.Args[0])
+	flag.Usage = func() {
+		fmt.Printf(
+			USAGE, config.prog,
+			strings.ToUpper(string(config.prog[0]))+config.prog[1:])
+	}
+
+	flag.StringVar(&config.out, "out", "", "")
+	flag.StringVar(&config.out, "o", "", "")
+	flag.StringVar(&config.pkg, "package", "compositetable", "")
+	flag.StringVar(&config.pkg, "p", "compositetable", "")
+	flag.Parse()
+
+	var in io.Reader = os.Stdin
+	config.file = flag.Arg(0)
+	if config.file == "" {
+		fmt.Fprintln(os.Stderr, "No file provided, reading from stdin...")
+		config.file = "<stdin>"
+	} else {
+		fh, err := os.Open(config.file)
+		if err != nil {
+			exit(fmt.Errorf("failed to open file: %w", err))
+		}
+		defer fh.Close()
+		in = fh
+	}
+
+	table, err := Generate(in)
+	if err != nil {
+		exit(fmt.Errorf("%v: %w", config.file, err))
+	}
+
+	var out io.Writer = os.Stdout
+	if config.out != "" && config.out != "-" {
+		fh, err := os.Create(config.out)
+		if err != nil {
+			exit(fmt.Errorf("failed to open output file ('%v'): %w", config.out, err))
+		}
+		defer fh.Close()
+		out = fh
+	}
+
+	if err := Emit(out, table, config.pkg, config.file); err != nil {
+		exit(err)
+	}
+}
+
+// Compiles the spec into a table
+func Generate(spec io.Reader) (*Table, error) {
+	s, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	n, start, err := s.NFA()
+	if err != nil {
+		return nil, err
+	}
+	return BuildTable(s, n.determinize(start).minimize())
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
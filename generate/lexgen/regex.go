@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A set of characters labelling an edge of the NFA. The alphabet of a spec is
+// only known once all of its expressions have been parsed, so a negated set
+// stays negated: it matches every character that it does not list, including
+// the characters that the spec never mentions
+type charset struct {
+	chars   map[rune]struct{}
+	negated bool
+}
+
+func newCharset(chars ...rune) charset {
+	set := charset{chars: make(map[rune]struct{}, len(chars))}
+	for _, c := range chars {
+		set.chars[c] = struct{}{}
+	}
+	return set
+}
+
+// Reports whether the set matches the symbol, OTHER stands for every character
+// that is not part of the alphabet
+func (c charset) matches(symbol rune) bool {
+	if symbol == OTHER {
+		return c.negated
+	}
+	_, ok := c.chars[symbol]
+	return ok != c.negated
+}
+
+// Parses regular expressions straight into fragments of an NFA (Thompson's
+// construction). The syntax is:
+//
+//	a       the character 'a'
+//	\n      escapes: \n, \t, \r, \xHH, anything else stands for itself
+//	"->"    a quoted literal
+//	[a-z_]  a character class, [^...] is a negated class
+//	.       any character
+//	{name}  the expression of a %define
+//	ab a|b  concatenation and alternation
+//	a* a+ a?
+//	(a|b)   grouping
+//
+// Unescaped blanks are insignificant, write "\ " or [ ] for a space
+type regexParser struct {
+	nfa     *nfa
+	defines map[string]string
+	src     []rune
+	pos     int
+	depth   int // Guards against definitions that refer to themselves
+}
+
+func parseRegex(n *nfa, defines map[string]string, src string) (fragment, error) {
+	p := &regexParser{nfa: n, defines: defines, src: []rune(src)}
+	return p.parse()
+}
+
+func (p *regexParser) parse() (frag fragment, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(regexError)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+	frag = p.alternation()
+	if p.skipBlanks(); p.pos < len(p.src) {
+		p.fail("unexpected '%c'", p.src[p.pos])
+	}
+	return frag, nil
+}
+
+type regexError struct {
+	src string
+	pos int
+	msg string
+}
+
+func (e regexError) Error() string {
+	return fmt.Sprintf("%v, at column %v of '%v'", e.msg, e.pos+1, e.src)
+}
+
+func (p *regexParser) fail(format string, args ...any) {
+	panic(regexError{string(p.src), p.pos, fmt.Sprintf(format, args...)})
+}
+
+func (p *regexParser) skipBlanks() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *regexParser) peek() (rune, bool) {
+	p.skipBlanks()
+	if p.pos < len(p.src) {
+		return p.src[p.pos], true
+	}
+	return 0, false
+}
+
+func (p *regexParser) next() rune {
+	if p.pos >= len(p.src) {
+		p.fail("unexpected end of expression")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	return c
+}
+
+func (p *regexParser) expect(c rune) {
+	if actual := p.next(); actual != c {
+		p.fail("expected '%c' but got '%c'", c, actual)
+	}
+}
+
+func (p *regexParser) alternation() fragment {
+	frags := []fragment{p.concatenation()}
+	for {
+		if c, ok := p.peek(); !ok || c != '|' {
+			break
+		}
+		p.next()
+		frags = append(frags, p.concatenation())
+	}
+	return p.nfa.union(frags...)
+}
+
+func (p *regexParser) concatenation() fragment {
+	var frags []fragment
+	for {
+		c, ok := p.peek()
+		if !ok || c == '|' || c == ')' {
+			break
+		}
+		frags = append(frags, p.repetition())
+	}
+	if len(frags) == 0 {
+		p.fail("empty expression")
+	}
+	return p.nfa.concat(frags...)
+}
+
+func (p *regexParser) repetition() fragment {
+	frag := p.atom()
+	for {
+		c, ok := p.peek()
+		if !ok {
+			return frag
+		}
+		switch c {
+		case '*':
+			frag = p.nfa.star(frag)
+		case '+':
+			frag = p.nfa.plus(frag)
+		case '?':
+			frag = p.nfa.optional(frag)
+		default:
+			return frag
+		}
+		p.next()
+	}
+}
+
+func (p *regexParser) atom() fragment {
+	p.skipBlanks()
+	switch c := p.next(); c {
+	case '(':
+		frag := p.alternation()
+		p.skipBlanks()
+		p.expect(')')
+		return frag
+	case '[':
+		return p.nfa.symbol(p.class())
+	case '.':
+		return p.nfa.symbol(charset{negated: true})
+	case '"':
+		return p.literal()
+	case '{':
+		return p.reference()
+	case '\\':
+		return p.nfa.symbol(newCharset(p.escape()))
+	case '*', '+', '?', '|', ')', ']', '}':
+		p.pos--
+		p.fail("unexpected '%c'", c)
+	default:
+		return p.nfa.symbol(newCharset(c))
+	}
+	panic("unreachable")
+}
+
+// Parses the rest of a character class, after the opening '['
+func (p *regexParser) class() charset {
+	set := newCharset()
+	if p.pos < len(p.src) && p.src[p.pos] == '^' {
+		set.negated = true
+		p.pos++
+	}
+	first := true
+	for {
+		c := p.next()
+		if c == ']' && !first {
+			return set
+		}
+		first = false
+		if c == '\\' {
+			c = p.escape()
+		}
+
+		// A '-' is a range, unless it is the last character of the class
+		if p.pos+1 < len(p.src) && p.src[p.pos] == '-' && p.src[p.pos+1] != ']' {
+			p.pos++
+			hi := p.next()
+			if hi == '\\' {
+				hi = p.escape()
+			}
+			if hi < c {
+				p.fail("invalid range '%c-%c'", c, hi)
+			}
+			for r := c; r <= hi; r++ {
+				set.chars[r] = struct{}{}
+			}
+			continue
+		}
+		set.chars[c] = struct{}{}
+	}
+}
+
+// Parses the rest of a quoted literal, after the opening '"'
+func (p *regexParser) literal() fragment {
+	var frags []fragment
+	for {
+		c := p.next()
+		if c == '"' {
+			break
+		}
+		if c == '\\' {
+			c = p.escape()
+		}
+		frags = append(frags, p.nfa.symbol(newCharset(c)))
+	}
+	if len(frags) == 0 {
+		p.fail("empty literal")
+	}
+	return p.nfa.concat(frags...)
+}
+
+// Parses the rest of a reference to a definition, after the opening '{'
+func (p *regexParser) reference() fragment {
+	end := p.pos
+	for end < len(p.src) && p.src[end] != '}' {
+		end++
+	}
+	if end == len(p.src) {
+		p.fail("unterminated reference")
+	}
+	name := string(p.src[p.pos:end])
+	p.pos = end + 1
+
+	src, ok := p.defines[name]
+	if !ok {
+		p.fail("undefined name '%v'", name)
+	}
+	if p.depth > len(p.defines) {
+		p.fail("definition '%v' refers to itself", name)
+	}
+	sub := &regexParser{nfa: p.nfa, defines: p.defines, src: []rune(src), depth: p.depth + 1}
+	frag, err := sub.parse()
+	if err != nil {
+		panic(err)
+	}
+	return frag
+}
+
+// Parses the rest of an escape sequence, after the '\'
+func (p *regexParser) escape() rune {
+	switch c := p.next(); c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case 'x':
+		if p.pos+2 > len(p.src) {
+			p.fail("invalid escape sequence")
+		}
+		hex := string(p.src[p.pos : p.pos+2])
+		p.pos += 2
+		r, err := strconv.ParseUint(hex, 16, 8)
+		if err != nil {
+			p.fail("invalid escape sequence '\\x%v'", hex)
+		}
+		return rune(r)
+	default:
+		return c
+	}
+}
+
+// Parses an expression that must be a single character class, like the ones
+// given to %whitespace and %letters
+func parseCharset(src string) (charset, error) {
+	n := new(nfa)
+	frag, err := parseRegex(n, nil, src)
+	if err != nil {
+		return charset{}, err
+	}
+	edges := n.states[frag.start].edges
+	if len(edges) != 1 || edges[0].to != frag.end || edges[0].set.negated {
+		return charset{}, fmt.Errorf("'%v' should be a character class", strings.TrimSpace(src))
+	}
+	return edges[0].set, nil
+}
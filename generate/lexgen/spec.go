@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The token kinds that delimit block comments. These are the kinds that the
+// CompositeTable knows how to match against each other on its comment stack
+const (
+	OPENBLOCK  = "OPENBLOCK"
+	CLOSEBLOCK = "CLOSEBLOCK"
+	BLOCKCMT   = "BLOCKCMT"
+)
+
+// A lexical spec. Each line of the spec file is one of:
+//
+//	# A comment
+//	%whitespace <class>        Characters skipped between tokens
+//	%letters    <class>        Characters that the table may group as LETTER
+//	%block      "<open>" "<close>"
+//	                           Delimiters of (nestable) block comments
+//	%define     <name> <regex> A named expression, used as {name}
+//	<KIND>      <regex>        A token, KIND is a constant of package token
+//
+// When more than one rule matches the longest lexeme, the rule that comes first
+// wins
+type Spec struct {
+	Whitespace charset
+	Letters    charset
+	Block      *Block
+	Defines    map[string]string
+	Rules      []Rule
+}
+
+type Block struct {
+	Open, Close string
+}
+
+type Rule struct {
+	Kind  string
+	Regex string
+	Line  int
+}
+
+var (
+	directiveRegex = regexp.MustCompile(`^%(\w+)\s*(.*)$`)
+	ruleRegex      = regexp.MustCompile(`^([A-Z][A-Z0-9_]*)\s+(.+)$`)
+	defineRegex    = regexp.MustCompile(`^(\w+)\s+(.+)$`)
+)
+
+func ParseSpec(r io.Reader) (*Spec, error) {
+	spec := &Spec{Defines: map[string]string{}}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if err := spec.parseLine(text, line); err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(spec.Rules) == 0 {
+		return nil, fmt.Errorf("spec has no rules")
+	}
+	return spec, nil
+}
+
+func (s *Spec) parseLine(text string, line int) error {
+	if m := ruleRegex.FindStringSubmatch(text); m != nil {
+		s.Rules = append(s.Rules, Rule{Kind: m[1], Regex: m[2], Line: line})
+		return nil
+	}
+
+	m := directiveRegex.FindStringSubmatch(text)
+	if m == nil {
+		return fmt.Errorf("expected a rule or a directive but got '%v'", text)
+	}
+	directive, args := m[1], m[2]
+
+	var err error
+	switch directive {
+	case "whitespace":
+		s.Whitespace, err = parseCharset(args)
+	case "letters":
+		s.Letters, err = parseCharset(args)
+	case "block":
+		s.Block, err = parseBlock(args)
+	case "define":
+		m := defineRegex.FindStringSubmatch(args)
+		if m == nil {
+			return fmt.Errorf("expected '%%define <name> <regex>' but got '%v'", text)
+		}
+		if _, ok := s.Defines[m[1]]; ok {
+			return fmt.Errorf("'%v' is defined twice", m[1])
+		}
+		s.Defines[m[1]] = m[2]
+	default:
+		return fmt.Errorf("unknown directive '%%%v'", directive)
+	}
+	return err
+}
+
+func parseBlock(args string) (*Block, error) {
+	var delims []string
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		quoted, err := strconv.QuotedPrefix(args)
+		if err != nil {
+			return nil, fmt.Errorf("expected a quoted delimiter but got '%v'", args)
+		}
+		delim, _ := strconv.Unquote(quoted)
+		delims = append(delims, delim)
+		args = args[len(quoted):]
+	}
+	if len(delims) != 2 || delims[0] == "" || delims[1] == "" {
+		return nil, fmt.Errorf(`expected '%%block "<open>" "<close>"'`)
+	}
+	return &Block{Open: delims[0], Close: delims[1]}, nil
+}
+
+// Builds the NFA that recognizes every rule of the spec. The opening delimiter
+// of block comments is an OPENBLOCK token that takes precedence over all of
+// the other rules
+func (s *Spec) NFA() (n *nfa, start int, err error) {
+	n = new(nfa)
+	start = n.state()
+
+	rules := s.Rules
+	if s.Block != nil {
+		open := Rule{Kind: OPENBLOCK, Regex: strconv.Quote(s.Block.Open)}
+		rules = append([]Rule{open}, rules...)
+	}
+
+	for i, rule := range rules {
+		frag, err := parseRegex(n, s.Defines, rule.Regex)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %v: %v: %w", rule.Line, rule.Kind, err)
+		}
+		n.epsilon(start, frag.start)
+		n.states[frag.end].accept = accept{rule: i, kind: rule.Kind}
+	}
+
+	// The whitespace and the letters are part of the alphabet, even if no rule
+	// mentions them
+	for _, set := range []charset{s.Whitespace, s.Letters} {
+		if len(set.chars) > 0 {
+			n.symbol(set)
+		}
+	}
+	return n, start, nil
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Stands for the characters of the %letters class, the table checks it after
+// the character itself and before OTHER
+const LETTER rune = -1
+
+// The end of the input, the table only needs it for tokens that can end where
+// the input does but would otherwise take the OTHER transition (e.g. '"abc'
+// at the end of the input is an unterminated string)
+const EOF rune = -3
+
+// The first state of the table, states are numbered from here
+const START = 1
+
+// The states and transitions of a CompositeTable.
+//
+// The scanner that drives the table only ever looks one character ahead, and
+// can back up at most two characters, so the DFA is laid out the same way
+// as the hand-written tables used to be:
+//
+//   - A state that accepts a token but can still be extended is not final.
+//     Every character that cannot extend the token leads to a final state
+//     that backs up once, so the lookahead is not part of the lexeme.
+//   - A state that accepts a token and cannot be extended is final.
+//   - A state that accepts nothing, and that is only ever entered from states
+//     that accept the same kind of token, leads to a final state that backs up
+//     twice on every character that cannot extend the lexeme (e.g. '1.5e'
+//     followed by something other than a digit is the float '1.5').
+//
+// Any other state that can get stuck is an error in the spec, the lexeme would
+// need more backing up than the scanner can do.
+type Table struct {
+	Transitions      []Transition
+	StackTransitions []Transition
+
+	Tokens   map[int]string
+	Comments map[string]int
+
+	NeedBackup       []int
+	NeedDoubleBackup []int
+	PopStates        []int
+	PushStates       []int
+
+	Letters    []rune
+	Whitespace []rune
+}
+
+type Transition struct {
+	From   int
+	Symbol rune // A character, OTHER, LETTER, or EOF
+	To     int
+}
+
+type tableBuilder struct {
+	spec  *Spec
+	dfa   *dfa
+	table *Table
+
+	trans   []map[rune]int // The transitions of the DFA, plus whitespace
+	number  map[int]int    // DFA state -> table state
+	order   []int          // DFA states, in the order that they are numbered
+	example map[int]string // The shortest lexeme that reaches a DFA state
+	preds   map[int][]int
+
+	next          int // The next free table state
+	backup        map[string]int
+	doubleBackup  map[string]int
+	openState     int
+	hasOpenState  bool
+	letterSymbols []rune
+}
+
+// Lays the minimized DFA of the spec out as a CompositeTable
+func BuildTable(spec *Spec, d *dfa) (*Table, error) {
+	b := &tableBuilder{
+		spec: spec,
+		dfa:  d,
+		table: &Table{
+			Tokens:     map[int]string{},
+			Comments:   map[string]int{},
+			Letters:    sortedChars(spec.Letters),
+			Whitespace: sortedChars(spec.Whitespace),
+		},
+		number:       map[int]int{},
+		example:      map[int]string{},
+		preds:        map[int][]int{},
+		backup:       map[string]int{},
+		doubleBackup: map[string]int{},
+	}
+
+	for _, c := range b.table.Letters {
+		if containsRune(d.alphabet, c) {
+			b.letterSymbols = append(b.letterSymbols, c)
+		}
+	}
+
+	b.addWhitespace()
+	b.numberStates()
+	for _, s := range b.order {
+		if err := b.layout(s); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.layoutBlockComments(); err != nil {
+		return nil, err
+	}
+
+	sortTransitions(b.table.Transitions)
+	sortTransitions(b.table.StackTransitions)
+	for _, states := range []*[]int{
+		&b.table.NeedBackup, &b.table.NeedDoubleBackup,
+		&b.table.PopStates, &b.table.PushStates,
+	} {
+		sort.Ints(*states)
+	}
+	return b.table, nil
+}
+
+// Whitespace loops on the start state, the scanner drops it from the lexeme
+func (b *tableBuilder) addWhitespace() {
+	b.trans = make([]map[rune]int, len(b.dfa.transitions))
+	for s, trans := range b.dfa.transitions {
+		b.trans[s] = make(map[rune]int, len(trans))
+		for symbol, to := range trans {
+			b.trans[s][symbol] = to
+		}
+	}
+	for _, c := range b.table.Whitespace {
+		b.trans[0][c] = 0
+	}
+}
+
+// Numbers the reachable states breadth first, remembering the shortest lexeme
+// that reaches each of them
+func (b *tableBuilder) numberStates() {
+	b.number[0] = START
+	b.order = []int{0}
+	for i := 0; i < len(b.order); i++ {
+		s := b.order[i]
+		for _, symbol := range b.dfa.alphabet {
+			to, ok := b.trans[s][symbol]
+			if !ok {
+				continue
+			}
+			b.preds[to] = append(b.preds[to], s)
+			if _, seen := b.number[to]; seen {
+				continue
+			}
+			b.number[to] = START + len(b.order)
+			b.order = append(b.order, to)
+			b.example[to] = b.example[s] + describe(symbol)
+		}
+	}
+	b.next = START + len(b.order)
+}
+
+func (b *tableBuilder) layout(s int) error {
+	n, a := b.number[s], b.dfa.accepts[s]
+	if a.ok() && len(b.trans[s]) == 0 {
+		b.table.Tokens[n] = a.kind
+		if a.kind == OPENBLOCK {
+			b.openState, b.hasOpenState = n, true
+			b.table.PushStates = append(b.table.PushStates, n)
+		}
+		return nil
+	}
+	if a.kind == OPENBLOCK {
+		return fmt.Errorf("the opening delimiter of block comments ('%v') is the prefix of another token", b.example[s])
+	}
+
+	dead, stuck := b.deadState(s)
+	targets := make(map[rune]int, len(b.dfa.alphabet))
+	for _, symbol := range b.dfa.alphabet {
+		if to, ok := b.trans[s][symbol]; ok {
+			targets[symbol] = b.number[to]
+			continue
+		}
+		if stuck != nil {
+			return stuck(symbol)
+		}
+		targets[symbol] = dead
+	}
+	b.addTransitions(n, targets)
+	if a.ok() && targets[OTHER] != dead {
+		b.table.Transitions = append(b.table.Transitions, Transition{n, EOF, dead})
+	}
+	return nil
+}
+
+// Picks the final state that is entered when a state cannot go any further. If
+// there is no such state, stuck reports the reason
+func (b *tableBuilder) deadState(s int) (dead int, stuck func(symbol rune) error) {
+	if a := b.dfa.accepts[s]; a.ok() {
+		return b.final(b.backup, &b.table.NeedBackup, a.kind), nil
+	}
+
+	kind := ""
+	for _, p := range b.preds[s] {
+		a := b.dfa.accepts[p]
+		if !a.ok() || (kind != "" && a.kind != kind) {
+			kind = ""
+			break
+		}
+		kind = a.kind
+	}
+	if kind != "" {
+		return b.final(b.doubleBackup, &b.table.NeedDoubleBackup, kind), nil
+	}
+
+	return 0, func(symbol rune) error {
+		if s == 0 {
+			return fmt.Errorf("no rule matches %v", describeSymbol(symbol))
+		}
+		return fmt.Errorf(
+			"'%v' followed by %v matches no rule, and the scanner cannot back up far enough to find the token that it ends with",
+			b.example[s], describeSymbol(symbol))
+	}
+}
+
+// Returns the final state of the kind that backs up once or twice, creating
+// it if needed
+func (b *tableBuilder) final(states map[string]int, backup *[]int, kind string) int {
+	if n, ok := states[kind]; ok {
+		return n
+	}
+	n := b.newState()
+	states[kind] = n
+	b.table.Tokens[n] = kind
+	*backup = append(*backup, n)
+	return n
+}
+
+func (b *tableBuilder) newState() int {
+	b.next++
+	return b.next - 1
+}
+
+// Adds the transitions of a state, leaving out the ones that the LETTER and
+// OTHER transitions already cover
+func (b *tableBuilder) addTransitions(from int, targets map[rune]int) {
+	fallback := targets[OTHER]
+	b.table.Transitions = append(b.table.Transitions, Transition{from, OTHER, fallback})
+
+	letters, ok := b.letterTarget(targets)
+	if ok && letters != fallback {
+		b.table.Transitions = append(b.table.Transitions, Transition{from, LETTER, letters})
+	}
+
+	for _, symbol := range b.dfa.alphabet {
+		if symbol == OTHER {
+			continue
+		}
+		def := fallback
+		if ok && containsRune(b.letterSymbols, symbol) {
+			def = letters
+		}
+		if to := targets[symbol]; to != def {
+			b.table.Transitions = append(b.table.Transitions, Transition{from, symbol, to})
+		}
+	}
+}
+
+// Letters that are not part of the alphabet go to the same state as OTHER, the
+// letters can only be grouped if all of them go to the same state
+func (b *tableBuilder) letterTarget(targets map[rune]int) (int, bool) {
+	if len(b.table.Letters) == 0 {
+		return 0, false
+	}
+	target, first := 0, true
+	for _, c := range b.table.Letters {
+		to, ok := targets[c]
+		if !ok {
+			to = targets[OTHER]
+		}
+		if !first && to != target {
+			return 0, false
+		}
+		target, first = to, false
+	}
+	return target, true
+}
+
+// Lays out the transitions that are taken inside of a block comment. They
+// find the delimiters of nested comments in any text, like the Aho-Corasick
+// automaton of the two delimiters. A character that is not part of either
+// delimiter goes back to the start state
+func (b *tableBuilder) layoutBlockComments() error {
+	block := b.spec.Block
+	if block == nil {
+		return nil
+	}
+	if !b.hasOpenState {
+		return fmt.Errorf("the opening delimiter of block comments is unreachable")
+	}
+	if block.Open == block.Close {
+		return fmt.Errorf("block comments need different delimiters")
+	}
+
+	open, close := []rune(block.Open), []rune(block.Close)
+	states := map[string]int{"": START, block.Open: b.openState}
+	var prefixes []string
+	for _, delim := range [][]rune{open, close} {
+		for i := 1; i < len(delim); i++ {
+			prefix := string(delim[:i])
+			if _, ok := states[prefix]; ok {
+				continue
+			}
+			prefixes = append(prefixes, prefix)
+			states[prefix] = b.newState()
+			if strings.HasPrefix(block.Close, prefix) {
+				b.table.PopStates = append(b.table.PopStates, states[prefix])
+			} else {
+				b.table.PushStates = append(b.table.PushStates, states[prefix])
+			}
+		}
+	}
+
+	closeState, comment := b.newState(), b.newState()
+	states[block.Close] = closeState
+	b.table.Tokens[closeState] = CLOSEBLOCK
+	b.table.Tokens[comment] = BLOCKCMT
+	b.table.Comments[BLOCKCMT] = comment
+	b.table.PopStates = append(b.table.PopStates, closeState)
+
+	var chars []rune
+	for _, c := range append(append([]rune(nil), open...), close...) {
+		if !containsRune(chars, c) {
+			chars = append(chars, c)
+		}
+	}
+
+	for _, prefix := range append([]string{""}, prefixes...) {
+		for _, c := range chars {
+			to := states[longestSuffix(append([]rune(prefix), c), states, block)]
+			if to != START {
+				b.table.StackTransitions = append(b.table.StackTransitions,
+					Transition{states[prefix], c, to})
+			}
+		}
+	}
+	return nil
+}
+
+// The longest suffix of the text that is a delimiter, or a prefix of one
+func longestSuffix(text []rune, states map[string]int, block *Block) string {
+	for _, delim := range []string{block.Close, block.Open} {
+		if strings.HasSuffix(string(text), delim) {
+			return delim
+		}
+	}
+	for i := range text {
+		if _, ok := states[string(text[i:])]; ok {
+			return string(text[i:])
+		}
+	}
+	return ""
+}
+
+func sortTransitions(ts []Transition) {
+	sort.Slice(ts, func(i, j int) bool {
+		if ts[i].From != ts[j].From {
+			return ts[i].From < ts[j].From
+		}
+		return ts[i].Symbol < ts[j].Symbol
+	})
+}
+
+func sortedChars(set charset) []rune {
+	chars := make([]rune, 0, len(set.chars))
+	for c := range set.chars {
+		chars = append(chars, c)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	return chars
+}
+
+func containsRune(rs []rune, r rune) bool {
+	for _, c := range rs {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+// A character of an example lexeme, OTHER is shown as a character that the
+// spec does not mention
+func describe(symbol rune) string {
+	if symbol == OTHER {
+		return "…"
+	}
+	return string(symbol)
+}
+
+func describeSymbol(symbol rune) string {
+	if symbol == OTHER {
+		return "a character that the spec does not mention"
+	}
+	return strconv.QuoteRune(symbol)
+}
@@ -26,6 +26,11 @@
 //  --out, -o
 //      Output file for generated code
 //
+//  --check
+//      Lint the grammar instead of compiling it. Lists every LL(1) conflict,
+//      left recursion, unreachable and unproductive nonterminals, and unused
+//      terminals. Exits with status 1 if any problems are found.
+//
 // The script will parse the source grammar file and output info about it
 // including all the rules, terminals, and nonterminals parsed from the source
 // grammar, as well as the FIRST, FOLLOW sets, and the final parsing table.
@@ -33,6 +38,11 @@
 // It is not a full parser generator - you have to write the algorithm yourself,
 // but it will do the hard work of generating the table after you disambiguate
 // your grammar.
+//
+// The FIRST and FOLLOW sets, the parser table, and the lint are computed by the
+// core/grammar/ll1 package, which core/grammar builds its tables with too. ll1
+// only depends on the standard library, so the script still runs when the
+// generated core/token/gen.go does not compile.
 // *****************************************************************************
 
 package main
@@ -48,6 +58,8 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/obonobo/esac/core/grammar/ll1"
 )
 
 var USAGE = strings.TrimLeft(`
@@ -71,6 +83,11 @@ Flags:
 
 	--out, -o
 		Output file for generated code
+
+	--check
+		Lint the grammar instead of compiling it. Lists every LL(1) conflict,
+		left recursion, unreachable and unproductive nonterminals, and unused
+		terminals. Exits with status 1 if any problems are found.
 `, "\n\r\t ")
 
 const (
@@ -100,6 +117,7 @@ func main() {
 	config := struct {
 		out        string
 		compile    bool
+		check      bool
 		all, table bool
 		file, prog string
 		fileHandle *os.File
@@ -118,6 +136,7 @@ func main() {
 	flag.BoolVar(&config.table, "t", false, "")
 	flag.BoolVar(&config.compile, "compile", false, "")
 	flag.BoolVar(&config.compile, "c", false, "")
+	flag.BoolVar(&config.check, "check", false, "")
 	flag.StringVar(&config.out, "out", "", "")
 	flag.StringVar(&config.out, "o", "", "")
 	flag.Parse()
@@ -135,8 +154,24 @@ func main() {
 		defer config.fileHandle.Close()
 	}
 
-	rules, terms, nonterms, firsts, follows, semActions := parseFile(config.fileHandle)
+	start, rules, terms, nonterms, semActions := scanFile(config.fileHandle)
 	config.fileHandle.Close()
+	g := analyse(start, rules, terms, nonterms)
+
+	if config.check {
+		report := g.Lint()
+		printReport(os.Stdout, rules, report)
+		if problems := report.Problems(); problems > 0 {
+			fmt.Fprintf(os.Stderr, "%v problem(s) found in grammar\n", problems)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// A left recursive grammar can't be parsed top-down
+	if cycles := g.LeftRecursion(); len(cycles) > 0 {
+		panic(fmt.Errorf("grammar: left recursion: %v", strings.Join(cycles, "; ")))
+	}
 
 	toolpath, ok := os.LookupEnv("TOOL")
 	if !ok {
@@ -154,14 +189,11 @@ func main() {
 
 	switch {
 	case config.compile:
-		compileAll(
-			out, rules, terms,
-			nonterms, firsts, follows,
-			toolpath, config.file, semActions)
+		compileAll(out, g, rules, toolpath, config.file, semActions)
 	case config.table:
-		compileTable(os.Stdout, rules, firsts, follows, false)
+		compileTable(os.Stdout, g, rules, false)
 	default:
-		printAll(os.Stdout, rules, terms, nonterms, firsts, follows)
+		printAll(os.Stdout, g, rules)
 	}
 
 	os.Exit(0)
@@ -169,16 +201,15 @@ func main() {
 
 func compileAll(
 	fh *os.File,
+	g *ll1.Grammar[string],
 	rules map[string][]Rule,
-	terminals, nonterminals StringSet,
-	firsts, follows map[string]StringSet,
 	toolpath, grammarfile string,
 	semanticActions StringSet,
 ) {
 	compileTypesKindsAndTerminals(fh, toolpath, grammarfile)
 	fmt.Fprintln(fh)
 
-	compileNonterminals(fh, nonterminals)
+	compileNonterminals(fh, g.Nonterminals)
 	fmt.Fprintln(fh)
 
 	compileSemanticActions(fh, semanticActions)
@@ -190,13 +221,13 @@ func compileAll(
 	compileRules(fh, rules)
 	fmt.Fprintln(fh)
 
-	compileFirstOrFollowSet(fh, "FIRSTS", firsts)
+	compileFirstOrFollowSet(fh, "FIRSTS", g.Firsts())
 	fmt.Fprintln(fh)
 
-	compileFirstOrFollowSet(fh, "FOLLOWS", follows)
+	compileFirstOrFollowSet(fh, "FOLLOWS", g.Follows())
 	fmt.Fprintln(fh)
 
-	compileTable(fh, rules, firsts, follows, true)
+	compileTable(fh, g, rules, true)
 }
 
 func compileSemanticActions(fh *os.File, semanticActions StringSet) {
@@ -279,6 +310,9 @@ var TERMINALS = func() KindSet {
 		INTEGER:   {},
 		INTNUM:    {},
 		WHILE:     {},
+		FOR:       {},
+		BREAK:     {},
+		CONTINUE:  {},
 		ID:        {},
 		EQ:        {},
 		VOID:      {},
@@ -289,6 +323,11 @@ var TERMINALS = func() KindSet {
 		THEN:      {},
 		STRUCT:    {},
 		FLOATNUM:  {},
+		STRINGLIT: {},
+		STRING:    {},
+		BOOL:      {},
+		TRUE:      {},
+		FALSE:     {},
 		WRITE:     {},
 		GT:        {},
 		PLUS:      {},
@@ -503,33 +542,36 @@ func sortedRules(rules map[string][]Rule) []ruleEntry {
 
 // Constructs a parser table from the provided rules, first and follow sets, in
 // the format of a Go map[Key][]token.Rule variable which can be copy and pasted
-// into your program. Reports on any ambiguous table entries (duplicate map
-// keys). If ambiguous entries are found, the map variable will not be legal due
-// to the duplicate keys.
+// into your program.
+//
+// The ambiguous table entries that the ll1 package can resolve are given the
+// rule that it picks for them. This is how the dangling 'else' is resolved:
+// 'else' 'if' continues an if-else chain rather than starting an else block
+// that holds a single if statement.
+//
+// Reports on any remaining ambiguous table entries (duplicate map keys). If
+// ambiguous entries are found, the map variable will not be legal due to the
+// duplicate keys.
 func compileTable(
 	fh *os.File,
+	g *ll1.Grammar[string],
 	rules map[string][]Rule,
-	firsts, follows map[string]StringSet,
 	variablifyEnabled bool,
 ) {
-	// Sort the rules firstly
-	sortedRules := sortedRules(rules)
-
-	type key struct{ a, t string }
-	entries := make(map[key][]string, 512)
+	keys, candidates := g.Candidates()
 
-	fprintentry := func(a, t string, rhs []string) {
+	formatEntry := func(a, t string, rhs []string) string {
 		if variablifyEnabled {
-			for i, r := range rhs {
-				rhs[i] = variablify(r)
+			variablified := make([]string, 0, len(rhs))
+			for _, r := range rhs {
+				variablified = append(variablified, variablify(r))
 			}
+			rhs = variablified
 			a = variablify(a)
 			t = variablify(t)
 		}
 		rhsPrint := fmt.Sprintf("{%v, []Kind{%v}", a, strings.Join(rhs, ", "))
-		entry := fmt.Sprintf("{%v, %v}: %v}", a, t, rhsPrint)
-		entries[key{a, t}] = append(entries[key{a, t}], entry)
-		fmt.Fprintf(fh, "		%v,\n", entry)
+		return fmt.Sprintf("{%v, %v}: %v}", a, t, rhsPrint)
 	}
 
 	fmt.Fprint(fh, `type Key struct {
@@ -542,39 +584,24 @@ func compileTable(
 	fmt.Fprintln(fh, "var TABLE = func() map[Key]Rule {")
 	fmt.Fprintln(fh, "	return map[Key]Rule{")
 
-	// for _, rs := range rules {
-	// for _, rs := range rules {
-	for _, e := range sortedRules {
-		rs := e.value
-
-		for _, r := range rs {
-			a := r.LHS
-			firstalpha := first(firsts, r.RHS)
-			sortedFirstAlpha := sortedStringSet(firstalpha)
-
-			// 2. For all terminals in FIRST(t), add r.RHS to TT[a, t]
-			for _, t := range sortedFirstAlpha {
-				if t != EPSILON && t != DOLLAR_SIGN {
-					fprintentry(a, t, r.RHS)
-				}
+	ambiguous := make([][]string, 0)
+	for _, k := range keys {
+		productions := candidates[k]
+		if len(productions) > 1 {
+			if winner, ok := g.ResolveEntry(k, productions); ok {
+				fmt.Fprintf(fh, "		// Ambiguous, resolved in favour of the rule starting with %v\n", k.Terminal)
+				productions = []int{winner}
 			}
+		}
 
-			// 3. If EPSILON in firstalpha, for all t in FOLLOW(a), add r.RHS TT[a, t]
-			if setContains(firstalpha, EPSILON) {
-				followa, ok := follows[a]
-				sortedFollowA := sortedStringSet(followa)
-
-				if !ok {
-					// All nonterminals should be in the FOLLOW set. If you hit
-					// this panic, then there is an error in the grammar
-					panic(fmt.Errorf("no FOLLOW entry for '%v'", a))
-				}
-				for _, t := range sortedFollowA {
-					if t != EPSILON && t != DOLLAR_SIGN {
-						fprintentry(a, t, r.RHS)
-					}
-				}
-			}
+		entries := make([]string, 0, len(productions))
+		for _, i := range productions {
+			entry := formatEntry(k.Nonterminal, k.Terminal, rules[k.Nonterminal][i].RHS)
+			entries = append(entries, entry)
+			fmt.Fprintf(fh, "		%v,\n", entry)
+		}
+		if len(entries) >= 2 {
+			ambiguous = append(ambiguous, entries)
 		}
 	}
 
@@ -582,20 +609,56 @@ func compileTable(
 	fmt.Fprintln(fh, "}")
 
 	// Report on the ambiguous entries found
-	printout, printMe := "AMBIGUITIES ENCOUNTERED\n", false
-	for _, entry := range entries {
-		if len(entry) >= 2 {
-			printMe = true
-			for _, e := range entry {
+	if len(ambiguous) > 0 {
+		printout := "AMBIGUITIES ENCOUNTERED\n"
+		for _, entries := range ambiguous {
+			for _, e := range entries {
 				printout += fmt.Sprintf("%v\n", e)
 			}
 		}
-	}
-	if printMe {
 		fmt.Fprintf(fh, "\n%v", printout)
 	}
 }
 
+// Prints the report of the lint of the grammar
+func printReport(fh *os.File, rules Rules, report *ll1.Report[string]) {
+	section := func(header string, lines []string) {
+		printHeader(fh, header)
+		if len(lines) == 0 {
+			fmt.Fprintln(fh, "none")
+		}
+		for _, l := range lines {
+			fmt.Fprintln(fh, l)
+		}
+		fmt.Fprintln(fh)
+	}
+
+	formatRule := func(r Rule) string {
+		return fmt.Sprintf("\t%v ::= %v", r.LHS, strings.Join(filterSemanticActionsOneRhs(r.RHS), " "))
+	}
+	conflicts := func(conflicts []ll1.Conflict[string], chosen bool) []string {
+		lines := make([]string, 0, 3*len(conflicts))
+		for _, c := range conflicts {
+			lines = append(lines, fmt.Sprintf("%v on %v:", c.Nonterminal, c.Terminal))
+			for i, p := range c.Productions {
+				if chosen && i == 0 {
+					lines = append(lines, formatRule(rules[c.Nonterminal][p])+" (chosen)")
+				} else {
+					lines = append(lines, formatRule(rules[c.Nonterminal][p]))
+				}
+			}
+		}
+		return lines
+	}
+
+	section("LEFT RECURSION", report.LeftRecursion)
+	section("UNPRODUCTIVE NONTERMINALS", report.Unproductive)
+	section("UNREACHABLE NONTERMINALS", report.Unreachable)
+	section("UNUSED TERMINALS", report.Unused)
+	section("RESOLVED LL(1) CONFLICTS", conflicts(report.Resolved, true))
+	section("LL(1) CONFLICTS", conflicts(report.Conflicts, false))
+}
+
 // This functions generates a header specifying which tool + grammar was used in
 // the codegen, then it generates the type declarations we will need, followed
 // by a series of hardcoded constants that correspond to the constants that I
@@ -689,12 +752,17 @@ func (r Rule) String() string {
 	INTNUM    Kind = "intnum"   // Integer '123'
 	EMPTY_DIM Kind = "emptydim" // An empty array dimension e.g.: 'integer[]'
 	FLOATNUM  Kind = "floatnum" // Floating-point number '1.23'
+	STRINGLIT Kind = "stringlit" // String literal '"hello"'
 
 	IF       Kind = "if"       // Reserved word 'if'
 	THEN     Kind = "then"     // Reserved word 'then'
 	ELSE     Kind = "else"     // Reserved word 'else'
 	INTEGER  Kind = "integer"  // Reserved word 'integer'
 	FLOAT    Kind = "float"    // Reserved word 'float'
+	STRING   Kind = "string"   // Reserved word 'string'
+	BOOL     Kind = "bool"     // Reserved word 'bool'
+	TRUE     Kind = "true"     // Reserved word 'true'
+	FALSE    Kind = "false"    // Reserved word 'false'
 	VOID     Kind = "void"     // Reserved word 'void'
 	PUBLIC   Kind = "public"   // Reserved word 'public'
 	PRIVATE  Kind = "private"  // Reserved word 'private'
@@ -702,6 +770,9 @@ func (r Rule) String() string {
 	VAR      Kind = "var"      // Reserved word 'var'
 	STRUCT   Kind = "struct"   // Reserved word 'struct'
 	WHILE    Kind = "while"    // Reserved word 'while'
+	FOR      Kind = "for"      // Reserved word 'for'
+	BREAK    Kind = "break"    // Reserved word 'break'
+	CONTINUE Kind = "continue" // Reserved word 'continue'
 	READ     Kind = "read"     // Reserved word 'read'
 	WRITE    Kind = "write"    // Reserved word 'write'
 	RETURN   Kind = "return"   // Reserved word 'return'
@@ -714,6 +785,8 @@ func (r Rule) String() string {
 	INVALIDNUM          Kind = "invalidnum"          // Error token
 	INVALIDCHAR         Kind = "invalidchar"         // Error token
 	UNTERMINATEDCOMMENT Kind = "unterminatedcomment" // Error token
+	UNTERMINATEDSTRING  Kind = "unterminatedstring"  // Error token
+	INVALIDESCAPE       Kind = "invalidescape"       // Error token
 )
 
 func Comments() []Kind {
@@ -722,70 +795,28 @@ func Comments() []Kind {
 `)
 }
 
-func first(firsts map[string]StringSet, rhs []string) StringSet {
-	firstSet := make(map[string]struct{}, len(rhs)*2)
-	add := func(symbol string) { firstSet[symbol] = struct{}{} }
-
-	rhs = filterSemanticActionsOneRhs(rhs)
-	for i, s := range rhs {
-		firstOfS, ok := firsts[s]
-		if !ok {
-			// All symbols should be present in the FIRST set, even
-			// nonterminals. If you hit this panic, then there is an error
-			// in the grammar
-			panic(fmt.Errorf("FIRST(%v) not found", s))
-		}
-
-		var hasEpsilon bool
-		for f := range firstOfS {
-			if f == EPSILON {
-				hasEpsilon = true
-			}
-			if f != EPSILON || i == len(rhs)-1 {
-				// If we are at the last element, add epsilon
-				add(f)
-			}
-		}
-
-		if !hasEpsilon {
-			break
-		}
-	}
-	return firstSet
-}
-
-func printAll(
-	fh *os.File,
-	rules Rules,
-	terminals, nonterminals StringSet,
-	firsts, follows map[string]StringSet,
-) {
+func printAll(fh *os.File, g *ll1.Grammar[string], rules Rules) {
 	printRules(fh, "RULES", rules)
 	fmt.Fprintln(fh)
 
-	printStringSet(fh, "TERMINALS", terminals)
+	printStringSet(fh, "TERMINALS", g.Terminals)
 	fmt.Fprintln(fh)
 
-	printStringSet(fh, "NONTERMINALS", nonterminals)
+	printStringSet(fh, "NONTERMINALS", g.Nonterminals)
 	fmt.Fprintln(fh)
 
-	printFirstOrFollowSet(fh, "FIRST SETS", firsts, true)
+	printFirstOrFollowSet(fh, "FIRST SETS", g.Firsts(), true)
 	fmt.Fprintln(fh)
 
-	printFirstOrFollowSet(fh, "FOLLOW SETS", follows, false)
+	printFirstOrFollowSet(fh, "FOLLOW SETS", g.Follows(), false)
 	fmt.Fprintln(fh)
 
-	printTable(fh, "TABLE", rules, firsts, follows)
+	printTable(fh, "TABLE", g, rules)
 }
 
-func printTable(
-	fh *os.File,
-	header string,
-	rules map[string][]Rule,
-	firsts, follows map[string]map[string]struct{},
-) {
+func printTable(fh *os.File, header string, g *ll1.Grammar[string], rules map[string][]Rule) {
 	printHeader(fh, header)
-	compileTable(fh, rules, firsts, follows, false)
+	compileTable(fh, g, rules, false)
 }
 
 func printFirstOrFollowSet(
@@ -848,12 +879,13 @@ func printHeader(fh *os.File, header string) {
 	fmt.Fprintln(fh, hr)
 }
 
-// Parses the provided file producing the terminals and nonterminal sets, the
-// FIRST and FOLLOW sets, as well as the set of production rules of the grammar
-func parseFile(inputFile io.Reader) (
+// Parses the provided file producing the terminals and nonterminal sets, as
+// well as the set of production rules of the grammar. The start symbol is the
+// LHS of the first rule
+func scanFile(inputFile io.Reader) (
+	start string,
 	rules Rules,
 	terminals, nonterminals StringSet,
-	firsts, follows map[string]StringSet,
 	semanticActions StringSet,
 ) {
 	data, err := io.ReadAll(inputFile)
@@ -862,26 +894,30 @@ func parseFile(inputFile io.Reader) (
 	}
 
 	contents := string(data)
-	rules = scanRules(contents)
+	start, rules = scanRules(contents)
 	terminals, nonterminals, semanticActions = scanTerminalsAndNonterminals(contents)
-	firsts = firstSets(nonterminals, terminals, rules)
-	follows = followSets(nonterminals, terminals, rules, firsts)
-
-	return rules, terminals, nonterminals, firsts, follows, semanticActions
+	return start, rules, terminals, nonterminals, semanticActions
 }
 
-// Removes the semantic actions from the RHS of all rules. Returns a new set of
-// rules with all the semantic actions removed
-func filterSemanticActions(rules Rules) Rules {
-	out := make(Rules, len(rules))
-	for k, v := range rules {
-		rhs := make([]Rule, 0, len(v))
-		for _, r := range v {
-			rhs = append(rhs, Rule{r.LHS, filterSemanticActionsOneRhs(r.RHS)})
+// Hands the rules, without their semantic actions, to the ll1 package which
+// computes the FIRST and FOLLOW sets and the parser table. The nonterminals
+// are analysed in alphabetical order, which is the order of the generated
+// table
+func analyse(start string, rules Rules, terminals, nonterminals StringSet) *ll1.Grammar[string] {
+	g := &ll1.Grammar[string]{
+		Start:        start,
+		Productions:  make(map[string][]ll1.Production[string], len(rules)),
+		Terminals:    terminals,
+		Nonterminals: nonterminals,
+	}
+	for _, e := range sortedRules(rules) {
+		g.Order = append(g.Order, e.key)
+		for _, r := range e.value {
+			g.Productions[e.key] = append(g.Productions[e.key],
+				ll1.Production[string]{LHS: r.LHS, RHS: filterSemanticActionsOneRhs(r.RHS)})
 		}
-		out[k] = rhs
 	}
-	return out
+	return g
 }
 
 // Filters a single rhs
@@ -895,240 +931,7 @@ func filterSemanticActionsOneRhs(rhs []string) []string {
 	return out
 }
 
-// Computes all FIRST sets for the provided terminals and nonterminals
-func firstSets(
-	nonterminals StringSet,
-	terminals StringSet,
-	rules Rules,
-) (set map[string]StringSet) {
-	newRules := filterSemanticActions(rules)
-
-	set = make(map[string]StringSet, len(nonterminals)+len(terminals))
-	for nonterminal := range nonterminals {
-		set[nonterminal] = firstSet(nonterminal, newRules, terminals)
-	}
-	for terminal := range terminals {
-		set[terminal] = StringSet{terminal: {}}
-	}
-	return set
-}
-
-// Computes all FOLLOW sets for the provided nonterminals
-func followSets(
-	nonterminals StringSet,
-	terminals StringSet,
-	rules Rules,
-	firstSets map[string]StringSet,
-) (set map[string]StringSet) {
-	newRules := filterSemanticActions(rules)
-
-	set = make(map[string]StringSet, len(nonterminals))
-	for nonterminal := range nonterminals {
-		followSet(
-			set, make(map[string]struct{}, 64),
-			nonterminal, newRules, terminals, firstSets)
-	}
-
-	for terminal := range terminals {
-		followSet(
-			set, make(map[string]struct{}, 64),
-			terminal, newRules, terminals, firstSets)
-	}
-	return set
-}
-
-// This procedure adds the follow set to the cache that you provide. It also
-// returns the same value that it adds to the cache. This is to support
-// recursion.
-//
-// The path parameter should be an empty map. The procedure uses the path to
-// keep track of its path as it traverses the grammar rules
-//
-// ALGO:
-//
-// 1. if A == S then FOLLOW(A) includes {$}
-//
-// 2. if there exists a rule B -> <alpha>A<beta>, then FOLLOW(A) includes
-// FIRST(<beta>) - {<epsilon>}
-//
-// 3. if there exists a rule B -> <alpha>A<beta> and <beta> can derive
-// <epsilon>, then FOLLOW(A) includes FOLLOW(B)
-func followSet(
-	cache map[string]StringSet,
-	path map[string]struct{},
-	nonterminal string,
-	rules Rules,
-	terminals StringSet,
-	firstSets map[string]StringSet,
-) (follow StringSet) {
-
-	path[nonterminal] = struct{}{}
-	defer func() {
-		// Only add this result if we are at the root of our search, i.e. if
-		// this function was original called with this nonterminal
-		if len(path) == 1 {
-			cache[nonterminal] = follow
-		}
-		delete(path, nonterminal) // Remove ourself from the path
-	}()
-
-	// If cache already has this nonterminal, return cached value
-	if got, ok := cache[nonterminal]; ok {
-		return got
-	}
-
-	follow = make(StringSet, 32)
-
-	// Returns true if the symbol is <START>
-	isStart := func(symbol string) bool {
-		return symbol == "<START>"
-	}
-
-	// Searches for rules that match B -> <alpha>A<beta>
-	existsRulesForProducing := func(symbol string) (Bs, betas []string, ok bool) {
-		Bs = make([]string, 0, len(rules))
-		betas = make([]string, 0, len(rules))
-		for _, v := range rules {
-			for _, rule := range v {
-				index := -1
-				var contains bool
-				for i, x := range rule.RHS {
-					if x == nonterminal {
-						contains = true
-						index = i
-					}
-				}
-				if contains {
-					// Then we have found a rule that matches B -> <alpha>A<beta>
-					ok = true
-					Bs = append(Bs, rule.LHS)
-					if index < len(rule.RHS)-1 {
-						betas = append(betas, rule.RHS[index+1])
-					} else {
-						betas = append(betas, EPSILON)
-					}
-				}
-			}
-		}
-		return Bs, betas, ok
-	}
-
-	// Determines whether the given symbol can derive <epsilon>
-	var canDeriveEpsilon func(string) bool
-	canDeriveEpsilon = func(symbol string) bool {
-		// Base case: we are epsilon
-		if symbol == EPSILON {
-			return true
-		}
-
-		// Resursive case: we can still produce epsilon if we have a rule where
-		// ALL symbols of the RHS can produce epsilon,
-		//
-		// e.g.: B -> FT; F -> epsilon; T -> epsilon;
-		for _, rule := range rules[symbol] {
-			all := true
-			for _, s := range rule.RHS {
-				if !canDeriveEpsilon(s) {
-					all = false
-					break
-				}
-			}
-			if all {
-				return true
-			}
-		}
-
-		return false // Otherwise, this symbol cannot produce epsilon
-	}
-
-	// 1. A == S
-	if isStart(nonterminal) {
-		// follow["'$'"] = struct{}{}
-		return follow // Start is followed by $
-	}
-
-	bs, betas, ok := existsRulesForProducing(nonterminal)
-	if !ok {
-		return follow
-	}
-
-	for i, beta := range betas {
-
-		// 2. Add beta's first set
-		first := firstSets[beta]
-		minusEpsilon := setDiff(first, StringSet{EPSILON: {}})
-		for k := range minusEpsilon {
-			follow[k] = struct{}{}
-		}
-
-		// 3. Add FOLLOW(B) if beta can derive epsilon
-		if _, seen := path[bs[i]]; !seen && canDeriveEpsilon(beta) {
-			followB := followSet(cache, path, bs[i], rules, terminals, firstSets)
-			for k := range followB {
-				follow[k] = struct{}{}
-			}
-		}
-	}
-
-	return follow
-}
-
-// LOOKAHEAD TREE GENERATOR
-func firstSet(nonterminal string, rules Rules, terminals StringSet) StringSet {
-	isTerminal := func(terminal string) (yes bool) {
-		_, ok := terminals[terminal]
-		return ok
-	}
-
-	var recurse func(string) []string
-	recurse = func(first string) (set []string) {
-		if isTerminal(first) { // Base case: we've reached a terminal
-			return []string{first}
-		}
-
-		// Otherwise, grab the set of productions where this nonterminal is LHS
-		rules, ok := rules[first]
-		if !ok {
-			// All nonterminals should have rules for how to derive a terminal
-			// sentential form i.e. a sentence
-			panic(fmt.Errorf("no rules found for nonterminal symbol '%v'", first))
-		}
-
-		// For each rule, expand the first symbol on RHS. If that symbol's first
-		// set does not contain epsilon then we are done - that first set shall
-		// be added to our first set. However, if that symbol's first set does
-		// contain epsilon, then we must look at the subsequent symbols on the
-		// RHS. Look at each symbol one-after-another, stop at the first symbol
-		// whose first set does not contain epsilon, add the union of all first
-		// sets encountered minus the set {epsilon}. If all symbol first sets on
-		// RHS contain epsilon, our first set shall contain the union of all
-		// first sets on RHS.
-		for _, r := range rules {
-			for i, rhs := range r.RHS {
-				frhs := recurse(rhs)
-				if i == len(r.RHS)-1 { // last element, add everything
-					set = append(set, frhs...)
-				} else {
-					set = append(set, sliceExcept(frhs, EPSILON)...)
-				}
-				if !sliceContains(frhs, EPSILON) {
-					break
-				}
-			}
-		}
-
-		return set
-	}
-
-	set := recurse(nonterminal)
-	ret := make(StringSet, len(set))
-	for _, term := range set {
-		ret[term] = struct{}{}
-	}
-	return ret
-}
-
-func scanRules(input string) map[string][]Rule {
+func scanRules(input string) (start string, rules map[string][]Rule) {
 	scanRule := func(line string) (r Rule) {
 		scnr := bufio.NewScanner(bytes.NewBufferString(line))
 		scnr.Split(bufio.ScanWords)
@@ -1144,7 +947,7 @@ func scanRules(input string) map[string][]Rule {
 		return r
 	}
 
-	rules := make(map[string][]Rule, 100)
+	rules = make(map[string][]Rule, 100)
 	scnr := bufio.NewScanner(bytes.NewBufferString(input))
 	for scnr.Scan() {
 		line := scnr.Text()
@@ -1152,10 +955,13 @@ func scanRules(input string) map[string][]Rule {
 			continue
 		}
 		rule := scanRule(line)
+		if start == "" {
+			start = rule.LHS
+		}
 		rules[rule.LHS] = append(rules[rule.LHS], rule)
 	}
 
-	return rules
+	return start, rules
 }
 
 func prefixSemanticAction(actionSymbol string) string {
@@ -1199,49 +1005,6 @@ func scanTerminalsAndNonterminals(input string) (terminals, nonterminals, semant
 	return terminals, nonterminals, semanticActions
 }
 
-// Checks if the needle is in your map haystack
-func setContains(haystack map[string]struct{}, needle string) bool {
-	_, ok := haystack[needle]
-	return ok
-}
-
-// Linear search to check if needle is in haystack
-func sliceContains(haystack []string, needle string) bool {
-	for _, s := range haystack {
-		if s == needle {
-			return true
-		}
-	}
-	return false
-}
-
-// Returns a new slice containing all elements of s excludng those in the except
-// slice
-func sliceExcept(s []string, except ...string) []string {
-	acc := make([]string, 0, len(s))
-	exceptm := make(map[string]struct{}, len(except))
-	for _, e := range except {
-		exceptm[e] = struct{}{}
-	}
-	for _, ss := range s {
-		if _, ok := exceptm[ss]; !ok {
-			acc = append(acc, ss)
-		}
-	}
-	return acc
-}
-
-// Returns a new set containing all elements of s1 that are not also in s2
-func setDiff(s1, s2 StringSet) StringSet {
-	ret := make(StringSet, len(s1))
-	for k := range s1 {
-		if _, ok := s2[k]; !ok {
-			ret[k] = struct{}{}
-		}
-	}
-	return ret
-}
-
 type entry struct{ original, variablified string }
 
 func orderifyAndVariablify(set StringSet) []entry {
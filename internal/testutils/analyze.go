@@ -0,0 +1,43 @@
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/tabledrivenscanner"
+	scannertable "github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/core/token/visitors"
+)
+
+// Parses the source and runs the SymTabVisitor, the SemCheckVisitor, and the
+// MemoryLayoutVisitor on it, as the front end does before handing the AST to a
+// back end. Fails the test if any error is reported
+func MustAnalyze(t testing.TB, src string) token.AST {
+	t.Helper()
+	errs := new(bytes.Buffer)
+	prsr := tabledrivenparser.NewParserNoComments(
+		tabledrivenscanner.NewScanner(
+			chuggingcharsource.MustChuggingReader(strings.NewReader(src)),
+			scannertable.TABLE()), parsertable.TABLE(),
+		func(e *tabledrivenparser.ParserError) { fmt.Fprintln(errs, e) },
+		nil, token.Comments()...)
+	if !prsr.Parse() {
+		t.Fatalf("Parse failed: %v", errs)
+	}
+
+	ast := prsr.AST()
+	logErr := func(e *visitors.VisitorError) { fmt.Fprintln(errs, e) }
+	ast.Root.Accept(visitors.NewSymTabVisitor(logErr))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(logErr))
+	ast.Root.Accept(visitors.NewMemoryLayoutVisitor(logErr))
+	if errs.Len() > 0 {
+		t.Fatalf("Semantic analysis failed: %v", errs)
+	}
+	return ast
+}
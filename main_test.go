@@ -3,10 +3,15 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/interp"
+	"github.com/obonobo/esac/core/moon"
 	"github.com/obonobo/esac/core/scanner"
 	"github.com/obonobo/esac/core/tabledrivenparser"
 	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
@@ -86,6 +91,120 @@ func TestSemCheckVisitor_General(t *testing.T) {
 	`)
 }
 
+func TestSemCheckVisitor_Strings(t *testing.T) {
+	t.Parallel()
+	assertSemCheckOutput(t, `
+	func greet(name: string) -> string {
+		return (name);
+	}
+
+	func main() -> void {
+		let s: string;
+		let x: integer;
+		s = greet("hello");
+		write(s);
+		s = "a" + s;
+		x = -"b";
+		x = s;
+		if (s == "c") then write(s); else;
+	}
+	`, `
+	typecheck: operator Plus(+) cannot be applied to strings (line 11)
+	typecheck: operator SignNegative(-) cannot be applied to strings (line 12)
+	typecheck: mismatched return type for assignment statement in function 'Global::main()' line 12 left-hand side has type Integer while right-hand side has type String
+	typecheck: mismatched return type for assignment statement in function 'Global::main()' line 13 left-hand side has type Integer while right-hand side has type String
+	typecheck: operator Eq(==) cannot be applied to strings (line 14)
+	`)
+}
+
+func TestSemCheckVisitor_Loops(t *testing.T) {
+	t.Parallel()
+	assertSemCheckOutput(t, `
+	func main() -> void {
+		let n: integer;
+		let s: string;
+		for (let i: integer = 0; i < 10; i = i + 1) {
+			if (i == n) then break; else { continue; };
+		};
+		for (n = 0; n < 10; n = s) {
+			while (n < 5) { break; };
+		};
+		break;
+		if (n == 1) then continue; else ;
+	}
+	`, `
+	typecheck: mismatched return type for assignment statement in function 'Global::main()' line 8 left-hand side has type Integer while right-hand side has type String
+	typecheck: 'break' is not inside a loop (line 11)
+	typecheck: 'continue' is not inside a loop (line 12)
+	`)
+}
+
+func TestSemCheckVisitor_LoopScopes(t *testing.T) {
+	t.Parallel()
+	assertSemCheckOutput(t, `
+	func main() -> void {
+		let n: integer;
+		for (let i: integer = 0; i < 10; i = i + 1) ;
+		for (let i: float = 0.5; i < 10.0; i = i + 1.0) ;
+		for (let i: integer = 0; i < 10; i = i + 1) {
+			for (let j: integer = 0; j < 10; j = j + 1) n = j;;
+			for (let j: integer = 0; j < 10; j = j + 1) {
+				for (let i: integer = 0; i < 10; i = i + 1) ;
+			};
+		};
+		write(i);
+		for (let n: integer = 0; n < 10; n = n + 1) ;
+	}
+	`, `
+	duplicate definition for 'i' (defined on line 6, and again on line 9)
+	duplicate definition for 'n' (defined on line 3, and again on line 13)
+	typecheck: id i was not found within the current scope (line 12)
+	`)
+}
+
+func TestSemCheckVisitor_DuplicateStructVar(t *testing.T) {
+	t.Parallel()
+	assertSemCheckOutput(t, `
+	struct Q {
+		public let a: integer;
+	};
+	func main() -> void {
+		let q: Q;
+		let q: Q;
+	}
+	`, `
+	duplicate definition for 'q' (defined on line 6, and again on line 7)
+	`)
+}
+
+func TestSemCheckVisitor_Bools(t *testing.T) {
+	t.Parallel()
+	assertSemCheckOutput(t, `
+	func main() -> void {
+		let b: bool;
+		let n: integer;
+		b = (n < 1) & !(n == 2) | b;
+		b = b == false;
+		if (n) then write(n);;
+		while (n + 1) ;
+		b = n & b;
+		b = !n;
+		n = b + b;
+		b = b < true;
+		n = n > 1;
+	}
+	`, `
+	typecheck: if condition must be of type Bool but has type Integer (line 7)
+	typecheck: while condition must be of type Bool but has type Integer (line 8)
+	typecheck: operator And(&) expects operands of type Bool but got Integer (line 9)
+	typecheck: operator Not(!) expects operands of type Bool but got Integer (line 10)
+	typecheck: operator Plus(+) cannot be applied to bools (line 11)
+	typecheck: mismatched return type for assignment statement in function 'Global::main()' line 11 left-hand side has type Integer while right-hand side has type Bool
+	typecheck: operator Lt(<) cannot be applied to bools (line 12)
+	typecheck: mismatched return type for assignment statement in function 'Global::main()' line 13 left-hand side has type Integer while right-hand side has type Bool
+	`)
+}
+
 func assertSemCheckOutput(t *testing.T, input, output string) {
 	output = clean(output, "	")
 	prsr, errs := createErrorLoggingParser(input)
@@ -127,36 +246,36 @@ func TestSymTabVisitor_SimpleImplBeforeStruct(t *testing.T) {
 		public func and_another_one() -> float;
 	};
 	`, `
-			                              Global
-			+-----------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                     |
-			+-----------------------------------------------------------------+
-			| MyImplementation | ImplDef    | ____ | ⊙---> MyImplementation   |
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation   |
-			+-----------------------------------------------------------------+
-
-				                                MyImplementation
-				+-------------------------------------------------------------------------------+
-				| Name            | Kind    | Type           | Link                             |
-				+-------------------------------------------------------------------------------+
-				| do_something    | FuncDef | (public) void  | ⊙---> do_something(integer[2])   |
-				| and_another_one | FuncDef | (public) float | ⊙---> and_another_one()          |
-				+-------------------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
+			                                      Global
+			+---------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                     |
+			+---------------------------------------------------------------------------------+
+			| MyImplementation | ImplDef    | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+---------------------------------------------------------------------------------+
+
+				                                        MyImplementation
+				+-----------------------------------------------------------------------------------------------+
+				| Name            | Kind    | Type           | Size | Offset | Link                             |
+				+-----------------------------------------------------------------------------------------------+
+				| do_something    | FuncDef | (public) void  | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| and_another_one | FuncDef | (public) float | ____ | ____   | ⊙---> and_another_one()          |
+				+-----------------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
 	`)
 }
 
@@ -180,36 +299,36 @@ func TestSymTabVisitor_SimpleStructBeforeImpl(t *testing.T) {
 		}
 	}
 	`, `
-			                              Global
-			+-----------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                     |
-			+-----------------------------------------------------------------+
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation   |
-			| MyImplementation | ImplDef    | ____ | ⊙---> MyImplementation   |
-			+-----------------------------------------------------------------+
-
-				                                MyImplementation
-				+-------------------------------------------------------------------------------+
-				| Name            | Kind    | Type           | Link                             |
-				+-------------------------------------------------------------------------------+
-				| do_something    | FuncDef | (public) void  | ⊙---> do_something(integer[2])   |
-				| and_another_one | FuncDef | (public) float | ⊙---> and_another_one()          |
-				+-------------------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
+			                                      Global
+			+---------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                     |
+			+---------------------------------------------------------------------------------+
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			| MyImplementation | ImplDef    | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+---------------------------------------------------------------------------------+
+
+				                                        MyImplementation
+				+-----------------------------------------------------------------------------------------------+
+				| Name            | Kind    | Type           | Size | Offset | Link                             |
+				+-----------------------------------------------------------------------------------------------+
+				| do_something    | FuncDef | (public) void  | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| and_another_one | FuncDef | (public) float | ____ | ____   | ⊙---> and_another_one()          |
+				+-----------------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
 	`)
 }
 
@@ -238,36 +357,36 @@ func TestSymTabVisitor_DuplicateStructDefined(t *testing.T) {
 		public func and_another_one() -> float;
 	};
 	`, `
-			                              Global
-			+-----------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                     |
-			+-----------------------------------------------------------------+
-			| MyImplementation | ImplDef    | ____ | ⊙---> MyImplementation   |
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation   |
-			+-----------------------------------------------------------------+
-
-				                                MyImplementation
-				+-------------------------------------------------------------------------------+
-				| Name            | Kind    | Type           | Link                             |
-				+-------------------------------------------------------------------------------+
-				| do_something    | FuncDef | (public) void  | ⊙---> do_something(integer[2])   |
-				| and_another_one | FuncDef | (public) float | ⊙---> and_another_one()          |
-				+-------------------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
+			                                      Global
+			+---------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                     |
+			+---------------------------------------------------------------------------------+
+			| MyImplementation | ImplDef    | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+---------------------------------------------------------------------------------+
+
+				                                        MyImplementation
+				+-----------------------------------------------------------------------------------------------+
+				| Name            | Kind    | Type           | Size | Offset | Link                             |
+				+-----------------------------------------------------------------------------------------------+
+				| do_something    | FuncDef | (public) void  | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| and_another_one | FuncDef | (public) float | ____ | ____   | ⊙---> and_another_one()          |
+				+-----------------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
 			duplicate definition for 'MyImplementation' (defined on line 14, and again on line 19)
 	`)
 }
@@ -293,30 +412,30 @@ func TestSymTabVisitor_ImplMissingStructMethod(t *testing.T) {
 		public func and_another_one() -> float;
 	};
 	`, `
-			                              Global
-			+-----------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                     |
-			+-----------------------------------------------------------------+
-			| MyImplementation | ImplDef    | ____ | ⊙---> MyImplementation   |
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation   |
-			+-----------------------------------------------------------------+
-
-				                                MyImplementation
-				+------------------------------------------------------------------------------+
-				| Name         | Kind    | Type             | Link                             |
-				+------------------------------------------------------------------------------+
-				| x            | VarDecl | (public) integer | ____                             |
-				| do_something | FuncDef | (public) void    | ⊙---> do_something(integer[2])   |
-				+------------------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
+			                                      Global
+			+---------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                     |
+			+---------------------------------------------------------------------------------+
+			| MyImplementation | ImplDef    | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+---------------------------------------------------------------------------------+
+
+				                                        MyImplementation
+				+----------------------------------------------------------------------------------------------+
+				| Name         | Kind    | Type             | Size | Offset | Link                             |
+				+----------------------------------------------------------------------------------------------+
+				| x            | VarDecl | (public) integer | ____ | ____   | ____                             |
+				| do_something | FuncDef | (public) void    | ____ | ____   | ⊙---> do_something(integer[2])   |
+				+----------------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
 			impl 'MyImplementation' is missing method 'public func and_another_one() -> float' defined in struct (line 17)
 	`)
 }
@@ -342,37 +461,37 @@ func TestSymTabVisitor_StructMissingImplMethod(t *testing.T) {
 		public func and_another_one() -> float;
 	};
 	`, `
-			                              Global
-			+-----------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                     |
-			+-----------------------------------------------------------------+
-			| MyImplementation | ImplDef    | ____ | ⊙---> MyImplementation   |
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation   |
-			+-----------------------------------------------------------------+
-
-				                                 MyImplementation
-				+---------------------------------------------------------------------------------+
-				| Name            | Kind    | Type             | Link                             |
-				+---------------------------------------------------------------------------------+
-				| x               | VarDecl | (public) integer | ____                             |
-				| do_something    | FuncDef | void             | ⊙---> do_something(integer[2])   |
-				| and_another_one | FuncDef | (public) float   | ⊙---> and_another_one()          |
-				+---------------------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
+			                                      Global
+			+---------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                     |
+			+---------------------------------------------------------------------------------+
+			| MyImplementation | ImplDef    | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+---------------------------------------------------------------------------------+
+
+				                                         MyImplementation
+				+-------------------------------------------------------------------------------------------------+
+				| Name            | Kind    | Type             | Size | Offset | Link                             |
+				+-------------------------------------------------------------------------------------------------+
+				| x               | VarDecl | (public) integer | ____ | ____   | ____                             |
+				| do_something    | FuncDef | void             | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| and_another_one | FuncDef | (public) float   | ____ | ____   | ⊙---> and_another_one()          |
+				+-------------------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
 			struct 'MyImplementation' is missing method 'func do_something(integer[2]) -> void' defined in impl (line 3)
 	`)
 }
@@ -397,39 +516,75 @@ func TestSymTabVisitor_NoStructFoundForImpl(t *testing.T) {
 		}
 	}
 	`, `
-			                            Global
-			+--------------------------------------------------------------+
-			| Name             | Kind    | Type | Link                     |
-			+--------------------------------------------------------------+
-			| MyImplementation | ImplDef | ____ | ⊙---> MyImplementation   |
-			+--------------------------------------------------------------+
-
-				                            MyImplementation
-				+----------------------------------------------------------------------+
-				| Name            | Kind    | Type  | Link                             |
-				+----------------------------------------------------------------------+
-				| do_something    | FuncDef | void  | ⊙---> do_something(integer[2])   |
-				| and_another_one | FuncDef | float | ⊙---> and_another_one()          |
-				+----------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
+			                                    Global
+			+------------------------------------------------------------------------------+
+			| Name             | Kind    | Type | Size | Offset | Link                     |
+			+------------------------------------------------------------------------------+
+			| MyImplementation | ImplDef | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+------------------------------------------------------------------------------+
+
+				                                    MyImplementation
+				+--------------------------------------------------------------------------------------+
+				| Name            | Kind    | Type  | Size | Offset | Link                             |
+				+--------------------------------------------------------------------------------------+
+				| do_something    | FuncDef | void  | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| and_another_one | FuncDef | float | ____ | ____   | ⊙---> and_another_one()          |
+				+--------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
 			malformed type: no struct found for impl 'MyImplementation', impl methods must first be declared in a struct
 	`)
 }
 
+func TestSymTabVisitor_CircularInheritance(t *testing.T) {
+	t.Parallel()
+	assertSymbolTableOutput(t, `
+	struct A inherits B {
+		public let a: integer;
+	};
+
+	struct B inherits A {
+		public let b: integer;
+	};
+	`, `
+			                        Global
+			+------------------------------------------------------+
+			| Name | Kind       | Type | Size | Offset | Link      |
+			+------------------------------------------------------+
+			| A    | StructDecl | ____ | ____ | ____   | ⊙---> A   |
+			| B    | StructDecl | ____ | ____ | ____   | ⊙---> B   |
+			+------------------------------------------------------+
+
+				                              A
+				+----------------------------------------------------------+
+				| Name | Kind    | Type             | Size | Offset | Link |
+				+----------------------------------------------------------+
+				| a    | VarDecl | (public) integer | ____ | ____   | ____ |
+				+----------------------------------------------------------+
+
+				                              B
+				+----------------------------------------------------------+
+				| Name | Kind    | Type             | Size | Offset | Link |
+				+----------------------------------------------------------+
+				| b    | VarDecl | (public) integer | ____ | ____   | ____ |
+				+----------------------------------------------------------+
+			malformed type: circular inheritance, struct 'B' cannot inherit from 'A' because 'A' already inherits from 'B' (line 6)
+	`)
+}
+
 func TestSymTabVisitor_NoImplFoundForStruct(t *testing.T) {
 	t.Parallel()
 	assertSymbolTableOutput(t, `
@@ -445,33 +600,33 @@ func TestSymTabVisitor_NoImplFoundForStruct(t *testing.T) {
 	// 		write(x);
 	// 	}
 	`, `
-			                              Global
-			+-----------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                     |
-			+-----------------------------------------------------------------+
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation   |
-			+-----------------------------------------------------------------+
-
-				                                 MyImplementation
-				+--------------------------------------------------------------------------------+
-				| Name            | Kind     | Type           | Link                             |
-				+--------------------------------------------------------------------------------+
-				| do_something    | FuncDecl | (public) void  | ⊙---> do_something(integer[2])   |
-				| and_another_one | FuncDecl | (public) float | ⊙---> and_another_one()          |
-				+--------------------------------------------------------------------------------+
-
-					      do_something(integer[2])
-					+----------------------------------+
-					| Name | Kind  | Type       | Link |
-					+----------------------------------+
-					| x    | Param | integer[2] | ____ |
-					+----------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
+			                                      Global
+			+---------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                     |
+			+---------------------------------------------------------------------------------+
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation   |
+			+---------------------------------------------------------------------------------+
+
+				                                         MyImplementation
+				+------------------------------------------------------------------------------------------------+
+				| Name            | Kind     | Type           | Size | Offset | Link                             |
+				+------------------------------------------------------------------------------------------------+
+				| do_something    | FuncDecl | (public) void  | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| and_another_one | FuncDecl | (public) float | ____ | ____   | ⊙---> and_another_one()          |
+				+------------------------------------------------------------------------------------------------+
+
+					              do_something(integer[2])
+					+--------------------------------------------------+
+					| Name | Kind  | Type       | Size | Offset | Link |
+					+--------------------------------------------------+
+					| x    | Param | integer[2] | ____ | ____   | ____ |
+					+--------------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
 			malformed type: no impl found for struct 'MyImplementation', struct methods declared but not defined
 	`)
 }
@@ -506,68 +661,68 @@ func TestSymTabVisitor_DuplicateMethodDefinitions(t *testing.T) {
 	func top_level(y: integer) -> void {}
 	func top_level(x: integer, y: float) -> void {}
 	`, `
-			                                  Global
-			+--------------------------------------------------------------------------+
-			| Name             | Kind       | Type | Link                              |
-			+--------------------------------------------------------------------------+
-			| MyImplementation | ImplDef    | ____ | ⊙---> MyImplementation            |
-			| MyImplementation | StructDecl | ____ | ⊙---> MyImplementation            |
-			| top_level        | FuncDef    | void | ⊙---> top_level()                 |
-			| top_level        | FuncDef    | void | ⊙---> top_level(integer)          |
-			| top_level        | FuncDef    | void | ⊙---> top_level(integer, float)   |
-			+--------------------------------------------------------------------------+
-
-				                                MyImplementation
-				+-------------------------------------------------------------------------------+
-				| Name            | Kind    | Type           | Link                             |
-				+-------------------------------------------------------------------------------+
-				| do_something    | FuncDef | (public) void  | ⊙---> do_something(integer[2])   |
-				| do_something    | FuncDef | (public) void  | ⊙---> do_something(integer)      |
-				| and_another_one | FuncDef | (public) float | ⊙---> and_another_one()          |
-				+-------------------------------------------------------------------------------+
-
-					            do_something(integer[2])
-					+---------------------------------------------+
-					| Name    | Kind    | Type             | Link |
-					+---------------------------------------------+
-					| x       | Param   | integer[2]       | ____ |
-					| result  | VarDecl | float            | ____ |
-					| result2 | VarDecl | integer[2][4][5] | ____ |
-					+---------------------------------------------+
-
-					       do_something(integer)
-					+-------------------------------+
-					| Name | Kind  | Type    | Link |
-					+-------------------------------+
-					| x    | Param | integer | ____ |
-					+-------------------------------+
-
-					       and_another_one()
-					+---------------------------+
-					| Name | Kind | Type | Link |
-					+---------------------------+
-					+---------------------------+
-
-				         top_level()
-				+---------------------------+
-				| Name | Kind | Type | Link |
-				+---------------------------+
-				+---------------------------+
-
-				       top_level(integer)
-				+-------------------------------+
-				| Name | Kind  | Type    | Link |
-				+-------------------------------+
-				| x    | Param | integer | ____ |
-				+-------------------------------+
-
-				    top_level(integer, float)
-				+-------------------------------+
-				| Name | Kind  | Type    | Link |
-				+-------------------------------+
-				| x    | Param | integer | ____ |
-				| y    | Param | float   | ____ |
-				+-------------------------------+
+			                                          Global
+			+------------------------------------------------------------------------------------------+
+			| Name             | Kind       | Type | Size | Offset | Link                              |
+			+------------------------------------------------------------------------------------------+
+			| MyImplementation | ImplDef    | ____ | ____ | ____   | ⊙---> MyImplementation            |
+			| MyImplementation | StructDecl | ____ | ____ | ____   | ⊙---> MyImplementation            |
+			| top_level        | FuncDef    | void | ____ | ____   | ⊙---> top_level()                 |
+			| top_level        | FuncDef    | void | ____ | ____   | ⊙---> top_level(integer)          |
+			| top_level        | FuncDef    | void | ____ | ____   | ⊙---> top_level(integer, float)   |
+			+------------------------------------------------------------------------------------------+
+
+				                                        MyImplementation
+				+-----------------------------------------------------------------------------------------------+
+				| Name            | Kind    | Type           | Size | Offset | Link                             |
+				+-----------------------------------------------------------------------------------------------+
+				| do_something    | FuncDef | (public) void  | ____ | ____   | ⊙---> do_something(integer[2])   |
+				| do_something    | FuncDef | (public) void  | ____ | ____   | ⊙---> do_something(integer)      |
+				| and_another_one | FuncDef | (public) float | ____ | ____   | ⊙---> and_another_one()          |
+				+-----------------------------------------------------------------------------------------------+
+
+					                    do_something(integer[2])
+					+-------------------------------------------------------------+
+					| Name    | Kind    | Type             | Size | Offset | Link |
+					+-------------------------------------------------------------+
+					| x       | Param   | integer[2]       | ____ | ____   | ____ |
+					| result  | VarDecl | float            | ____ | ____   | ____ |
+					| result2 | VarDecl | integer[2][4][5] | ____ | ____   | ____ |
+					+-------------------------------------------------------------+
+
+					               do_something(integer)
+					+-----------------------------------------------+
+					| Name | Kind  | Type    | Size | Offset | Link |
+					+-----------------------------------------------+
+					| x    | Param | integer | ____ | ____   | ____ |
+					+-----------------------------------------------+
+
+					               and_another_one()
+					+-------------------------------------------+
+					| Name | Kind | Type | Size | Offset | Link |
+					+-------------------------------------------+
+					+-------------------------------------------+
+
+				                 top_level()
+				+-------------------------------------------+
+				| Name | Kind | Type | Size | Offset | Link |
+				+-------------------------------------------+
+				+-------------------------------------------+
+
+				               top_level(integer)
+				+-----------------------------------------------+
+				| Name | Kind  | Type    | Size | Offset | Link |
+				+-----------------------------------------------+
+				| x    | Param | integer | ____ | ____   | ____ |
+				+-----------------------------------------------+
+
+				            top_level(integer, float)
+				+-----------------------------------------------+
+				| Name | Kind  | Type    | Size | Offset | Link |
+				+-----------------------------------------------+
+				| x    | Param | integer | ____ | ____   | ____ |
+				| y    | Param | float   | ____ | ____   | ____ |
+				+-----------------------------------------------+
 			duplicate definition for 'do_something' (defined on line 9, and again on line 10)
 			duplicate definition for 'do_something' (defined on line 19, and again on line 20)
 			'MyImplementation::do_something' has been overloaded 2 times: do_something(integer[2]), do_something(integer)
@@ -607,10 +762,10 @@ func TestParseWithStatementCloserErrors(t *testing.T) {
 	var (
 		expectedValid  = false
 		expectedErrors = strings.TrimLeft(strings.ReplaceAll(`
-		Syntax error on line 4, column 8: unexpected token 'opencubr', should be 'id'
+		Syntax error on line 4, column 8: unexpected token 'opencubr', should be 'id': inserted 'id'
 		Syntax error on line 9, column 16: unexpected token 'id', should be 'inherits', or 'opencubr'
-		Syntax error on line 12, column 2: unexpected token 'let', should be 'closecubr', 'private', or 'public'
-		Syntax error on line 18, column 2: unexpected token 'func', should be 'closecubr', 'private', or 'public'
+		Syntax error on line 12, column 2: unexpected token 'let', should be 'closecubr', 'private', or 'public': inserted 'private'
+		Syntax error on line 18, column 2: unexpected token 'func', should be 'closecubr', 'private', or 'public': inserted 'private'
 		Syntax error on line 41, column 3: unexpected token 'opencubr', should be 'arrow'
 		`, "\t", ""), "\n")
 	)
@@ -639,6 +794,496 @@ func TestParseWithStatementCloserErrors(t *testing.T) {
 	}
 }
 
+// Tests the repairs of syntax errors that can be fixed by inserting or deleting
+// a token. The AST is still built for these errors
+func TestParseWithRepairs(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		src    string
+		errors string
+		ast    string // A line of the printed AST
+	}{
+		{
+			name: "missing semicolon",
+			src: `
+			func main() -> void {
+				let x: integer;
+				x = 1
+				write(x);
+			}`,
+			errors: "Syntax error on line 5, column 5: " +
+				"unexpected token 'write', should be 'and', 'closepar', 'closesqbr', " +
+				"'comma', 'div', 'eq', 'geq', 'gt', 'leq', 'lt', 'minus', 'mult', " +
+				"'noteq', 'or', 'plus', or 'semi': inserted ';'",
+			ast: "| | | | Write",
+		},
+		{
+			name: "extra token",
+			src: `
+			func main() -> void {
+				write(1 2);
+			}`,
+			errors: "Syntax error on line 3, column 13: " +
+				"unexpected token 'intnum', should be 'and', 'closepar', 'closesqbr', " +
+				"'comma', 'div', 'eq', 'geq', 'gt', 'leq', 'lt', 'minus', 'mult', " +
+				"'noteq', 'or', 'plus', or 'semi': deleted '2'",
+			ast: "| | | | | | | IntNum: Token[Id=intnum, Lexeme=1, Line=3, Column=11]",
+		},
+		{
+			name: "missing identifier",
+			src: `
+			func main() -> void {
+				let : integer;
+			}`,
+			errors: "Syntax error on line 3, column 9: unexpected token 'colon', should be 'id': inserted 'id'",
+			ast:    "| | | | | Error: Token[Id=id, Lexeme=, Line=3, Column=9]",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			prsr, errs := createErrorLoggingParser(tc.src)
+			if prsr.Parse() {
+				t.Fatalf("Expected the parse to fail")
+			}
+			if expected, actual := tc.errors, strings.TrimSpace(errs()); expected != actual {
+				t.Errorf("\nExpected errors:\n%v\nBut got:\n%v", expected, actual)
+			}
+			ast := prsr.AST()
+			if ast.Root == nil {
+				t.Fatalf("Expected the AST to be built after a repair")
+			}
+			if tree := ast.TreeString(); !strings.Contains(tree, tc.ast+"\n") {
+				t.Errorf("Expected the AST to contain:\n%v\nBut got:\n%v", tc.ast, tree)
+			}
+		})
+	}
+}
+
+// After a syntax error that can't be repaired, the AST is still built. The
+// broken statement is wrapped in an Error node, and the visitors check the rest
+// of the program
+func TestParsePartialAST(t *testing.T) {
+	t.Parallel()
+	prsr, _ := createErrorLoggingParser(`
+	func broken() -> void {
+		let x: integer;
+		x = = ) 1 2 3;
+		write(x);
+	}
+
+	func main() -> void {
+		write(y);
+	}`)
+	if prsr.Parse() {
+		t.Fatalf("Expected the parse to fail")
+	}
+
+	ast := prsr.AST()
+	if ast.Root == nil || ast.Root.Type != token.FINAL_PROG {
+		t.Fatalf("Expected a partial AST but got %v", ast.Root)
+	}
+	tree := ast.TreeString()
+	for _, line := range []string{
+		"| | | | Error\n| | | | | Assign(=)",
+		"| | | | | | | | Error: Token[Id=intnum, Lexeme=, Line=4, Column=9]",
+		"| | | | Write",
+		"| | | | | | | | Id: Token[Id=id, Lexeme=y, Line=9, Column=9]",
+	} {
+		if !strings.Contains(tree, line+"\n") {
+			t.Errorf("Expected the AST to contain:\n%v\nBut got:\n%v", line, tree)
+		}
+	}
+
+	errs := make([]string, 0, 1)
+	report := func(e *visitors.VisitorError) { errs = append(errs, e.Error()) }
+	ast.Root.Accept(visitors.NewSymTabVisitor(report))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(report))
+	expected := []string{"typecheck: id y was not found within the current scope (line 9)"}
+	if fmt.Sprint(errs) != fmt.Sprint(expected) {
+		t.Errorf("Expected semantic errors %v but got %v", expected, errs)
+	}
+}
+
+// If the input ends in the middle of a declaration, only that declaration is
+// wrapped in an Error node, the declarations before it are kept
+func TestParseTruncatedAST(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		src    string
+		errors string
+	}{
+		{
+			name: "after a syntax error",
+			src:  "func main() -> void {\n\twrite(y);\n}\nfunc broken( -> {\n",
+			errors: "" +
+				"Syntax error on line 4, column 14: unexpected token 'arrow', should be 'closepar', or 'id'\n" +
+				"Syntax error on line 4, column 17: got EOF in the middle of a sentence, " +
+				"expected to find symbols for [closepar id]",
+		},
+		{
+			name: "after a token",
+			src:  "func main() -> void {\n\twrite(y);\n}\nfunc broken(",
+			errors: "Syntax error on line 4, column 12: got EOF in the middle of a sentence, " +
+				"expected to find symbols for [closepar id]",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			prsr, errs := createErrorLoggingParser(tc.src)
+			if prsr.Parse() {
+				t.Fatalf("Expected the parse to fail")
+			}
+			if expected, actual := tc.errors, strings.TrimSpace(errs()); expected != actual {
+				t.Errorf("\nExpected errors:\n%v\nBut got:\n%v", expected, actual)
+			}
+
+			ast := prsr.AST()
+			if ast.Root == nil || ast.Root.Type != token.FINAL_PROG {
+				t.Fatalf("Expected a partial AST but got %v", ast.Root)
+			}
+			tree := ast.TreeString()
+			for _, line := range []string{
+				"| | FuncDef\n| | | Id: Token[Id=id, Lexeme=main, Line=1, Column=6]",
+				"| | Error\n| | | FuncDef\n| | | | Id: Token[Id=id, Lexeme=broken, Line=4, Column=6]",
+			} {
+				if !strings.Contains(tree, line+"\n") {
+					t.Errorf("Expected the AST to contain:\n%v\nBut got:\n%v", line, tree)
+				}
+			}
+
+			semErrs := make([]string, 0, 1)
+			report := func(e *visitors.VisitorError) { semErrs = append(semErrs, e.Error()) }
+			ast.Root.Accept(visitors.NewSymTabVisitor(report))
+			ast.Root.Accept(visitors.NewSemCheckVisitor(report))
+			expected := []string{"typecheck: id y was not found within the current scope (line 2)"}
+			if fmt.Sprint(semErrs) != fmt.Sprint(expected) {
+				t.Errorf("Expected semantic errors %v but got %v", expected, semErrs)
+			}
+		})
+	}
+}
+
+// The visitors must not panic on the partial ASTs of broken sources. Every
+// source is truncated after each of its words, and has each of its lines
+// deleted
+func TestPartialASTNoPanic(t *testing.T) {
+	t.Parallel()
+	files, err := filepath.Glob("resources/src/*.src")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to find the sources: %v", err)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read %v: %v", file, err)
+			}
+			for i := range src {
+				if i > 0 && isSpace(src[i]) && !isSpace(src[i-1]) {
+					assertPartialASTNoPanic(t, fmt.Sprintf("truncated at byte %v", i), string(src[:i]))
+				}
+			}
+			lines := strings.SplitAfter(string(src), "\n")
+			for i := range lines {
+				mutated := strings.Join(lines[:i], "") + strings.Join(lines[i+1:], "")
+				assertPartialASTNoPanic(t, fmt.Sprintf("without line %v", i+1), mutated)
+			}
+		})
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// Runs the visitors on the AST of the source as the CLI does, the
+// MemoryLayoutVisitor only runs if the source parses
+func assertPartialASTNoPanic(t *testing.T, mutation, src string) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("Panic on the source %v: %v\n%v", mutation, r, src)
+		}
+	}()
+	prsr, _ := createErrorLoggingParser(src)
+	ok := prsr.Parse()
+	ast := prsr.AST()
+	if ast.Root == nil || ast.Root.Type == token.FINAL_ERROR {
+		return
+	}
+	ignore := func(e *visitors.VisitorError) {}
+	ast.Root.Accept(visitors.NewSymTabVisitor(ignore))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(ignore))
+	if ok {
+		ast.Root.Accept(visitors.NewMemoryLayoutVisitor(ignore))
+	}
+}
+
+// Even if the semantic actions can't build a tree, the AST has an Error root
+func TestParseUnrecoverableAST(t *testing.T) {
+	t.Parallel()
+	prsr, _ := createErrorLoggingParser("func ) ) ) let")
+	if prsr.Parse() {
+		t.Fatalf("Expected the parse to fail")
+	}
+	if root := prsr.AST().Root; root == nil {
+		t.Fatalf("Expected the AST to have a root")
+	}
+}
+
+// Printing the CST gives back the exact source, with or without syntax errors
+func TestCSTRoundTrip(t *testing.T) {
+	t.Parallel()
+	files, err := filepath.Glob("resources/src/*.src")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No source files found: %v", err)
+	}
+	sources := map[string]string{
+		"polynomial":   testutils.POLYNOMIAL_SRC,
+		"bubblesort":   testutils.BUBBLESORT_SRC,
+		"empty":        "",
+		"whitespace":   " \t\r\n ",
+		"crlf":         "func main() -> void {\r\n\twrite(1);\r\n}\r\n",
+		"trailing":     "func main() -> void { } // no newline",
+		"unterminated": "func main() -> void { /* never closed",
+		"lexical":      "func main() -> void {\n\twrite(01 @ \"s t\");\n}\n",
+		"syntax":       "func main() -> void {\n\tlet x integer;\n\tx = = ) 1;\n}\n",
+		"truncated":    "func main() -> void {\n\tlet x: integer;\n",
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[filepath.Base(file)] = string(src)
+	}
+
+	for name, src := range sources {
+		name, src := name, src
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			prsr := createCSTParser(src)
+			prsr.Parse()
+			if actual := prsr.CST().String(); actual != src {
+				t.Errorf("Expected the CST to print:\n%q\nBut got:\n%q", src, actual)
+			}
+		})
+	}
+}
+
+// Every token and every run of trivia is attached to the CST
+func TestCSTTrivia(t *testing.T) {
+	t.Parallel()
+	prsr := createCSTParser("// main\nfunc main() -> void {\n\twrite(1); /* one */\n}\n")
+	if !prsr.Parse() {
+		t.Fatalf("Expected the parse to succeed")
+	}
+	expected := []string{
+		`| | | | | | ~ inlinecmt "// main\n"`,
+		`| | | | | | func: "func"`,
+		`| | | | | | | ~ whitespace " "`,
+		`| | | | | | | id: "main"`,
+		`| | | | | | | | | ~ whitespace "\n\t"`,
+		`| | | | | | | | | write: "write"`,
+		`| | | | | | | | | | | | | | intnum: "1"`,
+		`| | | | | | ~ whitespace " "`,
+		`| | | | | | ~ blockcmt "/* one */"`,
+		`| | | | | | ~ whitespace "\n"`,
+		`| | | | | | closecubr: "}"`,
+		`~ whitespace "\n"`,
+	}
+	tree := prsr.CST().TreeString()
+	for _, line := range expected {
+		if !strings.Contains(tree, line+"\n") {
+			t.Errorf("Expected the CST to contain:\n%v\nBut got:\n%v", line, tree)
+		}
+	}
+}
+
+// The JSON and s-expression schemas keep every node, token, and position of
+// the AST
+func TestASTSchemaRoundTrip(t *testing.T) {
+	t.Parallel()
+	for name, src := range map[string]string{
+		"polynomial": testutils.POLYNOMIAL_SRC,
+		"bubblesort": testutils.BUBBLESORT_SRC,
+		"strings":    "func main() -> void {\n\twrite(\"a (string) with spaces\");\n}",
+	} {
+		name, src := name, src
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			prsr := createParser(src)
+			if !prsr.Parse() {
+				t.Fatalf("Expected the parse to succeed")
+			}
+			ast := prsr.AST()
+			expected := ast.TreeString()
+
+			for format, codec := range map[string]struct {
+				print  func(io.Writer) error
+				decode func(io.Reader) (token.AST, error)
+			}{
+				"json":  {ast.PrintJson, token.DecodeASTJson},
+				"sexpr": {ast.PrintSexpr, token.DecodeASTSexpr},
+			} {
+				out := new(bytes.Buffer)
+				if err := codec.print(out); err != nil {
+					t.Fatalf("Failed to print the %v AST: %v", format, err)
+				}
+				decoded, err := codec.decode(out)
+				if err != nil {
+					t.Fatalf("Failed to decode the %v AST: %v", format, err)
+				}
+				if actual := decoded.TreeString(); actual != expected {
+					t.Errorf("Expected the %v AST to decode as:\n%v\nBut got:\n%v", format, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestASTSchemaErrors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		decode func(io.Reader) (token.AST, error)
+		input  string
+		err    string
+	}{
+		{
+			name:   "json version",
+			decode: token.DecodeASTJson,
+			input:  `{"version": 2, "root": null}`,
+			err:    "unsupported AST schema version 2, should be 1",
+		},
+		{
+			name:   "json missing type",
+			decode: token.DecodeASTJson,
+			input:  `{"version": 1, "root": {"children": []}}`,
+			err:    "AST node is missing its type",
+		},
+		{
+			name:   "sexpr version",
+			decode: token.DecodeASTSexpr,
+			input:  `(ast 2)`,
+			err:    "invalid AST s-expression at offset 6: unsupported AST schema version 2, should be 1",
+		},
+		{
+			name:   "sexpr unbalanced",
+			decode: token.DecodeASTSexpr,
+			input:  `(ast 1 (node "Prog" (node "Id" (token "id" "x" 1 1)))`,
+			err:    "invalid AST s-expression at offset 53: expected ')'",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tc.decode(strings.NewReader(tc.input))
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Expected the error %q but got %v", tc.err, err)
+			}
+		})
+	}
+
+	// An empty AST is valid
+	if ast, err := token.DecodeASTSexpr(strings.NewReader("(ast 1)")); err != nil || ast.Root != nil {
+		t.Errorf("Expected an empty AST but got %v, %v", ast.Root, err)
+	}
+}
+
+func TestDot(t *testing.T) {
+	t.Parallel()
+	src := `
+	struct SHAPE {
+		public func area() -> float;
+	};
+	struct SQUARE inherits SHAPE {
+		private let side: float;
+	};
+	impl SHAPE { func area() -> float { return (0.0); } }
+	func main() -> void {
+		let sq: SQUARE;
+	}`
+	rules := make([]token.Rule, 0, 128)
+	prsr := tabledrivenparser.NewParserNoComments(
+		tabledrivenscanner.NewScanner(
+			chuggingcharsource.MustChuggingReader(bytes.NewBufferString(src)),
+			scannertable.TABLE()),
+		parsertable.TABLE(), nil,
+		func(r token.Rule) { rules = append(rules, r) }, token.Comments()...)
+	if !prsr.Parse() {
+		t.Fatalf("Expected the parse to succeed")
+	}
+	ast := prsr.AST()
+	ast.Root.Accept(visitors.NewSymTabVisitor(nil))
+
+	tokens := scanner.NewLoadableScanner(scanner.IgnoringComments(
+		tabledrivenscanner.NewScanner(
+			chuggingcharsource.MustChuggingReader(bytes.NewBufferString(src)),
+			scannertable.TABLE()),
+		token.Comments()...)).Tokens()
+
+	astDot, tablesDot, derivationDot := new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+	ast.PrintDot(astDot)
+	token.WriteSymbolTablesDot(tablesDot, ast.Root.Meta.SymbolTable)
+	token.WriteDerivationDot(derivationDot, rules, tokens)
+
+	for _, tc := range []struct {
+		name     string
+		dot      string
+		expected []string
+	}{
+		{
+			name: "ast",
+			dot:  astDot.String(),
+			expected: []string{
+				"digraph AST {",
+				`n0 [label="Prog"];`,
+				`[label="Id\n'SQUARE' 5:9"];`,
+				`[label="FloatNum\n'0.0' 8:46"];`,
+			},
+		},
+		{
+			name: "symbol tables",
+			dot:  tablesDot.String(),
+			expected: []string{
+				"digraph SymbolTables {",
+				`t0 [label="table: Global\nclass SHAPE (line 2)\lclass SQUARE (line 5)\limpl SHAPE (line 8)\lfunction main ():void (line 9)\l"];`,
+				`t2 [label="table: SQUARE\ndata side: float private (line 6)\l"];`,
+				`t2 -> t1 [label="inherits", style=bold];`,
+				`t1 -> t0 [label="parent", style=dashed];`,
+				`t0 -> t3 [label="main"];`,
+			},
+		},
+		{
+			name: "derivation",
+			dot:  derivationDot.String(),
+			expected: []string{
+				"digraph Derivation {",
+				`d0 [label="<START>", shape=ellipse];`,
+				`[label="struct\n'struct' 2:2", shape=box];`,
+				`[label="id\n'main' 9:7", shape=box];`,
+				`[label="closecubr\n'}' 11:2", shape=box];`,
+			},
+		},
+	} {
+		for _, line := range tc.expected {
+			if !strings.Contains(tc.dot, line) {
+				t.Errorf("Expected the %v graph to contain:\n%v\nBut got:\n%v", tc.name, line, tc.dot)
+			}
+		}
+		if !strings.HasSuffix(tc.dot, "}\n") {
+			t.Errorf("Expected the %v graph to be closed but got:\n%v", tc.name, tc.dot)
+		}
+	}
+}
+
 // Tests parsing the `polynomial-with-errors-2.src` file
 func TestParsePolynomialWithErrors2Src(t *testing.T) {
 	t.Parallel()
@@ -2049,6 +2694,54 @@ func TestSingleScans(t *testing.T) {
 				Lexeme: "while",
 			},
 		},
+		{
+			name:  token.FOR,
+			input: "for",
+			output: token.Token{
+				Id:     token.FOR,
+				Lexeme: "for",
+			},
+		},
+		{
+			name:  token.BREAK,
+			input: "break",
+			output: token.Token{
+				Id:     token.BREAK,
+				Lexeme: "break",
+			},
+		},
+		{
+			name:  token.CONTINUE,
+			input: "continue",
+			output: token.Token{
+				Id:     token.CONTINUE,
+				Lexeme: "continue",
+			},
+		},
+		{
+			name:  token.BOOL,
+			input: "bool",
+			output: token.Token{
+				Id:     token.BOOL,
+				Lexeme: "bool",
+			},
+		},
+		{
+			name:  token.TRUE,
+			input: "true",
+			output: token.Token{
+				Id:     token.TRUE,
+				Lexeme: "true",
+			},
+		},
+		{
+			name:  token.FALSE,
+			input: "false",
+			output: token.Token{
+				Id:     token.FALSE,
+				Lexeme: "false",
+			},
+		},
 		{
 			name:  token.READ,
 			input: "read",
@@ -2129,6 +2822,22 @@ func TestSingleScans(t *testing.T) {
 				Lexeme: "1.0",
 			},
 		},
+		{
+			name:  token.STRINGLIT,
+			input: "\"hello\"",
+			output: token.Token{
+				Id:     token.STRINGLIT,
+				Lexeme: "\"hello\"",
+			},
+		},
+		{
+			name:  token.STRINGLIT + "[escapes]",
+			input: `"a\nb\"c\\"`,
+			output: token.Token{
+				Id:     token.STRINGLIT,
+				Lexeme: `"a\nb\"c\\"`,
+			},
+		},
 		{
 			name:  token.INVALIDNUM + "[00]",
 			input: "00",
@@ -2322,13 +3031,53 @@ func TestSingleScans(t *testing.T) {
 			},
 		},
 		{
-			name:  token.INVALIDCHAR + "[\"]",
+			name:  token.UNTERMINATEDSTRING + "[\"]",
 			input: "\"",
 			output: token.Token{
-				Id:     token.INVALIDCHAR,
+				Id:     token.UNTERMINATEDSTRING,
 				Lexeme: "\"",
 			},
 		},
+		{
+			name:  token.UNTERMINATEDSTRING + "[\"abc\\n]",
+			input: "\"abc\n\"",
+			output: token.Token{
+				Id:     token.UNTERMINATEDSTRING,
+				Lexeme: "\"abc",
+			},
+		},
+		{
+			name:  token.UNTERMINATEDSTRING + "[\"abc\\]",
+			input: "\"abc\\",
+			output: token.Token{
+				Id:     token.UNTERMINATEDSTRING,
+				Lexeme: "\"abc\\",
+			},
+		},
+		{
+			name:  token.UNTERMINATEDSTRING + "[\"a\\qb]",
+			input: "\"a\\qb\n\"",
+			output: token.Token{
+				Id:     token.UNTERMINATEDSTRING,
+				Lexeme: "\"a\\qb",
+			},
+		},
+		{
+			name:  token.INVALIDESCAPE + "[\"a\\qb\"]",
+			input: "\"a\\qb\";",
+			output: token.Token{
+				Id:     token.INVALIDESCAPE,
+				Lexeme: "\"a\\qb\"",
+			},
+		},
+		{
+			name:  token.INVALIDESCAPE + "[\"\\\\\\t\\\"\"]",
+			input: "\"\\\\\\t\\\"\"",
+			output: token.Token{
+				Id:     token.INVALIDESCAPE,
+				Lexeme: "\"\\\\\\t\\\"\"",
+			},
+		},
 	} {
 		tc := tc
 		t.Run(string(tc.name), func(t *testing.T) {
@@ -2408,6 +3157,16 @@ func createErrorLoggingParser(contents string) (
 	}
 }
 
+// Creates a parser in the lossless CST mode that consumes `contents` source code
+func createCSTParser(contents string) *tabledrivenparser.TableDrivenParser {
+	src := scanner.Recording(chuggingcharsource.MustChuggingReader(bytes.NewBufferString(contents)))
+	prsr := tabledrivenparser.NewParserNoComments(
+		tabledrivenscanner.NewScanner(src, scannertable.TABLE()),
+		parsertable.TABLE(), nil, nil, token.Comments()...)
+	prsr.RecordCST(src)
+	return prsr
+}
+
 func assertParse(t *testing.T, contents string, result bool) {
 	if createParser(contents).Parse() != result {
 		t.Fatalf("Parse should succeed, but it returned false")
@@ -2443,3 +3202,499 @@ func clean(in string, linePrefix string) string {
 			"\n"),
 		"\t")
 }
+
+func TestMemoryLayoutVisitor_StructsAndFrames(t *testing.T) {
+	t.Parallel()
+	assertMemoryLayoutOutput(t, `
+	struct A {
+		public let a: integer;
+	};
+
+	struct B inherits A {
+		public let b: float;
+		public let m: integer[2][3];
+	};
+
+	func f(x: integer, y: B, z: integer[]) -> integer {
+		let t: A;
+		return (x + 1);
+	}
+
+	func main() -> void {
+		let b: B;
+		write(f(1, b, b.m[0]));
+	}
+	`, `
+			                                       Global
+			+------------------------------------------------------------------------------------+
+			| Name | Kind       | Type    | Size | Offset | Link                                 |
+			+------------------------------------------------------------------------------------+
+			| A    | StructDecl | ____    | 4    | 0      | ⊙---> A                              |
+			| B    | StructDecl | ____    | 36   | 0      | ⊙---> B                              |
+			| f    | FuncDef    | integer | 64   | 0      | ⊙---> f(integer, B, integer[-666])   |
+			| main | FuncDef    | void    | 52   | 0      | ⊙---> main()                         |
+			+------------------------------------------------------------------------------------+
+
+				                              A
+				+----------------------------------------------------------+
+				| Name | Kind    | Type             | Size | Offset | Link |
+				+----------------------------------------------------------+
+				| a    | VarDecl | (public) integer | 4    | 0      | ____ |
+				+----------------------------------------------------------+
+
+				                                 B
+				+----------------------------------------------------------------+
+				| Name | Kind    | Type                   | Size | Offset | Link |
+				+----------------------------------------------------------------+
+				| b    | VarDecl | (public) float         | 8    | 4      | ____ |
+				| m    | VarDecl | (public) integer[2][3] | 24   | 12     | ____ |
+				+----------------------------------------------------------------+
+
+				                    f(integer, B, integer[-666])
+				+------------------------------------------------------------------+
+				| Name   | Kind        | Type          | Size | Offset | Link      |
+				+------------------------------------------------------------------+
+				| return | ReturnValue | integer       | 4    | 4      | ____      |
+				| x      | Param       | integer       | 4    | 8      | ____      |
+				| y      | Param       | B             | 36   | 12     | ____      |
+				| z      | Param       | integer[-666] | 4    | 48     | ____      |
+				| t      | VarDecl     | A             | 4    | 52     | ⊙---> A   |
+				| $t1    | TempVar     | integer       | 4    | 56     | ____      |
+				| $t2    | TempVar     | integer       | 4    | 60     | ____      |
+				+------------------------------------------------------------------+
+
+				                         main()
+				+------------------------------------------------------+
+				| Name | Kind    | Type    | Size | Offset | Link      |
+				+------------------------------------------------------+
+				| b    | VarDecl | B       | 36   | 4      | ⊙---> B   |
+				| $t1  | TempVar | integer | 4    | 40     | ____      |
+				| $t2  | TempVar | integer | 4    | 44     | ____      |
+				| $t3  | TempVar | integer | 4    | 48     | ____      |
+				+------------------------------------------------------+
+	`)
+}
+
+func TestMemoryLayoutVisitor_RecursiveStruct(t *testing.T) {
+	t.Parallel()
+	assertMemoryLayoutOutput(t, `
+	struct A {
+		public let a: A;
+	};
+
+	func main() -> void {}
+	`, `
+			                           Global
+			+-----------------------------------------------------------+
+			| Name | Kind       | Type | Size | Offset | Link           |
+			+-----------------------------------------------------------+
+			| A    | StructDecl | ____ | ____ | ____   | ⊙---> A        |
+			| main | FuncDef    | void | 4    | 0      | ⊙---> main()   |
+			+-----------------------------------------------------------+
+
+				                             A
+				+---------------------------------------------------------+
+				| Name | Kind    | Type       | Size | Offset | Link      |
+				+---------------------------------------------------------+
+				| a    | VarDecl | (public) A | ____ | ____   | ⊙---> A   |
+				+---------------------------------------------------------+
+
+				                   main()
+				+-------------------------------------------+
+				| Name | Kind | Type | Size | Offset | Link |
+				+-------------------------------------------+
+				+-------------------------------------------+
+			layout: struct 'A' contains itself (line 3)
+	`)
+}
+
+func assertMemoryLayoutOutput(t *testing.T, input, output string) {
+	output = clean(output, "			")
+	prsr, errs := createErrorLoggingParser(input)
+	if !prsr.Parse() {
+		t.Fatalf("Parse failed: %v", errs())
+	}
+
+	visitorOut, visitorErr := new(bytes.Buffer), new(bytes.Buffer)
+	err := util.Logback[*visitors.VisitorError]
+	ast := prsr.AST()
+	ast.Root.Accept(visitors.NewSymTabVisitor(err(visitorErr)))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(err(visitorErr)))
+	ast.Root.Accept(visitors.NewMemoryLayoutVisitor(err(visitorErr)))
+	token.WritePrettySymbolTable(visitorOut, ast.Root.Meta.SymbolTable)
+
+	if expected, actual := output, visitorOut.String()+visitorErr.String(); expected != actual {
+		t.Fatalf("\nExpected output:\n%v\n\nActual output:\n%v", expected, actual)
+	}
+}
+
+func TestCodeGenVisitor_Simple(t *testing.T) {
+	t.Parallel()
+	assertCodeGenOutput(t, `
+	func main() -> void {
+		let x: integer;
+		x = 1 + 2;
+		write(x);
+	}
+	`, `
+	% generated by esac
+	                entry
+	                addi    r14, r0, stack
+	                jl      r15, f_main
+	                hlt
+
+	% func main() -> void
+	f_main          sw      0(r14), r15
+	                addi    r1, r14, 4
+	                sw      20(r14), r1
+	                addi    r1, r0, 1
+	                sw      8(r14), r1
+	                addi    r1, r0, 2
+	                sw      12(r14), r1
+	                lw      r1, 8(r14)
+	                lw      r2, 12(r14)
+	                add     r3, r1, r2
+	                sw      16(r14), r3
+	                lw      r1, 16(r14)
+	                lw      r2, 20(r14)
+	                sw      0(r2), r1
+	                addi    r1, r14, 4
+	                sw      24(r14), r1
+	                lw      r1, 24(r14)
+	                lw      r1, 0(r1)
+	                sw      28(r14), r1
+	                lw      r1, 28(r14)
+	                jl      r15, putint
+	                lw      r15, 0(r14)
+	                jr      r15
+	`, ``)
+}
+
+func TestCodeGenVisitor_FloatOutOfRange(t *testing.T) {
+	t.Parallel()
+	assertCodeGenOutput(t, `
+	func main() -> void {
+		write(3000000.0);
+	}
+	`, ``, `
+	codegen: float literal 3000000.0 is out of range (line 3)
+	`)
+}
+
+func TestCodeGenVisitor_NoMain(t *testing.T) {
+	t.Parallel()
+	assertCodeGenOutput(t, `
+	func notmain() -> void {}
+	`, ``, `
+	codegen: no 'main' function has been defined
+	`)
+}
+
+// Tests that the compiled `bubblesort-sorted.src` actually sorts its array. It
+// is `bubblesort.src` with the inner loop counter reset on every pass
+func TestCodeGenVisitor_RunBubbleSortSrc(t *testing.T) {
+	t.Parallel()
+	src, err := os.ReadFile("resources/src/bubblesort-sorted.src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertProgramOutput(t, string(src), "", `
+	64
+	34
+	25
+	12
+	22
+	11
+	90
+	11
+	12
+	22
+	25
+	34
+	64
+	90
+	`)
+}
+
+func TestCodeGenVisitor_Run(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		input  string
+		src    string
+		output string
+	}{
+		{
+			name: "recursion",
+			src: `
+			func fib(n: integer) -> integer {
+				if (n < 2) then { return (n); } else { return (fib(n - 1) + fib(n - 2)); };
+			}
+			func main() -> void { write(fib(12)); }
+			`,
+			output: "144",
+		},
+		{
+			name: "negative numbers and operators",
+			src: `
+			func main() -> void {
+				write(-7 / 2);
+				write(3 - 10 * 2);
+				write((6 > 0) & (0 > 1) | (5 > 0));
+				write(!false);
+				write(2 <> 3);
+			}
+			`,
+			output: "-3\n-17\ntrue\ntrue\ntrue",
+		},
+		{
+			name: "strings",
+			src: `
+			func greet(name: string) -> string { return (name); }
+			func main() -> void {
+				let s: string; let words: string[2];
+				s = "say \"hi\"\\";
+				words[1] = greet(s);
+				write("hello");
+				write(words[1]);
+				write(words[0]);
+				write(7);
+			}
+			`,
+			output: "hello\nsay \"hi\"\\\n\n7",
+		},
+		{
+			name: "for loops, break, and continue",
+			src: `
+			func main() -> void {
+				let n: integer; let j: integer;
+				n = 5;
+				for (let i: integer = 0; i < n; i = i + 1) {
+					if (i == 1) then continue; else ;
+					if (i == 4) then break; else ;
+					write(i);
+				};
+				for (j = 10; j > 8; j = j - 1) write(j);;
+				while (n > 0) {
+					n = n - 1;
+					if (n == 2) then break; else ;
+				};
+				write(n);
+			}
+			`,
+			output: "0\n2\n3\n10\n9\n2",
+		},
+		{
+			name: "sequential and nested for loops",
+			src: `
+			func main() -> void {
+				let total: integer;
+				for (let i: integer = 0; i < 2; i = i + 1) write(i);;
+				for (let i: float = 0.5; i < 2.0; i = i + 1.0) write(i);;
+				for (let i: integer = 1; i <= 3; i = i + 1) {
+					for (let j: integer = 1; j <= i; j = j + 1) total = total + i * j;;
+					for (let j: integer = 0; j < 2; j = j + 1) total = total + 100;;
+				};
+				write(total);
+			}
+			`,
+			output: "0\n1\n0.5\n1.5\n625",
+		},
+		{
+			name: "else-less if and else if chains",
+			src: `
+			func sign(x: integer) -> integer {
+				if (x < 0) then return (-1);
+				else if (x == 0) then return (0);
+				else return (1);;
+			}
+			func main() -> void {
+				if (1 < 2) then write(1);;
+				if (2 < 1) then write(2);;
+				if (1 > 2) then { write(3); } else if (1 < 2) then { write(4); };
+				if (1 > 1) then if (1 > 0) then write(5); else write(6);;;
+				write(sign(-7)); write(sign(0)); write(sign(7));
+			}
+			`,
+			output: "1\n4\n-1\n0\n1",
+		},
+		{
+			name: "bools",
+			src: `
+			func flip(b: bool) -> bool { return (!b); }
+			func main() -> void {
+				let b: bool; let bs: bool[2];
+				b = (1 < 2) & !(3 == 4);
+				bs[1] = flip(b);
+				write(b); write(bs[1]); write(bs[0] == false);
+				while (b) { b = false; };
+				if (b | (2 >= 2)) then write(true);;
+			}
+			`,
+			output: "true\nfalse\ntrue\ntrue",
+		},
+		{
+			name: "multi-dimensional arrays",
+			src: `
+			func main() -> void {
+				let m: integer[3][4]; let i: integer; let j: integer; let sum: integer;
+				i = 0;
+				while (i < 3) {
+					j = 0;
+					while (j < 4) { m[i][j] = i * j; j = j + 1; };
+					i = i + 1;
+				};
+				sum = 0; i = 0;
+				while (i < 3) { sum = sum + m[i][3]; i = i + 1; };
+				write(sum);
+				write(m[2][2]);
+			}
+			`,
+			output: "9\n4",
+		},
+		{
+			name: "structs, methods, and inheritance",
+			src: `
+			struct COUNTER {
+				private let n: integer;
+				public func inc() -> void;
+				public func get() -> integer;
+			};
+			struct NAMED inherits COUNTER {
+				public let id: integer;
+			};
+			impl COUNTER {
+				func inc() -> void { n = n + 1; }
+				func get() -> integer { return (n); }
+			}
+			func make(id: integer) -> NAMED {
+				let x: NAMED;
+				x.id = id;
+				return (x);
+			}
+			func main() -> void {
+				let a: NAMED;
+				a = make(7);
+				a.inc(); a.inc(); a.inc();
+				write(a.get());
+				write(a.id);
+				write(make(5).id);
+			}
+			`,
+			output: "3\n7\n5",
+		},
+		{
+			name:  "read",
+			input: "  12\n-30\n",
+			src: `
+			func main() -> void {
+				let x: integer; let y: integer;
+				read(x); read(y);
+				write(x + y);
+			}
+			`,
+			output: "-18",
+		},
+		{
+			name:  "fixed point floats",
+			input: " -2.125\n",
+			src: `
+			struct LINEAR {
+				public let a: float;
+				public let b: float;
+				public func evaluate(x: float) -> float;
+			};
+			impl LINEAR {
+				func evaluate(x: float) -> float { return (a * x + b); }
+			}
+			func half(x: float) -> float { return (x / 2.0); }
+			func main() -> void {
+				let f: LINEAR; let x: float; let fs: float[2];
+				f.a = 2.0; f.b = -0.25;
+				x = 1.0;
+				while (x <= 3.0) { write(f.evaluate(x)); x = x + 1.5; };
+				write(half(7.0)); write(-x * 0.5);
+				fs[1] = 1.0 / 8.0; write(fs[1]); write(fs[0]);
+				write(0.05 < 0.5);
+				read(x); write(x);
+			}
+			`,
+			output: "1.75\n4.75\n3.5\n-2\n0.125\n0\ntrue\n-2.125",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assertProgramOutput(t, tc.src, tc.input, tc.output)
+		})
+	}
+}
+
+// Asserts the code generated for a program, up until the runtime routines that
+// are appended to every program
+func assertCodeGenOutput(t *testing.T, input, code, errors string) {
+	code, errors = clean(code, "	"), clean(errors, "	")
+	prsr, errs := createErrorLoggingParser(input)
+	if !prsr.Parse() {
+		t.Fatalf("Parse failed: %v", errs())
+	}
+
+	visitorErr := new(bytes.Buffer)
+	err := util.Logback[*visitors.VisitorError]
+	ast := prsr.AST()
+	ast.Root.Accept(visitors.NewSymTabVisitor(err(visitorErr)))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(err(visitorErr)))
+	ast.Root.Accept(visitors.NewMemoryLayoutVisitor(err(visitorErr)))
+	if visitorErr.Len() > 0 {
+		t.Fatalf("Semantic analysis failed: %v", visitorErr)
+	}
+
+	out := new(bytes.Buffer)
+	ast.Root.Accept(visitors.NewCodeGenVisitor(out, err(visitorErr)))
+	actual, _, _ := strings.Cut(out.String(), "\n% runtime")
+	if strings.TrimSpace(actual) != strings.TrimSpace(code) {
+		t.Errorf("\nExpected code:\n%v\n\nActual code:\n%v", code, actual)
+	}
+	if expected, actual := errors, visitorErr.String(); expected != actual {
+		t.Errorf("\nExpected errors:\n%v\n\nActual errors:\n%v", expected, actual)
+	}
+}
+
+// Compiles a program and runs it on the MOON machine, asserting its output
+func assertProgramOutput(t *testing.T, input, stdin, output string) {
+	t.Helper()
+	prsr, errs := createErrorLoggingParser(input)
+	if !prsr.Parse() {
+		t.Fatalf("Parse failed: %v", errs())
+	}
+
+	visitorErr := new(bytes.Buffer)
+	err := util.Logback[*visitors.VisitorError]
+	ast := prsr.AST()
+	ast.Root.Accept(visitors.NewSymTabVisitor(err(visitorErr)))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(err(visitorErr)))
+	ast.Root.Accept(visitors.NewMemoryLayoutVisitor(err(visitorErr)))
+	code := new(bytes.Buffer)
+	ast.Root.Accept(visitors.NewCodeGenVisitor(code, err(visitorErr)))
+	if visitorErr.Len() > 0 {
+		t.Fatalf("Compilation failed: %v", visitorErr)
+	}
+
+	out := new(bytes.Buffer)
+	if e := moon.Run(code, strings.NewReader(stdin), out); e != nil {
+		t.Fatalf("Program failed: %v", e)
+	}
+	expected := strings.TrimSpace(testutils.TrimLeading(output, "\t"))
+	if actual := strings.TrimSpace(out.String()); actual != expected {
+		t.Errorf("\nExpected output:\n%v\n\nActual output:\n%v", expected, actual)
+	}
+
+	// The AST interpreter is the reference, the generated code must agree
+	oracle := new(bytes.Buffer)
+	if e := interp.Interpret(ast, strings.NewReader(stdin), oracle); e != nil {
+		t.Fatalf("Interpreter failed: %v", e)
+	}
+	if strings.TrimSpace(oracle.String()) != strings.TrimSpace(out.String()) {
+		t.Errorf("\nInterpreter output:\n%v\n\nMOON output:\n%v", oracle, out)
+	}
+}
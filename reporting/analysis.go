@@ -0,0 +1,64 @@
+package reporting
+
+import (
+	"github.com/obonobo/esac/core/scanner"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/tabledrivenscanner"
+	scannertable "github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/core/token/visitors"
+)
+
+// Scans and parses a single source, reporting lexical and syntax errors as
+// they are discovered. If ok is false an error was reported and the AST is not
+// usable, but it may be a partial AST in which the constructs that could not
+// be parsed are FINAL_ERROR nodes (see CheckSemantics)
+func ParseSource(src scanner.CharSource, report func(Diagnostic)) (ast token.AST, ok bool) {
+	failed := false
+	scnr := ReportLexicalErrors(
+		tabledrivenscanner.NewScanner(src, scannertable.TABLE()),
+		func(d Diagnostic) {
+			failed = true
+			report(d)
+		})
+	prsr := tabledrivenparser.NewParserNoDefaultComments(scnr, parsertable.TABLE(),
+		func(e *tabledrivenparser.ParserError) { report(SyntaxDiagnostic(e)) }, nil)
+	ok = prsr.Parse() && !failed
+	return prsr.AST(), ok
+}
+
+// Walks the AST with the SymTabVisitor and the SemCheckVisitor, reporting
+// semantic errors and warnings. The root of the AST is annotated with the
+// global symbol table.
+//
+// The AST may be a partial AST returned by ParseSource, so that semantic errors
+// are reported alongside the syntax errors. The visitors skip its FINAL_ERROR
+// nodes, nothing is checked if the root itself could not be parsed
+func CheckSemantics(ast token.AST, report func(Diagnostic)) {
+	if ast.Root == nil || ast.Root.Type == token.FINAL_ERROR {
+		return
+	}
+	semantic := func(e *visitors.VisitorError) { report(SemanticDiagnostic(e)) }
+	ast.Root.Accept(visitors.NewSymTabVisitor(semantic))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(semantic))
+}
+
+// Wraps the scanner so that the error tokens are reported as lexical
+// diagnostics as they are pulled from it
+func ReportLexicalErrors(scnr scanner.Scanner, report func(Diagnostic)) scanner.Scanner {
+	return &errorReportingScanner{Scanner: scnr, report: report}
+}
+
+type errorReportingScanner struct {
+	scanner.Scanner
+	report func(Diagnostic)
+}
+
+func (s *errorReportingScanner) NextToken() (token.Token, error) {
+	tok, err := s.Scanner.NextToken()
+	if err == nil && token.IsError(tok.Id) {
+		s.report(LexicalDiagnostic(tok))
+	}
+	return tok, err
+}
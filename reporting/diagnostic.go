@@ -0,0 +1,386 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/core/token/visitors"
+)
+
+// A stable identifier for a kind of diagnostic. Codes are never reused, new
+// kinds of diagnostics get new codes
+type Code string
+
+const (
+	CODE_UNKNOWN Code = "E0000"
+
+	// Lexical errors
+	CODE_INVALID_ID           Code = "E0001"
+	CODE_INVALID_CHAR         Code = "E0002"
+	CODE_INVALID_NUM          Code = "E0003"
+	CODE_UNTERMINATED_COMMENT Code = "E0004"
+	CODE_UNTERMINATED_STRING  Code = "E0005"
+	CODE_INVALID_ESCAPE       Code = "E0006"
+
+	// Syntax errors
+	CODE_SYNTAX           Code = "E0100"
+	CODE_UNEXPECTED_TOKEN Code = "E0101"
+	CODE_UNEXPECTED_EOF   Code = "E0102"
+
+	// Declaration errors, emitted by the SymTabVisitor
+	CODE_DUPLICATE_ID          Code = "E0201"
+	CODE_METHOD_MISMATCH       Code = "E0202"
+	CODE_STRUCT_MISSING_METHOD Code = "E0203"
+	CODE_IMPL_MISSING_METHOD   Code = "E0204"
+	CODE_IMPL_MEMBER           Code = "E0205"
+	CODE_STRUCT_MISSING_IMPL   Code = "E0206"
+	CODE_IMPL_MISSING_STRUCT   Code = "E0207"
+	CODE_CIRCULAR_INHERITANCE  Code = "E0208"
+	CODE_UNDEFINED_TYPE        Code = "E0209"
+	CODE_OVERLOADED            Code = "E0210"
+	CODE_SHADOWED_MEMBER       Code = "E0211"
+
+	// Type errors, emitted by the SemCheckVisitor
+	CODE_TYPECHECK        Code = "E0300"
+	CODE_NODE_KIND        Code = "E0301"
+	CODE_UNDEFINED_ID     Code = "E0302"
+	CODE_OPERAND_TYPES    Code = "E0303"
+	CODE_NO_MEMBERS       Code = "E0304"
+	CODE_NO_MATCHING_CALL Code = "E0305"
+	CODE_INDEX_COUNT      Code = "E0306"
+	CODE_INDEX_TYPE       Code = "E0307"
+	CODE_ASSIGN_TYPES     Code = "E0308"
+	CODE_RETURN_TYPE      Code = "E0309"
+	CODE_STRING_OPERAND   Code = "E0310"
+	CODE_OUTSIDE_LOOP     Code = "E0311"
+	CODE_CONDITION        Code = "E0312"
+	CODE_LOGICAL_OPERAND  Code = "E0313"
+	CODE_BOOL_OPERAND     Code = "E0314"
+
+	// Back end errors
+	CODE_MEMORY_LAYOUT Code = "E0401"
+	CODE_CODEGEN       Code = "E0501"
+)
+
+var descriptions = map[Code]string{
+	CODE_UNKNOWN:               "Unknown error",
+	CODE_INVALID_ID:            "Invalid identifier",
+	CODE_INVALID_CHAR:          "Invalid character",
+	CODE_INVALID_NUM:           "Invalid number",
+	CODE_UNTERMINATED_COMMENT:  "Unterminated comment",
+	CODE_UNTERMINATED_STRING:   "Unterminated string",
+	CODE_INVALID_ESCAPE:        "Invalid escape sequence",
+	CODE_SYNTAX:                "Syntax error",
+	CODE_UNEXPECTED_TOKEN:      "Unexpected token",
+	CODE_UNEXPECTED_EOF:        "Unexpected end of input",
+	CODE_DUPLICATE_ID:          "Duplicate definition",
+	CODE_METHOD_MISMATCH:       "Method definition does not match its declaration",
+	CODE_STRUCT_MISSING_METHOD: "Struct does not declare a method defined in its impl",
+	CODE_IMPL_MISSING_METHOD:   "Impl does not define a method declared in its struct",
+	CODE_IMPL_MEMBER:           "Impls may only contain function definitions",
+	CODE_STRUCT_MISSING_IMPL:   "Struct declares methods but has no impl",
+	CODE_IMPL_MISSING_STRUCT:   "Impl has no struct",
+	CODE_CIRCULAR_INHERITANCE:  "Circular inheritance",
+	CODE_UNDEFINED_TYPE:        "Undefined type",
+	CODE_OVERLOADED:            "Overloaded function",
+	CODE_SHADOWED_MEMBER:       "Shadowed member",
+	CODE_TYPECHECK:             "Type error",
+	CODE_NODE_KIND:             "Malformed AST",
+	CODE_UNDEFINED_ID:          "Undefined identifier",
+	CODE_OPERAND_TYPES:         "Operands have different types",
+	CODE_NO_MEMBERS:            "Value has no members",
+	CODE_NO_MATCHING_CALL:      "No definition matches the call",
+	CODE_INDEX_COUNT:           "Too many subscripts",
+	CODE_INDEX_TYPE:            "Subscript is not an integer",
+	CODE_ASSIGN_TYPES:          "Sides of the assignment have different types",
+	CODE_RETURN_TYPE:           "Return value has the wrong type",
+	CODE_STRING_OPERAND:        "Operator cannot be applied to strings",
+	CODE_OUTSIDE_LOOP:          "Break or continue outside of a loop",
+	CODE_CONDITION:             "Condition is not a bool",
+	CODE_LOGICAL_OPERAND:       "Operand of a logical operator is not a bool",
+	CODE_BOOL_OPERAND:          "Operator cannot be applied to bools",
+	CODE_MEMORY_LAYOUT:         "Size of a value cannot be determined",
+	CODE_CODEGEN:               "Program cannot be translated",
+}
+
+// A short, human readable description of the kind of diagnostic
+func (c Code) Description() string {
+	if d, ok := descriptions[c]; ok {
+		return d
+	}
+	return descriptions[CODE_UNKNOWN]
+}
+
+var lexicalCodes = map[token.Kind]Code{
+	token.INVALIDID:           CODE_INVALID_ID,
+	token.INVALIDCHAR:         CODE_INVALID_CHAR,
+	token.INVALIDNUM:          CODE_INVALID_NUM,
+	token.UNTERMINATEDCOMMENT: CODE_UNTERMINATED_COMMENT,
+	token.UNTERMINATEDSTRING:  CODE_UNTERMINATED_STRING,
+	token.INVALIDESCAPE:       CODE_INVALID_ESCAPE,
+}
+
+var typeCheckCodes = map[visitors.TypeCheck]Code{
+	visitors.CHECK_OTHER:            CODE_TYPECHECK,
+	visitors.CHECK_NODE_KIND:        CODE_NODE_KIND,
+	visitors.CHECK_UNDEFINED_ID:     CODE_UNDEFINED_ID,
+	visitors.CHECK_OPERANDS:         CODE_OPERAND_TYPES,
+	visitors.CHECK_NO_MEMBERS:       CODE_NO_MEMBERS,
+	visitors.CHECK_NO_MATCHING_CALL: CODE_NO_MATCHING_CALL,
+	visitors.CHECK_INDEX_COUNT:      CODE_INDEX_COUNT,
+	visitors.CHECK_INDEX_TYPE:       CODE_INDEX_TYPE,
+	visitors.CHECK_ASSIGN:           CODE_ASSIGN_TYPES,
+	visitors.CHECK_RETURN:           CODE_RETURN_TYPE,
+	visitors.CHECK_STRING_OPERAND:   CODE_STRING_OPERAND,
+	visitors.CHECK_LOOP:             CODE_OUTSIDE_LOOP,
+	visitors.CHECK_CONDITION:        CODE_CONDITION,
+	visitors.CHECK_LOGICAL_OPERAND:  CODE_LOGICAL_OPERAND,
+	visitors.CHECK_BOOL_OPERAND:     CODE_BOOL_OPERAND,
+}
+
+type Severity int
+
+const (
+	SEVERITY_ERROR Severity = iota
+	SEVERITY_WARNING
+)
+
+func (s Severity) String() string {
+	if s == SEVERITY_WARNING {
+		return "warning"
+	}
+	return "error"
+}
+
+// A location in the source. Lines and columns start at 1, a span with a zero
+// column covers its whole line, and a span with a zero line has no location
+type Span struct {
+	Line, Column int
+	Length       int // In characters
+}
+
+// The span of a token, tokens that cover multiple lines (block comments) are
+// cut off at the end of their first line
+func SpanOf(tok token.Token) Span {
+	if tok.Line == 0 {
+		return Span{}
+	}
+	first, _, _ := strings.Cut(string(tok.Lexeme), "\n")
+	return Span{
+		Line:   tok.Line,
+		Column: tok.Column,
+		Length: utf8.RuneCountInString(first),
+	}
+}
+
+func (s Span) Valid() bool {
+	return s.Line > 0
+}
+
+// Additional information attached to a diagnostic, optionally pointing to
+// another location in the source
+type Note struct {
+	Msg  string
+	Span Span
+}
+
+// A lexical, syntax, or semantic error, or a warning, that refers to a span of
+// the source
+type Diagnostic struct {
+	Code     Code
+	Severity Severity
+	Msg      string
+	Span     Span
+	Notes    []Note
+	Expected []string // The tokens that would have been valid, for syntax errors
+}
+
+func (d Diagnostic) Error() string {
+	return d.Msg
+}
+
+// Sorts diagnostics by the position of their primary span. The order of
+// diagnostics at the same position is preserved
+func SortDiagnostics(ds []Diagnostic) {
+	sort.SliceStable(ds, func(i, j int) bool {
+		a, b := ds[i].Span, ds[j].Span
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+}
+
+// Creates a diagnostic for an error token produced by the scanner
+func LexicalDiagnostic(tok token.Token) Diagnostic {
+	code, ok := lexicalCodes[tok.Id]
+	if !ok {
+		code = CODE_UNKNOWN
+	}
+	return Diagnostic{
+		Code:     code,
+		Severity: SEVERITY_ERROR,
+		Msg:      lexicalMessage(tok),
+		Span:     SpanOf(tok),
+	}
+}
+
+func lexicalMessage(tok token.Token) string {
+	lexeme := string(tok.Lexeme)
+	switch tok.Id {
+	case token.INVALIDID:
+		return fmt.Sprintf("invalid identifier '%v'", lexeme)
+	case token.INVALIDCHAR:
+		return fmt.Sprintf("invalid character '%v'", lexeme)
+	case token.INVALIDNUM:
+		return fmt.Sprintf("invalid number '%v'", lexeme)
+	case token.UNTERMINATEDCOMMENT:
+		return "unterminated comment"
+	case token.UNTERMINATEDSTRING:
+		return fmt.Sprintf("unterminated string %v", lexeme)
+	case token.INVALIDESCAPE:
+		return fmt.Sprintf("invalid escape sequence '%v' in string %v",
+			token.InvalidEscape(tok.Lexeme), lexeme)
+	}
+	return Errorify(tok)
+}
+
+// Creates a diagnostic for an error reported by the parser
+func SyntaxDiagnostic(e *tabledrivenparser.ParserError) Diagnostic {
+	d := Diagnostic{
+		Code:     CODE_SYNTAX,
+		Severity: SEVERITY_ERROR,
+		Msg:      "syntax error",
+		Span:     SpanOf(e.Tok),
+	}
+	if e.Err != nil {
+		d.Msg = "syntax error: " + e.Err.Error()
+	}
+	var unexpected *tabledrivenparser.UnexpectedTokenError
+	if errors.As(e, &unexpected) {
+		d.Code = CODE_UNEXPECTED_TOKEN
+		expected := unexpected.InsteadSlice
+		if len(expected) == 0 && unexpected.Instead != "" {
+			expected = []token.Kind{unexpected.Instead}
+		}
+		for _, kind := range expected {
+			d.Expected = append(d.Expected, string(kind))
+		}
+	}
+	var unterminated *tabledrivenparser.UnterminatedSentence
+	if errors.As(e, &unterminated) {
+		d.Code = CODE_UNEXPECTED_EOF
+		for _, kind := range unterminated.ExpectedSymbols {
+			d.Expected = append(d.Expected, string(kind))
+		}
+	}
+	return d
+}
+
+// Creates a diagnostic for an error or a warning emitted by one of the
+// visitors. The message is the same as the message of the error
+func SemanticDiagnostic(e *visitors.VisitorError) Diagnostic {
+	d := Diagnostic{
+		Code:     CODE_UNKNOWN,
+		Severity: SEVERITY_ERROR,
+		Msg:      e.Error(),
+	}
+	var warning *visitors.Warning
+	if errors.As(e, &warning) {
+		d.Severity = SEVERITY_WARNING
+	}
+
+	var (
+		duplicate     *visitors.DuplicateIdentifierError
+		mismatch      *visitors.MethodMismatchError
+		structMissing *visitors.StructMissingMethodFromImplError
+		implMissing   *visitors.ImplMissingMethodFromStructError
+		implMember    *visitors.ImplMayOnlyContainFuncDefsError
+		missingImpl   *visitors.StructMissingImplError
+		missingStruct *visitors.ImplMissingStructError
+		circular      *visitors.CircularInheritanceError
+		undefined     *visitors.UndefinedTypeError
+		overloaded    *visitors.OverloadedError
+		shadowed      *visitors.ShadowedMemberError
+		typeCheck     *visitors.TypeCheckError
+		layout        *visitors.MemoryLayoutError
+		codegen       *visitors.CodeGenError
+	)
+
+	switch {
+	case errors.As(e, &duplicate):
+		first, second := duplicate.First, duplicate.Second
+		if second.Line < first.Line {
+			first, second = second, first
+		}
+		name := duplicate.Name
+		if name == "" {
+			name = string(first.Lexeme)
+		}
+		d.Code, d.Span = CODE_DUPLICATE_ID, SpanOf(second)
+		d.Notes = append(d.Notes, Note{fmt.Sprintf("'%v' is first defined here", name), SpanOf(first)})
+	case errors.As(e, &mismatch):
+		d.Code, d.Span = CODE_METHOD_MISMATCH, SpanOf(mismatch.ImplMethod.Type.Token)
+		if !d.Span.Valid() {
+			d.Span = idSpan(mismatch.Struct)
+		}
+		d.Notes = append(d.Notes, Note{"declared in the struct here", SpanOf(mismatch.StructMethod.Type.Token)})
+	case errors.As(e, &structMissing):
+		d.Code, d.Span = CODE_STRUCT_MISSING_METHOD, SpanOf(structMissing.Method.Type.Token)
+		d.Notes = append(d.Notes, Note{"struct declared here", idSpan(structMissing.Node)})
+	case errors.As(e, &implMissing):
+		d.Code, d.Span = CODE_IMPL_MISSING_METHOD, idSpan(implMissing.Node)
+		d.Notes = append(d.Notes, Note{"method declared here", SpanOf(implMissing.Method.Type.Token)})
+	case errors.As(e, &implMember):
+		d.Code, d.Span = CODE_IMPL_MEMBER, idSpan(implMember.Impl)
+	case errors.As(e, &missingImpl):
+		d.Code, d.Span = CODE_STRUCT_MISSING_IMPL, idSpan(missingImpl.Struct)
+	case errors.As(e, &missingStruct):
+		d.Code, d.Span = CODE_IMPL_MISSING_STRUCT, idSpan(missingStruct.Impl)
+	case errors.As(e, &circular):
+		d.Code, d.Span = CODE_CIRCULAR_INHERITANCE, idSpan(circular.Struct)
+	case errors.As(e, &undefined):
+		d.Code, d.Span = CODE_UNDEFINED_TYPE, SpanOf(undefined.Type)
+	case errors.As(e, &overloaded):
+		d.Code = CODE_OVERLOADED
+		for i, overload := range overloaded.Overloads {
+			if i == len(overloaded.Overloads)-1 {
+				d.Span = SpanOf(overload.Type.Token)
+			} else {
+				d.Notes = append(d.Notes, Note{"also defined here", SpanOf(overload.Type.Token)})
+			}
+		}
+	case errors.As(e, &shadowed):
+		d.Code = CODE_SHADOWED_MEMBER
+		if len(shadowed.ChildMembers) > 0 {
+			d.Span = SpanOf(shadowed.ChildMembers[0].Type.Token)
+		}
+		for _, member := range shadowed.ParentMembers {
+			d.Notes = append(d.Notes, Note{
+				fmt.Sprintf("shadows the member of '%v' defined here", shadowed.Parent),
+				SpanOf(member.Type.Token),
+			})
+		}
+	case errors.As(e, &typeCheck):
+		d.Code, d.Span = typeCheckCodes[typeCheck.Check], SpanOf(typeCheck.Token)
+	case errors.As(e, &layout):
+		d.Code, d.Span = CODE_MEMORY_LAYOUT, SpanOf(layout.Token)
+	case errors.As(e, &codegen):
+		d.Code, d.Span = CODE_CODEGEN, SpanOf(codegen.Token)
+	}
+
+	if d.Code == "" {
+		d.Code = CODE_UNKNOWN
+	}
+	return d
+}
+
+// The span of the identifier of a declaration
+func idSpan(node *token.ASTNode) Span {
+	if node == nil || len(node.Children) == 0 {
+		return Span{}
+	}
+	return SpanOf(node.Children[0].Token)
+}
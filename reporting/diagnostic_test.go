@@ -0,0 +1,236 @@
+package reporting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/obonobo/esac/core/chuggingcharsource"
+	"github.com/obonobo/esac/core/tabledrivenparser"
+	parsertable "github.com/obonobo/esac/core/tabledrivenparser/compositetable"
+	"github.com/obonobo/esac/core/tabledrivenscanner"
+	scannertable "github.com/obonobo/esac/core/tabledrivenscanner/compositetable"
+	"github.com/obonobo/esac/core/token"
+	"github.com/obonobo/esac/core/token/visitors"
+)
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "lexical error",
+			src:  "func main() -> void {\n\tlet x: integer;\n\tx = 1 + 01;\n}",
+			expected: `
+error[E0003]: invalid number '01'
+ --> main.src:3:10
+  |
+3 |     x = 1 + 01;
+  |             ^^
+
+error[E0101]: syntax error: unexpected token 'invalidnum', should be 'false', 'floatnum', 'id', 'intnum', 'minus', 'not', 'openpar', 'plus', 'stringlit', or 'true'
+ --> main.src:3:10
+  |
+3 |     x = 1 + 01;
+  |             ^^
+`,
+		},
+		{
+			name: "unterminated string",
+			src:  "func main() -> void {\n  write(\"hi\n  );\n}",
+			expected: `
+error[E0005]: unterminated string "hi
+ --> main.src:2:9
+  |
+2 |   write("hi
+  |         ^^^
+
+error[E0101]: syntax error: unexpected token 'unterminatedstring', should be 'false', 'floatnum', 'id', 'intnum', 'minus', 'not', 'openpar', 'plus', 'stringlit', or 'true'
+ --> main.src:2:9
+  |
+2 |   write("hi
+  |         ^^^
+`,
+		},
+		{
+			name: "invalid escape",
+			src:  "func main() -> void {\n  write(\"a\\qb\");\n}",
+			expected: `
+error[E0006]: invalid escape sequence '\q' in string "a\qb"
+ --> main.src:2:9
+  |
+2 |   write("a\qb");
+  |         ^^^^^^
+
+error[E0101]: syntax error: unexpected token 'invalidescape', should be 'false', 'floatnum', 'id', 'intnum', 'minus', 'not', 'openpar', 'plus', 'stringlit', or 'true'
+ --> main.src:2:9
+  |
+2 |   write("a\qb");
+  |         ^^^^^^
+`,
+		},
+		{
+			name: "truncated input",
+			src:  "func main() -> void {\n  write(1",
+			expected: `
+error[E0102]: syntax error: got EOF in the middle of a sentence, expected to find symbols for [and closepar div eq geq gt leq lt minus mult noteq or plus]
+ --> main.src:2:9
+  |
+2 |   write(1
+  |         ^
+`,
+		},
+		{
+			name: "undefined id",
+			src:  "func main() -> void {\n  write(y);\n}",
+			expected: `
+error[E0302]: typecheck: id y was not found within the current scope (line 2)
+ --> main.src:2:9
+  |
+2 |   write(y);
+  |         ^
+`,
+		},
+		{
+			name: "break outside of a loop",
+			src:  "func main() -> void {\n  break;\n}",
+			expected: `
+error[E0311]: typecheck: 'break' is not inside a loop (line 2)
+ --> main.src:2:3
+  |
+2 |   break;
+  |   ^^^^^
+`,
+		},
+		{
+			name: "condition that is not a bool",
+			src:  "func main() -> void {\n  let n: integer;\n  while (n) ;\n}",
+			expected: `
+error[E0312]: typecheck: while condition must be of type Bool but has type Integer (line 3)
+ --> main.src:3:10
+  |
+3 |   while (n) ;
+  |          ^
+`,
+		},
+		{
+			name: "mismatched assignment",
+			src:  "func main() -> void {\n  let n: integer;\n  n = 1.5;\n}",
+			expected: `
+error[E0308]: typecheck: mismatched return type for assignment statement in function 'Global::main()' line 3 left-hand side has type Integer while right-hand side has type Float
+ --> main.src:3:5
+  |
+3 |   n = 1.5;
+  |     ^
+`,
+		},
+		{
+			name: "duplicate definition",
+			src:  "func main() -> void {\n  let x: integer;\n  let x: float;\n}",
+			expected: `
+error[E0201]: duplicate definition for 'x' (defined on line 2, and again on line 3)
+ --> main.src:3:10
+  |
+3 |   let x: float;
+  |          ^^^^^
+  = note: 'x' is first defined here
+ --> main.src:2:10
+  |
+2 |   let x: integer;
+  |          -------
+`,
+		},
+		{
+			name: "warning",
+			src: "" +
+				"struct A { public func f() -> void; };\n" +
+				"struct B inherits A { public func f() -> void; };\n" +
+				"impl A { func f() -> void {} }\n" +
+				"impl B { func f() -> void {} }\n" +
+				"func main() -> void {}",
+			expected: `
+warning[E0211]: shadowing: parent member(s) 'A::f()' shadowed by 'B::f()'
+ --> main.src:2:42
+  |
+2 | struct B inherits A { public func f() -> void; };
+  |                                          ^^^^
+  = note: shadows the member of 'A' defined here
+ --> main.src:1:31
+  |
+1 | struct A { public func f() -> void; };
+  |                               ----
+`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out := new(bytes.Buffer)
+			NewRenderer("main.src", []byte(tc.src)).RenderAll(out, diagnose(tc.src))
+			expected := strings.TrimPrefix(tc.expected, "\n") + "\n"
+			if actual := out.String(); actual != expected {
+				t.Errorf("Expected:\n%v\nbut got:\n%v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRender_NoSource(t *testing.T) {
+	t.Parallel()
+	out := new(bytes.Buffer)
+	NewRenderer("main.src", nil).Render(out, Diagnostic{
+		Code: CODE_UNDEFINED_ID,
+		Msg:  "something went wrong",
+		Span: Span{Line: 4},
+	})
+	expected := "error[E0302]: something went wrong\n --> main.src:4\n\n"
+	if actual := out.String(); actual != expected {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+}
+
+// The span of an error comes from its token, never from its message
+func TestSemanticDiagnostic_NoToken(t *testing.T) {
+	t.Parallel()
+	d := SemanticDiagnostic(&visitors.VisitorError{Msg: "bad things on line 12"})
+	if d.Code != CODE_UNKNOWN || d.Span.Valid() {
+		t.Errorf("Expected an unknown diagnostic without a span but got %+v", d)
+	}
+}
+
+// Runs the front end on the source, collecting diagnostics in the order that
+// they are reported
+func diagnose(src string) []Diagnostic {
+	var ds []Diagnostic
+	scnr := tabledrivenscanner.NewScanner(
+		chuggingcharsource.MustChuggingReader(strings.NewReader(src)),
+		scannertable.TABLE())
+	recorder := &lexicalRecorder{scnr, &ds}
+	prsr := tabledrivenparser.NewParserNoDefaultComments(recorder, parsertable.TABLE(),
+		func(e *tabledrivenparser.ParserError) { ds = append(ds, SyntaxDiagnostic(e)) }, nil)
+	if !prsr.Parse() || len(ds) > 0 {
+		return ds
+	}
+
+	report := func(e *visitors.VisitorError) { ds = append(ds, SemanticDiagnostic(e)) }
+	ast := prsr.AST()
+	ast.Root.Accept(visitors.NewSymTabVisitor(report))
+	ast.Root.Accept(visitors.NewSemCheckVisitor(report))
+	return ds
+}
+
+type lexicalRecorder struct {
+	*tabledrivenscanner.TableDrivenScanner
+	ds *[]Diagnostic
+}
+
+func (r *lexicalRecorder) NextToken() (token.Token, error) {
+	tok, err := r.TableDrivenScanner.NextToken()
+	if err == nil && token.IsError(tok.Id) {
+		*r.ds = append(*r.ds, LexicalDiagnostic(tok))
+	}
+	return tok, err
+}
@@ -0,0 +1,305 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The formats in which diagnostics can be written
+type Format string
+
+const (
+	FORMAT_TEXT  Format = "text"  // Rendered with source snippets, see Renderer
+	FORMAT_JSON  Format = "json"  // An array of Records
+	FORMAT_SARIF Format = "sarif" // A SARIF 2.1.0 log
+)
+
+const (
+	SARIF_VERSION = "2.1.0"
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+	TOOL_NAME     = "esac"
+	TOOL_URI      = "https://github.com/obonobo/compiler"
+)
+
+// The zero value of a Format is the text format
+func (f Format) IsText() bool {
+	return f == "" || f == FORMAT_TEXT
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FORMAT_TEXT, FORMAT_JSON, FORMAT_SARIF:
+		return f, nil
+	case "":
+		return FORMAT_TEXT, nil
+	}
+	return "", fmt.Errorf(
+		"invalid format '%v', should be %v, %v, or %v",
+		s, FORMAT_TEXT, FORMAT_JSON, FORMAT_SARIF)
+}
+
+// Receives the diagnostics of any number of source files and writes them in
+// one of the formats. Text diagnostics are written as soon as they are emitted,
+// the other formats produce a single document that is written by Flush
+type Emitter struct {
+	format    Format
+	out       io.Writer
+	renderers map[string]*Renderer
+	records   []Record
+}
+
+func NewEmitter(format Format, out io.Writer) *Emitter {
+	return &Emitter{
+		format:    format,
+		out:       out,
+		renderers: make(map[string]*Renderer, 1),
+	}
+}
+
+// Registers the text of a source file, so that the diagnostics of the file can
+// be rendered with snippets. The text may be nil
+func (e *Emitter) Source(file string, src []byte) {
+	e.renderers[file] = NewRenderer(file, src)
+}
+
+// Emits a diagnostic for the file
+func (e *Emitter) Emit(file string, d Diagnostic) {
+	if !e.format.IsText() {
+		e.records = append(e.records, NewRecord(file, d))
+		return
+	}
+	r, ok := e.renderers[file]
+	if !ok {
+		r = NewRenderer(file, nil)
+		e.renderers[file] = r
+	}
+	r.Render(e.out, d)
+}
+
+// Writes the document containing all of the emitted diagnostics, does nothing
+// for the text format
+func (e *Emitter) Flush() error {
+	switch e.format {
+	case FORMAT_JSON:
+		return writeJSON(e.out, records(e.records))
+	case FORMAT_SARIF:
+		return writeJSON(e.out, newSarifLog(e.records))
+	}
+	return nil
+}
+
+// A diagnostic along with the file that it belongs to, the JSON format is an
+// array of records
+type Record struct {
+	File      string     `json:"file"`
+	Line      int        `json:"line"`
+	Column    int        `json:"column"`
+	EndColumn int        `json:"endColumn"`
+	Code      Code       `json:"code"`
+	Severity  string     `json:"severity"`
+	Message   string     `json:"message"`
+	Expected  []string   `json:"expected,omitempty"`
+	Related   []Location `json:"related,omitempty"`
+}
+
+// A location that is related to a diagnostic, created from its notes
+type Location struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndColumn int    `json:"endColumn"`
+	Message   string `json:"message"`
+}
+
+func NewRecord(file string, d Diagnostic) Record {
+	start, end := columns(d.Span)
+	r := Record{
+		File:      file,
+		Line:      d.Span.Line,
+		Column:    start,
+		EndColumn: end,
+		Code:      d.Code,
+		Severity:  d.Severity.String(),
+		Message:   d.Msg,
+		Expected:  d.Expected,
+	}
+	for _, note := range d.Notes {
+		start, end := columns(note.Span)
+		r.Related = append(r.Related, Location{
+			File:      file,
+			Line:      note.Span.Line,
+			Column:    start,
+			EndColumn: end,
+			Message:   note.Msg,
+		})
+	}
+	return r
+}
+
+// The end column is exclusive, a span without a column has no columns
+func columns(span Span) (start, end int) {
+	if span.Column == 0 {
+		return 0, 0
+	}
+	length := span.Length
+	if length < 1 {
+		length = 1
+	}
+	return span.Column, span.Column + length
+}
+
+// Records are never written as null
+func records(rs []Record) []Record {
+	if rs == nil {
+		return []Record{}
+	}
+	return rs
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// The subset of SARIF 2.1.0 that is needed to describe diagnostics
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationUri string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		Id               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+
+	sarifResult struct {
+		RuleId           string          `json:"ruleId"`
+		Level            string          `json:"level"`
+		Message          sarifMessage    `json:"message"`
+		Locations        []sarifLocation `json:"locations"`
+		RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+		Properties       *sarifBag       `json:"properties,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		Id               *int                  `json:"id,omitempty"`
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		Uri string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+
+	sarifBag struct {
+		Expected []string `json:"expected,omitempty"`
+	}
+)
+
+func newSarifLog(records []Record) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           TOOL_NAME,
+			InformationUri: TOOL_URI,
+			Rules:          []sarifRule{},
+		}},
+		Results: make([]sarifResult, 0, len(records)),
+	}
+
+	rules := make(map[Code]struct{}, len(records))
+	for _, r := range records {
+		if _, ok := rules[r.Code]; !ok {
+			rules[r.Code] = struct{}{}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				Id:               string(r.Code),
+				ShortDescription: sarifMessage{r.Code.Description()},
+			})
+		}
+
+		result := sarifResult{
+			RuleId:    string(r.Code),
+			Level:     r.Severity,
+			Message:   sarifMessage{r.Message},
+			Locations: []sarifLocation{sarifLocationOf(r.File, r.Line, r.Column, r.EndColumn)},
+		}
+		for i, related := range r.Related {
+			l := sarifLocationOf(related.File, related.Line, related.Column, related.EndColumn)
+			id := i + 1
+			l.Id, l.Message = &id, &sarifMessage{related.Message}
+			result.RelatedLocations = append(result.RelatedLocations, l)
+		}
+		if len(r.Expected) > 0 {
+			result.Properties = &sarifBag{Expected: r.Expected}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].Id < run.Tool.Driver.Rules[j].Id
+	})
+	return sarifLog{Schema: SARIF_SCHEMA, Version: SARIF_VERSION, Runs: []sarifRun{run}}
+}
+
+func sarifLocationOf(file string, line, start, end int) sarifLocation {
+	l := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{Uri: sarifUri(file)},
+	}}
+	if line > 0 {
+		l.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   line,
+			StartColumn: start,
+			EndColumn:   end,
+		}
+	}
+	return l
+}
+
+// Relative paths are valid relative references, absolute paths are turned into
+// file URIs
+func sarifUri(file string) string {
+	uri := filepath.ToSlash(file)
+	if filepath.IsAbs(file) {
+		if !strings.HasPrefix(uri, "/") {
+			uri = "/" + uri
+		}
+		return "file://" + uri
+	}
+	return uri
+}
@@ -0,0 +1,110 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEmitter_JSON(t *testing.T) {
+	t.Parallel()
+	out := new(bytes.Buffer)
+	emitter := NewEmitter(FORMAT_JSON, out)
+	src := "func main() -> void {\n  let x: integer;\n  let x: float;\n}"
+	emitter.Source("main.src", []byte(src))
+	for _, d := range diagnose(src) {
+		emitter.Emit("main.src", d)
+	}
+	emitter.Flush()
+
+	var actual []Record
+	if err := json.Unmarshal(out.Bytes(), &actual); err != nil {
+		t.Fatalf("Expected a JSON array but got %v: %v", err, out)
+	}
+	expected := []Record{{
+		File:      "main.src",
+		Line:      3,
+		Column:    10,
+		EndColumn: 15,
+		Code:      CODE_DUPLICATE_ID,
+		Severity:  "error",
+		Message:   "duplicate definition for 'x' (defined on line 2, and again on line 3)",
+		Related: []Location{{
+			File:      "main.src",
+			Line:      2,
+			Column:    10,
+			EndColumn: 17,
+			Message:   "'x' is first defined here",
+		}},
+	}}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %+v but got %+v", expected, actual)
+	}
+}
+
+func TestEmitter_JSONNoDiagnostics(t *testing.T) {
+	t.Parallel()
+	out := new(bytes.Buffer)
+	NewEmitter(FORMAT_JSON, out).Flush()
+	if expected, actual := "[]\n", out.String(); expected != actual {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+}
+
+func TestEmitter_SARIF(t *testing.T) {
+	t.Parallel()
+	out := new(bytes.Buffer)
+	emitter := NewEmitter(FORMAT_SARIF, out)
+	for _, d := range diagnose("func main() -> void {\n  let x integer;\n}") {
+		emitter.Emit("src/main.src", d)
+	}
+	emitter.Flush()
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Expected a SARIF log but got %v: %v", err, out)
+	}
+	if log.Version != SARIF_VERSION || len(log.Runs) != 1 {
+		t.Fatalf("Expected a single run of version %v but got %v", SARIF_VERSION, out)
+	}
+
+	run := log.Runs[0]
+	if len(run.Results) == 0 {
+		t.Fatalf("Expected results but got %v", out)
+	}
+	result := run.Results[0]
+	if result.RuleId != string(CODE_UNEXPECTED_TOKEN) || result.Level != "error" {
+		t.Errorf("Expected an unexpected token error but got %+v", result)
+	}
+	location := result.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.Uri != "src/main.src" ||
+		location.Region == nil || location.Region.StartLine != 2 || location.Region.StartColumn != 9 {
+		t.Errorf("Expected the error at src/main.src:2:9 but got %+v", location)
+	}
+	if result.Properties == nil || !reflect.DeepEqual(result.Properties.Expected, []string{"colon"}) {
+		t.Errorf("Expected 'colon' to be the expected token but got %+v", result.Properties)
+	}
+
+	rules := map[string]bool{}
+	for _, rule := range run.Tool.Driver.Rules {
+		rules[rule.Id] = true
+	}
+	for _, r := range run.Results {
+		if !rules[r.RuleId] {
+			t.Errorf("Expected rule %v to be described by the driver", r.RuleId)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+	for _, s := range []string{"", "text", "json", "sarif"} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("Expected format '%v' to be valid but got %v", s, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("Expected format 'xml' to be invalid")
+	}
+}
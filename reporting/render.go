@@ -0,0 +1,138 @@
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const TAB_WIDTH = 4
+
+// Renders diagnostics against the text of a single source file. Every
+// diagnostic is printed with its code, the location of its primary span, and
+// the offending line of source with the span underlined:
+//
+//	error[E0302]: typecheck: id x was not found within the current scope (line 3)
+//	  --> main.src:3:3
+//	   |
+//	 3 |   x = 1;
+//	   |   ^
+//
+// Notes that point to another location in the source are printed the same way,
+// with the span underlined with dashes instead of carets
+type Renderer struct {
+	file  string
+	lines []string
+}
+
+// Creates a Renderer for the source file. The file name is only used when
+// printing locations. If src is nil, diagnostics are printed without snippets
+func NewRenderer(file string, src []byte) *Renderer {
+	r := &Renderer{file: file}
+	if src != nil {
+		r.lines = strings.Split(string(bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))), "\n")
+	}
+	return r
+}
+
+// Renders the diagnostic, followed by a blank line
+func (r *Renderer) Render(w io.Writer, d Diagnostic) {
+	width := r.gutterWidth(d)
+	fmt.Fprintf(w, "%v[%v]: %v\n", d.Severity, d.Code, d.Msg)
+	r.snippet(w, width, d.Span, '^')
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%v = note: %v\n", strings.Repeat(" ", width), note.Msg)
+		if note.Span.Valid() {
+			r.snippet(w, width, note.Span, '-')
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// Renders all of the diagnostics
+func (r *Renderer) RenderAll(w io.Writer, ds []Diagnostic) {
+	for _, d := range ds {
+		r.Render(w, d)
+	}
+}
+
+func (r *Renderer) location(span Span) string {
+	switch {
+	case !span.Valid():
+		return r.file
+	case span.Column == 0:
+		return fmt.Sprintf("%v:%v", r.file, span.Line)
+	default:
+		return fmt.Sprintf("%v:%v:%v", r.file, span.Line, span.Column)
+	}
+}
+
+func (r *Renderer) hasSnippet(span Span) bool {
+	return span.Valid() && span.Line <= len(r.lines)
+}
+
+// The gutter is as wide as the largest line number printed for the diagnostic
+func (r *Renderer) gutterWidth(d Diagnostic) int {
+	width := len(strconv.Itoa(d.Span.Line))
+	for _, note := range d.Notes {
+		if w := len(strconv.Itoa(note.Span.Line)); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+func (r *Renderer) snippet(w io.Writer, width int, span Span, underline rune) {
+	if r.file == "" && !span.Valid() {
+		return
+	}
+	pad := strings.Repeat(" ", width)
+	fmt.Fprintf(w, "%v--> %v\n", pad, r.location(span))
+	if !r.hasSnippet(span) {
+		return
+	}
+
+	line, offsets := expandTabs(r.lines[span.Line-1])
+	fmt.Fprintf(w, "%v |\n", pad)
+	fmt.Fprintf(w, "%*d | %v\n", width, span.Line, line)
+	if span.Column == 0 {
+		return
+	}
+
+	// Columns are 1-based, and a span may run past the end of the line (e.g.
+	// the EOF token)
+	start := len([]rune(line))
+	if span.Column-1 < len(offsets) {
+		start = offsets[span.Column-1]
+	}
+	end := start + 1
+	if last := span.Column - 1 + span.Length; span.Length > 1 && last <= len(offsets) {
+		end = offsets[last-1] + 1
+	}
+	fmt.Fprintf(w, "%v | %v%v\n", pad,
+		strings.Repeat(" ", start),
+		strings.Repeat(string(underline), end-start))
+}
+
+// Expands the tabs in the line so that underlines are aligned with the text.
+// Returns the expanded line, and the display column of every rune of the
+// original line
+func expandTabs(line string) (string, []int) {
+	out := new(strings.Builder)
+	offsets := make([]int, 0, len(line))
+	col := 0
+	for _, c := range line {
+		offsets = append(offsets, col)
+		if c == '\t' {
+			n := TAB_WIDTH - col%TAB_WIDTH
+			out.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		out.WriteRune(c)
+		col++
+	}
+	return out.String(), offsets
+}
@@ -102,7 +102,7 @@ func StreamLinesOptionallySplitErrors(
 
 			// Errors tokens may optionally be split into a separate stream
 			if splitErrors && token.IsError(t.Id) {
-				errs <- errorify(t)
+				errs <- Errorify(t)
 			} else {
 				if t.Line != line.n {
 					resetLine(t)
@@ -143,7 +143,7 @@ func StreamTokensSplitErrors(tokens <-chan token.Token) (tokc, errc <-chan strin
 
 		for t := range tokens {
 			if token.IsError(t.Id) {
-				errcc <- errorify(t)
+				errcc <- Errorify(t)
 			} else {
 				if t.Line != line.n {
 					resetLine(t)
@@ -161,7 +161,8 @@ func StreamTokensSplitErrors(tokens <-chan token.Token) (tokc, errc <-chan strin
 	return tokcc, errcc
 }
 
-func errorify(tok token.Token) string {
+// Formats an error token as a lexical error message
+func Errorify(tok token.Token) string {
 	if !token.IsError(tok.Id) {
 		return ""
 	}
@@ -171,6 +172,8 @@ func errorify(tok token.Token) string {
 		token.INVALIDCHAR:         "Invalid character",
 		token.INVALIDNUM:          "Invalid number",
 		token.UNTERMINATEDCOMMENT: "Unterminated comment",
+		token.UNTERMINATEDSTRING:  "Unterminated string",
+		token.INVALIDESCAPE:       "Invalid escape sequence",
 	}
 
 	return fmt.Sprintf(""+